@@ -25,6 +25,9 @@ type Chain struct {
 	root    common.Hash
 
 	mu *sync.RWMutex
+
+	txAddressIndexEnabled bool
+	txAddressIndexMu      *sync.Mutex
 }
 
 // NewChain creates a new Chain instance.
@@ -33,6 +36,8 @@ func NewChain(chainID string, store store.Store, root *core.Block) *Chain {
 		ChainID: chainID,
 		store:   store,
 		mu:      &sync.RWMutex{},
+
+		txAddressIndexMu: &sync.Mutex{},
 	}
 	rootBlock, err := chain.FindBlock(root.Hash())
 	if err != nil {
@@ -137,7 +142,7 @@ func (ch *Chain) FixMissingChildren(block *core.ExtendedBlock) {
 	for _, hash := range block.Children {
 		_, err := ch.findBlock(hash)
 		if err != nil {
-			logger.Warningf("Removing dead link from block $v to block %v", block.Hash().Hex(), hash.Hex())
+			logger.Warningf("Removing dead link from block %v to block %v", block.Hash().Hex(), hash.Hex())
 		} else {
 			newChildren = append(newChildren, hash)
 		}
@@ -338,6 +343,16 @@ func (ch *Chain) SaveBlock(block *core.ExtendedBlock) error {
 	return ch.saveBlock(block)
 }
 
+// CheckWritable verifies that the underlying store accepts writes, by writing and then
+// deleting a probe key.
+func (ch *Chain) CheckWritable() error {
+	key := common.Bytes("health/writeprobe")
+	if err := ch.store.Put(key, uint64(1)); err != nil {
+		return err
+	}
+	return ch.store.Delete(key)
+}
+
 // FindBlock tries to retrieve a block by hash.
 func (ch *Chain) FindBlock(hash common.Hash) (*core.ExtendedBlock, error) {
 	ch.mu.RLock()
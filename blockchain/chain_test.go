@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/store"
 )
 
 func TestBlockchain(t *testing.T) {
@@ -167,3 +168,53 @@ func TestBlockIndex(t *testing.T) {
 	assert.Equal(core.GetTestBlock("a2").Hash(), blocks[0].Hash())
 	assert.Equal(core.GetTestBlock("b2").Hash(), blocks[1].Hash())
 }
+
+func TestFinalityCertificateOfIndirectlyFinalizedBlocks(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	core.ResetTestBlocks()
+
+	ch := CreateTestChainByBlocks([]string{
+		"a1", "a0",
+		"a2", "a1",
+		"a3", "a2",
+		"a4", "a3",
+		"a5", "a4",
+		"a6", "a5",
+	})
+	finalize := func(name, commitName string) {
+		block, err := ch.FindBlock(core.GetTestBlock(name).Hash())
+		require.Nil(err)
+		require.Nil(ch.FinalizePreviousBlocks(block.Hash()))
+		ch.AddFinalityCertificate(&core.FinalityCertificate{
+			Header:       block.BlockHeader,
+			CommitHeader: core.GetTestBlock(commitName).BlockHeader,
+		})
+	}
+	finalize("a2", "a3")
+	finalize("a5", "a6")
+
+	// a1 is covered by the certificate of a2, while a3 and a4 are covered by that of a5
+	for name, certified := range map[string]string{
+		"a1": "a2",
+		"a2": "a2",
+		"a3": "a5",
+		"a4": "a5",
+		"a5": "a5",
+	} {
+		cert, err := ch.FindFinalityCertificate(core.GetTestBlock(name).Height)
+		require.Nil(err, name)
+		assert.Equal(core.GetTestBlock(certified).Hash(), cert.Header.Hash(), name)
+	}
+
+	// Updating the certificate keeps the ancestors pointed to it
+	cert, err := ch.FindFinalityCertificate(core.GetTestBlock("a5").Height)
+	require.Nil(err)
+	ch.AddFinalityCertificate(cert)
+	cert, err = ch.FindFinalityCertificate(core.GetTestBlock("a3").Height)
+	require.Nil(err)
+	assert.Equal(core.GetTestBlock("a5").Hash(), cert.Header.Hash())
+
+	_, err = ch.FindFinalityCertificate(core.GetTestBlock("a6").Height)
+	assert.Equal(store.ErrKeyNotFound, err)
+}
@@ -0,0 +1,77 @@
+package blockchain
+
+import (
+	"encoding/binary"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/store"
+)
+
+// finalityCertificateKey constructs the DB key for the finality certificate at the given height.
+func finalityCertificateKey(height uint64) common.Bytes {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, height)
+	return append(common.Bytes("fc/"), buf...)
+}
+
+// finalityCertificateRefKey constructs the DB key for the height of the certificate covering the
+// indirectly finalized block at the given height.
+func finalityCertificateRefKey(height uint64) common.Bytes {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, height)
+	return append(common.Bytes("fcr/"), buf...)
+}
+
+// AddFinalityCertificate saves the finality certificate of a finalized block. Since there is
+// only one finalized block at each height, the certificate is indexed by the block height. The
+// indirectly finalized ancestors of the block, which have no certificate of their own, are
+// pointed to the certificate of the block.
+func (ch *Chain) AddFinalityCertificate(cert *core.FinalityCertificate) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	height := cert.Header.Height
+	err := ch.store.Put(finalityCertificateKey(height), cert)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	hash := cert.Header.Parent
+	for !hash.IsEmpty() {
+		block, err := ch.findBlock(hash)
+		if err != nil || !block.Status.IsIndirectlyFinalized() {
+			return
+		}
+		var ref uint64
+		if ch.store.Get(finalityCertificateRefKey(block.Height), &ref) == nil {
+			return // the remaining ancestors are already pointed to a certificate
+		}
+		err = ch.store.Put(finalityCertificateRefKey(block.Height), height)
+		if err != nil {
+			logger.Panic(err)
+		}
+		hash = block.Parent
+	}
+}
+
+// FindFinalityCertificate looks up the finality certificate of the finalized block at the given
+// height. For an indirectly finalized block, it returns the certificate of the closest directly
+// finalized descendant, whose header is at a greater height than requested. The block can then
+// be proven by linking it to the certified header through the parent hashes. It returns
+// store.ErrKeyNotFound if the block is not finalized, or is not covered by any certificate.
+func (ch *Chain) FindFinalityCertificate(height uint64) (*core.FinalityCertificate, error) {
+	cert := &core.FinalityCertificate{}
+	err := ch.store.Get(finalityCertificateKey(height), cert)
+	if err == store.ErrKeyNotFound {
+		var ref uint64
+		if ch.store.Get(finalityCertificateRefKey(height), &ref) != nil {
+			return nil, store.ErrKeyNotFound
+		}
+		err = ch.store.Get(finalityCertificateKey(ref), cert)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
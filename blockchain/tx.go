@@ -49,6 +49,11 @@ func (ch *Chain) AddTxsToIndex(block *core.ExtendedBlock, force bool) {
 
 		ch.insertEthTxHash(block, tx, &txIndexEntry)
 	}
+
+	// Only finalized txs are added to the address index, so it never points to txs in a fork
+	if ch.txAddressIndexEnabled && block.Status.IsFinalized() {
+		ch.AddTxsToAddressIndex(block)
+	}
 }
 
 // Index the ETH smart contract transactions, using the ETH tx hash as the key
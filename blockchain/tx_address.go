@@ -0,0 +1,257 @@
+package blockchain
+
+import (
+	"encoding/binary"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store"
+)
+
+// ---------------- Address Tx Index ---------------
+
+// The address tx index is an optional secondary index which maps an address to the finalized
+// transactions touching it. For each address, it maintains
+//   - an entry keyed by (address, height, txIndex), which prevents a tx from being indexed twice
+//   - an append-only list of the indexed txs, which supports pagination without DB iteration
+//   - the number of txs in the list
+//
+// A tx is added by writing the list item, the count and the entry, in this order. If the node
+// stops in between, indexing the tx again either overwrites the list item not counted yet, or
+// finds the tx as the last counted item and only writes the entry, so the list never skips or
+// repeats a tx.
+
+// txAddressIndexKey constructs the DB key for the given (address, height, txIndex) tuple.
+func txAddressIndexKey(address common.Address, height uint64, txIndex uint64) common.Bytes {
+	key := append(common.Bytes("txa/e/"), address[:]...)
+	key = append(key, uint64ToBytes(height)...)
+	key = append(key, uint64ToBytes(txIndex)...)
+	return key
+}
+
+// txAddressListKey constructs the DB key for the seq-th tx in the tx list of the given address.
+func txAddressListKey(address common.Address, seq uint64) common.Bytes {
+	key := append(common.Bytes("txa/l/"), address[:]...)
+	key = append(key, uint64ToBytes(seq)...)
+	return key
+}
+
+// txAddressCountKey constructs the DB key for the number of indexed txs of the given address.
+func txAddressCountKey(address common.Address) common.Bytes {
+	return append(common.Bytes("txa/n/"), address[:]...)
+}
+
+func uint64ToBytes(val uint64) common.Bytes {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, val)
+	return buf
+}
+
+// TxAddressIndexEntry locates a transaction touching an address.
+type TxAddressIndexEntry struct {
+	BlockHash   common.Hash
+	BlockHeight uint64
+	Index       uint64
+	TxHash      common.Hash
+}
+
+// SetTxAddressIndexEnabled sets whether the txs of finalized blocks should be added to the
+// address tx index.
+func (ch *Chain) SetTxAddressIndexEnabled(enabled bool) {
+	ch.txAddressIndexEnabled = enabled
+}
+
+// TxAddressIndexEnabled returns whether the address tx index is enabled.
+func (ch *Chain) TxAddressIndexEnabled() bool {
+	return ch.txAddressIndexEnabled
+}
+
+// AddTxsToAddressIndex adds the transactions in the given block to the address tx index. The
+// block should be finalized. Transactions already indexed are skipped, so it is safe to call
+// multiple times for the same block.
+func (ch *Chain) AddTxsToAddressIndex(block *core.ExtendedBlock) {
+	ch.txAddressIndexMu.Lock()
+	defer ch.txAddressIndexMu.Unlock()
+
+	blockHash := block.Hash()
+	for idx, rawTx := range block.Txs {
+		tx, err := types.TxFromBytes(rawTx)
+		if err != nil {
+			logger.Warnf("Failed to parse tx %v of block %v for the address index: %v", idx, blockHash.Hex(), err)
+			continue
+		}
+		txHash := crypto.Keccak256Hash(rawTx)
+		entry := TxAddressIndexEntry{
+			BlockHash:   blockHash,
+			BlockHeight: block.Height,
+			Index:       uint64(idx),
+			TxHash:      txHash,
+		}
+
+		for _, address := range ch.getTxAddresses(blockHash, txHash, tx) {
+			ch.addTxToAddressIndex(address, entry)
+		}
+	}
+}
+
+func (ch *Chain) addTxToAddressIndex(address common.Address, entry TxAddressIndexEntry) {
+	key := txAddressIndexKey(address, entry.BlockHeight, entry.Index)
+	var seq uint64
+	err := ch.store.Get(key, &seq)
+	if err == nil {
+		return // already indexed
+	}
+	if err != store.ErrKeyNotFound {
+		logger.Panic(err)
+	}
+
+	count := ch.getAddressTxCount(address)
+	if count > 0 && ch.isLastAddressTx(address, count-1, entry) {
+		// The previous attempt was interrupted before writing the entry
+		seq = count - 1
+	} else {
+		seq = count
+		err = ch.store.Put(txAddressListKey(address, seq), entry)
+		if err != nil {
+			logger.Panic(err)
+		}
+		err = ch.store.Put(txAddressCountKey(address), seq+1)
+		if err != nil {
+			logger.Panic(err)
+		}
+	}
+	err = ch.store.Put(key, seq)
+	if err != nil {
+		logger.Panic(err)
+	}
+}
+
+func (ch *Chain) isLastAddressTx(address common.Address, seq uint64, entry TxAddressIndexEntry) bool {
+	last := TxAddressIndexEntry{}
+	err := ch.store.Get(txAddressListKey(address, seq), &last)
+	if err != nil {
+		if err != store.ErrKeyNotFound {
+			logger.Panic(err)
+		}
+		return false
+	}
+	return last == entry
+}
+
+func (ch *Chain) getAddressTxCount(address common.Address) uint64 {
+	var count uint64
+	err := ch.store.Get(txAddressCountKey(address), &count)
+	if err != nil {
+		if err != store.ErrKeyNotFound {
+			logger.Panic(err)
+		}
+		return 0
+	}
+	return count
+}
+
+// FindTxsByAddress returns up to limit entries of the txs touching the given address, skipping
+// the first offset ones, together with the total number of indexed txs of the address. Entries
+// are returned in the order they were indexed, which is the order of the block heights unless
+// the index was backfilled after it had been enabled. If newestFirst is true, the order is reversed.
+func (ch *Chain) FindTxsByAddress(address common.Address, offset, limit uint64, newestFirst bool) ([]*TxAddressIndexEntry, uint64) {
+	total := ch.getAddressTxCount(address)
+	entries := []*TxAddressIndexEntry{}
+	for i := offset; i < total && uint64(len(entries)) < limit; i++ {
+		seq := i
+		if newestFirst {
+			seq = total - 1 - i
+		}
+		entry := &TxAddressIndexEntry{}
+		err := ch.store.Get(txAddressListKey(address, seq), entry)
+		if err != nil {
+			logger.Errorf("Failed to load the address index entry %v of %v: %v", seq, address.Hex(), err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, total
+}
+
+// getTxAddresses returns the addresses touched by the transaction, including the fee payer of a
+// sponsored transaction and the addresses whose balances were changed by smart contract executions.
+func (ch *Chain) getTxAddresses(blockHash common.Hash, txHash common.Hash, tx types.Tx) []common.Address {
+	addresses := []common.Address{}
+	switch tx := tx.(type) {
+	case *types.CoinbaseTx:
+		addresses = append(addresses, tx.Proposer.Address)
+		for _, output := range tx.Outputs {
+			addresses = append(addresses, output.Address)
+		}
+	case *types.SlashTx:
+		addresses = append(addresses, tx.Proposer.Address, tx.SlashedAddress)
+	case *types.SendTx:
+		for _, input := range tx.Inputs {
+			addresses = append(addresses, input.Address)
+		}
+		for _, output := range tx.Outputs {
+			addresses = append(addresses, output.Address)
+		}
+	case *types.ReserveFundTx:
+		addresses = append(addresses, tx.Source.Address)
+	case *types.ReleaseFundTx:
+		addresses = append(addresses, tx.Source.Address)
+	case *types.ServicePaymentTx:
+		addresses = append(addresses, tx.Source.Address, tx.Target.Address)
+	case *types.SplitRuleTx:
+		addresses = append(addresses, tx.Initiator.Address)
+		for _, split := range tx.Splits {
+			addresses = append(addresses, split.Address)
+		}
+	case *types.SmartContractTx:
+		addresses = append(addresses, tx.From.Address, tx.To.Address)
+		if balanceChanges, found := ch.FindTxBalanceChangesByHash(blockHash, txHash); found {
+			addresses = append(addresses, balanceChanges.ContractAddress)
+			for _, balanceChange := range balanceChanges.BalanceChanges {
+				addresses = append(addresses, balanceChange.Address)
+			}
+		}
+	case *types.DepositStakeTx:
+		addresses = append(addresses, tx.Source.Address, tx.Holder.Address)
+	case *types.DepositStakeTxV2:
+		addresses = append(addresses, tx.Source.Address, tx.Holder.Address)
+	case *types.WithdrawStakeTx:
+		addresses = append(addresses, tx.Source.Address, tx.Holder.Address)
+	case *types.WithdrawStakeTxV2:
+		addresses = append(addresses, tx.Source.Address, tx.Holder.Address)
+	case *types.StakeRewardDistributionTx:
+		addresses = append(addresses, tx.Holder.Address, tx.Beneficiary.Address)
+	case *types.RedelegateStakeTx:
+		addresses = append(addresses, tx.Source.Address, tx.FromHolder.Address, tx.ToHolder.Address)
+	case *types.GovernanceProposalTx:
+		addresses = append(addresses, tx.Proposer.Address)
+	case *types.GovernanceVoteTx:
+		addresses = append(addresses, tx.Voter.Address)
+	case *types.GovernanceExecuteTx:
+		addresses = append(addresses, tx.Source.Address)
+	case *types.EvidenceTx:
+		addresses = append(addresses, tx.Proposer.Address)
+		if tx.Evidence != nil {
+			addresses = append(addresses, tx.Evidence.Offender())
+		}
+	}
+
+	// The sponsor paying the fee of the transaction
+	if dtx, ok := tx.(types.TxWithFeeDelegation); ok && dtx.GetFeeDelegation().HasFeePayer() {
+		addresses = append(addresses, *dtx.GetFeeDelegation().FeePayer)
+	}
+
+	// Deduplicate, and skip the empty address, e.g. the To address of a contract deployment
+	seen := make(map[common.Address]bool)
+	uniqueAddresses := []common.Address{}
+	for _, address := range addresses {
+		if (address == common.Address{}) || seen[address] {
+			continue
+		}
+		seen[address] = true
+		uniqueAddresses = append(uniqueAddresses, address)
+	}
+	return uniqueAddresses
+}
@@ -0,0 +1,174 @@
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func TestTxAddressIndex(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	contract := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	carol := common.HexToAddress("0x4444444444444444444444444444444444444444")
+
+	sendTx := &types.SendTx{
+		Fee:     types.NewCoins(0, 1000000000000),
+		Inputs:  []types.TxInput{{Address: alice, Coins: types.NewCoins(10, 1000000000000)}},
+		Outputs: []types.TxOutput{{Coins: types.NewCoins(10, 0)}},
+	}
+	sctx := &types.SmartContractTx{
+		From:     types.TxInput{Coins: types.NewCoins(0, 0)},
+		To:       types.TxOutput{Address: contract},
+		GasLimit: 100000,
+		GasPrice: big.NewInt(4000000000000),
+	}
+	bobKey, _, err := crypto.GenerateKeyPair()
+	require.Nil(err)
+	bob := bobKey.PublicKey().Address()
+	sctx.From.Address = bob
+	sendTx.Outputs[0].Address = bob
+	sig, err := bobKey.Sign(sctx.SignBytes("testchain"))
+	require.Nil(err)
+	sctx.From.Signature = sig
+
+	rawSendTx, err := types.TxToBytes(sendTx)
+	require.Nil(err)
+	rawSctx, err := types.TxToBytes(sctx)
+	require.Nil(err)
+
+	core.ResetTestBlocks()
+	chain := CreateTestChain()
+	chain.SetTxAddressIndexEnabled(true)
+
+	block1 := core.CreateTestBlock("b1", "a0")
+	block1.Height = 1
+	block1.Txs = []common.Bytes{rawSendTx, rawSctx}
+	_, err = chain.AddBlock(block1)
+	require.Nil(err)
+
+	// The contract transferred TFuel to carol
+	balanceChanges := []*types.BalanceChange{{Address: carol, TokenType: 1, Delta: big.NewInt(100)}}
+	chain.AddTxReceipt(block1, sctx, nil, balanceChanges, nil, common.Address{}, 21000, nil)
+
+	// Txs of blocks not finalized yet are not indexed
+	_, total := chain.FindTxsByAddress(alice, 0, 10, false)
+	assert.Equal(uint64(0), total)
+
+	require.Nil(chain.FinalizePreviousBlocks(block1.Hash()))
+
+	entries, total := chain.FindTxsByAddress(alice, 0, 10, false)
+	assert.Equal(uint64(1), total)
+	assert.Equal(crypto.Keccak256Hash(rawSendTx), entries[0].TxHash)
+	assert.Equal(uint64(0), entries[0].Index)
+
+	entries, total = chain.FindTxsByAddress(bob, 0, 10, false)
+	assert.Equal(uint64(2), total)
+	assert.Equal(crypto.Keccak256Hash(rawSendTx), entries[0].TxHash)
+	assert.Equal(crypto.Keccak256Hash(rawSctx), entries[1].TxHash)
+
+	_, total = chain.FindTxsByAddress(contract, 0, 10, false)
+	assert.Equal(uint64(1), total)
+
+	entries, total = chain.FindTxsByAddress(carol, 0, 10, false)
+	assert.Equal(uint64(1), total)
+	assert.Equal(block1.Hash(), entries[0].BlockHash)
+	assert.Equal(uint64(1), entries[0].Index)
+
+	// Indexing the same block again does not create duplicates
+	extBlock1, err := chain.FindBlock(block1.Hash())
+	require.Nil(err)
+	chain.AddTxsToAddressIndex(extBlock1)
+	_, total = chain.FindTxsByAddress(bob, 0, 10, false)
+	assert.Equal(uint64(2), total)
+
+	// Pagination
+	block2 := core.CreateTestBlock("b2", "b1")
+	block2.Height = 2
+	block2.Txs = []common.Bytes{rawSendTx}
+	_, err = chain.AddBlock(block2)
+	require.Nil(err)
+	require.Nil(chain.FinalizePreviousBlocks(block2.Hash()))
+
+	entries, total = chain.FindTxsByAddress(bob, 0, 2, true)
+	assert.Equal(uint64(3), total)
+	assert.Equal(2, len(entries))
+	assert.Equal(uint64(2), entries[0].BlockHeight)
+	assert.Equal(crypto.Keccak256Hash(rawSctx), entries[1].TxHash)
+
+	entries, _ = chain.FindTxsByAddress(bob, 2, 2, true)
+	assert.Equal(1, len(entries))
+	assert.Equal(uint64(1), entries[0].BlockHeight)
+	assert.Equal(uint64(0), entries[0].Index)
+}
+
+func TestTxAddressIndexInterruptedWrites(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	core.ResetTestBlocks()
+	chain := CreateTestChain()
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	entry1 := TxAddressIndexEntry{BlockHeight: 1, Index: 0, TxHash: common.HexToHash("0x01")}
+	entry2 := TxAddressIndexEntry{BlockHeight: 1, Index: 1, TxHash: common.HexToHash("0x02")}
+
+	// Interrupted after the count was written
+	require.Nil(chain.store.Put(txAddressListKey(alice, 0), entry1))
+	require.Nil(chain.store.Put(txAddressCountKey(alice), uint64(1)))
+	chain.addTxToAddressIndex(alice, entry1)
+	chain.addTxToAddressIndex(alice, entry1)
+	entries, total := chain.FindTxsByAddress(alice, 0, 10, false)
+	assert.Equal(uint64(1), total)
+	assert.Equal(entry1, *entries[0])
+
+	// Interrupted after the list item was written
+	require.Nil(chain.store.Put(txAddressListKey(alice, 1), entry1))
+	chain.addTxToAddressIndex(alice, entry2)
+	entries, total = chain.FindTxsByAddress(alice, 0, 10, false)
+	assert.Equal(uint64(2), total)
+	assert.Equal(entry1, *entries[0])
+	assert.Equal(entry2, *entries[1])
+}
+
+func TestTxAddressIndexFeePayer(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	sponsor := common.HexToAddress("0x5555555555555555555555555555555555555555")
+
+	sendTx := &types.SendTx{
+		Fee:     types.NewCoins(0, 1000000000000),
+		Inputs:  []types.TxInput{{Address: alice, Coins: types.NewCoins(10, 0)}},
+		Outputs: []types.TxOutput{{Address: bob, Coins: types.NewCoins(10, 0)}},
+	}
+	sendTx.SetFeePayer(sponsor)
+	rawSendTx, err := types.TxToBytes(sendTx)
+	require.Nil(err)
+
+	core.ResetTestBlocks()
+	chain := CreateTestChain()
+	chain.SetTxAddressIndexEnabled(true)
+
+	block1 := core.CreateTestBlock("b1", "a0")
+	block1.Height = 1
+	block1.Txs = []common.Bytes{rawSendTx}
+	_, err = chain.AddBlock(block1)
+	require.Nil(err)
+	require.Nil(chain.FinalizePreviousBlocks(block1.Hash()))
+
+	for _, address := range []common.Address{alice, bob, sponsor} {
+		entries, total := chain.FindTxsByAddress(address, 0, 10, false)
+		require.Equal(uint64(1), total, address.Hex())
+		assert.Equal(crypto.Keccak256Hash(rawSendTx), entries[0].TxHash)
+	}
+}
@@ -17,7 +17,9 @@ import (
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
 	"github.com/thetatoken/theta/common/util"
+	"github.com/thetatoken/theta/consensus"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/node"
@@ -25,6 +27,7 @@ import (
 	msgl "github.com/thetatoken/theta/p2pl/messenger"
 	"github.com/thetatoken/theta/rlp"
 	"github.com/thetatoken/theta/snapshot"
+	"github.com/thetatoken/theta/store/database"
 	"github.com/thetatoken/theta/store/database/backend"
 	"github.com/thetatoken/theta/store/rollingdb"
 	"github.com/thetatoken/theta/version"
@@ -52,6 +55,11 @@ func runStart(cmd *cobra.Command, args []string) {
 		log.Fatalf("Failed to load or create key: %v", err)
 	}
 
+	// Metrics need to be enabled before the components creating them are initialized
+	if viper.GetBool(common.CfgMetricsPrometheusEnabled) {
+		metrics.Enabled = true
+	}
+
 	// Open database
 	dbPath := viper.GetString(common.CfgDataPath)
 	if dbPath == "" {
@@ -76,6 +84,21 @@ func runStart(cmd *cobra.Command, args []string) {
 		snapshotPath = path.Join(cfgPath, "snapshot")
 	}
 
+	// The fork schedule needs to be activated before any block is processed, including the
+	// ones validated with the snapshot
+	chainID := viper.GetString(common.CfgGenesisChainID)
+	if chainID == "" {
+		if header := snapshot.LoadSnapshotCheckpointHeader(snapshotPath); header != nil {
+			chainID = header.ChainID
+		}
+	}
+	activateForkSchedule(chainID)
+
+	if viper.GetBool(common.CfgSyncHeadersOnly) {
+		runHeaderNode(privKey, chainID, db)
+		return
+	}
+
 	var root *core.Block
 	var snapshotBlockHeader *core.BlockHeader
 	dbSnapshotHeader := &core.BlockHeader{}
@@ -111,6 +134,9 @@ func runStart(cmd *cobra.Command, args []string) {
 	}
 
 	root = &core.Block{BlockHeader: snapshotBlockHeader}
+	if root.ChainID != chainID {
+		log.Fatalf("The snapshot is for chain %v, but the fork schedule of chain %v is activated", root.ChainID, chainID)
+	}
 
 	viper.Set(common.CfgGenesisChainID, root.ChainID)
 
@@ -135,6 +161,11 @@ func runStart(cmd *cobra.Command, args []string) {
 		networkOld = newMessengerOld(privKey, peerSeedsOld, portOld, ctx)
 	}
 
+	signer, err := newSigner(privKey, root.ChainID)
+	if err != nil {
+		log.Fatalf("Failed to create the signer: %v", err)
+	}
+
 	params := &node.Params{
 		ChainID:             root.ChainID,
 		PrivateKey:          privKey,
@@ -146,6 +177,8 @@ func runStart(cmd *cobra.Command, args []string) {
 		SnapshotPath:        snapshotPath,
 		ChainImportDirPath:  chainImportDirPath,
 		ChainCorrectionPath: chainCorrectionPath,
+
+		Signer: signer,
 	}
 
 	n := node.NewNode(params)
@@ -186,6 +219,111 @@ func runStart(cmd *cobra.Command, args []string) {
 	printExitBanner()
 }
 
+// runHeaderNode runs the node in the headers only mode, which syncs the finalized headers without
+// the snapshot, the blocks or the state.
+func runHeaderNode(privKey *crypto.PrivateKey, chainID string, db database.Database) {
+	if chainID == "" {
+		log.Fatalf("The chain ID needs to be configured with %v in the headers only mode", common.CfgGenesisChainID)
+	}
+	viper.Set(common.CfgGenesisChainID, chainID)
+
+	f := func(c rune) bool {
+		return c == ','
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var networkOld *msg.Messenger
+	var network *msgl.Messenger
+	p2pOpt := common.P2POptEnum(viper.GetInt(common.CfgP2POpt))
+	if p2pOpt != common.P2POptOld {
+		port := viper.GetInt(common.CfgP2PLPort)
+		peerSeeds := strings.FieldsFunc(viper.GetString(common.CfgLibP2PSeeds), f)
+		seedPeerOnly := viper.GetBool(common.CfgP2PSeedPeerOnly)
+		network = newMessenger(privKey, peerSeeds, port, seedPeerOnly, ctx)
+	}
+	if p2pOpt != common.P2POptLibp2p {
+		portOld := viper.GetInt(common.CfgP2PPort)
+		peerSeedsOld := strings.FieldsFunc(viper.GetString(common.CfgP2PSeeds), f)
+		networkOld = newMessengerOld(privKey, peerSeedsOld, portOld, ctx)
+	}
+
+	n := node.NewHeaderNode(&node.Params{
+		ChainID:    chainID,
+		PrivateKey: privKey,
+		NetworkOld: networkOld,
+		Network:    network,
+		DB:         db,
+	})
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		<-c
+		signal.Stop(c)
+		cancel()
+		// Wait at most 5 seconds before forcefully shutting down.
+		<-time.After(time.Duration(5) * time.Second)
+		close(done)
+	}()
+
+	n.Start(ctx)
+	log.Infof("Running in the headers only mode")
+
+	go func() {
+		n.Wait()
+		close(done)
+	}()
+
+	<-done
+	log.Infof("")
+	log.Infof("Graceful exit.")
+	printExitBanner()
+}
+
+// activateForkSchedule switches the fork heights to the schedule of the chain. The schedule is read
+// from the fork schedule file if there is one, and otherwise is the built-in one of the chain.
+func activateForkSchedule(chainID string) {
+	schedulePath := viper.GetString(common.CfgGenesisForkSchedulePath)
+	if schedulePath == "" {
+		schedulePath = common.DefaultForkSchedulePath(cfgPath)
+	}
+	schedule, err := common.SetupForkSchedule(chainID, schedulePath)
+	if err != nil {
+		log.Fatalf("Failed to load the fork schedule: %v", err)
+	}
+	log.Infof("Using the fork schedule of chain %v: %+v", chainID, schedule)
+}
+
+// newSigner connects to the remote signer if one is configured, otherwise signs with the node's
+// own key. In the latter case the signing watermarks are kept by the node.
+func newSigner(privKey *crypto.PrivateKey, chainID string) (consensus.Signer, error) {
+	signerAddress := viper.GetString(common.CfgConsensusRemoteSignerAddress)
+	if signerAddress == "" {
+		watermarks, err := consensus.NewWatermarkStore(getSigningWatermarkPath(), chainID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the signing watermarks: %v", err)
+		}
+		return consensus.NewLocalSigner(privKey, watermarks)
+	}
+
+	tlsConfig, err := consensus.LoadSignerTLSConfig(
+		viper.GetString(common.CfgConsensusRemoteSignerCertFile),
+		viper.GetString(common.CfgConsensusRemoteSignerKeyFile),
+		viper.GetString(common.CfgConsensusRemoteSignerCAFile),
+		false)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := consensus.NewRemoteSigner(signerAddress, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Using the remote signer at %v, validator address: %v", signerAddress, signer.Address().Hex())
+	return signer, nil
+}
+
 func loadOrCreateKey() (*crypto.PrivateKey, error) {
 	keyPath := viper.GetString(common.CfgKeyPath)
 	if keyPath == "" {
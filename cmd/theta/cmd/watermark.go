@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"path"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/consensus"
+)
+
+var watermarkChainID string
+var watermarkFile string
+
+// watermarkCmd represents the signing watermark command
+var watermarkCmd = &cobra.Command{
+	Use:   "watermark",
+	Short: "Manage the signing watermarks of the validator keys.",
+	Long:  `Export or import the signing watermarks, e.g. when moving a validator key to another machine.`,
+}
+
+// watermarkExportCmd represents the signing watermark export command
+// Example:
+//		theta watermark export --config=../privatenet/node --chain=privatenet --file=watermark.json
+var watermarkExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the signing watermarks in the interchange format.",
+	Run:   runWatermarkExport,
+}
+
+// watermarkImportCmd represents the signing watermark import command
+// Example:
+//		theta watermark import --config=../privatenet/node --chain=privatenet --file=watermark.json
+var watermarkImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import the signing watermarks in the interchange format, keeping the higher watermark of each key.",
+	Run:   runWatermarkImport,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{watermarkExportCmd, watermarkImportCmd} {
+		cmd.Flags().StringVar(&watermarkChainID, "chain", "", "chain ID (default to the chain ID in the config)")
+		cmd.Flags().StringVar(&watermarkFile, "file", "", "path of the interchange file")
+		cmd.MarkFlagRequired("file")
+		watermarkCmd.AddCommand(cmd)
+	}
+	RootCmd.AddCommand(watermarkCmd)
+}
+
+func runWatermarkExport(cmd *cobra.Command, args []string) {
+	data, err := openSigningWatermarkStore().Export()
+	if err != nil {
+		log.Fatalf("Failed to export the signing watermarks: %v", err)
+	}
+	if err := common.WriteFileAtomic(watermarkFile, data, 0600); err != nil {
+		log.WithFields(log.Fields{"err": err, "path": watermarkFile}).Fatal("Failed to write the signing watermarks")
+	}
+}
+
+func runWatermarkImport(cmd *cobra.Command, args []string) {
+	data, err := ioutil.ReadFile(watermarkFile)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err, "path": watermarkFile}).Fatal("Failed to read the signing watermarks")
+	}
+	if err := openSigningWatermarkStore().Import(data); err != nil {
+		log.Fatalf("Failed to import the signing watermarks: %v", err)
+	}
+}
+
+func openSigningWatermarkStore() *consensus.WatermarkStore {
+	chainID := watermarkChainID
+	if chainID == "" {
+		chainID = viper.GetString(common.CfgGenesisChainID)
+	}
+	if chainID == "" {
+		log.Fatal("Chain ID is required, please specify it with --chain")
+	}
+	watermarks, err := consensus.NewWatermarkStore(getSigningWatermarkPath(), chainID)
+	if err != nil {
+		log.Fatalf("Failed to load the signing watermarks: %v", err)
+	}
+	return watermarks
+}
+
+// getSigningWatermarkPath returns the path of the signing watermark file, which defaults to
+// the config path so that it survives wiping the data path
+func getSigningWatermarkPath() string {
+	watermarkPath := viper.GetString(common.CfgConsensusSigningWatermarkPath)
+	if watermarkPath == "" {
+		watermarkPath = path.Join(cfgPath, "signing_watermark.json")
+	}
+	return watermarkPath
+}
@@ -1,9 +1,6 @@
 package backup
 
 import (
-	"encoding/json"
-	"fmt"
-
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/rpc"
@@ -38,11 +35,11 @@ func doChainCorrectionCmd(cmd *cobra.Command, args []string) {
 	if res.Error != nil {
 		utils.Error("Failed to get backup chain res details: %v\n", res.Error)
 	}
-	json, err := json.MarshalIndent(res.Result, "", "    ")
-	if err != nil {
-		utils.Error("Failed to parse server response: %v\n%v\n", err, string(json))
+	result := &rpc.BackupChainCorrectionResult{}
+	if err = res.GetObject(result); err != nil {
+		utils.Error("Failed to parse server response: %v\n", err)
 	}
-	fmt.Println(string(json))
+	waitForBackupJob(client, result.JobID)
 }
 
 func init() {
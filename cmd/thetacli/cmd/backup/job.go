@@ -0,0 +1,49 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/rpc"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// waitForBackupJob polls the status of the backup job until it finishes, reporting the
+// progress on stderr, and prints the final status of the job.
+func waitForBackupJob(client *rpcc.RPCClient, jobID string) {
+	fmt.Fprintf(os.Stderr, "Backup job %v started\n", jobID)
+
+	for {
+		res, err := client.Call("theta.GetBackupJobStatus", rpc.GetBackupJobStatusArgs{JobID: jobID})
+		if err != nil {
+			utils.Error("Failed to get backup job status call details: %v\n", err)
+		}
+		if res.Error != nil {
+			utils.Error("Failed to get backup job status res details: %v\n", res.Error)
+		}
+		status := &rpc.GetBackupJobStatusResult{}
+		if err = res.GetObject(status); err != nil {
+			utils.Error("Failed to parse server response: %v\n", err)
+		}
+
+		if status.Status != rpc.BackupJobStatusRunning {
+			json, err := json.MarshalIndent(status, "", "    ")
+			if err != nil {
+				utils.Error("Failed to parse server response: %v\n%v\n", err, string(json))
+			}
+			fmt.Println(string(json))
+			if status.Status != rpc.BackupJobStatusCompleted {
+				os.Exit(1)
+			}
+			return
+		}
+
+		fmt.Fprintf(os.Stderr, "%v: %.1f%% done, stage: %v, height: %v, bytes written: %v, elapsed: %vs, eta: %vs\n",
+			jobID, status.Progress*100, status.Stage, status.CurrentHeight, status.BytesWritten, status.ElapsedSecs, status.ETASecs)
+		time.Sleep(time.Duration(pollIntervalFlag) * time.Second)
+	}
+}
@@ -7,6 +7,8 @@ var (
 	versionFlag uint64
 	hashFlag    string
 	configFlag  string
+
+	pollIntervalFlag uint64
 )
 
 // BackupCmd represents the backup command
@@ -20,4 +22,6 @@ func init() {
 	BackupCmd.AddCommand(chainCmd)
 	BackupCmd.AddCommand(snapshotCmd)
 	BackupCmd.AddCommand(chainCorrectionCmd)
+
+	BackupCmd.PersistentFlags().Uint64Var(&pollIntervalFlag, "poll_interval", 5, "Interval in seconds between two backup job status queries")
 }
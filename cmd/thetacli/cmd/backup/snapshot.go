@@ -1,9 +1,6 @@
 package backup
 
 import (
-	"encoding/json"
-	"fmt"
-
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/rpc"
 
@@ -33,11 +30,11 @@ func doSnapshotCmd(cmd *cobra.Command, args []string) {
 	if res.Error != nil {
 		utils.Error("Failed to get backup snapshot res details: %v\n", res.Error)
 	}
-	json, err := json.MarshalIndent(res.Result, "", "    ")
-	if err != nil {
-		utils.Error("Failed to parse server response: %v\n%v\n", err, string(json))
+	result := &rpc.BackupSnapshotResult{}
+	if err = res.GetObject(result); err != nil {
+		utils.Error("Failed to parse server response: %v\n", err)
 	}
-	fmt.Println(string(json))
+	waitForBackupJob(client, result.JobID)
 }
 
 func init() {
@@ -20,6 +20,9 @@ var (
 	sourceFlag                   string
 	holderFlag                   string
 	withdrawnOnlyFlag            bool
+	offsetFlag                   uint64
+	limitFlag                    uint64
+	ascendingFlag                bool
 )
 
 // QueryCmd represents the query command
@@ -35,6 +38,7 @@ func init() {
 	QueryCmd.AddCommand(blockCmd)
 	QueryCmd.AddCommand(traceBlocksCmd)
 	QueryCmd.AddCommand(txCmd)
+	QueryCmd.AddCommand(txsCmd)
 	QueryCmd.AddCommand(splitRuleCmd)
 	QueryCmd.AddCommand(vcpCmd)
 	QueryCmd.AddCommand(gcpCmd)
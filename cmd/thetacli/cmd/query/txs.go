@@ -0,0 +1,54 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// txsCmd represents the query txs command.
+// Example:
+//		thetacli query txs --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab --offset=0 --limit=20
+//
+var txsCmd = &cobra.Command{
+	Use:     "txs",
+	Short:   "Get transactions touching an address",
+	Long:    `Get the finalized transactions touching an address, newest first unless --ascending is set. Requires the address tx index to be enabled on the node.`,
+	Example: `thetacli query txs --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab --offset=0 --limit=20`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+		res, err := client.Call("theta.GetTransactionsByAddress", rpc.GetTransactionsByAddressArgs{
+			Address:   addressFlag,
+			Offset:    common.JSONUint64(offsetFlag),
+			Limit:     common.JSONUint64(limitFlag),
+			Ascending: ascendingFlag,
+		})
+
+		if err != nil {
+			utils.Error("Failed to get transactions: %v\n", err)
+		}
+		if res.Error != nil {
+			utils.Error("Failed to retrieve transactions: %v\n", res.Error)
+		}
+		json, err := json.MarshalIndent(res.Result, "", "    ")
+		if err != nil {
+			utils.Error("Failed to parse server response: %v\n%v\n", err, string(json))
+		}
+		fmt.Println(string(json))
+	},
+}
+
+func init() {
+	txsCmd.Flags().StringVar(&addressFlag, "address", "", "Address touched by the transactions")
+	txsCmd.Flags().Uint64Var(&offsetFlag, "offset", uint64(0), "Number of transactions to skip")
+	txsCmd.Flags().Uint64Var(&limitFlag, "limit", uint64(20), "Maximum number of transactions to return")
+	txsCmd.Flags().BoolVar(&ascendingFlag, "ascending", false, "Return the oldest transactions first")
+	txsCmd.MarkFlagRequired("address")
+}
@@ -16,15 +16,17 @@ import (
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/key"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/query"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/tx"
+	"github.com/thetatoken/theta/common"
 )
 
 var cfgPath string
 
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
-	Use:   "thetacli",
-	Short: "Theta wallet",
-	Long:  `Theta wallet.`,
+	Use:              "thetacli",
+	Short:            "Theta wallet",
+	Long:             `Theta wallet.`,
+	PersistentPreRun: activateForkSchedule,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -66,6 +68,20 @@ func initConfig() {
 	}
 }
 
+// activateForkSchedule switches the fork heights to the schedule of the chain given by the --chain
+// flag of the command, so the blocks and txs are encoded the same way as by the nodes of the chain.
+func activateForkSchedule(cmd *cobra.Command, args []string) {
+	chainFlag := cmd.Flags().Lookup("chain")
+	if chainFlag == nil || chainFlag.Value.String() == "" {
+		return
+	}
+	_, err := common.SetupForkSchedule(chainFlag.Value.String(), common.DefaultForkSchedulePath(cfgPath))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
 func getDefaultConfigPath() string {
 	home, err := homedir.Dir()
 	if err != nil {
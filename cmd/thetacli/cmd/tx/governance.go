@@ -0,0 +1,200 @@
+package tx
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// governanceProposeCmd represents the governance proposal command
+// Example:
+//		thetacli tx gov_propose --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --params=min_gas_price=5000000000000,max_tx_gas_limit=30000000 --seq=10
+var governanceProposeCmd = &cobra.Command{
+	Use:     "gov_propose",
+	Short:   "Propose changes to the governable protocol parameters",
+	Long:    `Propose changes to the governable protocol parameters. Only validator or guardian stake holders can submit proposals.`,
+	Example: `thetacli tx gov_propose --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --params=min_gas_price=5000000000000,max_tx_gas_limit=30000000 --seq=10`,
+	Run:     doGovernanceProposeCmd,
+}
+
+// governanceVoteCmd represents the governance vote command
+// Example:
+//		thetacli tx gov_vote --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --proposal_id=0 --approve=true --seq=11
+var governanceVoteCmd = &cobra.Command{
+	Use:     "gov_vote",
+	Short:   "Vote on a governance proposal",
+	Long:    `Vote on a governance proposal. The vote is weighted by the validator and guardian stake held by the voter.`,
+	Example: `thetacli tx gov_vote --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --proposal_id=0 --approve=true --seq=11`,
+	Run:     doGovernanceVoteCmd,
+}
+
+// governanceExecuteCmd represents the governance execution command
+// Example:
+//		thetacli tx gov_execute --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --proposal_id=0 --seq=12
+var governanceExecuteCmd = &cobra.Command{
+	Use:     "gov_execute",
+	Short:   "Apply the parameter changes of an approved governance proposal",
+	Long:    `Apply the parameter changes of an approved governance proposal. The new values take effect from the next block.`,
+	Example: `thetacli tx gov_execute --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --proposal_id=0 --seq=12`,
+	Run:     doGovernanceExecuteCmd,
+}
+
+func doGovernanceProposeCmd(cmd *cobra.Command, args []string) {
+	changes := []types.ParamChange{}
+	for _, param := range paramsFlag {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			utils.Error("Failed to parse parameter change %v, expected name=value\n", param)
+		}
+		value, ok := new(big.Int).SetString(kv[1], 10)
+		if !ok {
+			utils.Error("Failed to parse the value of parameter %v\n", kv[0])
+		}
+		changes = append(changes, types.ParamChange{Name: kv[0], Value: value})
+	}
+	if err := types.ValidateParamChanges(changes); err != nil {
+		utils.Error("Invalid proposal: %v\n", err)
+	}
+
+	broadcastGovernanceTx(cmd, func(address common.Address, fee *big.Int) types.Tx {
+		return &types.GovernanceProposalTx{
+			Fee: types.Coins{
+				ThetaWei: new(big.Int).SetUint64(0),
+				TFuelWei: fee,
+			},
+			Proposer: types.TxInput{
+				Address:  address,
+				Sequence: uint64(seqFlag),
+			},
+			Changes: changes,
+		}
+	})
+}
+
+func doGovernanceVoteCmd(cmd *cobra.Command, args []string) {
+	broadcastGovernanceTx(cmd, func(address common.Address, fee *big.Int) types.Tx {
+		return &types.GovernanceVoteTx{
+			Fee: types.Coins{
+				ThetaWei: new(big.Int).SetUint64(0),
+				TFuelWei: fee,
+			},
+			Voter: types.TxInput{
+				Address:  address,
+				Sequence: uint64(seqFlag),
+			},
+			ProposalID: proposalIDFlag,
+			Approve:    approveFlag,
+		}
+	})
+}
+
+func doGovernanceExecuteCmd(cmd *cobra.Command, args []string) {
+	broadcastGovernanceTx(cmd, func(address common.Address, fee *big.Int) types.Tx {
+		return &types.GovernanceExecuteTx{
+			Fee: types.Coins{
+				ThetaWei: new(big.Int).SetUint64(0),
+				TFuelWei: fee,
+			},
+			Source: types.TxInput{
+				Address:  address,
+				Sequence: uint64(seqFlag),
+			},
+			ProposalID: proposalIDFlag,
+		}
+	})
+}
+
+func broadcastGovernanceTx(cmd *cobra.Command, makeTx func(address common.Address, fee *big.Int) types.Tx) {
+	wallet, address, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil {
+		return
+	}
+	defer wallet.Lock(address)
+
+	fee, ok := types.ParseCoinAmount(feeFlag)
+	if !ok {
+		utils.Error("Failed to parse fee")
+	}
+
+	tx := makeTx(address, fee)
+	sig, err := wallet.Sign(address, tx.SignBytes(chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	setGovernanceTxSignature(tx, address, sig)
+
+	raw, err := types.TxToBytes(tx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	signedTx := hex.EncodeToString(raw)
+
+	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	var res *rpcc.RPCResponse
+	if asyncFlag {
+		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+	} else {
+		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+	}
+	if err != nil {
+		utils.Error("Failed to broadcast transaction: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Server returned error: %v\n", res.Error)
+	}
+	fmt.Printf("Successfully broadcasted transaction.\n")
+}
+
+func setGovernanceTxSignature(tx types.Tx, address common.Address, sig *crypto.Signature) {
+	switch tx := tx.(type) {
+	case *types.GovernanceProposalTx:
+		tx.SetSignature(address, sig)
+	case *types.GovernanceVoteTx:
+		tx.SetSignature(address, sig)
+	case *types.GovernanceExecuteTx:
+		tx.SetSignature(address, sig)
+	}
+}
+
+func addGovernanceTxFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	cmd.Flags().StringVar(&fromFlag, "from", "", "Address of the account submitting the transaction")
+	cmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	cmd.Flags().StringVar(&feeFlag, "fee", fmt.Sprintf("%dwei", types.MinimumTransactionFeeTFuelWeiJune2021), "Fee")
+	cmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
+	cmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	cmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	cmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	cmd.MarkFlagRequired("chain")
+	cmd.MarkFlagRequired("from")
+	cmd.MarkFlagRequired("seq")
+}
+
+func init() {
+	addGovernanceTxFlags(governanceProposeCmd)
+	governanceProposeCmd.Flags().StringSliceVar(&paramsFlag, "params", []string{}, "List of parameter changes in the form of name=value")
+	governanceProposeCmd.MarkFlagRequired("params")
+
+	addGovernanceTxFlags(governanceVoteCmd)
+	governanceVoteCmd.Flags().Uint64Var(&proposalIDFlag, "proposal_id", 0, "ID of the proposal")
+	governanceVoteCmd.Flags().BoolVar(&approveFlag, "approve", false, "Whether to approve the proposal")
+	governanceVoteCmd.MarkFlagRequired("proposal_id")
+	governanceVoteCmd.MarkFlagRequired("approve")
+
+	addGovernanceTxFlags(governanceExecuteCmd)
+	governanceExecuteCmd.Flags().Uint64Var(&proposalIDFlag, "proposal_id", 0, "ID of the proposal")
+	governanceExecuteCmd.MarkFlagRequired("proposal_id")
+}
@@ -29,13 +29,21 @@ var (
 	dataFlag                     string
 	walletFlag                   string
 	stakeInThetaFlag             string
+	amountFlag                   string
 	purposeFlag                  uint8
 	sourceFlag                   string
 	holderFlag                   string
+	fromHolderFlag               string
+	toHolderFlag                 string
 	asyncFlag                    bool
 	beneficiaryFlag              string
 	splitBasisPointFlag          uint64
 	passwordFlag                 string
+	validFromFlag                uint64
+	validUntilFlag               uint64
+	paramsFlag                   []string
+	proposalIDFlag               uint64
+	approveFlag                  bool
 )
 
 // TxCmd represents the Tx command
@@ -53,5 +61,9 @@ func init() {
 	TxCmd.AddCommand(smartContractCmd)
 	TxCmd.AddCommand(depositStakeCmd)
 	TxCmd.AddCommand(withdrawStakeCmd)
+	TxCmd.AddCommand(redelegateStakeCmd)
 	TxCmd.AddCommand(stakeRewardDistributionCmd)
+	TxCmd.AddCommand(governanceProposeCmd)
+	TxCmd.AddCommand(governanceVoteCmd)
+	TxCmd.AddCommand(governanceExecuteCmd)
 }
@@ -0,0 +1,157 @@
+package tx
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/crypto/bls"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// redelegateStakeCmd represents the redelegate stake command
+// Example:
+//		thetacli tx redelegate --chain="privatenet" --source=2E833968E5bB786Ae419c4d13189fB081Cc43bab --from_holder=70f587259738cB626A1720Af7038B8DcDb6a42a0 --to_holder=<guardian summary> --purpose=1 --seq=9
+var redelegateStakeCmd = &cobra.Command{
+	Use:     "redelegate",
+	Short:   "Move stake from a guardian or elite edge node to another one without unbonding",
+	Long:    `Move stake from a guardian or elite edge node to another one without unbonding. The new holder can be specified by its address if it already has stake, otherwise its summary is required.`,
+	Example: `thetacli tx redelegate --chain="privatenet" --source=2E833968E5bB786Ae419c4d13189fB081Cc43bab --from_holder=70f587259738cB626A1720Af7038B8DcDb6a42a0 --to_holder=<guardian summary> --purpose=1 --seq=9`,
+	Run:     doRedelegateStakeCmd,
+}
+
+func doRedelegateStakeCmd(cmd *cobra.Command, args []string) {
+	wallet, sourceAddress, err := walletUnlockWithPath(cmd, sourceFlag, pathFlag, passwordFlag)
+	if err != nil {
+		return
+	}
+	defer wallet.Lock(sourceAddress)
+
+	fee, ok := types.ParseCoinAmount(feeFlag)
+	if !ok {
+		utils.Error("Failed to parse fee")
+	}
+
+	if purposeFlag != core.StakeForGuardian && purposeFlag != core.StakeForEliteEdgeNode {
+		utils.Error("Only guardian or elite edge node stakes can be redelegated")
+	}
+
+	redelegateStakeTx := &types.RedelegateStakeTx{
+		Fee: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: fee,
+		},
+		Source: types.TxInput{
+			Address:  sourceAddress,
+			Sequence: uint64(seqFlag),
+		},
+		FromHolder: types.TxOutput{
+			Address: common.HexToAddress(fromHolderFlag),
+		},
+		Purpose: purposeFlag,
+	}
+
+	// Parse the to holder flag, which is either an address or a holder summary
+	toHolder := strings.TrimPrefix(toHolderFlag, "0x")
+	if len(toHolder) == 40 {
+		redelegateStakeTx.ToHolder = types.TxOutput{
+			Address: common.HexToAddress(toHolder),
+		}
+	} else {
+		if purposeFlag == core.StakeForGuardian && len(toHolder) != 458 {
+			utils.Error("to_holder must be a valid address or guardian summary")
+		}
+		if purposeFlag == core.StakeForEliteEdgeNode && len(toHolder) != 522 {
+			utils.Error("to_holder must be a valid address or elite edge node summary")
+		}
+		summaryBytes, err := hex.DecodeString(toHolder)
+		if err != nil {
+			utils.Error("Failed to decode holder summary: %v\n", err)
+		}
+		blsPubkey, err := bls.PublicKeyFromBytes(summaryBytes[20:68])
+		if err != nil {
+			utils.Error("Failed to decode bls Pubkey: %v\n", err)
+		}
+		blsPop, err := bls.SignatureFromBytes(summaryBytes[68:164])
+		if err != nil {
+			utils.Error("Failed to decode bls POP: %v\n", err)
+		}
+		holderSig, err := crypto.SignatureFromBytes(summaryBytes[164:229])
+		if err != nil {
+			utils.Error("Failed to decode signature: %v\n", err)
+		}
+		if purposeFlag == core.StakeForEliteEdgeNode {
+			expectedSummaryHash := crypto.Keccak256Hash([]byte("0x" + toHolder[:458])).Hex()
+			summaryHash := hex.EncodeToString(summaryBytes[229:])
+			if expectedSummaryHash[2:] != summaryHash {
+				utils.Error("Failed to verify elite edge node summary: unmatched summary hash - %v vs %v\n",
+					expectedSummaryHash, summaryHash)
+			}
+		}
+
+		redelegateStakeTx.ToHolder = types.TxOutput{
+			Address: common.BytesToAddress(summaryBytes[:20]),
+		}
+		redelegateStakeTx.BlsPubkey = blsPubkey
+		redelegateStakeTx.BlsPop = blsPop
+		redelegateStakeTx.HolderSig = holderSig
+	}
+
+	sig, err := wallet.Sign(sourceAddress, redelegateStakeTx.SignBytes(chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	redelegateStakeTx.SetSignature(sourceAddress, sig)
+
+	raw, err := types.TxToBytes(redelegateStakeTx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	signedTx := hex.EncodeToString(raw)
+
+	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	var res *rpcc.RPCResponse
+	if asyncFlag {
+		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+	} else {
+		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+	}
+	if err != nil {
+		utils.Error("Failed to broadcast transaction: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Server returned error: %v\n", res.Error)
+	}
+	fmt.Printf("Successfully broadcasted transaction.\n")
+}
+
+func init() {
+	redelegateStakeCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	redelegateStakeCmd.Flags().StringVar(&sourceFlag, "source", "", "Source of the stake")
+	redelegateStakeCmd.Flags().StringVar(&fromHolderFlag, "from_holder", "", "Current holder of the stake")
+	redelegateStakeCmd.Flags().StringVar(&toHolderFlag, "to_holder", "", "Address or summary of the new holder of the stake")
+	redelegateStakeCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	redelegateStakeCmd.Flags().StringVar(&feeFlag, "fee", fmt.Sprintf("%dwei", types.MinimumTransactionFeeTFuelWeiJune2021), "Fee")
+	redelegateStakeCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
+	redelegateStakeCmd.Flags().Uint8Var(&purposeFlag, "purpose", core.StakeForGuardian, "Purpose of staking")
+	redelegateStakeCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	redelegateStakeCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	redelegateStakeCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	redelegateStakeCmd.MarkFlagRequired("chain")
+	redelegateStakeCmd.MarkFlagRequired("source")
+	redelegateStakeCmd.MarkFlagRequired("from_holder")
+	redelegateStakeCmd.MarkFlagRequired("to_holder")
+	redelegateStakeCmd.MarkFlagRequired("seq")
+}
@@ -87,6 +87,7 @@ func doSendCmd(cmd *cobra.Command, args []string) {
 		Inputs:  inputs,
 		Outputs: outputs,
 	}
+	sendTx.SetValidityWindow(validFromFlag, validUntilFlag)
 
 	sig, err := wallet.Sign(fromAddress, sendTx.SignBytes(chainIDFlag))
 	if err != nil {
@@ -139,6 +140,8 @@ func init() {
 	sendCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano|trezor)")
 	sendCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
 	sendCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+	sendCmd.Flags().Uint64Var(&validFromFlag, "valid_from", 0, "Block height from which the transaction can be included (0 for no lower bound)")
+	sendCmd.Flags().Uint64Var(&validUntilFlag, "valid_until", 0, "Block height after which the transaction expires (0 for no expiry)")
 
 	sendCmd.MarkFlagRequired("chain")
 	//sendCmd.MarkFlagRequired("from")
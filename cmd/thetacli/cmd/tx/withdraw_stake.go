@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/ledger/types"
 	"github.com/thetatoken/theta/rpc"
 
@@ -18,9 +19,11 @@ import (
 // withdrawStakeCmd represents the withdraw stake command
 // Example:
 //		thetacli tx withdraw --chain="privatenet" --source=2E833968E5bB786Ae419c4d13189fB081Cc43bab --holder=2E833968E5bB786Ae419c4d13189fB081Cc43bab --purpose=0 --seq=8
+//		thetacli tx withdraw --chain="privatenet" --source=2E833968E5bB786Ae419c4d13189fB081Cc43bab --holder=2E833968E5bB786Ae419c4d13189fB081Cc43bab --purpose=0 --amount=200000 --seq=8
 var withdrawStakeCmd = &cobra.Command{
 	Use:     "withdraw",
 	Short:   "withdraw stake to a validator or guardian",
+	Long:    `withdraw stake to a validator or guardian. The whole stake is withdrawn unless an amount is specified.`,
 	Example: `thetacli tx withdraw --chain="privatenet" --source=2E833968E5bB786Ae419c4d13189fB081Cc43bab --holder=2E833968E5bB786Ae419c4d13189fB081Cc43bab --purpose=0 --seq=8`,
 	Run:     doWithdrawStakeCmd,
 }
@@ -45,21 +48,48 @@ func doWithdrawStakeCmd(cmd *cobra.Command, args []string) {
 		Address: common.HexToAddress(holderFlag),
 	}
 
-	withdrawStakeTx := &types.WithdrawStakeTx{
-		Fee: types.Coins{
-			ThetaWei: new(big.Int).SetUint64(0),
-			TFuelWei: fee,
-		},
-		Source:  source,
-		Holder:  holder,
-		Purpose: purposeFlag,
+	var withdrawStakeTx types.Tx
+	if amountFlag == "" {
+		withdrawStakeTx = &types.WithdrawStakeTx{
+			Fee: types.Coins{
+				ThetaWei: new(big.Int).SetUint64(0),
+				TFuelWei: fee,
+			},
+			Source:  source,
+			Holder:  holder,
+			Purpose: purposeFlag,
+		}
+	} else {
+		amount, ok := types.ParseCoinAmount(amountFlag)
+		if !ok || amount.Sign() <= 0 {
+			utils.Error("Failed to parse amount")
+		}
+		withdrawAmount := types.Coins{ThetaWei: amount, TFuelWei: new(big.Int).SetUint64(0)}
+		if purposeFlag == core.StakeForEliteEdgeNode { // elite edge nodes stake TFuel
+			withdrawAmount = types.Coins{ThetaWei: new(big.Int).SetUint64(0), TFuelWei: amount}
+		}
+		withdrawStakeTx = &types.WithdrawStakeTxV2{
+			Fee: types.Coins{
+				ThetaWei: new(big.Int).SetUint64(0),
+				TFuelWei: fee,
+			},
+			Source:  source,
+			Holder:  holder,
+			Purpose: purposeFlag,
+			Amount:  withdrawAmount,
+		}
 	}
 
 	sig, err := wallet.Sign(sourceAddress, withdrawStakeTx.SignBytes(chainIDFlag))
 	if err != nil {
 		utils.Error("Failed to sign transaction: %v\n", err)
 	}
-	withdrawStakeTx.SetSignature(sourceAddress, sig)
+	switch tx := withdrawStakeTx.(type) {
+	case *types.WithdrawStakeTx:
+		tx.SetSignature(sourceAddress, sig)
+	case *types.WithdrawStakeTxV2:
+		tx.SetSignature(sourceAddress, sig)
+	}
 
 	raw, err := types.TxToBytes(withdrawStakeTx)
 	if err != nil {
@@ -92,6 +122,7 @@ func init() {
 	withdrawStakeCmd.Flags().StringVar(&feeFlag, "fee", fmt.Sprintf("%dwei", types.MinimumTransactionFeeTFuelWeiJune2021), "Fee")
 	withdrawStakeCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
 	withdrawStakeCmd.Flags().Uint8Var(&purposeFlag, "purpose", 0, "Purpose of staking")
+	withdrawStakeCmd.Flags().StringVar(&amountFlag, "amount", "", "Amount of stake to withdraw, the whole stake if not specified")
 	withdrawStakeCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
 	withdrawStakeCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
 	withdrawStakeCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
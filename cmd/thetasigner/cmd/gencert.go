@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/consensus"
+)
+
+const certValidity = 10 * 365 * 24 * time.Hour
+
+var outFlag string
+
+// gencertCmd represents the gencert command
+// Example:
+//		thetasigner gencert --config=~/.thetasigner
+var gencertCmd = &cobra.Command{
+	Use:   "gencert",
+	Short: "Generate the TLS certificates for the signer and the node",
+	Long: `Generate a CA, and the certificates issued by it for the signer (signer.crt/signer.key) and the
+node (node.crt/node.key). Copy ca.crt, node.crt and node.key to the node, and configure them as
+consensus.remoteSigner.caFile, certFile and keyFile. Keep ca.key offline.`,
+	Example: `thetasigner gencert --config=~/.thetasigner`,
+	Run:     runGencert,
+}
+
+func init() {
+	gencertCmd.Flags().StringVar(&outFlag, "out", "", "Output folder (default to <config>/tls)")
+	RootCmd.AddCommand(gencertCmd)
+}
+
+func runGencert(cmd *cobra.Command, args []string) {
+	out := withDefault(outFlag, "tls")
+	if err := os.MkdirAll(out, 0700); err != nil {
+		log.Fatalf("Failed to create %v: %v", out, err)
+	}
+
+	caKey, caCert := generateCert(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "thetasigner CA"},
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	writeCert(path.Join(out, "ca"), caKey, caCert)
+
+	signerKey, signerCert := generateCert(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: consensus.SignerTLSServerName},
+		DNSNames:    []string{consensus.SignerTLSServerName},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, caKey, caCert)
+	writeCert(path.Join(out, "signer"), signerKey, signerCert)
+
+	nodeKey, nodeCert := generateCert(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: "theta node"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caKey, caCert)
+	writeCert(path.Join(out, "node"), nodeKey, nodeCert)
+
+	log.Infof("Generated the certificates under %v", out)
+}
+
+// generateCert issues the certificate with the parent, or self-signs it if the parent is nil
+func generateCert(template *x509.Certificate, parentKey *ecdsa.PrivateKey, parent *x509.Certificate) (*ecdsa.PrivateKey, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		log.Fatalf("Failed to generate key: %v", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		log.Fatalf("Failed to generate serial number: %v", err)
+	}
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(certValidity)
+
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		log.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		log.Fatalf("Failed to parse certificate: %v", err)
+	}
+	return key, cert
+}
+
+func writeCert(basePath string, key *ecdsa.PrivateKey, cert *x509.Certificate) {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		log.Fatalf("Failed to encode key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := common.WriteFileAtomic(basePath+".key", keyPEM, 0600); err != nil {
+		log.Fatalf("Failed to write %v.key: %v", basePath, err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	if err := common.WriteFileAtomic(basePath+".crt", certPEM, 0644); err != nil {
+		log.Fatalf("Failed to write %v.crt: %v", basePath, err)
+	}
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/consensus"
+	"github.com/thetatoken/theta/crypto"
+	ks "github.com/thetatoken/theta/wallet/softwallet/keystore"
+)
+
+var chainIDFlag string
+var listenFlag string
+var passwordFlag string
+var watermarkFlag string
+var certFlag string
+var keyFlag string
+var caFlag string
+var forkScheduleFlag string
+
+// startCmd represents the start command
+// Example:
+//		thetasigner start --config=~/.thetasigner --chain=mainnet --listen=unix:///var/run/thetasigner.sock
+var startCmd = &cobra.Command{
+	Use:     "start",
+	Short:   "Start the signer",
+	Long:    `Start the signer with the validator key under <config>/key, serving the nodes presenting a certificate issued by the CA.`,
+	Example: `thetasigner start --config=~/.thetasigner --chain=mainnet --listen=unix:///var/run/thetasigner.sock`,
+	Run:     runStart,
+}
+
+func init() {
+	startCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	startCmd.Flags().StringVar(&listenFlag, "listen", "tcp://127.0.0.1:16900", "Address to listen on, unix://<path> or tcp://<host>:<port>")
+	startCmd.Flags().StringVar(&passwordFlag, "password", "", "Password of the validator key")
+	startCmd.Flags().StringVar(&watermarkFlag, "watermark", "", "Path of the signing watermark file (default to <config>/signing_watermark.json)")
+	startCmd.Flags().StringVar(&certFlag, "cert", "", "TLS certificate of the signer (default to <config>/tls/signer.crt)")
+	startCmd.Flags().StringVar(&keyFlag, "key", "", "Key of the TLS certificate (default to <config>/tls/signer.key)")
+	startCmd.Flags().StringVar(&caFlag, "ca", "", "CA certificate the nodes' certificates are verified against (default to <config>/tls/ca.crt)")
+	startCmd.Flags().StringVar(&forkScheduleFlag, "fork_schedule", "", "Fork schedule file of the chain (default to <config>/fork_schedule.json if it exists)")
+	startCmd.MarkFlagRequired("chain")
+
+	RootCmd.AddCommand(startCmd)
+}
+
+func runStart(cmd *cobra.Command, args []string) {
+	// The block header encoding depends on the fork heights, which need to match the ones of the nodes
+	schedulePath := forkScheduleFlag
+	if schedulePath == "" {
+		schedulePath = common.DefaultForkSchedulePath(cfgPath)
+	}
+	schedule, err := common.SetupForkSchedule(chainIDFlag, schedulePath)
+	if err != nil {
+		log.Fatalf("Failed to load the fork schedule: %v", err)
+	}
+	log.Infof("Using the fork schedule of chain %v: %+v", chainIDFlag, schedule)
+
+	privKey, err := loadKey()
+	if err != nil {
+		log.Fatalf("Failed to load the validator key: %v", err)
+	}
+
+	watermarks, err := consensus.NewWatermarkStore(withDefault(watermarkFlag, "signing_watermark.json"), chainIDFlag)
+	if err != nil {
+		log.Fatalf("Failed to load the signing watermarks: %v", err)
+	}
+	signer, err := consensus.NewLocalSigner(privKey, watermarks)
+	if err != nil {
+		log.Fatalf("Failed to create the signer: %v", err)
+	}
+
+	tlsConfig, err := consensus.LoadSignerTLSConfig(withDefault(certFlag, "tls/signer.crt"),
+		withDefault(keyFlag, "tls/signer.key"), withDefault(caFlag, "tls/ca.crt"), true)
+	if err != nil {
+		log.Fatalf("Failed to load the TLS config: %v", err)
+	}
+	server, err := consensus.NewSignerServer(signer, tlsConfig)
+	if err != nil {
+		log.Fatalf("Failed to create the signer server: %v", err)
+	}
+
+	network, address, err := consensus.ParseSignerAddress(listenFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if network == "unix" {
+		os.Remove(address)
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		log.Fatalf("Failed to listen on %v: %v", listenFlag, err)
+	}
+	if network == "unix" {
+		os.Chmod(address, 0600)
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-c
+		listener.Close()
+	}()
+
+	log.Infof("Signing for %v on %v", signer.Address().Hex(), listenFlag)
+	if err := server.Serve(listener); err != nil {
+		log.Infof("Signer stopped: %v", err)
+	}
+}
+
+func loadKey() (*crypto.PrivateKey, error) {
+	keystore, err := ks.NewKeystoreEncrypted(path.Join(cfgPath, "key"), ks.StandardScryptN, ks.StandardScryptP)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := keystore.ListKeyAddresses()
+	if err != nil {
+		return nil, err
+	}
+	if len(addresses) != 1 {
+		return nil, fmt.Errorf("Expected exactly one encrypted key under %v, found %v", path.Join(cfgPath, "key", "encrypted"), len(addresses))
+	}
+
+	password := passwordFlag
+	if len(password) == 0 {
+		password, err = utils.GetPassword("Please enter the password of the validator key: ")
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get password: %v", err)
+		}
+	}
+	key, err := keystore.GetKey(addresses[0], password)
+	if err != nil {
+		return nil, err
+	}
+	return key.PrivateKey, nil
+}
+
+func withDefault(value string, defaultPath string) string {
+	if value != "" {
+		return value
+	}
+	return path.Join(cfgPath, defaultPath)
+}
@@ -0,0 +1,7 @@
+package main
+
+import "github.com/thetatoken/theta/cmd/thetasigner/cmd"
+
+func main() {
+	cmd.Execute()
+}
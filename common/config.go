@@ -23,6 +23,8 @@ const (
 	CfgGenesisHash = "genesis.hash"
 	// CfgGenesisChainID defines the chainID.
 	CfgGenesisChainID = "genesis.chainID"
+	// CfgGenesisForkSchedulePath defines the path of the fork schedule file of the chain.
+	CfgGenesisForkSchedulePath = "genesis.forkSchedulePath"
 
 	// CfgConsensusMaxEpochLength defines the maxium length of an epoch.
 	CfgConsensusMaxEpochLength = "consensus.maxEpochLength"
@@ -37,6 +39,18 @@ const (
 	CfgConsensusForceLastVote             = "consensus.forceLastVote"
 	CfgConsensusForceLastVoteTargetBlock  = "consensus.forceLastVoteTargetBlock"
 	CfgConsensusForceLastVoteTargetHeight = "consensus.forceLastVoteTargetHeight"
+	// CfgConsensusSigningWatermarkPath defines the path of the file keeping the highest vote and proposal
+	// signed by the validator key. It should be kept outside of the data path.
+	CfgConsensusSigningWatermarkPath = "consensus.signingWatermarkPath"
+	// CfgConsensusRemoteSignerAddress defines the address of the remote signer holding the validator key,
+	// e.g. unix:///var/run/thetasigner.sock or tcp://10.0.0.2:16900. The local key is used if it is empty.
+	CfgConsensusRemoteSignerAddress = "consensus.remoteSigner.address"
+	// CfgConsensusRemoteSignerCertFile defines the TLS certificate the node presents to the remote signer.
+	CfgConsensusRemoteSignerCertFile = "consensus.remoteSigner.certFile"
+	// CfgConsensusRemoteSignerKeyFile defines the key of the TLS certificate of the node.
+	CfgConsensusRemoteSignerKeyFile = "consensus.remoteSigner.keyFile"
+	// CfgConsensusRemoteSignerCAFile defines the CA certificate the remote signer's certificate is verified against.
+	CfgConsensusRemoteSignerCAFile = "consensus.remoteSigner.caFile"
 
 	// CfgStorageRollingEnabled indicates whether rolling is enabled
 	CfgStorageRollingEnabled = "storage.stateRollingEnabled"
@@ -54,6 +68,13 @@ const (
 	CfgStorageLevelDBHandles = "storage.levelDBHandles"
 	// CfgStorageRollingInterval is the block interval that we start new db layer
 	CfgStorageRollingInterval = "storage.rollingInterval"
+	// CfgStorageTxAddressIndexEnabled indicates whether finalized txs should be indexed by the addresses they touch
+	CfgStorageTxAddressIndexEnabled = "storage.txAddressIndexEnabled"
+
+	// CfgLedgerParallelTxExecutionEnabled indicates whether the block txs should be executed optimistically in parallel
+	CfgLedgerParallelTxExecutionEnabled = "ledger.parallelTxExecutionEnabled"
+	// CfgLedgerParallelTxExecutionWorkers is the number of goroutines executing the block txs in parallel, zero means the number of CPUs
+	CfgLedgerParallelTxExecutionWorkers = "ledger.parallelTxExecutionWorkers"
 
 	// CfgSyncMessageQueueSize defines the capacity of Sync Manager message queue.
 	CfgSyncMessageQueueSize = "sync.messageQueueSize"
@@ -66,6 +87,11 @@ const (
 	CfgSyncForcedDownloadBlockHash             = "sync.forcedDownloadBlockHash"
 	CfgSyncDownloadBranchTimeGapInMilliseconds = "sync.downloadBranchTimeGapInMilliseconds"
 	CfgSyncRecoveryModeBlockGapThreshold       = "sync.recoveryModeBlockGapThreshold"
+	// CfgSyncHeadersOnly runs the node as a light client, which only syncs the finalized headers.
+	CfgSyncHeadersOnly = "sync.headersOnly"
+	// CfgSyncTrustedCheckpointHash is the hash of the block the light client starts from, the genesis
+	// block if not set. The state of a checkpoint block is retained by the peers, so it can be proven.
+	CfgSyncTrustedCheckpointHash = "sync.trustedCheckpointHash"
 
 	// CfgP2POpt sets which P2P network to use: p2p, libp2p, or both.
 	CfgP2POpt = "p2p.opt"
@@ -136,6 +162,35 @@ const (
 	CfgRPCGetBlocksHeavyQueryThreshold = "rpc.getBlocksHeavyQueryThreshold"
 	CfgRPCMaxHeavyGetBlocksQueryCount  = "rpc.maxHeavyGetBlocksQueryCount"
 	CfgRPCIdleTimeoutSecs              = "rpc.idleTimeoutSecs"
+	// CfgRPCGetLogsMaxBlockRange limits the number of blocks a single GetLogs query can scan.
+	CfgRPCGetLogsMaxBlockRange = "rpc.getLogsMaxBlockRange"
+	// CfgRPCGetLogsHeavyQueryThreshold sets the block range above which a GetLogs query counts as
+	// a heavy query, which is rejected when there are too many pending heavy GetLogs queries.
+	CfgRPCGetLogsHeavyQueryThreshold = "rpc.getLogsHeavyQueryThreshold"
+	// CfgRPCMaxHeavyGetLogsQueryCount limits the number of pending heavy GetLogs queries.
+	CfgRPCMaxHeavyGetLogsQueryCount = "rpc.maxHeavyGetLogsQueryCount"
+	// CfgRPCReadinessMaxBlockAgeSecs sets the maximum age of the last finalized block for /readyz to report ready.
+	CfgRPCReadinessMaxBlockAgeSecs = "rpc.readinessMaxBlockAgeSecs"
+	// CfgRPCReadinessMinPeers sets the minimum number of peers for /readyz to report ready.
+	CfgRPCReadinessMinPeers = "rpc.readinessMinPeers"
+	// CfgRPCAdminEnabled sets whether to run the admin RPC service.
+	CfgRPCAdminEnabled = "rpc.adminEnabled"
+	// CfgRPCAdminAddress sets the binding address of the admin RPC service.
+	CfgRPCAdminAddress = "rpc.adminAddress"
+	// CfgRPCAdminPort sets the port of the admin RPC service.
+	CfgRPCAdminPort = "rpc.adminPort"
+	// CfgRPCAdminUnixSocket sets the path of the Unix socket to serve the admin RPC service on,
+	// in place of the TCP address.
+	CfgRPCAdminUnixSocket = "rpc.adminUnixSocket"
+	// CfgRPCAdminAuthToken sets the bearer token the admin RPC requests must present, empty disables the check.
+	CfgRPCAdminAuthToken = "rpc.adminAuthToken"
+	// CfgRPCWSNotificationBufferSize sets the number of notifications buffered per websocket
+	// connection before the connection is dropped as a slow consumer.
+	CfgRPCWSNotificationBufferSize = "rpc.wsNotificationBufferSize"
+	// CfgRPCWSMaxSubscriptions limits the number of subscriptions per websocket connection.
+	CfgRPCWSMaxSubscriptions = "rpc.wsMaxSubscriptions"
+	// CfgRPCWSWriteTimeoutSecs sets the write deadline for pushing notifications to a websocket connection.
+	CfgRPCWSWriteTimeoutSecs = "rpc.wsWriteTimeoutSecs"
 
 	// CfgLogLevels sets the log level.
 	CfgLogLevels = "log.levels"
@@ -148,6 +203,10 @@ const (
 
 	// Graphite Server to collet metrics
 	CfgMetricsServer = "metrics.server"
+	// CfgMetricsPrometheusEnabled sets whether to collect metrics and serve them at /metrics in the Prometheus text format.
+	CfgMetricsPrometheusEnabled = "metrics.prometheusEnabled"
+	// CfgMetricsPrometheusAddress sets the binding address of the /metrics endpoint.
+	CfgMetricsPrometheusAddress = "metrics.prometheusAddress"
 
 	// CfgProfEnabled to enable profiling
 	CfgProfEnabled = "prof.enabled"
@@ -183,6 +242,7 @@ func init() {
 	viper.SetDefault(CfgConsensusForceLastVote, false)
 	viper.SetDefault(CfgConsensusForceLastVoteTargetBlock, "")
 	viper.SetDefault(CfgConsensusForceLastVoteTargetHeight, 0)
+	viper.SetDefault(CfgConsensusRemoteSignerAddress, "")
 
 	viper.SetDefault(CfgSyncMessageQueueSize, 512)
 	viper.SetDefault(CfgSyncDownloadByHash, false)
@@ -192,6 +252,8 @@ func init() {
 	viper.SetDefault(CfgSyncForcedDownloadBlockHash, "")
 	viper.SetDefault(CfgSyncDownloadBranchTimeGapInMilliseconds, 200)
 	viper.SetDefault(CfgSyncRecoveryModeBlockGapThreshold, 4)
+	viper.SetDefault(CfgSyncHeadersOnly, false)
+	viper.SetDefault(CfgSyncTrustedCheckpointHash, "")
 
 	viper.SetDefault(CfgStorageRollingEnabled, true)
 	viper.SetDefault(CfgStorageStatePruningEnabled, true)
@@ -201,6 +263,10 @@ func init() {
 	viper.SetDefault(CfgStorageLevelDBCacheSize, 256)
 	viper.SetDefault(CfgStorageLevelDBHandles, 16)
 	viper.SetDefault(CfgStorageRollingInterval, 14400) // approximately 1 days by default
+	viper.SetDefault(CfgStorageTxAddressIndexEnabled, false)
+
+	viper.SetDefault(CfgLedgerParallelTxExecutionEnabled, false)
+	viper.SetDefault(CfgLedgerParallelTxExecutionWorkers, 0)
 
 	viper.SetDefault(CfgRPCEnabled, false)
 	viper.SetDefault(CfgP2PMessageQueueSize, 512)
@@ -237,6 +303,19 @@ func init() {
 	viper.SetDefault(CfgRPCGetBlocksHeavyQueryThreshold, 500)
 	viper.SetDefault(CfgRPCMaxHeavyGetBlocksQueryCount, 30)
 	viper.SetDefault(CfgRPCIdleTimeoutSecs, 1)
+	viper.SetDefault(CfgRPCGetLogsMaxBlockRange, 5000)
+	viper.SetDefault(CfgRPCGetLogsHeavyQueryThreshold, 500)
+	viper.SetDefault(CfgRPCMaxHeavyGetLogsQueryCount, 10)
+	viper.SetDefault(CfgRPCReadinessMaxBlockAgeSecs, 120)
+	viper.SetDefault(CfgRPCReadinessMinPeers, 1)
+	viper.SetDefault(CfgRPCAdminEnabled, false)
+	viper.SetDefault(CfgRPCAdminAddress, "127.0.0.1")
+	viper.SetDefault(CfgRPCAdminPort, "16890")
+	viper.SetDefault(CfgRPCAdminUnixSocket, "")
+	viper.SetDefault(CfgRPCAdminAuthToken, "")
+	viper.SetDefault(CfgRPCWSNotificationBufferSize, 256)
+	viper.SetDefault(CfgRPCWSMaxSubscriptions, 64)
+	viper.SetDefault(CfgRPCWSWriteTimeoutSecs, 10)
 
 	viper.SetDefault(CfgLogLevels, "*:debug")
 	viper.SetDefault(CfgLogPrintSelfID, false)
@@ -244,6 +323,8 @@ func init() {
 	viper.SetDefault(CfgGuardianRoundLength, 30)
 
 	viper.SetDefault(CfgMetricsServer, "guardian-metrics.thetatoken.org")
+	viper.SetDefault(CfgMetricsPrometheusEnabled, false)
+	viper.SetDefault(CfgMetricsPrometheusAddress, "127.0.0.1:17900")
 
 	viper.SetDefault(CfgProfEnabled, false)
 	viper.SetDefault(CfgForceGCEnabled, true)
@@ -0,0 +1,199 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"sync"
+)
+
+// ForkScheduleFileName is the name of the fork schedule file under the config folder
+const ForkScheduleFileName = "fork_schedule.json"
+
+// ForkSchedule defines the block height of every fork of a chain. A chain other than the
+// mainnet and the testnet can define its own schedule in a fork schedule file, e.g. to
+// enable all the features at block 0 on a private network:
+//
+//	{
+//		"chain_id": "privatenet",
+//		"enable_smart_contract": 0,
+//		...
+//	}
+//
+// The forks missing from the file follow the mainnet schedule.
+type ForkSchedule struct {
+	ChainID string `json:"chain_id"`
+
+	// EnableValidatorReward specifies the minimal block height to enable the validtor TFUEL reward
+	EnableValidatorReward uint64 `json:"enable_validator_reward"`
+	// EnableTheta2 specifies the minimal block height to enable the Theta2.0 feature.
+	EnableTheta2 uint64 `json:"enable_theta2"`
+	// LowerGNStakeThresholdTo1000 specifies the minimal block height to lower the GN Stake Threshold to 1,000 THETA
+	LowerGNStakeThresholdTo1000 uint64 `json:"lower_gn_stake_threshold_to_1000"`
+	// EnableSmartContract specifies the minimal block height to eanble the Turing-complete smart contract support
+	EnableSmartContract uint64 `json:"enable_smart_contract"`
+	// SampleStakingReward specifies the block heigth to enable sampling of staking reward
+	SampleStakingReward uint64 `json:"sample_staking_reward"`
+	// June2021FeeAdjustment specifies the block heigth to enable transaction fee burning adjustment
+	June2021FeeAdjustment uint64 `json:"june2021_fee_adjustment"`
+	// EnableTheta3 specifies the minimal block height to enable the Theta3.0 feature.
+	EnableTheta3 uint64 `json:"enable_theta3"`
+	// RPCCompatibility specifies the block height to enable Ethereum compatible RPC support
+	RPCCompatibility uint64 `json:"rpc_compatibility"`
+	// TxWrapperExtension specifies the block height to extend the Tx Wrapper
+	TxWrapperExtension uint64 `json:"tx_wrapper_extension"`
+	// SupportThetaTokenInSmartContract specifies the block height to support Theta in smart contracts
+	SupportThetaTokenInSmartContract uint64 `json:"support_theta_token_in_smart_contract"`
+	// ValidatorStakeChangedTo200K specifies the block height to lower the validator stake to 200,000 Theta
+	ValidatorStakeChangedTo200K uint64 `json:"validator_stake_changed_to_200k"`
+	// SupportWrappedTheta specifies the block height to support wrapped Theta
+	SupportWrappedTheta uint64 `json:"support_wrapped_theta"`
+	// EnableMetachainSupport specifies the block height to enable Theta Metachain support (i.e. Mainnet 4.0)
+	EnableMetachainSupport uint64 `json:"enable_metachain_support"`
+	// EnableNativeMultisig specifies the block height to enable the native multisig accounts
+	EnableNativeMultisig uint64 `json:"enable_native_multisig"`
+	// EnableTxValidityWindow specifies the block height to enable the versioned tx encoding which carries a validity window
+	EnableTxValidityWindow uint64 `json:"enable_tx_validity_window"`
+	// EnableFeeDelegation specifies the block height to enable the txs with their fee paid by a sponsor account
+	EnableFeeDelegation uint64 `json:"enable_fee_delegation"`
+	// EnableDynamicBaseFee specifies the block height to enable the per-block base fee for the smart contract txs
+	EnableDynamicBaseFee uint64 `json:"enable_dynamic_base_fee"`
+	// EnableStakeRedelegation specifies the block height to enable the stake redelegation between guardians/elite edge nodes
+	EnableStakeRedelegation uint64 `json:"enable_stake_redelegation"`
+	// EnablePartialStakeWithdrawal specifies the block height to enable the withdrawal of part of a stake
+	EnablePartialStakeWithdrawal uint64 `json:"enable_partial_stake_withdrawal"`
+	// EnableGovernance specifies the block height to enable the on-chain governance of the protocol parameters
+	EnableGovernance uint64 `json:"enable_governance"`
+	// EnableEquivocationSlashing specifies the block height to enable slashing the validators that signed conflicting votes
+	EnableEquivocationSlashing uint64 `json:"enable_equivocation_slashing"`
+}
+
+// MainnetForkSchedule is the fork schedule of the mainnet
+var MainnetForkSchedule = ForkSchedule{
+	EnableValidatorReward:            4164982,  // approximate time: 2pm January 14th, 2020 PST
+	EnableTheta2:                     5877350,  // approximate time: 12pm May 27th, 2020 PDT
+	LowerGNStakeThresholdTo1000:      8411427,  // approximate time: 12pm Dec 10th, 2020 PST
+	EnableSmartContract:              8411427,  // approximate time: 12pm Dec 10th, 2020 PST
+	SampleStakingReward:              9497418,  // approximate time: 7pm Mar 10th, 2021 PST
+	June2021FeeAdjustment:            10709540, // approximate time: 12pm June 11, 2021 PT
+	EnableTheta3:                     10968061, // approximate time: 12pm June 30, 2021 PT
+	RPCCompatibility:                 11354820, // approximate time: 12pm July 30, 2021 PT
+	TxWrapperExtension:               12749952,
+	SupportThetaTokenInSmartContract: 13123789,       // approximate time: 5pm Dec 4, 2021 PT
+	ValidatorStakeChangedTo200K:      14526120,       // approximate time: 12pm Mar 14, 2022 PT
+	SupportWrappedTheta:              17285755,       // approximate time: 7pm Sep 28, 2022 PT
+	EnableMetachainSupport:           17790756,       // approximate time: 7pm Nov 3, 2022 PT
+	EnableNativeMultisig:             math.MaxUint64, // disabled until scheduled
+	EnableTxValidityWindow:           math.MaxUint64, // disabled until scheduled
+	EnableFeeDelegation:              math.MaxUint64, // disabled until scheduled
+	EnableDynamicBaseFee:             math.MaxUint64, // disabled until scheduled
+	EnableStakeRedelegation:          math.MaxUint64, // disabled until scheduled
+	EnablePartialStakeWithdrawal:     math.MaxUint64, // disabled until scheduled
+	EnableGovernance:                 math.MaxUint64, // disabled until scheduled
+	EnableEquivocationSlashing:       math.MaxUint64, // disabled until scheduled
+}
+
+// TestnetForkSchedule is the fork schedule of the testnet. The forks already live on the mainnet
+// were activated at the same heights on the testnet. A testnet node with a different schedule
+// needs to put it in the fork schedule file.
+var TestnetForkSchedule = ForkSchedule{
+	EnableValidatorReward:            4164982,
+	EnableTheta2:                     5877350,
+	LowerGNStakeThresholdTo1000:      8411427,
+	EnableSmartContract:              8411427,
+	SampleStakingReward:              9497418,
+	June2021FeeAdjustment:            10709540,
+	EnableTheta3:                     10968061,
+	RPCCompatibility:                 11354820,
+	TxWrapperExtension:               12749952,
+	SupportThetaTokenInSmartContract: 13123789,
+	ValidatorStakeChangedTo200K:      14526120,
+	SupportWrappedTheta:              17285755,
+	EnableMetachainSupport:           17790756,
+	EnableNativeMultisig:             math.MaxUint64, // disabled until scheduled
+	EnableTxValidityWindow:           math.MaxUint64, // disabled until scheduled
+	EnableFeeDelegation:              math.MaxUint64, // disabled until scheduled
+	EnableDynamicBaseFee:             math.MaxUint64, // disabled until scheduled
+	EnableStakeRedelegation:          math.MaxUint64, // disabled until scheduled
+	EnablePartialStakeWithdrawal:     math.MaxUint64, // disabled until scheduled
+	EnableGovernance:                 math.MaxUint64, // disabled until scheduled
+	EnableEquivocationSlashing:       math.MaxUint64, // disabled until scheduled
+}
+
+var (
+	forkSchedules = map[string]ForkSchedule{
+		"mainnet": MainnetForkSchedule,
+		"testnet": TestnetForkSchedule,
+	}
+	forkSchedulesLock = &sync.RWMutex{}
+)
+
+// RegisterForkSchedule sets the fork schedule of the given chain, replacing the built-in
+// schedule if any
+func RegisterForkSchedule(chainID string, schedule ForkSchedule) {
+	forkSchedulesLock.Lock()
+	defer forkSchedulesLock.Unlock()
+
+	schedule.ChainID = chainID
+	forkSchedules[chainID] = schedule
+}
+
+// GetForkSchedule returns the fork schedule of the given chain, which all the height checks
+// go through. The chains without a registered schedule follow the mainnet schedule.
+func GetForkSchedule(chainID string) ForkSchedule {
+	forkSchedulesLock.RLock()
+	defer forkSchedulesLock.RUnlock()
+
+	schedule, ok := forkSchedules[chainID]
+	if !ok {
+		schedule = MainnetForkSchedule
+	}
+	schedule.ChainID = chainID
+	return schedule
+}
+
+// LoadForkScheduleFile reads the fork schedule of the given chain from a JSON file. The
+// chain ID in the file, if specified, needs to match the given one.
+func LoadForkScheduleFile(filePath string, chainID string) (ForkSchedule, error) {
+	schedule := MainnetForkSchedule
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return schedule, err
+	}
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return schedule, fmt.Errorf("failed to parse fork schedule file %v: %v", filePath, err)
+	}
+	if schedule.ChainID != "" && schedule.ChainID != chainID {
+		return schedule, fmt.Errorf("fork schedule file %v is for chain %v, not %v", filePath, schedule.ChainID, chainID)
+	}
+	schedule.ChainID = chainID
+	return schedule, nil
+}
+
+// DefaultForkSchedulePath returns the path of the fork schedule file under the given config
+// folder, or an empty string if the file does not exist.
+func DefaultForkSchedulePath(cfgPath string) string {
+	schedulePath := path.Join(cfgPath, ForkScheduleFileName)
+	if _, err := os.Stat(schedulePath); os.IsNotExist(err) {
+		return ""
+	}
+	return schedulePath
+}
+
+// SetupForkSchedule registers the fork schedule of the given chain read from the fork schedule
+// file, if the path is not empty, and returns the schedule the chain follows. Every program
+// processing the blocks or txs of a chain with a fork schedule file needs to call it at startup,
+// as their encoding depends on the fork heights.
+func SetupForkSchedule(chainID string, schedulePath string) (ForkSchedule, error) {
+	if schedulePath != "" {
+		schedule, err := LoadForkScheduleFile(schedulePath, chainID)
+		if err != nil {
+			return schedule, err
+		}
+		RegisterForkSchedule(chainID, schedule)
+	}
+	return GetForkSchedule(chainID), nil
+}
@@ -0,0 +1,86 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetForkSchedule(t *testing.T) {
+	assert := assert.New(t)
+
+	mainnet := GetForkSchedule("mainnet")
+	assert.Equal("mainnet", mainnet.ChainID)
+	assert.Equal(uint64(8411427), mainnet.EnableSmartContract)
+
+	// Chains without a schedule follow the mainnet
+	unknown := GetForkSchedule("unknown_chain")
+	assert.Equal("unknown_chain", unknown.ChainID)
+	assert.Equal(mainnet.EnableTheta3, unknown.EnableTheta3)
+}
+
+func TestLoadAndRegisterForkSchedule(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "fork_schedule")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+
+	schedulePath := filepath.Join(dir, "fork_schedule.json")
+	require.Nil(ioutil.WriteFile(schedulePath, []byte(`{
+		"chain_id": "fork_test_chain",
+		"enable_smart_contract": 0,
+		"enable_theta3": 10
+	}`), 0600))
+
+	_, err = LoadForkScheduleFile(schedulePath, "another_chain")
+	assert.NotNil(err)
+
+	schedule, err := LoadForkScheduleFile(schedulePath, "fork_test_chain")
+	require.Nil(err)
+	assert.Equal(uint64(0), schedule.EnableSmartContract)
+	assert.Equal(uint64(10), schedule.EnableTheta3)
+	assert.Equal(MainnetForkSchedule.SupportWrappedTheta, schedule.SupportWrappedTheta)
+
+	RegisterForkSchedule("fork_test_chain", schedule)
+	registered := GetForkSchedule("fork_test_chain")
+	assert.Equal(uint64(0), registered.EnableSmartContract)
+	assert.Equal(uint64(10), registered.EnableTheta3)
+	assert.Equal(MainnetForkSchedule.SupportWrappedTheta, registered.SupportWrappedTheta)
+
+	// The schedules of the other chains are not affected
+	assert.Equal(MainnetForkSchedule.EnableSmartContract, GetForkSchedule("mainnet").EnableSmartContract)
+	assert.Equal(MainnetForkSchedule.EnableTheta3, GetForkSchedule("unknown_chain").EnableTheta3)
+}
+
+func TestSetupForkSchedule(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "fork_schedule")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+
+	// Without a fork schedule file, the chain follows its built-in schedule
+	assert.Equal("", DefaultForkSchedulePath(dir))
+	schedule, err := SetupForkSchedule("testnet", DefaultForkSchedulePath(dir))
+	require.Nil(err)
+	assert.Equal(TestnetForkSchedule.EnableTheta3, schedule.EnableTheta3)
+
+	require.Nil(ioutil.WriteFile(filepath.Join(dir, ForkScheduleFileName), []byte(`{
+		"chain_id": "setup_test_chain",
+		"enable_dynamic_base_fee": 5
+	}`), 0600))
+	_, err = SetupForkSchedule("another_chain", DefaultForkSchedulePath(dir))
+	assert.NotNil(err)
+
+	schedule, err = SetupForkSchedule("setup_test_chain", DefaultForkSchedulePath(dir))
+	require.Nil(err)
+	assert.Equal(uint64(5), schedule.EnableDynamicBaseFee)
+	assert.Equal(uint64(5), GetForkSchedule("setup_test_chain").EnableDynamicBaseFee)
+}
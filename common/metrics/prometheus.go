@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusContentType is the content type of the Prometheus text exposition format
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// prometheusQuantiles are the quantiles exported for timers and histograms
+var prometheusQuantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+
+// PrometheusHandler returns an HTTP handler which serves the metrics in r
+// in the Prometheus text exposition format. Metric names are prepended with
+// prefix, and characters not allowed by Prometheus are replaced by '_'.
+func PrometheusHandler(r Registry, prefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", PrometheusContentType)
+		WritePrometheus(w, r, prefix)
+	})
+}
+
+// WritePrometheus writes the metrics in r to w in the Prometheus text
+// exposition format. Metrics are sorted by name so that the output is stable.
+// Durations are exported in seconds.
+func WritePrometheus(w io.Writer, r Registry, prefix string) error {
+	names := []string{}
+	metrics := make(map[string]interface{})
+	r.Each(func(name string, i interface{}) {
+		names = append(names, name)
+		metrics[name] = i
+	})
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		writePrometheusMetric(bw, PrometheusName(prefix+name), metrics[name])
+	}
+	return bw.Flush()
+}
+
+func writePrometheusMetric(w io.Writer, name string, i interface{}) {
+	seconds := float64(time.Second)
+	switch metric := i.(type) {
+	case Counter:
+		fmt.Fprintf(w, "# TYPE %s counter\n", name)
+		fmt.Fprintf(w, "%s %d\n", name, metric.Count())
+	case Gauge:
+		fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+		fmt.Fprintf(w, "%s %d\n", name, metric.Value())
+	case GaugeFloat64:
+		fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+		fmt.Fprintf(w, "%s %s\n", name, formatPrometheusFloat(metric.Value()))
+	case Meter:
+		m := metric.Snapshot()
+		fmt.Fprintf(w, "# TYPE %s counter\n", name)
+		fmt.Fprintf(w, "%s %d\n", name, m.Count())
+		fmt.Fprintf(w, "# TYPE %s_rate1m gauge\n", name)
+		fmt.Fprintf(w, "%s_rate1m %s\n", name, formatPrometheusFloat(m.Rate1()))
+	case Histogram:
+		h := metric.Snapshot()
+		ps := h.Percentiles(prometheusQuantiles)
+		fmt.Fprintf(w, "# TYPE %s summary\n", name)
+		for idx, q := range prometheusQuantiles {
+			fmt.Fprintf(w, "%s{quantile=\"%s\"} %s\n", name, formatPrometheusFloat(q), formatPrometheusFloat(ps[idx]))
+		}
+		fmt.Fprintf(w, "%s_count %d\n", name, h.Count())
+	case Timer:
+		t := metric.Snapshot()
+		ps := t.Percentiles(prometheusQuantiles)
+		fmt.Fprintf(w, "# TYPE %s summary\n", name)
+		for idx, q := range prometheusQuantiles {
+			fmt.Fprintf(w, "%s{quantile=\"%s\"} %s\n", name, formatPrometheusFloat(q), formatPrometheusFloat(ps[idx]/seconds))
+		}
+		fmt.Fprintf(w, "%s_count %d\n", name, t.Count())
+	case ResettingTimer:
+		t := metric.Snapshot()
+		ps := t.Percentiles(prometheusQuantiles)
+		fmt.Fprintf(w, "# TYPE %s summary\n", name)
+		for idx, q := range prometheusQuantiles {
+			fmt.Fprintf(w, "%s{quantile=\"%s\"} %s\n", name, formatPrometheusFloat(q), formatPrometheusFloat(float64(ps[idx])/seconds))
+		}
+		fmt.Fprintf(w, "%s_count %d\n", name, len(t.Values()))
+	}
+}
+
+// PrometheusName converts the metric name to a valid Prometheus metric name,
+// e.g. "p2p/channel/block/sent" becomes "p2p_channel_block_sent".
+func PrometheusName(name string) string {
+	var sb strings.Builder
+	for idx, c := range name {
+		valid := c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			(idx > 0 && c >= '0' && c <= '9')
+		if valid {
+			sb.WriteRune(c)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
+
+func formatPrometheusFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
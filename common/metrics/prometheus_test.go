@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	r := NewRegistry()
+	NewRegisteredCounter("p2p/channel/block/sent", r).Inc(3)
+	NewRegisteredGauge("consensus/epoch", r).Update(42)
+	NewRegisteredGaugeFloat64("system/load", r).Update(1.5)
+	NewRegisteredTimer("rpc/theta.GetStatus", r).Update(2 * time.Second)
+
+	buf := &bytes.Buffer{}
+	if err := WritePrometheus(buf, r, "theta/"); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	expected := []string{
+		"# TYPE theta_consensus_epoch gauge\ntheta_consensus_epoch 42\n",
+		"# TYPE theta_p2p_channel_block_sent counter\ntheta_p2p_channel_block_sent 3\n",
+		"# TYPE theta_system_load gauge\ntheta_system_load 1.5\n",
+		"# TYPE theta_rpc_theta_GetStatus summary\n",
+		"theta_rpc_theta_GetStatus{quantile=\"0.5\"} 2\n",
+		"theta_rpc_theta_GetStatus_count 1\n",
+	}
+	for _, e := range expected {
+		if !strings.Contains(out, e) {
+			t.Errorf("output does not contain %q:\n%s", e, out)
+		}
+	}
+
+	// Metrics are sorted by name
+	if strings.Index(out, "theta_consensus_epoch") > strings.Index(out, "theta_p2p_channel_block_sent") {
+		t.Errorf("metrics are not sorted:\n%s", out)
+	}
+}
+
+func TestPrometheusHandler(t *testing.T) {
+	r := NewRegistry()
+	NewRegisteredCounter("mempool/size", r).Inc(1)
+
+	rec := httptest.NewRecorder()
+	PrometheusHandler(r, "").ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != PrometheusContentType {
+		t.Errorf("unexpected content type: %v", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "mempool_size 1\n") {
+		t.Errorf("unexpected body: %v", rec.Body.String())
+	}
+}
+
+func TestPrometheusName(t *testing.T) {
+	cases := map[string]string{
+		"db/get":             "db_get",
+		"rpc/theta.GetBlock": "rpc_theta_GetBlock",
+		"9lives":             "_lives",
+		"a:b_c1":             "a:b_c1",
+	}
+	for name, expected := range cases {
+		if actual := PrometheusName(name); actual != expected {
+			t.Errorf("PrometheusName(%q) = %q, expected %q", name, actual, expected)
+		}
+	}
+}
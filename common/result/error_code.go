@@ -15,6 +15,8 @@ const (
 	CodeEmptyPubKeyWithSequence1 ErrorCode = 100004
 	CodeUnauthorizedTx           ErrorCode = 100005
 	CodeInvalidFee               ErrorCode = 100006
+	CodeTxExpired                ErrorCode = 100007
+	CodeTxNotYetValid            ErrorCode = 100008
 
 	// ReserveFund Errors
 	CodeReserveFundCheckFailed   ErrorCode = 101001
@@ -39,9 +41,21 @@ const (
 	CodeDoNotSupportNativeThetaInSubchain ErrorCode = 105006
 
 	// Stake Deposit/Withdrawal Errors
-	CodeInvalidStakePurpose     ErrorCode = 106001
-	CodeInvalidStake            ErrorCode = 106002
-	CodeInsufficientStake       ErrorCode = 106003
-	CodeNotEnoughBalanceToStake ErrorCode = 106004
-	CodeStakeExceedsCap         ErrorCode = 106005
+	CodeInvalidStakePurpose          ErrorCode = 106001
+	CodeInvalidStake                 ErrorCode = 106002
+	CodeInsufficientStake            ErrorCode = 106003
+	CodeNotEnoughBalanceToStake      ErrorCode = 106004
+	CodeStakeExceedsCap              ErrorCode = 106005
+	CodeStakeRedelegationTooFrequent ErrorCode = 106006
+
+	// Governance Errors
+	CodeInvalidGovernanceProposal  ErrorCode = 107001
+	CodeGovernanceProposalNotFound ErrorCode = 107002
+	CodeNoGovernanceVotingPower    ErrorCode = 107003
+	CodeGovernanceVotingClosed     ErrorCode = 107004
+	CodeGovernanceProposalRejected ErrorCode = 107005
+
+	// Equivocation Slashing Errors
+	CodeInvalidEquivocationEvidence ErrorCode = 108001
+	CodeValidatorJailed             ErrorCode = 108002
 )
@@ -409,6 +409,12 @@ const (
 
 	// ChannelIDAggregatedEliteEdgeNodeVotes indicates the channel for Elite Edge Node aggregated vote messages
 	ChannelIDAggregatedEliteEdgeNodeVotes
+
+	// ChannelIDEvidence indicates the channel for the evidences of validator misbehavior
+	ChannelIDEvidence
+
+	// ChannelIDValidatorSetProof indicates the channel for the validator set proofs requested by light clients
+	ChannelIDValidatorSetProof
 )
 
 // P2POptEnum defines the p2p network
@@ -3,6 +3,7 @@ package util
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -11,6 +12,12 @@ import (
 
 var logLevels map[string]string
 
+// loggers tracks the loggers created for each module, so that their levels can be changed at runtime
+var (
+	loggers   = make(map[string][]*log.Logger)
+	loggersMu sync.Mutex
+)
+
 const (
 	panicLevel = "panic"
 	fatalLevel = "fatal"
@@ -68,10 +75,12 @@ func GetLoggerForModule(module string) *log.Entry {
 	logger := log.New()
 	logger.Formatter = customFormatter
 
+	loggersMu.Lock()
 	level, ok := logLevels[module]
 	if !ok {
 		level = logLevels["*"]
 	}
+	loggersMu.Unlock()
 
 	if level == panicLevel {
 		logger.SetLevel(log.PanicLevel)
@@ -87,5 +96,54 @@ func GetLoggerForModule(module string) *log.Entry {
 		logger.SetLevel(log.DebugLevel)
 	}
 
+	loggersMu.Lock()
+	loggers[module] = append(loggers[module], logger)
+	loggersMu.Unlock()
+
 	return logger.WithFields(log.Fields{"prefix": module})
 }
+
+// SetLogLevel changes the log level of the given module at runtime. Module "*" sets the
+// default level, which also applies to the modules without a level of their own.
+func SetLogLevel(module string, level string) error {
+	lvl, err := log.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	loggersMu.Lock()
+	defer loggersMu.Unlock()
+
+	if logLevels == nil {
+		logLevels = map[string]string{"*": defaultLevel}
+	}
+	logLevels[module] = level
+
+	for mod, modLoggers := range loggers {
+		if module != "*" && mod != module {
+			continue
+		}
+		if _, ok := logLevels[mod]; module == "*" && ok && mod != "*" {
+			continue // the module has a level of its own
+		}
+		for _, logger := range modLoggers {
+			logger.SetLevel(lvl)
+		}
+	}
+	if module == "*" {
+		log.SetLevel(lvl)
+	}
+	return nil
+}
+
+// GetLogLevels returns the current log level of each configured module.
+func GetLogLevels() map[string]string {
+	loggersMu.Lock()
+	defer loggersMu.Unlock()
+
+	levels := make(map[string]string)
+	for module, level := range logLevels {
+		levels[module] = level
+	}
+	return levels
+}
@@ -35,3 +35,24 @@ func TestGetLoggerForModule(t *testing.T) {
 	assert.Equal(log.InfoLevel, GetLoggerForModule("consensus").Logger.Level)
 	assert.Equal(log.ErrorLevel, GetLoggerForModule("sync").Logger.Level)
 }
+
+func TestSetLogLevel(t *testing.T) {
+	assert := assert.New(t)
+
+	logLevels = parseLogLevelConfig("*:error,p2p:debug")
+	p2pLogger := GetLoggerForModule("p2p")
+	syncLogger := GetLoggerForModule("sync")
+
+	assert.Nil(SetLogLevel("sync", "info"))
+	assert.Equal(log.DebugLevel, p2pLogger.Logger.Level)
+	assert.Equal(log.InfoLevel, syncLogger.Logger.Level)
+
+	// The default level does not override the modules with levels of their own
+	assert.Nil(SetLogLevel("*", "warn"))
+	assert.Equal(log.DebugLevel, p2pLogger.Logger.Level)
+	assert.Equal(log.InfoLevel, syncLogger.Logger.Level)
+	assert.Equal(log.WarnLevel, GetLoggerForModule("rpc").Logger.Level)
+
+	assert.NotNil(SetLogLevel("sync", "verbose"))
+	assert.Equal("info", GetLogLevels()["sync"])
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/core"
-	"github.com/thetatoken/theta/crypto/bls"
 )
 
 const (
@@ -22,8 +21,8 @@ const (
 type EliteEdgeNodeEngine struct {
 	logger *log.Entry
 
-	engine  *ConsensusEngine
-	privKey *bls.SecretKey
+	engine *ConsensusEngine
+	signer Signer
 
 	voteBookkeeper *EENVoteBookkeeper
 
@@ -40,11 +39,11 @@ type EliteEdgeNodeEngine struct {
 	mu          *sync.Mutex
 }
 
-func NewEliteEdgeNodeEngine(c *ConsensusEngine, privateKey *bls.SecretKey) *EliteEdgeNodeEngine {
+func NewEliteEdgeNodeEngine(c *ConsensusEngine, signer Signer) *EliteEdgeNodeEngine {
 	return &EliteEdgeNodeEngine{
-		logger:  util.GetLoggerForModule("elite edge node"),
-		engine:  c,
-		privKey: privateKey,
+		logger: util.GetLoggerForModule("elite edge node"),
+		engine: c,
+		signer: signer,
 
 		voteBookkeeper: CreateEENVoteBookkeeper(DefaultMaxNumVotesCached),
 
@@ -249,7 +248,7 @@ func (e *EliteEdgeNodeEngine) HandleVote(vote *core.EENVote) {
 	case e.evIncoming <- vote:
 		return
 	default:
-		e.logger.Debug("EliteEdgeNodeEngine queue is full, discarding elite edge node vote: %v", vote)
+		e.logger.Debugf("EliteEdgeNodeEngine queue is full, discarding elite edge node vote: %v", vote)
 	}
 }
 
@@ -258,7 +257,7 @@ func (e *EliteEdgeNodeEngine) HandleAggregatedVote(vote *core.AggregatedEENVotes
 	case e.aevIncoming <- vote:
 		return
 	default:
-		e.logger.Debug("EliteEdgeNodeEngine queue is full, discarding aggregated elite edge node vote: %v", vote)
+		e.logger.Debugf("EliteEdgeNodeEngine queue is full, discarding aggregated elite edge node vote: %v", vote)
 	}
 }
 
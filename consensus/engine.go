@@ -5,16 +5,15 @@ import (
 	"fmt"
 	"math/big"
 	"sort"
-	"strings"
 	"sync"
 	"time"
 
-	"github.com/thetatoken/theta/crypto/bls"
-
+	lru "github.com/hashicorp/golang-lru"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
 	"github.com/thetatoken/theta/common/result"
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/core"
@@ -32,7 +31,7 @@ var _ core.ConsensusEngine = (*ConsensusEngine)(nil)
 type ConsensusEngine struct {
 	logger *log.Entry
 
-	privateKey *crypto.PrivateKey
+	signer Signer
 
 	chain            *blockchain.Chain
 	dispatcher       *dispatcher.Dispatcher
@@ -41,10 +40,12 @@ type ConsensusEngine struct {
 	branchDownloader core.BranchDownloader
 	guardian         *GuardianEngine
 	eliteEdgeNode    *EliteEdgeNodeEngine
+	evidenceCache    *lru.Cache // Hashes of the equivocation evidences handled
 
 	incoming         chan interface{}
 	priorityIncoming chan interface{} // High-priority channel
 	finalizedBlocks  chan *core.Block
+	validatedBlocks  chan *core.Block
 	hasSynced        bool
 
 	// Life cycle
@@ -65,7 +66,7 @@ type ConsensusEngine struct {
 }
 
 // NewConsensusEngine creates a instance of ConsensusEngine.
-func NewConsensusEngine(privateKey *crypto.PrivateKey, db store.Store, chain *blockchain.Chain, dispatcher *dispatcher.Dispatcher, validatorManager core.ValidatorManager) *ConsensusEngine {
+func NewConsensusEngine(signer Signer, db store.Store, chain *blockchain.Chain, dispatcher *dispatcher.Dispatcher, validatorManager core.ValidatorManager) *ConsensusEngine {
 	var forcedLastVote *core.Vote = nil
 	if viper.GetBool(common.CfgConsensusForceLastVote) {
 		targetBlockHashStr := viper.GetString(common.CfgConsensusForceLastVoteTargetBlock)
@@ -84,21 +85,26 @@ func NewConsensusEngine(privateKey *crypto.PrivateKey, db store.Store, chain *bl
 		forcedLastVote = &core.Vote{
 			Block:  forceVoteTargetBlockHash,
 			Height: uint64(forceVoteTargetHeight),
-			ID:     privateKey.PublicKey().Address(),
+			ID:     signer.Address(),
 			Epoch:  chain.Root().Epoch,
 		}
-		forcedLastVote.Sign(privateKey)
+		sig, err := signer.SignVote(*forcedLastVote)
+		if err != nil {
+			logger.Panicf("Failed to sign the forced vote: %v", err)
+		}
+		forcedLastVote.SetSignature(sig)
 	}
 
 	e := &ConsensusEngine{
 		chain:      chain,
 		dispatcher: dispatcher,
 
-		privateKey: privateKey,
+		signer: signer,
 
 		incoming:         make(chan interface{}, viper.GetInt(common.CfgConsensusMessageQueueSize)),
 		priorityIncoming: make(chan interface{}, viper.GetInt(common.CfgConsensusMessageQueueSize)),
 		finalizedBlocks:  make(chan *core.Block, viper.GetInt(common.CfgConsensusMessageQueueSize)),
+		validatedBlocks:  make(chan *core.Block, viper.GetInt(common.CfgConsensusMessageQueueSize)),
 
 		wg: &sync.WaitGroup{},
 
@@ -114,12 +120,21 @@ func NewConsensusEngine(privateKey *crypto.PrivateKey, db store.Store, chain *bl
 	logger = util.GetLoggerForModule("consensus")
 	e.logger = logger
 
-	blsKey, err := bls.GenKey(strings.NewReader(common.Bytes2Hex(privateKey.PublicKey().ToBytes())))
+	e.guardian = NewGuardianEngine(e, signer)
+	e.eliteEdgeNode = NewEliteEdgeNodeEngine(e, signer)
+
+	evidenceCache, err := lru.New(evidenceCacheSize)
 	if err != nil {
 		e.logger.Panic(err)
 	}
-	e.guardian = NewGuardianEngine(e, blsKey)
-	e.eliteEdgeNode = NewEliteEdgeNodeEngine(e, blsKey)
+	e.evidenceCache = evidenceCache
+
+	metrics.NewRegisteredFunctionalGauge("consensus/epoch", nil, func() int64 {
+		return int64(e.GetEpoch())
+	})
+	metrics.NewRegisteredFunctionalGauge("consensus/finalized_height", nil, func() int64 {
+		return int64(e.GetLastFinalizedBlock().Height)
+	})
 
 	e.logger.WithFields(log.Fields{"state": e.state}).Info("Starting state")
 
@@ -142,12 +157,25 @@ func (e *ConsensusEngine) SetBranchDownloader(downloader core.BranchDownloader)
 
 // ID returns the identifier of current node.
 func (e *ConsensusEngine) ID() string {
-	return e.privateKey.PublicKey().Address().Hex()
+	return e.signer.Address().Hex()
 }
 
-// PrivateKey returns the private key
+// PrivateKey returns the private key, or nil if the keys are held by a remote signer
 func (e *ConsensusEngine) PrivateKey() *crypto.PrivateKey {
-	return e.privateKey
+	if ls, ok := e.signer.(*LocalSigner); ok {
+		return ls.PrivateKey()
+	}
+	return nil
+}
+
+// Signer returns the signer of the votes and proposals
+func (e *ConsensusEngine) Signer() Signer {
+	return e.signer
+}
+
+// SignTx signs the raw transaction proposed by the node, e.g. the coinbase transaction
+func (e *ConsensusEngine) SignTx(chainID string, rawTx common.Bytes) (*crypto.Signature, error) {
+	return e.signer.SignTx(chainID, rawTx)
 }
 
 // Chain return a pointer to the underlying chain store.
@@ -379,6 +407,7 @@ func (e *ConsensusEngine) GetChannelIDs() []common.ChannelIDEnum {
 		common.ChannelIDHeader,
 		common.ChannelIDBlock,
 		common.ChannelIDVote,
+		common.ChannelIDEvidence,
 	}
 }
 
@@ -413,6 +442,8 @@ func (e *ConsensusEngine) processMessage(msg interface{}) (endEpoch bool) {
 	case *core.AggregatedEENVotes:
 		// e.logger.WithFields(log.Fields{"aggregated elite edge node vote": m}).Debug("Received agggregated elite edge node vote")
 		e.handleAggregatedEliteEdgeNodeVote(m)
+	case *core.EquivocationEvidence:
+		e.handleEquivocationEvidence(m)
 	default:
 		// Should not happen.
 		log.Errorf("Unknown message type: %v", m)
@@ -564,7 +595,7 @@ func (e *ConsensusEngine) validateBlock(block *core.Block, parent *core.Extended
 
 	// Validate Guardian Votes.
 	// We allow checkpoint blocs to have nil guardian votes.
-	if block.GuardianVotes != nil && block.Height >= common.HeightEnableTheta2 && common.IsCheckPointHeight(block.Height) {
+	if block.GuardianVotes != nil && block.Height >= common.GetForkSchedule(block.ChainID).EnableTheta2 && common.IsCheckPointHeight(block.Height) {
 		// Voted block must exist.
 		padding := uint64(20)
 		if e.chain.Root().Height+padding*uint64(common.CheckpointInterval) < block.Height {
@@ -635,7 +666,7 @@ func (e *ConsensusEngine) validateBlock(block *core.Block, parent *core.Extended
 
 	// Validate Elite Edge Node Votes.
 	// We allow checkpoint blocks to have nil elite edge node votes.
-	if block.EliteEdgeNodeVotes != nil && block.Height >= common.HeightEnableTheta3 && common.IsCheckPointHeight(block.Height) {
+	if block.EliteEdgeNodeVotes != nil && block.Height >= common.GetForkSchedule(block.ChainID).EnableTheta3 && common.IsCheckPointHeight(block.Height) {
 		// Voted block must exist.
 		padding := uint64(20)
 		if e.chain.Root().Height+padding*uint64(common.CheckpointInterval) < block.Height {
@@ -846,6 +877,12 @@ func (e *ConsensusEngine) handleNormalBlock(eb *core.ExtendedBlock) {
 
 	e.chain.MarkBlockValid(block.Hash())
 
+	select {
+	case e.validatedBlocks <- block:
+	default:
+		e.logger.Debugf("Failed to notify validated block, height=%v", block.Height)
+	}
+
 	// Skip voting for block older than current best known epoch.
 	// Allow block with one epoch behind since votes are processed first and might advance epoch
 	// before block is processed.
@@ -876,7 +913,7 @@ func (e *ConsensusEngine) handleNormalBlock(eb *core.ExtendedBlock) {
 }
 
 func (e *ConsensusEngine) shouldVote(block common.Hash) bool {
-	return e.shouldVoteByID(e.privateKey.PublicKey().Address(), block)
+	return e.shouldVoteByID(e.signer.Address(), block)
 }
 
 func (e *ConsensusEngine) shouldVoteByID(id common.Address, block common.Hash) bool {
@@ -893,6 +930,7 @@ func (e *ConsensusEngine) vote() {
 	}
 
 	var vote core.Vote
+	var err error
 	lastVote := e.state.GetLastVote()
 	shouldRepeatVote := false
 	if lastVote.Height != 0 && lastVote.Height >= tip.Height {
@@ -921,10 +959,16 @@ func (e *ConsensusEngine) vote() {
 			log.Panic(err)
 		}
 		// Recreating vote so that it has updated epoch and signature.
-		vote = e.createVote(block.Block)
+		vote, err = e.createVote(block.Block)
 	} else {
-		vote = e.createVote(tip.Block)
-		e.state.SetLastVote(vote)
+		vote, err = e.createVote(tip.Block)
+		if err == nil {
+			e.state.SetLastVote(vote)
+		}
+	}
+	if err != nil {
+		e.logger.WithFields(log.Fields{"error": err}).Error("Refused to sign vote")
+		return
 	}
 	e.logger.WithFields(log.Fields{
 		"vote": vote,
@@ -949,15 +993,19 @@ func (e *ConsensusEngine) broadcastVote(vote core.Vote) {
 	e.dispatcher.SendData([]string{}, voteMsg)
 }
 
-func (e *ConsensusEngine) createVote(block *core.Block) core.Vote {
+func (e *ConsensusEngine) createVote(block *core.Block) (core.Vote, error) {
 	vote := core.Vote{
 		Block:  block.Hash(),
 		Height: block.Height,
-		ID:     e.privateKey.PublicKey().Address(),
+		ID:     e.signer.Address(),
 		Epoch:  e.GetEpoch(),
 	}
-	vote.Sign(e.privateKey)
-	return vote
+	sig, err := e.signer.SignVote(vote)
+	if err != nil {
+		return core.Vote{}, err
+	}
+	vote.SetSignature(sig)
+	return vote, nil
 }
 
 func (e *ConsensusEngine) validateVote(vote core.Vote) bool {
@@ -979,6 +1027,9 @@ func (e *ConsensusEngine) handleVote(vote core.Vote) (endEpoch bool) {
 		return
 	}
 
+	// Check for conflicting votes of the voter.
+	e.detectEquivocation(vote)
+
 	// Save vote.
 	err := e.state.AddVote(&vote)
 	if err != nil {
@@ -1212,6 +1263,12 @@ func (e *ConsensusEngine) FinalizedBlocks() chan *core.Block {
 	return e.finalizedBlocks
 }
 
+// ValidatedBlocks returns a channel that will be published with blocks that passed validation
+// and whose transactions have been applied by the engine. These blocks are not necessarily finalized.
+func (e *ConsensusEngine) ValidatedBlocks() chan *core.Block {
+	return e.validatedBlocks
+}
+
 // GetLastFinalizedBlock returns the last finalized block.
 func (e *ConsensusEngine) GetLastFinalizedBlock() *core.ExtendedBlock {
 	return e.state.GetLastFinalizedBlock()
@@ -1235,7 +1292,7 @@ func (e *ConsensusEngine) processCCBlock(ccBlock *core.ExtendedBlock) {
 			e.logger.WithFields(log.Fields{"err": err, "hash": ccBlock.Parent}).Error("Failed to load block")
 			return
 		}
-		if err := e.finalizeBlock(parent); err != nil {
+		if err := e.finalizeBlock(parent, ccBlock); err != nil {
 			return
 		}
 	}
@@ -1245,7 +1302,9 @@ func (e *ConsensusEngine) processCCBlock(ccBlock *core.ExtendedBlock) {
 	e.chain.CommitBlock(ccBlock.Hash())
 }
 
-func (e *ConsensusEngine) finalizeBlock(block *core.ExtendedBlock) error {
+// finalizeBlock finalizes the block, which is directly extended and certified by the committed
+// ccBlock.
+func (e *ConsensusEngine) finalizeBlock(block *core.ExtendedBlock, ccBlock *core.ExtendedBlock) error {
 	if e.stopped {
 		return nil
 	}
@@ -1271,6 +1330,9 @@ func (e *ConsensusEngine) finalizeBlock(block *core.ExtendedBlock) error {
 	// duplicate TX in fork.
 	e.chain.AddTxsToIndex(block, true)
 
+	e.addFinalityCertificate(block, ccBlock)
+	e.attachCheckpointVotes(block)
+
 	// Guardians and Elite Edge Nodes to vote for checkpoint blocks.
 	if common.IsCheckPointHeight(block.Height) {
 		e.guardian.StartNewBlock(block.Hash())
@@ -1287,6 +1349,58 @@ func (e *ConsensusEngine) finalizeBlock(block *core.ExtendedBlock) error {
 	return nil
 }
 
+// addFinalityCertificate saves the finality certificate of the block, with the votes committing
+// ccBlock. The certificate also covers the ancestors finalized along with the block.
+func (e *ConsensusEngine) addFinalityCertificate(block *core.ExtendedBlock, ccBlock *core.ExtendedBlock) {
+	if ccBlock.Status.IsTrusted() {
+		return // hardcoded blocks are not committed by votes
+	}
+	votes := e.chain.FindVotesByHash(ccBlock.Hash()).UniqueVoter()
+	validators := e.validatorManager.GetValidatorSet(ccBlock.Hash())
+	if !validators.HasMajority(votes) {
+		return
+	}
+	proof, err := e.ledger.ProveValidatorCandidatePool(block.Hash())
+	if err != nil {
+		e.logger.WithFields(log.Fields{
+			"block.Hash":   block.Hash().Hex(),
+			"block.Height": block.Height,
+			"error":        err,
+		}).Warn("Failed to prove validator candidate pool for finality certificate")
+		return
+	}
+	cert := core.NewFinalityCertificate(block.BlockHeader, ccBlock.BlockHeader, validators, votes, proof)
+	e.chain.AddFinalityCertificate(cert)
+}
+
+// attachCheckpointVotes attaches the guardian and elite edge node votes included in the block to
+// the finality certificate of the checkpoint block they vote for.
+func (e *ConsensusEngine) attachCheckpointVotes(block *core.ExtendedBlock) {
+	if block.GuardianVotes != nil {
+		e.updateFinalityCertificate(block.GuardianVotes.Block, func(cert *core.FinalityCertificate) {
+			cert.GuardianVotes = block.GuardianVotes
+		})
+	}
+	if block.EliteEdgeNodeVotes != nil {
+		e.updateFinalityCertificate(block.EliteEdgeNodeVotes.Block, func(cert *core.FinalityCertificate) {
+			cert.EliteEdgeNodeVotes = block.EliteEdgeNodeVotes
+		})
+	}
+}
+
+func (e *ConsensusEngine) updateFinalityCertificate(blockHash common.Hash, update func(cert *core.FinalityCertificate)) {
+	checkpoint, err := e.chain.FindBlock(blockHash)
+	if err != nil {
+		return
+	}
+	cert, err := e.chain.FindFinalityCertificate(checkpoint.Height)
+	if err != nil || cert.Header.Hash() != blockHash {
+		return
+	}
+	update(cert)
+	e.chain.AddFinalityCertificate(cert)
+}
+
 func (e *ConsensusEngine) shouldPropose(tip *core.ExtendedBlock, epoch uint64) bool {
 	if epoch <= tip.Epoch {
 		e.logger.WithFields(log.Fields{
@@ -1368,19 +1482,21 @@ func (e *ConsensusEngine) createProposal(shouldIncludeValidatorUpdateTxs bool) (
 	block.Epoch = e.GetEpoch()
 	block.Parent = tip.Hash()
 	block.Height = tip.Height + 1
-	block.Proposer = e.privateKey.PublicKey().Address()
+	block.Proposer = e.signer.Address()
 	block.Timestamp = big.NewInt(time.Now().Unix())
 	block.HCC.BlockHash = e.state.GetHighestCCBlock().Hash()
 	hccValidators := e.validatorManager.GetValidatorSet(block.HCC.BlockHash)
 	block.HCC.Votes = e.chain.FindVotesByHash(block.HCC.BlockHash).UniqueVoter().FilterByValidators(hccValidators)
 
+	forks := common.GetForkSchedule(block.ChainID)
+
 	// Add guardian votes.
-	if block.Height >= common.HeightEnableTheta2 && common.IsCheckPointHeight(block.Height) {
+	if block.Height >= forks.EnableTheta2 && common.IsCheckPointHeight(block.Height) {
 		block.GuardianVotes = e.guardian.GetBestVote()
 	}
 
 	// Add elite edge node votes.
-	if block.Height >= common.HeightEnableTheta3 && common.IsCheckPointHeight(block.Height) {
+	if block.Height >= forks.EnableTheta3 && common.IsCheckPointHeight(block.Height) {
 		block.EliteEdgeNodeVotes = e.eliteEdgeNode.GetBestVote()
 	}
 
@@ -1394,9 +1510,9 @@ func (e *ConsensusEngine) createProposal(shouldIncludeValidatorUpdateTxs bool) (
 	block.StateHash = newRoot
 
 	// Sign block.
-	sig, err := e.privateKey.Sign(block.SignBytes())
+	sig, err := e.signer.SignProposal(block.BlockHeader)
 	if err != nil {
-		e.logger.WithFields(log.Fields{"error": err}).Panic("Failed to sign vote")
+		return core.Proposal{}, fmt.Errorf("Refused to sign proposal: %v", err)
 	}
 	block.SetSignature(sig)
 
@@ -0,0 +1,83 @@
+package consensus
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/dispatcher"
+	"github.com/thetatoken/theta/rlp"
+)
+
+const (
+	evidenceCacheSize = 4096
+)
+
+// detectEquivocation looks for a vote of the same validator for another block at the height of
+// the given vote. The height of a vote is not signed, so only the votes for the blocks known
+// locally can be checked.
+func (e *ConsensusEngine) detectEquivocation(vote core.Vote) {
+	block, err := e.chain.FindBlock(vote.Block)
+	if err != nil {
+		return
+	}
+	if block.Height < common.GetForkSchedule(block.ChainID).EnableEquivocationSlashing {
+		return
+	}
+
+	for _, other := range e.chain.FindBlocksByHeight(block.Height) {
+		if other.Hash() == vote.Block {
+			continue
+		}
+		for _, otherVote := range e.chain.FindVotesByHash(other.Hash()).Votes() {
+			if otherVote.ID != vote.ID {
+				continue
+			}
+			evidence := core.NewEquivocationEvidence(vote, block.BlockHeader, otherVote, other.BlockHeader)
+			e.handleEquivocationEvidence(evidence)
+			return
+		}
+	}
+}
+
+// handleEquivocationEvidence passes the evidence detected locally or received from the peers
+// to the ledger, which includes it in the blocks proposed by the node, and relays it to the peers
+func (e *ConsensusEngine) handleEquivocationEvidence(evidence *core.EquivocationEvidence) {
+	hash := evidence.Hash()
+	if e.evidenceCache.Contains(hash) {
+		return
+	}
+	if res := evidence.Validate(e.chain.ChainID); res.IsError() {
+		e.logger.WithFields(log.Fields{
+			"evidence": evidence,
+			"err":      res.String(),
+		}).Warn("Ignoring invalid equivocation evidence")
+		return
+	}
+	e.evidenceCache.Add(hash, struct{}{})
+
+	e.logger.WithFields(log.Fields{
+		"offender": evidence.Offender().Hex(),
+		"height":   evidence.Height(),
+		"blockA":   evidence.VoteA.Block.Hex(),
+		"blockB":   evidence.VoteB.Block.Hex(),
+	}).Warn("Validator voted for conflicting blocks")
+
+	if e.ledger != nil {
+		e.ledger.AddEquivocationEvidence(evidence)
+	}
+	e.broadcastEquivocationEvidence(evidence)
+}
+
+func (e *ConsensusEngine) broadcastEquivocationEvidence(evidence *core.EquivocationEvidence) {
+	payload, err := rlp.EncodeToBytes(evidence)
+	if err != nil {
+		e.logger.WithFields(log.Fields{"evidence": evidence}).Error("Failed to encode equivocation evidence")
+		return
+	}
+	evidenceMsg := dispatcher.DataResponse{
+		ChannelID: common.ChannelIDEvidence,
+		Payload:   payload,
+	}
+	e.dispatcher.SendData([]string{}, evidenceMsg)
+}
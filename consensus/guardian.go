@@ -9,7 +9,6 @@ import (
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/core"
-	"github.com/thetatoken/theta/crypto/bls"
 )
 
 const (
@@ -20,8 +19,8 @@ const (
 type GuardianEngine struct {
 	logger *log.Entry
 
-	engine  *ConsensusEngine
-	privKey *bls.SecretKey
+	engine *ConsensusEngine
+	signer Signer
 
 	// State for current voting
 	block       common.Hash
@@ -36,11 +35,11 @@ type GuardianEngine struct {
 	mu       *sync.Mutex
 }
 
-func NewGuardianEngine(c *ConsensusEngine, privateKey *bls.SecretKey) *GuardianEngine {
+func NewGuardianEngine(c *ConsensusEngine, signer Signer) *GuardianEngine {
 	return &GuardianEngine{
-		logger:  util.GetLoggerForModule("guardian"),
-		engine:  c,
-		privKey: privateKey,
+		logger: util.GetLoggerForModule("guardian"),
+		engine: c,
+		signer: signer,
 
 		incoming: make(chan *core.AggregatedVotes, viper.GetInt(common.CfgConsensusMessageQueueSize)),
 		mu:       &sync.Mutex{},
@@ -67,7 +66,7 @@ func (g *GuardianEngine) StartNewBlock(block common.Hash) {
 	}
 	g.gcp = gcp
 	g.gcpHash = gcp.Hash()
-	g.signerIndex = gcp.WithStake().Index(g.privKey.PublicKey())
+	g.signerIndex = gcp.WithStake().Index(g.signer.BLSPublicKey())
 
 	g.logger.WithFields(log.Fields{
 		"block":       block.Hex(),
@@ -77,7 +76,14 @@ func (g *GuardianEngine) StartNewBlock(block common.Hash) {
 
 	if g.isGuardian() {
 		g.nextVote = core.NewAggregateVotes(block, gcp)
-		g.nextVote.Sign(g.privKey, g.signerIndex)
+		sig, err := g.signer.SignAggregatedVotes(g.nextVote)
+		if err != nil {
+			g.logger.WithFields(log.Fields{"block": block.Hex(), "error": err}).Error("Failed to sign guardian vote")
+			g.nextVote = nil
+			g.currVote = nil
+			return
+		}
+		g.nextVote.AddSignature(sig, g.signerIndex)
 		g.currVote = g.nextVote.Copy()
 	} else {
 		g.nextVote = nil
@@ -217,7 +223,7 @@ func (g *GuardianEngine) HandleVote(vote *core.AggregatedVotes) {
 	case g.incoming <- vote:
 		return
 	default:
-		g.logger.Debug("GuardianEngine queue is full, discarding vote: %v", vote)
+		g.logger.Debugf("GuardianEngine queue is full, discarding vote: %v", vote)
 	}
 }
 
@@ -0,0 +1,420 @@
+package consensus
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/rpc"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/crypto/bls"
+	"github.com/thetatoken/theta/rlp"
+)
+
+// SignerTLSServerName is the name the certificate of the signer process is issued for
+const SignerTLSServerName = "thetasigner"
+
+const (
+	signerServiceName    = "Signer"
+	remoteSignerTimeout  = 10 * time.Second
+	remoteSignerMaxRetry = 1
+)
+
+// ParseSignerAddress splits the signer address, e.g. unix:///var/run/thetasigner.sock or
+// tcp://10.0.0.2:16900, into the network and the address to dial or listen on
+func ParseSignerAddress(addr string) (network string, address string, err error) {
+	parts := strings.SplitN(addr, "://", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid signer address %v, expected unix://<path> or tcp://<host>:<port>", addr)
+	}
+	switch parts[0] {
+	case "unix", "tcp":
+		return parts[0], parts[1], nil
+	default:
+		return "", "", fmt.Errorf("unsupported signer network %v", parts[0])
+	}
+}
+
+// LoadSignerTLSConfig loads the TLS config used between the node and the signer process. Both
+// sides present a certificate issued by the given CA, and only accept peers with such a
+// certificate.
+func LoadSignerTLSConfig(certFile, keyFile, caFile string, isServer bool) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the TLS certificate: %v", err)
+	}
+	caPEM, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the CA certificate: %v", err)
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no CA certificate found in %v", caFile)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if isServer {
+		config.ClientCAs = caPool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	} else {
+		config.RootCAs = caPool
+		config.ServerName = SignerTLSServerName
+	}
+	return config, nil
+}
+
+//
+// ------- Signer Requests ------- //
+//
+
+// SignerInfoArgs is the request for the keys held by the signer
+type SignerInfoArgs struct{}
+
+// SignerInfoResult is the response to SignerInfoArgs
+type SignerInfoResult struct {
+	Address      common.Address
+	BLSPublicKey common.Bytes
+}
+
+// SignVoteArgs is the request to sign a vote
+type SignVoteArgs struct {
+	Vote common.Bytes // RLP encoded core.Vote
+}
+
+// SignProposalArgs is the request to sign the header of a proposed block
+type SignProposalArgs struct {
+	Header common.Bytes // RLP encoded core.BlockHeader
+}
+
+// SignAggregatedVotesArgs is the request to sign the guardian votes on a block
+type SignAggregatedVotesArgs struct {
+	Block common.Hash
+	Gcp   common.Hash
+}
+
+// SignTxArgs is the request to sign a coinbase or slash transaction
+type SignTxArgs struct {
+	ChainID string
+	Tx      common.Bytes
+}
+
+// SignBLSPopArgs is the request for the proof of possession of the BLS key
+type SignBLSPopArgs struct{}
+
+// SignerResult is the response to the signing requests
+type SignerResult struct {
+	Signature    common.Bytes
+	BLSSignature common.Bytes
+}
+
+// signerService exposes a Signer to the remote nodes
+type signerService struct {
+	signer Signer
+}
+
+func (s *signerService) Info(args *SignerInfoArgs, result *SignerInfoResult) error {
+	result.Address = s.signer.Address()
+	result.BLSPublicKey = s.signer.BLSPublicKey().ToBytes()
+	return nil
+}
+
+func (s *signerService) SignVote(args *SignVoteArgs, result *SignerResult) error {
+	vote := core.Vote{}
+	if err := rlp.DecodeBytes(args.Vote, &vote); err != nil {
+		return err
+	}
+	sig, err := s.signer.SignVote(vote)
+	if err != nil {
+		return err
+	}
+	result.Signature = sig.ToBytes()
+	return nil
+}
+
+func (s *signerService) SignProposal(args *SignProposalArgs, result *SignerResult) error {
+	header := &core.BlockHeader{}
+	if err := rlp.DecodeBytes(args.Header, header); err != nil {
+		return err
+	}
+	// The header encoding depends on the fork heights. Only sign if the header re-encodes to
+	// exactly the bytes sent by the node, so the signature never covers bytes the node did not see.
+	if !bytes.Equal(header.SignBytes(), args.Header) {
+		return fmt.Errorf("header %v does not re-encode to the bytes to sign, check the fork schedule of chain %v",
+			header.Height, header.ChainID)
+	}
+	sig, err := s.signer.SignProposal(header)
+	if err != nil {
+		return err
+	}
+	result.Signature = sig.ToBytes()
+	return nil
+}
+
+func (s *signerService) SignAggregatedVotes(args *SignAggregatedVotesArgs, result *SignerResult) error {
+	sig, err := s.signer.SignAggregatedVotes(&core.AggregatedVotes{Block: args.Block, Gcp: args.Gcp})
+	if err != nil {
+		return err
+	}
+	result.BLSSignature = sig.ToBytes()
+	return nil
+}
+
+func (s *signerService) SignTx(args *SignTxArgs, result *SignerResult) error {
+	sig, err := s.signer.SignTx(args.ChainID, args.Tx)
+	if err != nil {
+		return err
+	}
+	result.Signature = sig.ToBytes()
+	return nil
+}
+
+func (s *signerService) SignBLSPop(args *SignBLSPopArgs, result *SignerResult) error {
+	pop, sig, err := s.signer.SignBLSPop()
+	if err != nil {
+		return err
+	}
+	result.BLSSignature = pop.ToBytes()
+	result.Signature = sig.ToBytes()
+	return nil
+}
+
+//
+// ------- SignerServer ------- //
+//
+
+// SignerServer serves the signing requests of the remote nodes over mutually authenticated TLS
+type SignerServer struct {
+	logger *log.Entry
+
+	server    *rpc.Server
+	tlsConfig *tls.Config
+}
+
+// NewSignerServer creates a server signing the requests with the given signer. The signer
+// should enforce the signing watermarks, as the requests come from other processes.
+func NewSignerServer(signer Signer, tlsConfig *tls.Config) (*SignerServer, error) {
+	server := rpc.NewServer()
+	if err := server.RegisterName(signerServiceName, &signerService{signer: signer}); err != nil {
+		return nil, err
+	}
+	return &SignerServer{
+		logger:    logger.WithFields(log.Fields{"component": "signer"}),
+		server:    server,
+		tlsConfig: tlsConfig,
+	}, nil
+}
+
+// Serve accepts the connections on the listener until it is closed
+func (ss *SignerServer) Serve(listener net.Listener) error {
+	tlsListener := tls.NewListener(listener, ss.tlsConfig)
+	for {
+		conn, err := tlsListener.Accept()
+		if err != nil {
+			return err
+		}
+		go ss.serveConn(conn.(*tls.Conn))
+	}
+}
+
+func (ss *SignerServer) serveConn(conn *tls.Conn) {
+	if err := conn.Handshake(); err != nil {
+		ss.logger.WithFields(log.Fields{"remote": conn.RemoteAddr(), "error": err}).Warn("TLS handshake failed")
+		conn.Close()
+		return
+	}
+	ss.logger.WithFields(log.Fields{"remote": conn.RemoteAddr()}).Info("Node connected")
+	ss.server.ServeConn(conn)
+	ss.logger.WithFields(log.Fields{"remote": conn.RemoteAddr()}).Info("Node disconnected")
+}
+
+//
+// ------- RemoteSigner ------- //
+//
+
+var _ Signer = (*RemoteSigner)(nil)
+
+// RemoteSigner forwards the signing requests to a signer process over mutually authenticated TLS
+type RemoteSigner struct {
+	network   string
+	address   string
+	tlsConfig *tls.Config
+
+	mu     *sync.Mutex
+	client *rpc.Client
+
+	signerAddress common.Address
+	blsPubKey     *bls.PublicKey
+}
+
+// NewRemoteSigner connects to the signer process at the given address, e.g.
+// unix:///var/run/thetasigner.sock or tcp://10.0.0.2:16900
+func NewRemoteSigner(addr string, tlsConfig *tls.Config) (*RemoteSigner, error) {
+	network, address, err := ParseSignerAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	rs := &RemoteSigner{
+		network:   network,
+		address:   address,
+		tlsConfig: tlsConfig,
+		mu:        &sync.Mutex{},
+	}
+
+	info := &SignerInfoResult{}
+	if err := rs.call("Info", &SignerInfoArgs{}, info); err != nil {
+		return nil, fmt.Errorf("failed to query the remote signer: %v", err)
+	}
+	blsPubKey, err := bls.PublicKeyFromBytes(info.BLSPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BLS public key from the remote signer: %v", err)
+	}
+	rs.signerAddress = info.Address
+	rs.blsPubKey = blsPubKey
+	return rs, nil
+}
+
+// Address implements the Signer interface
+func (rs *RemoteSigner) Address() common.Address {
+	return rs.signerAddress
+}
+
+// BLSPublicKey implements the Signer interface
+func (rs *RemoteSigner) BLSPublicKey() *bls.PublicKey {
+	return rs.blsPubKey
+}
+
+// SignVote implements the Signer interface
+func (rs *RemoteSigner) SignVote(vote core.Vote) (*crypto.Signature, error) {
+	vote.Signature = nil
+	raw, err := rlp.EncodeToBytes(vote)
+	if err != nil {
+		return nil, err
+	}
+	result := &SignerResult{}
+	if err := rs.call("SignVote", &SignVoteArgs{Vote: raw}, result); err != nil {
+		return nil, err
+	}
+	sig, err := crypto.SignatureFromBytes(result.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if !sig.Verify(vote.SignBytes(), rs.signerAddress) {
+		return nil, errors.New("invalid vote signature from the remote signer")
+	}
+	return sig, nil
+}
+
+// SignProposal implements the Signer interface
+func (rs *RemoteSigner) SignProposal(header *core.BlockHeader) (*crypto.Signature, error) {
+	signBytes := header.SignBytes()
+	result := &SignerResult{}
+	if err := rs.call("SignProposal", &SignProposalArgs{Header: signBytes}, result); err != nil {
+		return nil, err
+	}
+	sig, err := crypto.SignatureFromBytes(result.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if !sig.Verify(signBytes, rs.signerAddress) {
+		return nil, errors.New("invalid proposal signature from the remote signer")
+	}
+	return sig, nil
+}
+
+// SignAggregatedVotes implements the Signer interface
+func (rs *RemoteSigner) SignAggregatedVotes(votes *core.AggregatedVotes) (*bls.Signature, error) {
+	result := &SignerResult{}
+	args := &SignAggregatedVotesArgs{Block: votes.Block, Gcp: votes.Gcp}
+	if err := rs.call("SignAggregatedVotes", args, result); err != nil {
+		return nil, err
+	}
+	sig, err := bls.SignatureFromBytes(result.BLSSignature)
+	if err != nil {
+		return nil, err
+	}
+	if !sig.Verify(votes.SignBytes(), rs.blsPubKey) {
+		return nil, errors.New("invalid guardian vote signature from the remote signer")
+	}
+	return sig, nil
+}
+
+// SignTx implements the Signer interface
+func (rs *RemoteSigner) SignTx(chainID string, rawTx common.Bytes) (*crypto.Signature, error) {
+	result := &SignerResult{}
+	if err := rs.call("SignTx", &SignTxArgs{ChainID: chainID, Tx: rawTx}, result); err != nil {
+		return nil, err
+	}
+	return crypto.SignatureFromBytes(result.Signature)
+}
+
+// SignBLSPop implements the Signer interface
+func (rs *RemoteSigner) SignBLSPop() (*bls.Signature, *crypto.Signature, error) {
+	result := &SignerResult{}
+	if err := rs.call("SignBLSPop", &SignBLSPopArgs{}, result); err != nil {
+		return nil, nil, err
+	}
+	pop, err := bls.SignatureFromBytes(result.BLSSignature)
+	if err != nil {
+		return nil, nil, err
+	}
+	sig, err := crypto.SignatureFromBytes(result.Signature)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pop, sig, nil
+}
+
+// call sends the request to the signer process, reconnecting if the connection was lost
+func (rs *RemoteSigner) call(method string, args interface{}, result interface{}) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	var err error
+	for i := 0; i <= remoteSignerMaxRetry; i++ {
+		if rs.client == nil {
+			if err = rs.connect(); err != nil {
+				continue
+			}
+		}
+
+		call := rs.client.Go(signerServiceName+"."+method, args, result, make(chan *rpc.Call, 1))
+		select {
+		case <-call.Done:
+			err = call.Error
+		case <-time.After(remoteSignerTimeout):
+			err = fmt.Errorf("remote signer did not respond to %v within %v", method, remoteSignerTimeout)
+		}
+		if _, ok := err.(rpc.ServerError); ok || err == nil {
+			// The signer refused the request, retrying does not help
+			return err
+		}
+
+		// The connection is broken or stuck, reconnect and retry
+		rs.client.Close()
+		rs.client = nil
+	}
+	return err
+}
+
+func (rs *RemoteSigner) connect() error {
+	dialer := &net.Dialer{Timeout: remoteSignerTimeout}
+	conn, err := tls.DialWithDialer(dialer, rs.network, rs.address, rs.tlsConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the remote signer at %v: %v", rs.address, err)
+	}
+	rs.client = rpc.NewClient(conn)
+	return nil
+}
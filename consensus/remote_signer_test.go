@@ -0,0 +1,192 @@
+package consensus
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/rlp"
+)
+
+func newTestCert(t *testing.T, template *x509.Certificate, parentKey *ecdsa.PrivateKey, parent *x509.Certificate) (*ecdsa.PrivateKey, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+	return key, cert
+}
+
+func newTestSignerTLSConfigs(t *testing.T) (serverConfig *tls.Config, clientConfig *tls.Config, rogueConfig *tls.Config) {
+	caKey, caCert := newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test CA"},
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	serverKey, serverCert := newTestCert(t, &x509.Certificate{
+		DNSNames:    []string{SignerTLSServerName},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, caKey, caCert)
+	clientKey, clientCert := newTestCert(t, &x509.Certificate{
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caKey, caCert)
+	rogueKey, rogueCert := newTestCert(t, &x509.Certificate{
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, nil, nil)
+
+	caPool := x509.NewCertPool()
+	caPool.AddCert(caCert)
+	serverConfig = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.Raw}, PrivateKey: serverKey}},
+		ClientCAs:    caPool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	clientConfig = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{clientCert.Raw}, PrivateKey: clientKey}},
+		RootCAs:      caPool,
+		ServerName:   SignerTLSServerName,
+	}
+	rogueConfig = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{rogueCert.Raw}, PrivateKey: rogueKey}},
+		RootCAs:      caPool,
+		ServerName:   SignerTLSServerName,
+	}
+	return serverConfig, clientConfig, rogueConfig
+}
+
+func TestRemoteSigner(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "remote_signer")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+
+	privKey, _, err := crypto.GenerateKeyPair()
+	require.Nil(err)
+	watermarks, err := NewWatermarkStore(filepath.Join(dir, "signing_watermark.json"), "testchain")
+	require.Nil(err)
+	localSigner, err := NewLocalSigner(privKey, watermarks)
+	require.Nil(err)
+
+	serverConfig, clientConfig, rogueConfig := newTestSignerTLSConfigs(t)
+	server, err := NewSignerServer(localSigner, serverConfig)
+	require.Nil(err)
+	socketPath := filepath.Join(dir, "signer.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.Nil(err)
+	defer listener.Close()
+	go server.Serve(listener)
+
+	// Clients without a certificate issued by the CA are rejected
+	_, err = NewRemoteSigner("unix://"+socketPath, rogueConfig)
+	assert.NotNil(err)
+
+	signer, err := NewRemoteSigner("unix://"+socketPath, clientConfig)
+	require.Nil(err)
+	assert.Equal(localSigner.Address(), signer.Address())
+	assert.True(localSigner.BLSPublicKey().Equals(signer.BLSPublicKey()))
+
+	// Votes
+	vote := core.Vote{Block: common.BytesToHash([]byte("block_a")), Height: 10, Epoch: 12, ID: signer.Address()}
+	sig, err := signer.SignVote(vote)
+	require.Nil(err)
+	vote.SetSignature(sig)
+	assert.True(vote.Validate().IsOK())
+	conflicting := core.Vote{Block: common.BytesToHash([]byte("block_b")), Height: 10, Epoch: 13, ID: signer.Address()}
+	_, err = signer.SignVote(conflicting)
+	assert.NotNil(err)
+
+	// Proposals
+	block := core.NewBlock()
+	block.ChainID = "testchain"
+	block.Epoch = 20
+	block.Height = 11
+	block.Parent = vote.Block
+	block.Proposer = signer.Address()
+	block.Timestamp = big.NewInt(time.Now().Unix())
+	sig, err = signer.SignProposal(block.BlockHeader)
+	require.Nil(err)
+	block.SetSignature(sig)
+	assert.True(sig.Verify(block.SignBytes(), signer.Address()))
+	block.Timestamp = big.NewInt(block.Timestamp.Int64() + 1)
+	_, err = signer.SignProposal(block.BlockHeader)
+	assert.NotNil(err)
+
+	// Guardian votes
+	votes := &core.AggregatedVotes{Block: block.Hash(), Gcp: common.BytesToHash([]byte("gcp"))}
+	blsSig, err := signer.SignAggregatedVotes(votes)
+	require.Nil(err)
+	assert.True(blsSig.Verify(votes.SignBytes(), signer.BLSPublicKey()))
+
+	pop, sig, err := signer.SignBLSPop()
+	require.Nil(err)
+	assert.True(pop.PopVerify(signer.BLSPublicKey()))
+	assert.True(sig.Verify(pop.ToBytes(), signer.Address()))
+}
+
+func TestSignerServiceRejectsNonCanonicalHeader(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "remote_signer")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+
+	privKey, _, err := crypto.GenerateKeyPair()
+	require.Nil(err)
+	watermarks, err := NewWatermarkStore(filepath.Join(dir, "signing_watermark.json"), "testchain")
+	require.Nil(err)
+	localSigner, err := NewLocalSigner(privKey, watermarks)
+	require.Nil(err)
+	service := &signerService{signer: localSigner}
+
+	block := core.NewBlock()
+	block.ChainID = "testchain"
+	block.Epoch = 20
+	block.Height = common.GetForkSchedule(block.ChainID).EnableTheta2
+	block.Proposer = localSigner.Address()
+	block.Timestamp = big.NewInt(time.Now().Unix())
+	signBytes := block.SignBytes()
+
+	// Replace the guardian votes with bytes that decode, but do not re-encode to the same bytes
+	fields := []rlp.RawValue{}
+	require.Nil(rlp.DecodeBytes(signBytes, &fields))
+	garbage, err := rlp.EncodeToBytes([]interface{}{uint64(1)})
+	require.Nil(err)
+	fields[len(fields)-1] = garbage
+	tampered, err := rlp.EncodeToBytes(fields)
+	require.Nil(err)
+
+	err = service.SignProposal(&SignProposalArgs{Header: tampered}, &SignerResult{})
+	assert.NotNil(err)
+
+	result := &SignerResult{}
+	require.Nil(service.SignProposal(&SignProposalArgs{Header: signBytes}, result))
+	sig, err := crypto.SignatureFromBytes(result.Signature)
+	require.Nil(err)
+	assert.True(sig.Verify(signBytes, localSigner.Address()))
+}
@@ -0,0 +1,147 @@
+package consensus
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/crypto/bls"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// Signer holds the validator key and the derived guardian/elite edge node BLS key, and signs
+// the messages the node produces with them. The keys can either live in the node process
+// (LocalSigner), or in a separate signer process (RemoteSigner).
+type Signer interface {
+	// Address returns the address of the validator key
+	Address() common.Address
+
+	// BLSPublicKey returns the public key of the BLS key
+	BLSPublicKey() *bls.PublicKey
+
+	// SignVote signs the vote, refusing votes that conflict with the ones signed before
+	SignVote(vote core.Vote) (*crypto.Signature, error)
+
+	// SignProposal signs the header of the proposed block, refusing proposals that conflict
+	// with the ones signed before
+	SignProposal(header *core.BlockHeader) (*crypto.Signature, error)
+
+	// SignAggregatedVotes signs the guardian votes with the BLS key
+	SignAggregatedVotes(votes *core.AggregatedVotes) (*bls.Signature, error)
+
+	// SignTx signs the raw transaction. Only the coinbase, slash and evidence transactions
+	// proposed by the validator are signed.
+	SignTx(chainID string, rawTx common.Bytes) (*crypto.Signature, error)
+
+	// SignBLSPop returns the proof of possession of the BLS key, and its signature by the
+	// validator key
+	SignBLSPop() (*bls.Signature, *crypto.Signature, error)
+}
+
+var _ Signer = (*LocalSigner)(nil)
+
+// LocalSigner signs with keys held in memory. It is used by the node when no remote signer is
+// configured, and by the signer process to serve the requests of the remote nodes.
+type LocalSigner struct {
+	privKey    *crypto.PrivateKey
+	blsKey     *bls.SecretKey
+	watermarks *WatermarkStore
+}
+
+// NewLocalSigner creates a signer with the given validator key. The BLS key is derived from
+// the validator key. If the watermark store is not nil, it is consulted before signing votes
+// and proposals.
+func NewLocalSigner(privKey *crypto.PrivateKey, watermarks *WatermarkStore) (*LocalSigner, error) {
+	blsKey, err := bls.GenKey(strings.NewReader(common.Bytes2Hex(privKey.PublicKey().ToBytes())))
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the BLS key: %v", err)
+	}
+	return &LocalSigner{
+		privKey:    privKey,
+		blsKey:     blsKey,
+		watermarks: watermarks,
+	}, nil
+}
+
+// PrivateKey returns the validator key
+func (ls *LocalSigner) PrivateKey() *crypto.PrivateKey {
+	return ls.privKey
+}
+
+// Address implements the Signer interface
+func (ls *LocalSigner) Address() common.Address {
+	return ls.privKey.PublicKey().Address()
+}
+
+// BLSPublicKey implements the Signer interface
+func (ls *LocalSigner) BLSPublicKey() *bls.PublicKey {
+	return ls.blsKey.PublicKey()
+}
+
+// SignVote implements the Signer interface
+func (ls *LocalSigner) SignVote(vote core.Vote) (*crypto.Signature, error) {
+	if vote.ID != ls.Address() {
+		return nil, fmt.Errorf("vote is cast by %v, not by the signer %v", vote.ID.Hex(), ls.Address().Hex())
+	}
+	if ls.watermarks != nil {
+		if err := ls.watermarks.CheckAndRecordVote(vote.ID, vote.Height, vote.Block); err != nil {
+			return nil, err
+		}
+	}
+	return ls.privKey.Sign(vote.SignBytes())
+}
+
+// SignProposal implements the Signer interface
+func (ls *LocalSigner) SignProposal(header *core.BlockHeader) (*crypto.Signature, error) {
+	if header.Proposer != ls.Address() {
+		return nil, fmt.Errorf("block is proposed by %v, not by the signer %v", header.Proposer.Hex(), ls.Address().Hex())
+	}
+	signBytes := header.SignBytes()
+	if ls.watermarks != nil {
+		err := ls.watermarks.CheckAndRecordProposal(header.Proposer, header.Epoch, crypto.Keccak256Hash(signBytes))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ls.privKey.Sign(signBytes)
+}
+
+// SignAggregatedVotes implements the Signer interface
+func (ls *LocalSigner) SignAggregatedVotes(votes *core.AggregatedVotes) (*bls.Signature, error) {
+	return ls.blsKey.Sign(votes.SignBytes()), nil
+}
+
+// SignTx implements the Signer interface
+func (ls *LocalSigner) SignTx(chainID string, rawTx common.Bytes) (*crypto.Signature, error) {
+	tx, err := types.TxFromBytes(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	var proposer common.Address
+	switch tx := tx.(type) {
+	case *types.CoinbaseTx:
+		proposer = tx.Proposer.Address
+	case *types.SlashTx:
+		proposer = tx.Proposer.Address
+	case *types.EvidenceTx:
+		proposer = tx.Proposer.Address
+	default:
+		return nil, fmt.Errorf("signing %T is not allowed", tx)
+	}
+	if proposer != ls.Address() {
+		return nil, fmt.Errorf("transaction is proposed by %v, not by the signer %v", proposer.Hex(), ls.Address().Hex())
+	}
+	return ls.privKey.Sign(tx.SignBytes(chainID))
+}
+
+// SignBLSPop implements the Signer interface
+func (ls *LocalSigner) SignBLSPop() (*bls.Signature, *crypto.Signature, error) {
+	pop := ls.blsKey.PopProve()
+	sig, err := ls.privKey.Sign(pop.ToBytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	return pop, sig, nil
+}
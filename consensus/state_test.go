@@ -25,12 +25,12 @@ func TestConsensusStateBasic(t *testing.T) {
 	vote := core.Vote{
 		Height: 10,
 	}
-	state1 := NewState(db, chain)
+	state1 := NewState(db, chain, nil)
 	state1.SetEpoch(3)
 	state1.SetLastVote(vote)
 	state1.SetHighestCCBlock(cc)
 
-	state2 := NewState(db, chain)
+	state2 := NewState(db, chain, nil)
 	assert.Equal(uint64(3), state2.GetEpoch())
 	assert.Equal(uint64(10), state2.GetLastVote().Height)
 	assert.NotNil(state2.GetHighestCCBlock())
@@ -50,7 +50,7 @@ func TestConsensusStateVoteSet(t *testing.T) {
 	block1 := core.CreateTestBlock("A1", "A0")
 	block2 := core.CreateTestBlock("A2", "A1")
 
-	state1 := NewState(db, chain)
+	state1 := NewState(db, chain, nil)
 	vote1 := &core.Vote{
 		Block: block1.Hash(),
 		ID:    common.HexToAddress("A1"),
@@ -70,8 +70,8 @@ func TestConsensusStateVoteSet(t *testing.T) {
 	state1.AddVote(vote2)
 	state1.AddVote(vote3)
 
-	state2 := NewState(db, chain)
-	state2.Load()
+	state2 := NewState(db, chain, nil)
+	state2.Load(nil)
 	vs1, _ := state2.GetEpochVotes()
 	votes := vs1.Votes()
 	assert.Equal(2, len(votes))
@@ -79,8 +79,8 @@ func TestConsensusStateVoteSet(t *testing.T) {
 	assert.Equal(uint64(20), votes[0].Epoch)
 
 	db = kvstore.NewKVStore(backend.NewMemDatabase())
-	state3 := NewState(db, chain)
-	state3.Load()
+	state3 := NewState(db, chain, nil)
+	state3.Load(nil)
 	state3.AddEpochVote(&core.Vote{
 		Block: block1.Hash(),
 		ID:    common.HexToAddress("A2"),
@@ -0,0 +1,258 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/thetatoken/theta/common"
+)
+
+// WatermarkInterchangeFormatVersion is the version of the interchange format used to move
+// the signing watermarks between nodes
+const WatermarkInterchangeFormatVersion = "1"
+
+// SigningWatermark records the highest vote and proposal signed with a validator key. A
+// watermark with a height/epoch but an empty block hash refuses to sign anything at that
+// height/epoch, which happens when conflicting watermarks were merged by an import. Since the
+// hash of a block covers its signature, a proposal is identified by the hash of the sign bytes
+// of the block header instead.
+type SigningWatermark struct {
+	Address       common.Address `json:"address"`
+	VoteHeight    uint64         `json:"vote_height"`
+	VoteBlock     common.Hash    `json:"vote_block"`
+	ProposalEpoch uint64         `json:"proposal_epoch"`
+	ProposalBlock common.Hash    `json:"proposal_block"`
+}
+
+func (w *SigningWatermark) checkVote(height uint64, block common.Hash) error {
+	if w.VoteHeight == 0 {
+		return nil
+	}
+	if height < w.VoteHeight {
+		return fmt.Errorf("vote at height %v is below the signing watermark %v", height, w.VoteHeight)
+	}
+	if height == w.VoteHeight && block != w.VoteBlock {
+		return fmt.Errorf("vote for block %v conflicts with the vote for block %v at height %v", block.Hex(), w.VoteBlock.Hex(), height)
+	}
+	return nil
+}
+
+func (w *SigningWatermark) checkProposal(epoch uint64, block common.Hash) error {
+	if w.ProposalEpoch == 0 {
+		return nil
+	}
+	if epoch < w.ProposalEpoch {
+		return fmt.Errorf("proposal at epoch %v is below the signing watermark %v", epoch, w.ProposalEpoch)
+	}
+	if epoch == w.ProposalEpoch && block != w.ProposalBlock {
+		return fmt.Errorf("proposal of block %v conflicts with the proposal of block %v at epoch %v", block.Hex(), w.ProposalBlock.Hex(), epoch)
+	}
+	return nil
+}
+
+// merge raises the watermark to the other one
+func (w *SigningWatermark) merge(other *SigningWatermark) {
+	if other.VoteHeight > w.VoteHeight {
+		w.VoteHeight, w.VoteBlock = other.VoteHeight, other.VoteBlock
+	} else if other.VoteHeight == w.VoteHeight && other.VoteBlock != w.VoteBlock {
+		w.VoteBlock = common.Hash{}
+	}
+	if other.ProposalEpoch > w.ProposalEpoch {
+		w.ProposalEpoch, w.ProposalBlock = other.ProposalEpoch, other.ProposalBlock
+	} else if other.ProposalEpoch == w.ProposalEpoch && other.ProposalBlock != w.ProposalBlock {
+		w.ProposalBlock = common.Hash{}
+	}
+}
+
+// WatermarkInterchange is the format the signing watermarks are exported and imported in
+type WatermarkInterchange struct {
+	Version    string              `json:"interchange_format_version"`
+	ChainID    string              `json:"chain_id"`
+	Watermarks []*SigningWatermark `json:"watermarks"`
+}
+
+// WatermarkStore keeps the signing watermarks of the validator keys in a file, which is
+// updated before each vote or proposal is signed. Keeping the file outside of the data path
+// protects the keys from signing conflicting votes/proposals after the data path is restored
+// or wiped.
+type WatermarkStore struct {
+	mu *sync.Mutex
+
+	filePath   string
+	chainID    string
+	watermarks map[common.Address]*SigningWatermark
+}
+
+// NewWatermarkStore creates a watermark store backed by the given file, loading the
+// watermarks from it if it exists
+func NewWatermarkStore(filePath string, chainID string) (*WatermarkStore, error) {
+	ws := &WatermarkStore{
+		mu:         &sync.Mutex{},
+		filePath:   filePath,
+		chainID:    chainID,
+		watermarks: make(map[common.Address]*SigningWatermark),
+	}
+
+	data, err := ioutil.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return ws, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := ws.importInterchange(data); err != nil {
+		return nil, fmt.Errorf("failed to load signing watermarks from %v: %v", filePath, err)
+	}
+	return ws, nil
+}
+
+// Get returns the signing watermark of the given key
+func (ws *WatermarkStore) Get(addr common.Address) SigningWatermark {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if w, ok := ws.watermarks[addr]; ok {
+		return *w
+	}
+	return SigningWatermark{Address: addr}
+}
+
+// CheckAndRecordVote checks that the key has not signed a conflicting vote, and raises its
+// watermark to the vote. The vote must not be signed if an error is returned.
+func (ws *WatermarkStore) CheckAndRecordVote(addr common.Address, height uint64, block common.Hash) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	w := ws.getOrCreate(addr)
+	if err := w.checkVote(height, block); err != nil {
+		return err
+	}
+	if w.VoteHeight == height && w.VoteBlock == block {
+		return nil // repeating the same vote
+	}
+
+	updated := *w
+	updated.VoteHeight, updated.VoteBlock = height, block
+	return ws.save(&updated)
+}
+
+// CheckAndRecordProposal checks that the key has not signed a conflicting proposal, and
+// raises its watermark to the proposal. The proposal must not be broadcasted if an error is
+// returned.
+func (ws *WatermarkStore) CheckAndRecordProposal(addr common.Address, epoch uint64, block common.Hash) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	w := ws.getOrCreate(addr)
+	if err := w.checkProposal(epoch, block); err != nil {
+		return err
+	}
+	if w.ProposalEpoch == epoch && w.ProposalBlock == block {
+		return nil // repeating the same proposal
+	}
+
+	updated := *w
+	updated.ProposalEpoch, updated.ProposalBlock = epoch, block
+	return ws.save(&updated)
+}
+
+// Export returns the signing watermarks in the interchange format
+func (ws *WatermarkStore) Export() ([]byte, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return ws.exportInterchange(ws.watermarks)
+}
+
+// Import merges the signing watermarks in the interchange format into the store, keeping
+// the higher watermark of each key
+func (ws *WatermarkStore) Import(data []byte) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	backup := make(map[common.Address]*SigningWatermark)
+	for addr, w := range ws.watermarks {
+		copied := *w
+		backup[addr] = &copied
+	}
+	if err := ws.importInterchange(data); err != nil {
+		ws.watermarks = backup
+		return err
+	}
+	if err := ws.writeFile(ws.watermarks); err != nil {
+		ws.watermarks = backup
+		return err
+	}
+	return nil
+}
+
+func (ws *WatermarkStore) getOrCreate(addr common.Address) *SigningWatermark {
+	w, ok := ws.watermarks[addr]
+	if !ok {
+		w = &SigningWatermark{Address: addr}
+		ws.watermarks[addr] = w
+	}
+	return w
+}
+
+// save persists the store with the updated watermark, and only then applies the update in memory
+func (ws *WatermarkStore) save(updated *SigningWatermark) error {
+	watermarks := make(map[common.Address]*SigningWatermark)
+	for addr, w := range ws.watermarks {
+		watermarks[addr] = w
+	}
+	watermarks[updated.Address] = updated
+
+	if err := ws.writeFile(watermarks); err != nil {
+		return fmt.Errorf("failed to persist the signing watermark: %v", err)
+	}
+	ws.watermarks = watermarks
+	return nil
+}
+
+func (ws *WatermarkStore) writeFile(watermarks map[common.Address]*SigningWatermark) error {
+	data, err := ws.exportInterchange(watermarks)
+	if err != nil {
+		return err
+	}
+	return common.WriteFileAtomic(ws.filePath, data, 0600)
+}
+
+func (ws *WatermarkStore) exportInterchange(watermarks map[common.Address]*SigningWatermark) ([]byte, error) {
+	interchange := WatermarkInterchange{
+		Version:    WatermarkInterchangeFormatVersion,
+		ChainID:    ws.chainID,
+		Watermarks: []*SigningWatermark{},
+	}
+	for _, w := range watermarks {
+		interchange.Watermarks = append(interchange.Watermarks, w)
+	}
+	sort.Slice(interchange.Watermarks, func(i, j int) bool {
+		return interchange.Watermarks[i].Address.Hex() < interchange.Watermarks[j].Address.Hex()
+	})
+	return json.MarshalIndent(interchange, "", "  ")
+}
+
+func (ws *WatermarkStore) importInterchange(data []byte) error {
+	interchange := WatermarkInterchange{}
+	if err := json.Unmarshal(data, &interchange); err != nil {
+		return err
+	}
+	if interchange.Version != WatermarkInterchangeFormatVersion {
+		return fmt.Errorf("unsupported interchange format version: %v", interchange.Version)
+	}
+	if interchange.ChainID != ws.chainID {
+		return fmt.Errorf("the signing watermarks are for chain %v, not %v", interchange.ChainID, ws.chainID)
+	}
+	for _, w := range interchange.Watermarks {
+		if w == nil {
+			continue
+		}
+		ws.getOrCreate(w.Address).merge(w)
+	}
+	return nil
+}
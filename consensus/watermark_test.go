@@ -0,0 +1,85 @@
+package consensus
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+)
+
+func TestWatermarkStoreRefusesConflicts(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "watermark")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "signing_watermark.json")
+
+	addr := common.HexToAddress("0x2E833968E5bB786Ae419c4d13189fB081Cc43bab")
+	blockA := common.BytesToHash([]byte("block_a"))
+	blockB := common.BytesToHash([]byte("block_b"))
+
+	ws, err := NewWatermarkStore(filePath, "privatenet")
+	require.Nil(err)
+	require.Nil(ws.CheckAndRecordVote(addr, 10, blockA))
+	assert.Nil(ws.CheckAndRecordVote(addr, 10, blockA))
+	assert.NotNil(ws.CheckAndRecordVote(addr, 10, blockB))
+	assert.NotNil(ws.CheckAndRecordVote(addr, 9, blockB))
+	require.Nil(ws.CheckAndRecordProposal(addr, 5, blockA))
+	assert.NotNil(ws.CheckAndRecordProposal(addr, 5, blockB))
+
+	// The watermarks survive a restart
+	ws, err = NewWatermarkStore(filePath, "privatenet")
+	require.Nil(err)
+	assert.NotNil(ws.CheckAndRecordVote(addr, 10, blockB))
+	assert.NotNil(ws.CheckAndRecordProposal(addr, 4, blockB))
+	assert.Nil(ws.CheckAndRecordVote(addr, 11, blockB))
+	assert.Equal(uint64(11), ws.Get(addr).VoteHeight)
+
+	_, err = NewWatermarkStore(filePath, "another_chain")
+	assert.NotNil(err)
+}
+
+func TestWatermarkStoreExportImport(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "watermark")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+
+	addr := common.HexToAddress("0x2E833968E5bB786Ae419c4d13189fB081Cc43bab")
+	blockA := common.BytesToHash([]byte("block_a"))
+	blockB := common.BytesToHash([]byte("block_b"))
+
+	src, err := NewWatermarkStore(filepath.Join(dir, "src.json"), "privatenet")
+	require.Nil(err)
+	require.Nil(src.CheckAndRecordVote(addr, 20, blockA))
+	require.Nil(src.CheckAndRecordProposal(addr, 7, blockA))
+	data, err := src.Export()
+	require.Nil(err)
+
+	dst, err := NewWatermarkStore(filepath.Join(dir, "dst.json"), "privatenet")
+	require.Nil(err)
+	require.Nil(dst.CheckAndRecordVote(addr, 20, blockB))
+	require.Nil(dst.CheckAndRecordProposal(addr, 3, blockB))
+	require.Nil(dst.Import(data))
+
+	// Conflicting watermarks at the same height refuse to sign anything at that height
+	w := dst.Get(addr)
+	assert.Equal(uint64(20), w.VoteHeight)
+	assert.Equal(common.Hash{}, w.VoteBlock)
+	assert.NotNil(dst.CheckAndRecordVote(addr, 20, blockA))
+	assert.NotNil(dst.CheckAndRecordVote(addr, 20, blockB))
+	assert.Equal(uint64(7), w.ProposalEpoch)
+	assert.Equal(blockA, w.ProposalBlock)
+
+	other, err := NewWatermarkStore(filepath.Join(dir, "other.json"), "another_chain")
+	require.Nil(err)
+	assert.NotNil(other.Import(data))
+}
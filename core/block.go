@@ -135,6 +135,8 @@ type BlockHeader struct {
 	Timestamp          *big.Int
 	Proposer           common.Address
 	Signature          *crypto.Signature
+	GasUsed            uint64   // Added in the dynamic base fee fork.
+	BaseFee            *big.Int // Added in the dynamic base fee fork.
 
 	hash common.Hash // Cache of calculated hash.
 }
@@ -146,7 +148,8 @@ func (h *BlockHeader) EncodeRLP(w io.Writer) error {
 	if h == nil {
 		return rlp.Encode(w, &BlockHeader{})
 	}
-	if h.Height < common.HeightEnableTheta2 {
+	forks := common.GetForkSchedule(h.ChainID)
+	if h.Height < forks.EnableTheta2 {
 		return rlp.Encode(w, []interface{}{
 			h.ChainID,
 			h.Epoch,
@@ -164,7 +167,7 @@ func (h *BlockHeader) EncodeRLP(w io.Writer) error {
 	}
 
 	// Theta2.0 fork
-	if h.Height >= common.HeightEnableTheta2 && h.Height < common.HeightEnableTheta3 {
+	if h.Height >= forks.EnableTheta2 && h.Height < forks.EnableTheta3 {
 		return rlp.Encode(w, []interface{}{
 			h.ChainID,
 			h.Epoch,
@@ -183,6 +186,26 @@ func (h *BlockHeader) EncodeRLP(w io.Writer) error {
 	}
 
 	// Theta3.0 fork
+	if h.Height >= forks.EnableTheta3 && h.Height < forks.EnableDynamicBaseFee {
+		return rlp.Encode(w, []interface{}{
+			h.ChainID,
+			h.Epoch,
+			h.Height,
+			h.Parent,
+			h.HCC,
+			h.TxHash,
+			h.ReceiptHash,
+			h.Bloom,
+			h.StateHash,
+			h.Timestamp,
+			h.Proposer,
+			h.Signature,
+			h.GuardianVotes,
+			h.EliteEdgeNodeVotes,
+		})
+	}
+
+	// Dynamic base fee fork
 	return rlp.Encode(w, []interface{}{
 		h.ChainID,
 		h.Epoch,
@@ -198,6 +221,8 @@ func (h *BlockHeader) EncodeRLP(w io.Writer) error {
 		h.Signature,
 		h.GuardianVotes,
 		h.EliteEdgeNodeVotes,
+		h.GasUsed,
+		h.BaseFee,
 	})
 }
 
@@ -270,8 +295,10 @@ func (h *BlockHeader) DecodeRLP(stream *rlp.Stream) error {
 		return err
 	}
 
+	forks := common.GetForkSchedule(h.ChainID)
+
 	// Theta2.0 fork
-	if h.Height >= common.HeightEnableTheta2 {
+	if h.Height >= forks.EnableTheta2 {
 		raw, err := stream.Raw()
 		if err != nil {
 			return err
@@ -290,7 +317,7 @@ func (h *BlockHeader) DecodeRLP(stream *rlp.Stream) error {
 	}
 
 	// Theta3.0 fork
-	if h.Height >= common.HeightEnableTheta3 {
+	if h.Height >= forks.EnableTheta3 {
 		raw, err := stream.Raw()
 		if err != nil {
 			return err
@@ -307,6 +334,20 @@ func (h *BlockHeader) DecodeRLP(stream *rlp.Stream) error {
 		}
 	}
 
+	// Dynamic base fee fork
+	if h.Height >= forks.EnableDynamicBaseFee {
+		err = stream.Decode(&h.GasUsed)
+		if err != nil {
+			return err
+		}
+
+		h.BaseFee = new(big.Int)
+		err = stream.Decode(h.BaseFee)
+		if err != nil {
+			return err
+		}
+	}
+
 	return stream.ListEnd()
 }
 
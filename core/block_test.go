@@ -42,7 +42,7 @@ func TestBlockEncoding(t *testing.T) {
 	require.Equal(b2raw1, b2raw2)
 
 	// Should be able to encode/decode blocks after Theta2.0 fork.
-	b2.Height = common.HeightEnableTheta2
+	b2.Height = common.GetForkSchedule(b2.ChainID).EnableTheta2
 	b2raw1, _ = rlp.EncodeToBytes(b2)
 	err = rlp.DecodeBytes(b2raw1, tmp)
 	require.Nil(err)
@@ -8,7 +8,8 @@ import (
 // ConsensusEngine is the interface of a consensus engine.
 type ConsensusEngine interface {
 	ID() string
-	PrivateKey() *crypto.PrivateKey
+	PrivateKey() *crypto.PrivateKey // nil if the keys are held by a remote signer
+	SignTx(chainID string, rawTx common.Bytes) (*crypto.Signature, error)
 	GetTip(includePendingBlockingLeaf bool) *ExtendedBlock
 	GetEpoch() uint64
 	GetLedger() Ledger
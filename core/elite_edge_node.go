@@ -292,6 +292,16 @@ func (een *EliteEdgeNode) WithdrawStake(source common.Address, currentHeight uin
 	return een.StakeHolder.withdrawStake(source, currentHeight)
 }
 
+// WithdrawPartialStake withdraws the given amount from the stake of the source. The remaining
+// stake of the source needs to meet the given minimum elite edge node stake.
+func (een *EliteEdgeNode) WithdrawPartialStake(source common.Address, amount *big.Int, minEliteEdgeNodeStake *big.Int, currentHeight uint64) (*Stake, error) {
+	return een.StakeHolder.withdrawPartialStake(source, amount, minEliteEdgeNodeStake, currentHeight)
+}
+
+func (een *EliteEdgeNode) RemoveStake(source common.Address) (*Stake, error) {
+	return een.StakeHolder.removeStake(source)
+}
+
 func (een *EliteEdgeNode) ReturnStake(source common.Address, currentHeight uint64) (*Stake, error) {
 	return een.StakeHolder.returnStake(source, currentHeight)
 }
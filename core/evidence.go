@@ -0,0 +1,109 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/rlp"
+)
+
+const (
+	// MaxEquivocationEvidenceAge is the number of blocks after the conflicting votes within
+	// which the evidence can be submitted. It is shorter than the stake return locking period,
+	// so the offender cannot get the stake back before being slashed.
+	MaxEquivocationEvidenceAge uint64 = ReturnLockingPeriod / 2
+
+	// EquivocationJailPeriod is the number of blocks the stakes of the offender are locked for
+	// after being slashed. No stake can be deposited to the offender in the meantime.
+	EquivocationJailPeriod uint64 = 3 * ReturnLockingPeriod
+
+	// EquivocationSlashPercentage is the percentage of the stakes of the offender to be burned
+	EquivocationSlashPercentage int64 = 5
+
+	// MaxNumEvidenceTxsPerBlock is the maximum number of evidence transactions a proposer
+	// includes in a block
+	MaxNumEvidenceTxsPerBlock int = 8
+)
+
+// EquivocationEvidence proves that a validator voted for two different blocks at the same
+// height. Since the height is not covered by the vote signature, the headers of the two
+// blocks are included to prove their heights. The votes are ordered by the block hashes, so
+// the same pair of votes always makes the same evidence.
+type EquivocationEvidence struct {
+	VoteA   Vote
+	HeaderA *BlockHeader
+	VoteB   Vote
+	HeaderB *BlockHeader
+}
+
+// NewEquivocationEvidence creates the evidence from the two conflicting votes and the headers
+// of the blocks voted for.
+func NewEquivocationEvidence(voteA Vote, headerA *BlockHeader, voteB Vote, headerB *BlockHeader) *EquivocationEvidence {
+	if bytes.Compare(voteA.Block[:], voteB.Block[:]) > 0 {
+		voteA, voteB = voteB, voteA
+		headerA, headerB = headerB, headerA
+	}
+	return &EquivocationEvidence{
+		VoteA:   voteA,
+		HeaderA: headerA,
+		VoteB:   voteB,
+		HeaderB: headerB,
+	}
+}
+
+// Offender returns the address of the validator who cast the conflicting votes.
+func (ev *EquivocationEvidence) Offender() common.Address {
+	return ev.VoteA.ID
+}
+
+// Height returns the height of the blocks voted for.
+func (ev *EquivocationEvidence) Height() uint64 {
+	if ev.HeaderA == nil {
+		return 0
+	}
+	return ev.HeaderA.Height
+}
+
+// Hash calculates the hash of the evidence.
+func (ev *EquivocationEvidence) Hash() common.Hash {
+	raw, _ := rlp.EncodeToBytes(ev)
+	return crypto.Keccak256Hash(raw)
+}
+
+// Validate checks that the votes are signed by the same validator, and are for two different
+// blocks of the given chain at the same height.
+func (ev *EquivocationEvidence) Validate(chainID string) result.Result {
+	if ev.HeaderA == nil || ev.HeaderB == nil {
+		return result.Error("Block header is missing")
+	}
+	if ev.VoteA.ID != ev.VoteB.ID {
+		return result.Error("Votes are cast by different validators: %v, %v", ev.VoteA.ID.Hex(), ev.VoteB.ID.Hex())
+	}
+	if bytes.Compare(ev.VoteA.Block[:], ev.VoteB.Block[:]) >= 0 {
+		return result.Error("Votes are not for different blocks in order")
+	}
+	if res := ev.VoteA.Validate(); res.IsError() {
+		return res
+	}
+	if res := ev.VoteB.Validate(); res.IsError() {
+		return res
+	}
+	if ev.HeaderA.CalculateHash() != ev.VoteA.Block || ev.HeaderB.CalculateHash() != ev.VoteB.Block {
+		return result.Error("Block header does not match the vote")
+	}
+	if ev.HeaderA.ChainID != chainID || ev.HeaderB.ChainID != chainID {
+		return result.Error("ChainID mismatch")
+	}
+	if ev.HeaderA.Height != ev.HeaderB.Height {
+		return result.Error("Blocks are at different heights: %v, %v", ev.HeaderA.Height, ev.HeaderB.Height)
+	}
+	return result.OK
+}
+
+func (ev *EquivocationEvidence) String() string {
+	return fmt.Sprintf("EquivocationEvidence{offender: %v, height: %v, blockA: %v, blockB: %v}",
+		ev.Offender().Hex(), ev.Height(), ev.VoteA.Block.Hex(), ev.VoteB.Block.Hex())
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+)
+
+// FinalityCertificate is a compact, self-contained proof that a block is finalized. The block is
+// finalized since CommitHeader directly extends and certifies it (i.e. its Parent and HCC both
+// point to the block), and CommitHeader is committed by the validator signatures.
+//
+// The signers are encoded as a bitmap over the validator set of CommitHeader sorted by ID, with
+// the vote epoch and signature of each signer in the same order. The validator set is the one
+// proven by the certificate of Header.HCC.BlockHash, and ValidatorsProof in turn proves the
+// validator set for the certificate of the next directly finalized block.
+type FinalityCertificate struct {
+	Header          *BlockHeader        `json:"header"`
+	CommitHeader    *BlockHeader        `json:"commit_header"`
+	SignerBitmap    common.Bytes        `json:"signer_bitmap"`
+	Epochs          []uint64            `json:"epochs"`
+	Signatures      []*crypto.Signature `json:"signatures"`
+	ValidatorsProof VCPProof            `json:"validators_proof"` // VCP proof against Header.StateHash
+
+	// Guardian and elite edge node votes for the block. They are only available for checkpoint
+	// blocks, and are attached once the votes are included in a later finalized block.
+	GuardianVotes      *AggregatedVotes    `json:"guardian_votes" rlp:"nil"`
+	EliteEdgeNodeVotes *AggregatedEENVotes `json:"elite_edge_node_votes" rlp:"nil"`
+}
+
+// NewFinalityCertificate creates the finality certificate of the block from its commit header,
+// the validator set of the commit header and the votes for it. Votes not cast by the validators
+// in the set are left out.
+func NewFinalityCertificate(header, commitHeader *BlockHeader, validators *ValidatorSet, votes *VoteSet, proof *VCPProof) *FinalityCertificate {
+	cert := &FinalityCertificate{
+		Header:          header,
+		CommitHeader:    commitHeader,
+		SignerBitmap:    make(common.Bytes, (validators.Size()+7)/8),
+		Epochs:          []uint64{},
+		Signatures:      []*crypto.Signature{},
+		ValidatorsProof: *proof,
+	}
+
+	voteByID := make(map[common.Address]Vote)
+	for _, vote := range votes.UniqueVoter().Votes() {
+		if vote.Block == commitHeader.Hash() {
+			voteByID[vote.ID] = vote
+		}
+	}
+	for i, validator := range validators.Validators() {
+		vote, ok := voteByID[validator.ID()]
+		if !ok {
+			continue
+		}
+		cert.SignerBitmap[i/8] |= 1 << uint(i%8)
+		cert.Epochs = append(cert.Epochs, vote.Epoch)
+		cert.Signatures = append(cert.Signatures, vote.Signature)
+	}
+	return cert
+}
+
+// Votes reconstructs the votes for the commit header from the signer bitmap, using the given
+// validator set of the commit header.
+func (fc *FinalityCertificate) Votes(validators *ValidatorSet) (*VoteSet, error) {
+	if len(fc.SignerBitmap) != (validators.Size()+7)/8 {
+		return nil, fmt.Errorf("signer bitmap size %v does not match the validator set size %v",
+			len(fc.SignerBitmap), validators.Size())
+	}
+	if len(fc.Epochs) != len(fc.Signatures) {
+		return nil, fmt.Errorf("number of epochs %v does not match the number of signatures %v",
+			len(fc.Epochs), len(fc.Signatures))
+	}
+
+	votes := NewVoteSet()
+	idx := 0
+	for i, validator := range validators.Validators() {
+		if fc.SignerBitmap[i/8]&(1<<uint(i%8)) == 0 {
+			continue
+		}
+		if idx >= len(fc.Signatures) {
+			return nil, fmt.Errorf("signer bitmap has more signers than signatures")
+		}
+		votes.AddVote(Vote{
+			Block:     fc.CommitHeader.Hash(),
+			Height:    fc.CommitHeader.Height,
+			Epoch:     fc.Epochs[idx],
+			ID:        validator.ID(),
+			Signature: fc.Signatures[idx],
+		})
+		idx++
+	}
+	if idx != len(fc.Signatures) {
+		return nil, fmt.Errorf("signer bitmap has fewer signers than signatures")
+	}
+	return votes, nil
+}
+
+func (fc *FinalityCertificate) String() string {
+	return fmt.Sprintf("FinalityCertificate{Block: %v, Height: %v, CommitBlock: %v, Signers: %v}",
+		fc.Header.Hash().Hex(), fc.Header.Height, fc.CommitHeader.Hash().Hex(), len(fc.Signatures))
+}
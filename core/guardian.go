@@ -39,8 +39,8 @@ func (a *AggregatedVotes) String() string {
 	return fmt.Sprintf("AggregatedVotes{Block: %s, Gcp: %s,  Multiplies: %v}", a.Block.Hex(), a.Gcp.Hex(), a.Multiplies)
 }
 
-// signBytes returns the bytes to be signed.
-func (a *AggregatedVotes) signBytes() common.Bytes {
+// SignBytes returns the bytes to be signed.
+func (a *AggregatedVotes) SignBytes() common.Bytes {
 	tmp := &AggregatedVotes{
 		Block: a.Block,
 		Gcp:   a.Gcp,
@@ -51,13 +51,18 @@ func (a *AggregatedVotes) signBytes() common.Bytes {
 
 // Sign adds signer's signature. Returns false if signer has already signed.
 func (a *AggregatedVotes) Sign(key *bls.SecretKey, signerIdx int) bool {
+	return a.AddSignature(key.Sign(a.SignBytes()), signerIdx)
+}
+
+// AddSignature adds signer's signature over SignBytes(). Returns false if signer has already signed.
+func (a *AggregatedVotes) AddSignature(sig *bls.Signature, signerIdx int) bool {
 	if a.Multiplies[signerIdx] > 0 {
 		// Already signed, do nothing.
 		return false
 	}
 
 	a.Multiplies[signerIdx] = 1
-	a.Signature.Aggregate(key.Sign(a.signBytes()))
+	a.Signature.Aggregate(sig)
 	return true
 }
 
@@ -127,7 +132,7 @@ func (a *AggregatedVotes) Validate(gcp *GuardianCandidatePool) result.Result {
 	}
 	pubKeys := gcp.WithStake().PubKeys()
 	aggPubkey := bls.AggregatePublicKeysVec(pubKeys, a.Multiplies)
-	if !a.Signature.Verify(a.signBytes(), aggPubkey) {
+	if !a.Signature.Verify(a.SignBytes(), aggPubkey) {
 		return result.Error("signature verification failed")
 	}
 	return result.OK
@@ -297,9 +302,9 @@ func (gcp *GuardianCandidatePool) Hash() common.Hash {
 	return crypto.Keccak256Hash(raw)
 }
 
-func (gcp *GuardianCandidatePool) DepositStake(source common.Address, holder common.Address, amount *big.Int, pubkey *bls.PublicKey, blockHeight uint64) (err error) {
+func (gcp *GuardianCandidatePool) DepositStake(chainID string, source common.Address, holder common.Address, amount *big.Int, pubkey *bls.PublicKey, blockHeight uint64) (err error) {
 	minGuardianStake := MinGuardianStakeDeposit
-	if blockHeight >= common.HeightLowerGNStakeThresholdTo1000 {
+	if blockHeight >= common.GetForkSchedule(chainID).LowerGNStakeThresholdTo1000 {
 		minGuardianStake = MinGuardianStakeDeposit1000
 	}
 	if amount.Cmp(minGuardianStake) < 0 {
@@ -347,6 +352,51 @@ func (gcp *GuardianCandidatePool) WithdrawStake(source common.Address, holder co
 	return nil
 }
 
+// WithdrawPartialStake withdraws the given amount from the stake of the source. The remaining
+// stake of the source needs to meet the given minimum guardian stake.
+func (gcp *GuardianCandidatePool) WithdrawPartialStake(source common.Address, holder common.Address, amount *big.Int, minGuardianStake *big.Int, currentHeight uint64) error {
+	g := gcp.GetWithHolderAddress(holder)
+	if g == nil {
+		return fmt.Errorf("No matched stake holder address found: %v", holder)
+	}
+	_, err := g.withdrawPartialStake(source, amount, minGuardianStake, currentHeight)
+	return err
+}
+
+// RedelegateStake moves the stake of the source from one guardian to another without going
+// through the withdrawal locking period. The pubkey is only used if the new holder is not a
+// guardian yet. The pool is left unchanged if an error is returned.
+func (gcp *GuardianCandidatePool) RedelegateStake(chainID string, source common.Address, fromHolder common.Address, toHolder common.Address, pubkey *bls.PublicKey, blockHeight uint64) (*big.Int, error) {
+	from := gcp.GetWithHolderAddress(fromHolder)
+	if from == nil {
+		return nil, fmt.Errorf("No matched stake holder address found: %v", fromHolder)
+	}
+	stake, err := from.getStake(source, false)
+	if err != nil {
+		return nil, err
+	}
+	if stake.Withdrawn {
+		return nil, fmt.Errorf("Cannot move stake during the withdrawal locking period for: %v", source)
+	}
+	if to := gcp.GetWithHolderAddress(toHolder); to != nil {
+		if existing, err := to.getStake(source, false); err == nil && existing.Withdrawn {
+			return nil, fmt.Errorf("Cannot deposit during the withdrawal locking period for: %v", source)
+		}
+	}
+
+	amount := stake.Amount
+	if err := gcp.DepositStake(chainID, source, toHolder, amount, pubkey, blockHeight); err != nil {
+		return nil, err
+	}
+	if _, err := from.removeStake(source); err != nil {
+		logger.Panicf("Failed to remove the redelegated stake: %v", err)
+	}
+	if len(from.Stakes) == 0 {
+		gcp.Remove(fromHolder)
+	}
+	return amount, nil
+}
+
 func (gcp *GuardianCandidatePool) ReturnStakes(currentHeight uint64) []*Stake {
 	returnedStakes := []*Stake{}
 
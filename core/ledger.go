@@ -39,6 +39,8 @@ type Ledger interface {
 	FinalizeState(height uint64, rootHash common.Hash) result.Result
 	GetFinalizedValidatorCandidatePool(blockHash common.Hash, isNext bool) (*ValidatorCandidatePool, error)
 	GetGuardianCandidatePool(blockHash common.Hash) (*GuardianCandidatePool, error)
+	ProveValidatorCandidatePool(blockHash common.Hash) (*VCPProof, error)
 	GetEliteEdgeNodePoolOfLastCheckpoint(blockHash common.Hash) (EliteEdgeNodePool, error)
 	PruneState(endHeight uint64) error
+	AddEquivocationEvidence(evidence *EquivocationEvidence)
 }
@@ -5,11 +5,13 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/rlp"
 )
 
@@ -191,6 +193,28 @@ func (vp *VCPProof) DecodeRLP(stream *rlp.Stream) error {
 	return nil
 }
 
+// MarshalJSON encodes the proof as the list of the trie nodes, since the keys are the node hashes.
+func (vp VCPProof) MarshalJSON() ([]byte, error) {
+	nodes := []common.Bytes{}
+	for _, kv := range vp.kvs {
+		nodes = append(nodes, kv.Val)
+	}
+	return json.Marshal(nodes)
+}
+
+// UnmarshalJSON decodes the proof from the list of the trie nodes.
+func (vp *VCPProof) UnmarshalJSON(data []byte) error {
+	nodes := []common.Bytes{}
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		return err
+	}
+	vp.kvs = []*proofKV{}
+	for _, node := range nodes {
+		vp.kvs = append(vp.kvs, &proofKV{crypto.Keccak256(node), node})
+	}
+	return nil
+}
+
 func (vp *VCPProof) Get(key []byte) (value []byte, err error) {
 	for _, kv := range vp.kvs {
 		if bytes.Compare(key, kv.Key) == 0 {
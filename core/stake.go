@@ -13,8 +13,9 @@ const (
 	StakeForGuardian      uint8 = 1
 	StakeForEliteEdgeNode uint8 = 2
 
-	ReturnLockingPeriod uint64 = 28800      // number of blocks, approximately 2 days with 6 second block time
-	InvalidReturnHeight uint64 = ^uint64(0) // max uint64
+	ReturnLockingPeriod  uint64 = 28800      // number of blocks, approximately 2 days with 6 second block time
+	RedelegationCooldown uint64 = 28800      // number of blocks a source needs to wait between two stake redelegations
+	InvalidReturnHeight  uint64 = ^uint64(0) // max uint64
 )
 
 var (
@@ -117,6 +118,13 @@ func (sh *StakeHolder) TotalStake() *big.Int {
 }
 
 func (sh *StakeHolder) getStake(source common.Address, withdrawnOnly bool) (*Stake, error) {
+	if !withdrawnOnly {
+		// After a partial withdrawal, the source has both a stake being withdrawn and
+		// the remaining stake, the latter takes precedence
+		if stake := sh.getActiveStake(source); stake != nil {
+			return stake, nil
+		}
+	}
 	for _, stake := range sh.Stakes {
 		if stake.Source == source {
 			if !withdrawnOnly || stake.Withdrawn {
@@ -127,20 +135,28 @@ func (sh *StakeHolder) getStake(source common.Address, withdrawnOnly bool) (*Sta
 	return nil, fmt.Errorf("Cannot get stake for: %v", source)
 }
 
+// getActiveStake returns the stake of the source which is not being withdrawn, or nil if
+// there is none
+func (sh *StakeHolder) getActiveStake(source common.Address) *Stake {
+	for _, stake := range sh.Stakes {
+		if stake.Source == source && !stake.Withdrawn {
+			return stake
+		}
+	}
+	return nil
+}
+
 func (sh *StakeHolder) depositStake(source common.Address, amount *big.Int) error {
 	if amount.Cmp(Zero) < 0 {
 		return fmt.Errorf("Invalid stake: %v", amount)
 	}
 
-	for _, stake := range sh.Stakes {
-		if stake.Source == source {
-			if stake.Withdrawn {
-				return fmt.Errorf("Cannot deposit during the withdrawal locking period for: %v", source)
-			}
-
-			stake.Amount = new(big.Int).Add(stake.Amount, amount)
-			return nil
-		}
+	if stake := sh.getActiveStake(source); stake != nil {
+		stake.Amount = new(big.Int).Add(stake.Amount, amount)
+		return nil
+	}
+	if _, err := sh.getStake(source, true); err == nil {
+		return fmt.Errorf("Cannot deposit during the withdrawal locking period for: %v", source)
 	}
 
 	newStake := NewStake(source, amount)
@@ -150,36 +166,83 @@ func (sh *StakeHolder) depositStake(source common.Address, amount *big.Int) erro
 }
 
 func (sh *StakeHolder) withdrawStake(source common.Address, currentHeight uint64) (*Stake, error) {
-	for _, stake := range sh.Stakes {
-		if stake.Source == source {
-			if stake.Withdrawn {
-				return nil, fmt.Errorf("Already withdrawn, cannot withdraw again for source: %v", source)
-			}
-			stake.Withdrawn = true
-			stake.ReturnHeight = currentHeight + ReturnLockingPeriod
+	if stake := sh.getActiveStake(source); stake != nil {
+		stake.Withdrawn = true
+		stake.ReturnHeight = currentHeight + ReturnLockingPeriod
+		return stake, nil
+	}
+	if _, err := sh.getStake(source, true); err == nil {
+		return nil, fmt.Errorf("Already withdrawn, cannot withdraw again for source: %v", source)
+	}
+
+	return nil, fmt.Errorf("Cannot withdraw, no matched stake source address found: %v", source)
+}
+
+// withdrawPartialStake withdraws the given amount from the stake of the source. The withdrawn
+// amount becomes a separate stake which is returned after the locking period, like a fully
+// withdrawn stake. The remaining stake needs to be at least minStake, and withdrawing the
+// whole stake is the same as withdrawStake.
+func (sh *StakeHolder) withdrawPartialStake(source common.Address, amount *big.Int, minStake *big.Int, currentHeight uint64) (*Stake, error) {
+	if amount.Cmp(Zero) <= 0 {
+		return nil, fmt.Errorf("Invalid amount to withdraw: %v", amount)
+	}
+
+	stake := sh.getActiveStake(source)
+	if stake == nil {
+		return sh.withdrawStake(source, currentHeight) // for the error message
+	}
+	if amount.Cmp(stake.Amount) > 0 {
+		return nil, fmt.Errorf("Cannot withdraw %v, only %v is staked by source: %v", amount, stake.Amount, source)
+	}
+	if amount.Cmp(stake.Amount) == 0 {
+		return sh.withdrawStake(source, currentHeight)
+	}
+	if remaining := new(big.Int).Sub(stake.Amount, amount); remaining.Cmp(minStake) < 0 {
+		return nil, fmt.Errorf("Remaining stake %v would be below the minimum stake %v", remaining, minStake)
+	}
+
+	stake.Amount = new(big.Int).Sub(stake.Amount, amount)
+	withdrawnStake := NewStake(source, new(big.Int).Set(amount))
+	withdrawnStake.Withdrawn = true
+	withdrawnStake.ReturnHeight = currentHeight + ReturnLockingPeriod
+	sh.Stakes = append(sh.Stakes, withdrawnStake)
+
+	return withdrawnStake, nil
+}
+
+// removeStake removes the stake of the source which is not being withdrawn, and returns it.
+// Used when the stake is moved to another holder without going through the locking period.
+func (sh *StakeHolder) removeStake(source common.Address) (*Stake, error) {
+	for idx, stake := range sh.Stakes {
+		if stake.Source == source && !stake.Withdrawn {
+			sh.Stakes = append(sh.Stakes[:idx], sh.Stakes[idx+1:]...)
 			return stake, nil
 		}
 	}
+	if _, err := sh.getStake(source, true); err == nil {
+		return nil, fmt.Errorf("Cannot move stake during the withdrawal locking period for: %v", source)
+	}
 
-	return nil, fmt.Errorf("Cannot withdraw, no matched stake source address found: %v", source)
+	return nil, fmt.Errorf("Cannot move stake, no matched stake source address found: %v", source)
 }
 
 func (sh *StakeHolder) returnStake(source common.Address, currentHeight uint64) (*Stake, error) {
 	for idx, stake := range sh.Stakes {
-		if stake.Source == source {
-			if !stake.Withdrawn {
-				return nil, fmt.Errorf("Cannot return, stake not withdrawn yet")
-			}
-			if stake.ReturnHeight > currentHeight {
-				return nil, fmt.Errorf("Cannot return, current height: %v, return height: %v",
-					currentHeight, stake.ReturnHeight)
-			}
+		if stake.Source == source && stake.Withdrawn && stake.ReturnHeight <= currentHeight {
 			sh.Stakes = append(sh.Stakes[:idx], sh.Stakes[idx+1:]...)
 			return stake, nil
 		}
 	}
 
-	return nil, fmt.Errorf("Cannot return, no matched stake source address found: %v", source)
+	stake, err := sh.getStake(source, true)
+	if err != nil {
+		if _, err := sh.getStake(source, false); err == nil {
+			return nil, fmt.Errorf("Cannot return, stake not withdrawn yet")
+		}
+		return nil, fmt.Errorf("Cannot return, no matched stake source address found: %v", source)
+	}
+	return nil, fmt.Errorf("Cannot return, current height: %v, return height: %v",
+		currentHeight, stake.ReturnHeight)
 }
 
 func (sh *StakeHolder) String() string {
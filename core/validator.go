@@ -202,9 +202,9 @@ func (vcp *ValidatorCandidatePool) GetTopStakeHolders(maxNumStakeHolders int) []
 	return vcp.SortedCandidates[:n]
 }
 
-func (vcp *ValidatorCandidatePool) DepositStake(source common.Address, holder common.Address, amount *big.Int, blockHeight uint64) (err error) {
+func (vcp *ValidatorCandidatePool) DepositStake(chainID string, source common.Address, holder common.Address, amount *big.Int, blockHeight uint64) (err error) {
 	minValidatorStake := MinValidatorStakeDeposit
-	if blockHeight >= common.HeightValidatorStakeChangedTo200K {
+	if blockHeight >= common.GetForkSchedule(chainID).ValidatorStakeChangedTo200K {
 		minValidatorStake = MinValidatorStakeDeposit200K
 	}
 	if amount.Cmp(minValidatorStake) < 0 {
@@ -255,6 +255,53 @@ func (vcp *ValidatorCandidatePool) WithdrawStake(source common.Address, holder c
 	return nil
 }
 
+// WithdrawPartialStake withdraws the given amount from the stake of the source. The remaining
+// stake of the source needs to meet the given minimum validator stake.
+func (vcp *ValidatorCandidatePool) WithdrawPartialStake(source common.Address, holder common.Address, amount *big.Int, minValidatorStake *big.Int, currentHeight uint64) error {
+	candidate := vcp.FindStakeDelegate(holder)
+	if candidate == nil {
+		return fmt.Errorf("No matched stake holder address found: %v", holder)
+	}
+	if _, err := candidate.withdrawPartialStake(source, amount, minValidatorStake, currentHeight); err != nil {
+		return err
+	}
+
+	vcp.sortCandidates()
+
+	return nil
+}
+
+// SlashAndJail burns the given percentage of all the stakes deposited to the holder, including
+// the ones being withdrawn, and withdraws the rest, which is not returned before the release
+// height. Returns the total amount slashed.
+func (vcp *ValidatorCandidatePool) SlashAndJail(holder common.Address, slashPercentage int64, releaseHeight uint64) (*big.Int, error) {
+	if slashPercentage < 0 || slashPercentage > 100 {
+		return nil, fmt.Errorf("Invalid slash percentage: %v", slashPercentage)
+	}
+
+	candidate := vcp.FindStakeDelegate(holder)
+	if candidate == nil {
+		return nil, fmt.Errorf("No matched stake holder address found: %v", holder)
+	}
+
+	slashedAmount := big.NewInt(0)
+	for _, stake := range candidate.Stakes {
+		slashed := new(big.Int).Mul(stake.Amount, big.NewInt(slashPercentage))
+		slashed.Div(slashed, big.NewInt(100))
+		stake.Amount = new(big.Int).Sub(stake.Amount, slashed)
+		slashedAmount.Add(slashedAmount, slashed)
+
+		if !stake.Withdrawn || stake.ReturnHeight < releaseHeight {
+			stake.Withdrawn = true
+			stake.ReturnHeight = releaseHeight
+		}
+	}
+
+	vcp.sortCandidates()
+
+	return slashedAmount, nil
+}
+
 func (vcp *ValidatorCandidatePool) ReturnStakes(currentHeight uint64) []*Stake {
 	returnedStakes := []*Stake{}
 
@@ -2,8 +2,12 @@ package dispatcher
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/spf13/viper"
 
@@ -191,6 +195,57 @@ func (dp *Dispatcher) IsSeedPeer(peerID string) bool {
 	return false
 }
 
+// ConnectToPeer connects to the peer at the given address. Multiaddresses, e.g.
+// "/ip4/127.0.0.1/tcp/12000/p2p/12D3KooW...", are dialed through the libp2p network, and
+// other addresses, e.g. "127.0.0.1:50001", through the old p2p network.
+func (dp *Dispatcher) ConnectToPeer(address string) error {
+	if strings.HasPrefix(address, "/") {
+		if reflect.ValueOf(dp.p2plnet).IsNil() {
+			return errors.New("libp2p network is not enabled")
+		}
+		return dp.p2plnet.ConnectToPeer(address)
+	}
+	if reflect.ValueOf(dp.p2pnet).IsNil() {
+		return errors.New("p2p network is not enabled")
+	}
+	return dp.p2pnet.ConnectToPeer(address)
+}
+
+// DisconnectPeer disconnects from the given peer
+func (dp *Dispatcher) DisconnectPeer(peerID string) error {
+	if !reflect.ValueOf(dp.p2pnet).IsNil() && dp.p2pnet.PeerExists(peerID) {
+		return dp.p2pnet.DisconnectPeer(peerID)
+	}
+	if !reflect.ValueOf(dp.p2plnet).IsNil() && dp.p2plnet.PeerExists(peerID) {
+		return dp.p2plnet.DisconnectPeer(peerID)
+	}
+	return fmt.Errorf("peer %v is not connected", peerID)
+}
+
+// BanPeer disconnects from the given peer, and rejects its connections for the given duration.
+// The ban applies to each network which accepts the format of the peer ID.
+func (dp *Dispatcher) BanPeer(peerID string, duration time.Duration) error {
+	var err error
+	banned := false
+	if !reflect.ValueOf(dp.p2pnet).IsNil() {
+		if err = dp.p2pnet.BanPeer(peerID, duration); err == nil {
+			banned = true
+		}
+	}
+	if !reflect.ValueOf(dp.p2plnet).IsNil() {
+		if err = dp.p2plnet.BanPeer(peerID, duration); err == nil {
+			banned = true
+		}
+	}
+	if banned {
+		return nil
+	}
+	if err == nil {
+		err = errors.New("no p2p network is enabled")
+	}
+	return err
+}
+
 // send delivers message directly to a list of peers.
 func (dp *Dispatcher) send(peerIDs []string, channelID common.ChannelIDEnum, content interface{}) {
 	messageOld := p2ptypes.Message{
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/store/database/backend"
+	"github.com/thetatoken/theta/store/kvstore"
+)
+
+// backfill_tx_address_index adds the finalized transactions in the given height range to the
+// address tx index. It should be run while the node is stopped, before the node is restarted
+// with storage.txAddressIndexEnabled turned on. Transactions already in the index are skipped.
+
+func handleError(err error) {
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: backfill_tx_address_index -chain=<chain_id> -config=<path_to_config_home> -start=<start_height> -end=<end_height>")
+}
+
+func main() {
+	chainPtr := flag.String("chain", "", "chain id")
+	configPathPtr := flag.String("config", "", "path to theta config home")
+	startPtr := flag.Uint64("start", 0, "start height of the backfill")
+	endPtr := flag.Uint64("end", 0, "end height of the backfill (inclusive)")
+
+	flag.Parse()
+
+	chainID := *chainPtr
+	configPath := *configPathPtr
+	start := *startPtr
+	end := *endPtr
+
+	if configPath == "" || end < start {
+		handleError(fmt.Errorf("invalid arguments"))
+	}
+
+	mainDBPath := path.Join(configPath, "db", "main")
+	refDBPath := path.Join(configPath, "db", "ref")
+	db, err := backend.NewLDBDatabase(mainDBPath, refDBPath, 256, 0)
+	handleError(err)
+	defer db.Close()
+
+	root := core.NewBlock()
+	if chainID == "" {
+		root.ChainID = core.MainnetChainID
+	} else {
+		root.ChainID = chainID
+	}
+	_, err = common.SetupForkSchedule(root.ChainID, common.DefaultForkSchedulePath(configPath))
+	handleError(err)
+	store := kvstore.NewKVStore(db)
+	chain := blockchain.NewChain(root.ChainID, store, root)
+
+	numIndexedBlocks := 0
+	for height := start; height <= end; height++ {
+		for _, block := range chain.FindBlocksByHeight(height) {
+			if block.Status.IsFinalized() {
+				chain.AddTxsToAddressIndex(block)
+				numIndexedBlocks++
+				break
+			}
+		}
+		if height%10000 == 0 {
+			fmt.Printf("Backfilled the address tx index up to height %v\n", height)
+		}
+	}
+
+	fmt.Printf("Done, backfilled the address tx index for %v finalized blocks between height %v and %v\n", numIndexedBlocks, start, end)
+}
@@ -31,10 +31,11 @@ func handleError(err error) {
 }
 
 func printUsage() {
-	fmt.Println("Usage: dump_storeview -config=<path_to_config_home> -height=<height> -state_hash=<state_hash>")
+	fmt.Println("Usage: dump_storeview -chain=<chain_id> -config=<path_to_config_home> -height=<height> -state_hash=<state_hash>")
 }
 
 func main() {
+	chainPtr := flag.String("chain", core.MainnetChainID, "chain id")
 	configPathPtr := flag.String("config", "", "path to ukuele config home")
 	heightPtr := flag.Uint64("height", 0, "height of storeview block")
 	stateHashPtr := flag.String("state_hash", "", "hash of state root")
@@ -44,6 +45,9 @@ func main() {
 	stateHashStr := *stateHashPtr
 	heightStr := strconv.FormatUint(height, 10)
 
+	_, err := common.SetupForkSchedule(*chainPtr, common.DefaultForkSchedulePath(configPath))
+	handleError(err)
+
 	mainDBPath := path.Join(configPath, "db", "main")
 	refDBPath := path.Join(configPath, "db", "ref")
 	db, err := backend.NewLDBDatabase(mainDBPath, refDBPath, 256, 0)
@@ -91,7 +91,7 @@ func generateGenesisSnapshot(chainID, erc20SnapshotJSONFilePath, stakeDepositFil
 	genesisHeight := core.GenesisBlockHeight
 
 	sv := loadInitialBalances(erc20SnapshotJSONFilePath)
-	performInitialStakeDeposit(stakeDepositFilePath, genesisHeight, sv)
+	performInitialStakeDeposit(chainID, stakeDepositFilePath, genesisHeight, sv)
 
 	stateHash := sv.Hash()
 
@@ -156,7 +156,7 @@ func loadInitialBalances(erc20SnapshotJSONFilePath string) *state.StoreView {
 	return sv
 }
 
-func performInitialStakeDeposit(stakeDepositFilePath string, genesisHeight uint64, sv *state.StoreView) *core.ValidatorCandidatePool {
+func performInitialStakeDeposit(chainID string, stakeDepositFilePath string, genesisHeight uint64, sv *state.StoreView) *core.ValidatorCandidatePool {
 	var stakeDeposits []StakeDeposit
 	stakeDepositFile, err := os.Open(stakeDepositFilePath)
 	stakeDepositByteValue, err := ioutil.ReadAll(stakeDepositFile)
@@ -188,7 +188,7 @@ func performInitialStakeDeposit(stakeDepositFilePath string, genesisHeight uint6
 			panic(fmt.Sprintf("The source account %v does NOT have sufficient balance for stake deposit. ThetaWeiBalance = %v, StakeAmount = %v",
 				sourceAddress, sourceAccount.Balance.ThetaWei, stakeDeposit.Amount))
 		}
-		err := vcp.DepositStake(sourceAddress, holderAddress, stakeAmount, genesisHeight)
+		err := vcp.DepositStake(chainID, sourceAddress, holderAddress, stakeAmount, genesisHeight)
 		if err != nil {
 			panic(fmt.Sprintf("Failed to deposit stake, err: %v", err))
 		}
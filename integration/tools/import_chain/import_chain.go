@@ -10,6 +10,7 @@ import (
 
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/snapshot"
 	"github.com/thetatoken/theta/store/database/backend"
@@ -53,10 +54,16 @@ func main() {
 	} else {
 		root.ChainID = chainID
 	}
+	schedulePath := viper.GetString(common.CfgGenesisForkSchedulePath)
+	if schedulePath == "" {
+		schedulePath = common.DefaultForkSchedulePath(configPath)
+	}
+	_, err := common.SetupForkSchedule(root.ChainID, schedulePath)
+	handleError(err)
 	store := kvstore.NewKVStore(db)
 	chain := blockchain.NewChain(root.ChainID, store, root)
 
-	_, err := snapshot.ValidateSnapshot(snapshotPath, chainImportDirPath, "")
+	_, err = snapshot.ValidateSnapshot(snapshotPath, chainImportDirPath, "")
 	if err != nil {
 		log.Fatalf("Snapshot validation failed, err: %v", err)
 	}
@@ -26,10 +26,11 @@ func handleError(err error) {
 }
 
 func printUsage() {
-	fmt.Println("Usage: inspect_data -config=<path_to_config_home> -key=<key> -level=<level>")
+	fmt.Println("Usage: inspect_data -chain=<chain_id> -config=<path_to_config_home> -key=<key> -level=<level>")
 }
 
 func main() {
+	chainPtr := flag.String("chain", core.MainnetChainID, "chain id")
 	configPathPtr := flag.String("config", "", "path to ukuele config home")
 	keyPtr := flag.String("key", "", "db key")
 	levelPrt := flag.String("level", "", "level of trie to print")
@@ -38,6 +39,9 @@ func main() {
 	key := *keyPtr
 	level, _ := strconv.Atoi(*levelPrt)
 
+	_, err := common.SetupForkSchedule(*chainPtr, common.DefaultForkSchedulePath(configPath))
+	handleError(err)
+
 	mainDBPath := path.Join(configPath, "db", "main")
 	refDBPath := path.Join(configPath, "db", "ref")
 	db, err := backend.NewLDBDatabase(mainDBPath, refDBPath, 256, 0)
@@ -25,10 +25,11 @@ func handleError(err error) {
 }
 
 func printUsage() {
-	fmt.Println("Usage: query_db -config=<path_to_config_home> -type=block -hash=<hash> -height=<height>")
+	fmt.Println("Usage: query_db -chain=<chain_id> -config=<path_to_config_home> -type=block -hash=<hash> -height=<height>")
 }
 
 func main() {
+	chainPtr := flag.String("chain", core.MainnetChainID, "chain id")
 	configPathPtr := flag.String("config", "", "path to ukuele config home")
 	queryTypePtr := flag.String("type", "block", "type of object to query")
 	hashStrPtr := flag.String("hash", "", "hash of the object")
@@ -41,6 +42,9 @@ func main() {
 	hashStr := *hashStrPtr
 	heightStr := *heightStrPtr
 
+	_, err := common.SetupForkSchedule(*chainPtr, common.DefaultForkSchedulePath(configPath))
+	handleError(err)
+
 	mainDBPath := path.Join(configPath, "db", "main")
 	refDBPath := path.Join(configPath, "db", "ref")
 	db, _ := backend.NewLDBDatabase(mainDBPath, refDBPath, 256, 0)
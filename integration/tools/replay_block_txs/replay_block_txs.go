@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger"
+	"github.com/thetatoken/theta/store/database/backend"
+	"github.com/thetatoken/theta/store/kvstore"
+)
+
+// replay_block_txs replays the transactions of the finalized blocks in the given height range
+// both serially and in parallel, and checks that both executions produce the state root
+// recorded in the blocks. The states of the parent blocks need to be available, so the node
+// should not prune its state (storage.statePruningEnabled turned off) for the blocks to replay.
+// It should be run while the node is stopped.
+
+func handleError(err error) {
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: replay_block_txs -chain=<chain_id> -config=<path_to_config_home> -start=<start_height> -end=<end_height>")
+}
+
+func main() {
+	chainPtr := flag.String("chain", "", "chain id")
+	configPathPtr := flag.String("config", "", "path to theta config home")
+	startPtr := flag.Uint64("start", 0, "start height of the replay")
+	endPtr := flag.Uint64("end", 0, "end height of the replay (inclusive)")
+
+	flag.Parse()
+
+	chainID := *chainPtr
+	configPath := *configPathPtr
+	start := *startPtr
+	end := *endPtr
+
+	if configPath == "" || start == 0 || end < start {
+		handleError(fmt.Errorf("invalid arguments"))
+	}
+
+	mainDBPath := path.Join(configPath, "db", "main")
+	refDBPath := path.Join(configPath, "db", "ref")
+	db, err := backend.NewLDBDatabase(mainDBPath, refDBPath, 256, 0)
+	handleError(err)
+	defer db.Close()
+
+	root := core.NewBlock()
+	if chainID == "" {
+		root.ChainID = core.MainnetChainID
+	} else {
+		root.ChainID = chainID
+	}
+	_, err = common.SetupForkSchedule(root.ChainID, common.DefaultForkSchedulePath(configPath))
+	handleError(err)
+	store := kvstore.NewKVStore(db)
+	chain := blockchain.NewChain(root.ChainID, store, root)
+	ldg := ledger.NewLedger(root.ChainID, db, nil, chain, nil, nil, nil)
+
+	numBlocks, numTxs := 0, 0
+	var serialTime, parallelTime time.Duration
+	for height := start; height <= end; height++ {
+		var block *core.ExtendedBlock
+		for _, b := range chain.FindBlocksByHeight(height) {
+			if b.Status.IsFinalized() {
+				block = b
+				break
+			}
+		}
+		if block == nil {
+			handleError(fmt.Errorf("finalized block at height %v not found", height))
+		}
+
+		startTime := time.Now()
+		serialRoot, err := ldg.ReplayBlockTxs(block.Block, false)
+		handleError(err)
+		serialTime += time.Since(startTime)
+
+		startTime = time.Now()
+		parallelRoot, err := ldg.ReplayBlockTxs(block.Block, true)
+		handleError(err)
+		parallelTime += time.Since(startTime)
+
+		if serialRoot != block.StateHash || parallelRoot != block.StateHash {
+			handleError(fmt.Errorf("state root mismatch at height %v, expected: %v, serial: %v, parallel: %v",
+				height, block.StateHash.Hex(), serialRoot.Hex(), parallelRoot.Hex()))
+		}
+
+		numBlocks++
+		numTxs += len(block.Txs)
+		if height%1000 == 0 {
+			fmt.Printf("Replayed the blocks up to height %v\n", height)
+		}
+	}
+
+	fmt.Printf("Done, replayed %v blocks with %v transactions between height %v and %v, serial: %v, parallel: %v\n",
+		numBlocks, numTxs, start, end, serialTime, parallelTime)
+}
@@ -0,0 +1,107 @@
+package ledger
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	exec "github.com/thetatoken/theta/ledger/execution"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func newDynamicFeeSmartContractTx(chainID string, sender, recipient types.PrivAccount, gasPrice, gasTipCap *big.Int) *types.SmartContractTx {
+	tx := &types.SmartContractTx{
+		From: types.TxInput{
+			Address:  sender.Address,
+			Coins:    types.NewCoins(0, 1000),
+			Sequence: 1,
+		},
+		To:       types.TxOutput{Address: recipient.Address},
+		GasLimit: 100000,
+		GasPrice: gasPrice,
+	}
+	if gasTipCap != nil {
+		tx.SetGasTipCap(gasTipCap)
+	}
+	sig, err := sender.PrivKey.Sign(tx.SignBytes(chainID))
+	if err != nil {
+		panic(err)
+	}
+	tx.SetSignature(sender.Address, sig)
+	return tx
+}
+
+func TestDynamicBaseFee(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	baseFee := new(big.Int).Mul(types.GetMinimumGasPrice(chainID, testForks.EnableDynamicBaseFee), big.NewInt(2))
+	ledger.ResetState(&core.Block{
+		BlockHeader: &core.BlockHeader{
+			ChainID: chainID,
+			Height:  testForks.EnableDynamicBaseFee,
+			GasUsed: types.BlockGasTarget,
+			BaseFee: baseFee,
+		},
+	})
+
+	sender := types.MakeAcc("dynamic_fee_sender")
+	recipient := types.MakeAcc("dynamic_fee_recipient")
+	setTestAccount(ledger.state.Checked(), sender, types.NewCoins(0, 5e18))
+
+	// The gas price needs to cover the base fee
+	belowBaseFee := new(big.Int).Sub(baseFee, big.NewInt(1))
+	_, res := ledger.executor.CheckTx(newDynamicFeeSmartContractTx(chainID, sender, recipient, belowBaseFee, nil))
+	assert.Equal(result.CodeInvalidGasPrice, res.Code)
+
+	// The tip cap cannot exceed the gas price
+	maxFee := new(big.Int).Mul(baseFee, big.NewInt(3))
+	tooHighTip := new(big.Int).Add(maxFee, big.NewInt(1))
+	_, res = ledger.executor.CheckTx(newDynamicFeeSmartContractTx(chainID, sender, recipient, maxFee, tooHighTip))
+	assert.Equal(result.CodeInvalidGasPrice, res.Code)
+
+	// The tx is charged the base fee plus the tip, not its max fee
+	tip := big.NewInt(1e12)
+	tx := newDynamicFeeSmartContractTx(chainID, sender, recipient, maxFee, tip)
+	raw, err := types.TxToBytes(tx)
+	require.Nil(err)
+	decoded, err := types.TxFromBytes(raw)
+	require.Nil(err)
+
+	txInfo, res := ledger.executor.GetTxInfo(decoded)
+	require.True(res.IsOK(), res.Message)
+	assert.Equal(new(big.Int).Add(baseFee, tip), txInfo.EffectiveGasPrice)
+
+	_, res = ledger.executor.CheckTx(decoded)
+	require.True(res.IsOK(), res.Message)
+	gasUsed := exec.GetGasUsed(res)
+	assert.True(gasUsed > 0)
+
+	fee := new(big.Int).Mul(new(big.Int).Add(baseFee, tip), new(big.Int).SetUint64(gasUsed))
+	expectedBalance := new(big.Int).Sub(big.NewInt(5e18), fee)
+	expectedBalance.Sub(expectedBalance, big.NewInt(1000))
+	assert.Equal(expectedBalance, ledger.state.Checked().GetAccount(sender.Address).Balance.TFuelWei)
+}
+
+func TestDynamicBaseFeeNotActive(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	resetTestLedgerToHeight(ledger, testForks.EnableDynamicBaseFee-2)
+
+	sender := types.MakeAcc("dynamic_fee_sender")
+	recipient := types.MakeAcc("dynamic_fee_recipient")
+	setTestAccount(ledger.state.Checked(), sender, types.NewCoins(0, 1e18))
+
+	gasPrice := types.GetMinimumGasPrice(chainID, testForks.EnableDynamicBaseFee)
+	_, res := ledger.executor.CheckTx(newDynamicFeeSmartContractTx(chainID, sender, recipient, gasPrice, big.NewInt(1)))
+	assert.True(res.IsError())
+
+	_, res = ledger.executor.CheckTx(newDynamicFeeSmartContractTx(chainID, sender, recipient, gasPrice, nil))
+	require.True(res.IsOK(), res.Message)
+}
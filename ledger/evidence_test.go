@@ -0,0 +1,212 @@
+package ledger
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func newTestVotedHeader(chainID string, height uint64, epoch uint64) *core.BlockHeader {
+	return &core.BlockHeader{
+		ChainID:   chainID,
+		Height:    height,
+		Epoch:     epoch,
+		Parent:    common.BytesToHash([]byte("parent")),
+		Timestamp: big.NewInt(int64(epoch)),
+	}
+}
+
+func newTestEquivocationEvidence(chainID string, validator types.PrivAccount, height uint64) *core.EquivocationEvidence {
+	headerA := newTestVotedHeader(chainID, height, 1)
+	headerB := newTestVotedHeader(chainID, height, 2)
+	voteA := core.Vote{Block: headerA.Hash(), Height: height, Epoch: 1, ID: validator.Address}
+	voteA.Sign(validator.PrivKey)
+	voteB := core.Vote{Block: headerB.Hash(), Height: height, Epoch: 2, ID: validator.Address}
+	voteB.Sign(validator.PrivKey)
+	return core.NewEquivocationEvidence(voteA, headerA, voteB, headerB)
+}
+
+func newTestEvidenceTx(chainID string, ledger *Ledger, evidence *core.EquivocationEvidence) *types.EvidenceTx {
+	proposer := ledger.consensus.PrivateKey()
+	tx := &types.EvidenceTx{
+		Proposer: types.TxInput{Address: proposer.PublicKey().Address()},
+		Evidence: evidence,
+	}
+	sig, err := proposer.Sign(tx.SignBytes(chainID))
+	if err != nil {
+		panic(err)
+	}
+	tx.SetSignature(proposer.PublicKey().Address(), sig)
+	return tx
+}
+
+func TestEquivocationEvidenceValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	chainID := "test_chain_id"
+	validator := types.MakeAcc("equivocation_validator")
+	other := types.MakeAcc("equivocation_other")
+	height := uint64(100)
+
+	evidence := newTestEquivocationEvidence(chainID, validator, height)
+	assert.True(evidence.Validate(chainID).IsOK())
+	assert.True(evidence.Validate("another_chain").IsError())
+	assert.Equal(validator.Address, evidence.Offender())
+	assert.Equal(height, evidence.Height())
+
+	// The same pair of votes always makes the same evidence
+	reversed := core.NewEquivocationEvidence(evidence.VoteB, evidence.HeaderB, evidence.VoteA, evidence.HeaderA)
+	assert.Equal(evidence.Hash(), reversed.Hash())
+
+	// Votes for the same block do not conflict
+	sameBlock := core.NewEquivocationEvidence(evidence.VoteA, evidence.HeaderA, evidence.VoteA, evidence.HeaderA)
+	assert.True(sameBlock.Validate(chainID).IsError())
+
+	// Votes of different validators do not conflict
+	otherVote := core.Vote{Block: evidence.VoteB.Block, Epoch: 2, ID: other.Address}
+	otherVote.Sign(other.PrivKey)
+	differentVoters := core.NewEquivocationEvidence(evidence.VoteA, evidence.HeaderA, otherVote, evidence.HeaderB)
+	assert.True(differentVoters.Validate(chainID).IsError())
+
+	// Votes for blocks at different heights do not conflict
+	headerC := newTestVotedHeader(chainID, height+1, 3)
+	voteC := core.Vote{Block: headerC.Hash(), Epoch: 3, ID: validator.Address}
+	voteC.Sign(validator.PrivKey)
+	differentHeights := core.NewEquivocationEvidence(evidence.VoteA, evidence.HeaderA, voteC, headerC)
+	assert.True(differentHeights.Validate(chainID).IsError())
+
+	// The headers need to match the blocks voted for
+	mismatched := core.NewEquivocationEvidence(evidence.VoteA, evidence.HeaderA, evidence.VoteB, headerC)
+	assert.True(mismatched.Validate(chainID).IsError())
+}
+
+func TestEquivocationSlashing(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	blockHeight := testForks.EnableEquivocationSlashing + 10
+	resetTestLedgerToHeight(ledger, blockHeight-1)
+	ledger.currentBlock = &core.Block{BlockHeader: &core.BlockHeader{ChainID: chainID, Height: blockHeight}}
+	view := ledger.state.Checked()
+
+	offender := types.MakeAcc("equivocation_offender")
+	staker := types.MakeAcc("equivocation_staker")
+	minStake := core.MinValidatorStakeDeposit200K
+	setTestAccount(view, staker, types.Coins{ThetaWei: minStake, TFuelWei: big.NewInt(1e18)})
+
+	vcp := &core.ValidatorCandidatePool{}
+	require.Nil(vcp.DepositStake(chainID, offender.Address, offender.Address, new(big.Int).Mul(minStake, big.NewInt(2)), blockHeight))
+	require.Nil(vcp.DepositStake(chainID, staker.Address, offender.Address, minStake, blockHeight))
+	require.Nil(vcp.WithdrawStake(staker.Address, offender.Address, blockHeight))
+	view.UpdateValidatorCandidatePool(vcp)
+
+	// Expired evidence is rejected
+	expired := newTestEquivocationEvidence(chainID, offender, blockHeight-core.MaxEquivocationEvidenceAge-1)
+	_, res := ledger.executor.CheckTx(newTestEvidenceTx(chainID, ledger, expired))
+	assert.Equal(result.CodeInvalidEquivocationEvidence, res.Code)
+
+	// Evidence needs to be submitted by a validator
+	evidence := newTestEquivocationEvidence(chainID, offender, blockHeight-5)
+	tx := newTestEvidenceTx(chainID, ledger, evidence)
+	outsiderTx := &types.EvidenceTx{
+		Proposer: types.TxInput{Address: staker.Address},
+		Evidence: evidence,
+	}
+	outsiderTx.Proposer.Signature = staker.Sign(outsiderTx.SignBytes(chainID))
+	_, res = ledger.executor.CheckTx(outsiderTx)
+	assert.True(res.IsError())
+
+	_, res = ledger.executor.CheckTx(tx)
+	require.True(res.IsOK(), res.Message)
+
+	// 5% of all the stakes are burned, and the rest is locked until the offender is released
+	releaseHeight := blockHeight + core.EquivocationJailPeriod
+	candidate := view.GetValidatorCandidatePool().FindStakeDelegate(offender.Address)
+	require.NotNil(candidate)
+	assert.Equal(0, candidate.TotalStake().Sign())
+	require.Equal(2, len(candidate.Stakes))
+	for _, stake := range candidate.Stakes {
+		assert.True(stake.Withdrawn)
+		assert.Equal(releaseHeight, stake.ReturnHeight)
+	}
+	assert.Equal(new(big.Int).Mul(minStake, big.NewInt(190)).String(),
+		new(big.Int).Mul(candidate.Stakes[0].Amount, big.NewInt(100)).String())
+	assert.Equal(new(big.Int).Mul(minStake, big.NewInt(95)).String(),
+		new(big.Int).Mul(candidate.Stakes[1].Amount, big.NewInt(100)).String())
+	assert.True(view.IsValidatorJailed(offender.Address, releaseHeight-1))
+	assert.False(view.IsValidatorJailed(offender.Address, releaseHeight))
+	assert.True(view.GetStakeTransactionHeightList().Contains(blockHeight))
+
+	// The offender is only slashed once while jailed
+	_, res = ledger.executor.CheckTx(newTestEvidenceTx(chainID, ledger, newTestEquivocationEvidence(chainID, offender, blockHeight-3)))
+	assert.Equal(result.CodeValidatorJailed, res.Code)
+
+	// No stake can be deposited to a jailed validator
+	depositTx := &types.DepositStakeTx{
+		Fee: types.Coins{ThetaWei: big.NewInt(0), TFuelWei: types.GetMinimumTransactionFeeTFuelWei(chainID, blockHeight)},
+		Source: types.TxInput{
+			Address:  staker.Address,
+			Coins:    types.Coins{ThetaWei: minStake, TFuelWei: big.NewInt(0)},
+			Sequence: 1,
+		},
+		Holder:  types.TxOutput{Address: offender.Address},
+		Purpose: core.StakeForValidator,
+	}
+	depositTx.Source.Signature = staker.Sign(depositTx.SignBytes(chainID))
+	_, res = ledger.executor.CheckTx(depositTx)
+	assert.Equal(result.CodeValidatorJailed, res.Code)
+}
+
+func TestAddEvidenceTxs(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	blockHeight := testForks.EnableEquivocationSlashing + 10
+	resetTestLedgerToHeight(ledger, blockHeight-1)
+	view := ledger.state.Checked()
+
+	offender := types.MakeAcc("equivocation_offender")
+	evidence := newTestEquivocationEvidence(chainID, offender, blockHeight-5)
+	ledger.AddEquivocationEvidence(evidence)
+	ledger.AddEquivocationEvidence(evidence)
+	ledger.AddEquivocationEvidence(newTestEquivocationEvidence(chainID, offender, blockHeight-3))
+	ledger.AddEquivocationEvidence(newTestEquivocationEvidence(chainID, offender, blockHeight-core.MaxEquivocationEvidenceAge-1))
+
+	// Only the oldest evidence of each offender is included, and the expired ones are dropped
+	proposer := ledger.valMgr.GetNextProposer(common.Hash{}, 0)
+	rawTxs := []common.Bytes{}
+	ledger.addEvidenceTxs(view, &proposer, blockHeight, &rawTxs)
+	require.Equal(1, len(rawTxs))
+	assert.Equal(2, len(ledger.evidences))
+
+	tx, err := types.TxFromBytes(rawTxs[0])
+	require.Nil(err)
+	evidenceTx, ok := tx.(*types.EvidenceTx)
+	require.True(ok)
+	assert.Equal(proposer.Address, evidenceTx.Proposer.Address)
+	assert.Equal(offender.Address, evidenceTx.Evidence.Offender())
+	assert.Equal(blockHeight-5, evidenceTx.Evidence.Height())
+	assert.True(evidenceTx.Proposer.Signature.Verify(evidenceTx.SignBytes(chainID), proposer.Address))
+
+	// The selection does not depend on the map iteration order
+	for i := 0; i < 20; i++ {
+		selectedTxs := []common.Bytes{}
+		ledger.addEvidenceTxs(view, &proposer, blockHeight, &selectedTxs)
+		assert.Equal(rawTxs, selectedTxs)
+	}
+
+	// The evidences are dropped once the offender is jailed
+	view.SetValidatorJailReleaseHeight(offender.Address, blockHeight+core.EquivocationJailPeriod)
+	rawTxs = []common.Bytes{}
+	ledger.addEvidenceTxs(view, &proposer, blockHeight, &rawTxs)
+	assert.Equal(0, len(rawTxs))
+	assert.Equal(0, len(ledger.evidences))
+}
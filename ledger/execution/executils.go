@@ -7,6 +7,7 @@ import (
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/result"
 	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/ledger/state"
 	"github.com/thetatoken/theta/ledger/types"
 )
@@ -162,14 +163,14 @@ func validateInputsBasic(ins []types.TxInput) result.Result {
 }
 
 // Validate inputs and compute total amount of coins
-func validateInputsAdvanced(accounts map[string]*types.Account, signBytes []byte, ins []types.TxInput, blockHeight uint64) (total types.Coins, res result.Result) {
+func validateInputsAdvanced(chainID string, accounts map[string]*types.Account, signBytes []byte, ins []types.TxInput, blockHeight uint64, skipSignatureCheck bool) (total types.Coins, res result.Result) {
 	total = types.NewCoins(0, 0)
 	for _, in := range ins {
 		acc := accounts[string(in.Address[:])]
 		if acc == nil {
-			panic("validateInputsAdvanced() expects account in accounts")
+			panic("validateInputsAdvanced(chainID, ) expects account in accounts")
 		}
-		res = validateInputAdvanced(acc, signBytes, in, blockHeight)
+		res = validateInputAdvanced(chainID, acc, signBytes, in, blockHeight, skipSignatureCheck)
 		if res.IsError() {
 			return
 		}
@@ -179,7 +180,7 @@ func validateInputsAdvanced(accounts map[string]*types.Account, signBytes []byte
 	return total, result.OK
 }
 
-func validateInputAdvanced(acc *types.Account, signBytes []byte, in types.TxInput, blockHeight uint64) result.Result {
+func validateInputAdvanced(chainID string, acc *types.Account, signBytes []byte, in types.TxInput, blockHeight uint64, skipSignatureCheck bool) result.Result {
 	// Check sequence/coins
 	seq, balance := acc.Sequence, acc.Balance
 	if seq+1 != in.Sequence {
@@ -193,11 +194,15 @@ func validateInputAdvanced(acc *types.Account, signBytes []byte, in types.TxInpu
 			balance, in.Coins).WithErrorCode(result.CodeInsufficientFund)
 	}
 
+	if skipSignatureCheck {
+		return result.OK
+	}
+
 	// Check signatures
-	signatureValid := in.Signature.Verify(signBytes, acc.Address)
-	if blockHeight >= common.HeightTxWrapperExtension {
+	signatureValid := verifySignature(chainID, in.Signature, signBytes, acc.Address, blockHeight)
+	if blockHeight >= common.GetForkSchedule(chainID).TxWrapperExtension {
 		signBytesV2 := types.ChangeEthereumTxWrapper(signBytes, 2)
-		signatureValid = signatureValid || in.Signature.Verify(signBytesV2, acc.Address)
+		signatureValid = signatureValid || verifySignature(chainID, in.Signature, signBytesV2, acc.Address, blockHeight)
 	}
 
 	if !signatureValid {
@@ -208,6 +213,49 @@ func validateInputAdvanced(acc *types.Account, signBytes []byte, in types.TxInpu
 	return result.OK
 }
 
+// verifySignature verifies the signature of the given address. Once the native multisig accounts
+// are enabled, the signature can also be the signature bundle of a multisig account.
+func verifySignature(chainID string, sig *crypto.Signature, msg []byte, address common.Address, blockHeight uint64) bool {
+	if blockHeight >= common.GetForkSchedule(chainID).EnableNativeMultisig {
+		if multisig, ok := types.MultisigSignatureFromSignature(sig); ok {
+			return multisig.Verify(msg, address)
+		}
+	}
+	return sig.Verify(msg, address)
+}
+
+// validateFeePayer checks the fee payer of a tx with fee delegation, and returns its account. The
+// fee payer cannot be one of the signers, otherwise the signer would just pay the fee itself.
+func validateFeePayer(chainID string, view *state.StoreView, tx types.TxWithFeeDelegation, signers []common.Address,
+	fee types.Coins, blockHeight uint64, skipSignatureCheck bool) (*types.Account, result.Result) {
+	delegation := tx.GetFeeDelegation()
+	feePayer := *delegation.FeePayer
+	for _, signer := range signers {
+		if signer == feePayer {
+			return nil, result.Error("The fee payer %v cannot be a signer of the transaction", feePayer.Hex())
+		}
+	}
+
+	feePayerAccount, res := getAccount(view, feePayer)
+	if res.IsError() {
+		return nil, result.Error("Failed to get the fee payer account %v", feePayer.Hex())
+	}
+
+	if !skipSignatureCheck {
+		signBytes := types.FeePayerSignBytes(chainID, tx)
+		if delegation.FeePayerSignature == nil || !verifySignature(chainID, delegation.FeePayerSignature, signBytes, feePayer, blockHeight) {
+			return nil, result.Error("Fee payer signature verification failed, SignBytes: %v",
+				hex.EncodeToString(signBytes)).WithErrorCode(result.CodeInvalidSignature)
+		}
+	}
+
+	if !feePayerAccount.Balance.IsGTE(fee) {
+		return nil, result.Error("Insufficient fund: fee payer balance is %v, fee is %v",
+			feePayerAccount.Balance, fee).WithErrorCode(result.CodeInsufficientFund)
+	}
+	return feePayerAccount, result.OK
+}
+
 func validateOutputsBasic(outs []types.TxOutput) result.Result {
 	for _, out := range outs {
 		// Check TxOutput basic
@@ -254,12 +302,12 @@ func adjustByOutputs(view *state.StoreView, accounts map[string]*types.Account,
 	}
 }
 
-func sanityCheckForGasPrice(gasPrice *big.Int, blockHeight uint64) bool {
+func sanityCheckForGasPrice(chainID string, view *state.StoreView, gasPrice *big.Int, blockHeight uint64) bool {
 	if gasPrice == nil {
 		return false
 	}
 
-	minimumGasPrice := types.GetMinimumGasPrice(blockHeight)
+	minimumGasPrice := view.GetProtocolParam(chainID, types.ParamMinimumGasPrice, blockHeight)
 	if gasPrice.Cmp(minimumGasPrice) < 0 {
 		return false
 	}
@@ -267,17 +315,22 @@ func sanityCheckForGasPrice(gasPrice *big.Int, blockHeight uint64) bool {
 	return true
 }
 
-func sanityCheckForFee(fee types.Coins, blockHeight uint64) (minimumFee *big.Int, success bool) {
+func sanityCheckForFee(chainID string, view *state.StoreView, fee types.Coins, blockHeight uint64) (minimumFee *big.Int, success bool) {
 	fee = fee.NoNil()
-	minimumFee = types.GetMinimumTransactionFeeTFuelWei(blockHeight)
+	minimumFee = view.GetProtocolParam(chainID, types.ParamMinimumTransactionFeeTFuelWei, blockHeight)
 	success = (fee.ThetaWei.Cmp(types.Zero) == 0 && fee.TFuelWei.Cmp(minimumFee) >= 0)
 
 	return minimumFee, success
 }
 
-func sanityCheckForSendTxFee(fee types.Coins, numAccountsAffected uint64, blockHeight uint64) (minimumFee *big.Int, success bool) {
+func sanityCheckForSendTxFee(chainID string, view *state.StoreView, fee types.Coins, numAccountsAffected uint64, blockHeight uint64) (minimumFee *big.Int, success bool) {
 	fee = fee.NoNil()
-	minimumFee = types.GetSendTxMinimumTransactionFeeTFuelWei(numAccountsAffected, blockHeight)
+	minimumFee = types.GetSendTxMinimumTransactionFeeTFuelWei(chainID, numAccountsAffected, blockHeight)
+	if blockHeight >= common.GetForkSchedule(chainID).EnableGovernance {
+		// scale the minimum fee by the change of the minimum transaction fee made by the governance proposals
+		minimumFee.Mul(minimumFee, view.GetProtocolParam(chainID, types.ParamMinimumTransactionFeeTFuelWei, blockHeight))
+		minimumFee.Div(minimumFee, types.GetMinimumTransactionFeeTFuelWei(chainID, blockHeight))
+	}
 	success = (fee.ThetaWei.Cmp(types.Zero) == 0 && fee.TFuelWei.Cmp(minimumFee) >= 0)
 
 	return minimumFee, success
@@ -297,9 +350,18 @@ func getBlockHeight(ledgerState *state.LedgerState) uint64 {
 	return blockHeight
 }
 
+// getBaseFee returns the base fee per gas of the block being processed, or nil if the dynamic
+// base fee is not enabled yet
+func getBaseFee(ledgerState *state.LedgerState) *big.Int {
+	if getBlockHeight(ledgerState) < common.GetForkSchedule(ledgerState.GetChainID()).EnableDynamicBaseFee {
+		return nil
+	}
+	return types.CalculateBaseFee(ledgerState.ParentBlock().BlockHeader)
+}
+
 func getRegularTxGas(ledgerState *state.LedgerState) uint64 {
 	blockHeight := getBlockHeight(ledgerState)
-	if blockHeight < common.HeightJune2021FeeAdjustment {
+	if blockHeight < common.GetForkSchedule(ledgerState.GetChainID()).June2021FeeAdjustment {
 		return types.GasRegularTx
 	}
 	return types.GasRegularTxJune2021
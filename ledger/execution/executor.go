@@ -1,6 +1,8 @@
 package execution
 
 import (
+	"sync/atomic"
+
 	log "github.com/sirupsen/logrus"
 
 	"github.com/thetatoken/theta/blockchain"
@@ -9,6 +11,7 @@ import (
 	"github.com/thetatoken/theta/core"
 	st "github.com/thetatoken/theta/ledger/state"
 	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/vm"
 	"github.com/thetatoken/theta/store/database"
 )
 
@@ -44,6 +47,9 @@ type Executor struct {
 	smartContractTxExec           *SmartContractTxExecutor
 	depositStakeTxExec            *DepositStakeExecutor
 	withdrawStakeTxExec           *WithdrawStakeExecutor
+	redelegateStakeTxExec         *RedelegateStakeExecutor
+	governanceTxExec              *GovernanceTxExecutor
+	evidenceTxExec                *EvidenceTxExecutor
 	stakeRewardDistributionTxExec *StakeRewardDistributionTxExecutor
 
 	skipSanityCheck bool
@@ -67,6 +73,9 @@ func NewExecutor(db database.Database, chain *blockchain.Chain, state *st.Ledger
 		smartContractTxExec:           NewSmartContractTxExecutor(chain, state, ledger),
 		depositStakeTxExec:            NewDepositStakeExecutor(state),
 		withdrawStakeTxExec:           NewWithdrawStakeExecutor(state),
+		redelegateStakeTxExec:         NewRedelegateStakeExecutor(state),
+		governanceTxExec:              NewGovernanceTxExecutor(state),
+		evidenceTxExec:                NewEvidenceTxExecutor(ledger, valMgr),
 		stakeRewardDistributionTxExec: NewStakeRewardDistributionTxExecutor(state),
 		skipSanityCheck:               false,
 	}
@@ -80,11 +89,80 @@ func (exec *Executor) SetSkipSanityCheck(skip bool) {
 	exec.skipSanityCheck = skip
 }
 
+// SetTracer attaches the given tracer to the smart contract executions. Passing
+// nil detaches the tracer. Should only be used on executors created for replays.
+func (exec *Executor) SetTracer(tracer vm.Tracer) {
+	exec.smartContractTxExec.tracer = tracer
+}
+
+// SetSkipTxReceipts sets the flag for recording the receipts of the smart contract transactions
+// executed on the delivered view. Should only be used on executors created for replays.
+func (exec *Executor) SetSkipTxReceipts(skip bool) {
+	exec.smartContractTxExec.skipTxReceipts = skip
+}
+
+// SetSkipSignatureCheck sets the flag for the signature checks of the transactions.
+// Should only be used on executors created for simulations.
+func (exec *Executor) SetSkipSignatureCheck(skip bool) {
+	exec.sendTxExec.skipSignatureCheck = skip
+	exec.reserveFundTxExec.skipSignatureCheck = skip
+	exec.releaseFundTxExec.skipSignatureCheck = skip
+	exec.servicePaymentTxExec.skipSignatureCheck = skip
+	exec.splitRuleTxExec.skipSignatureCheck = skip
+	exec.smartContractTxExec.skipSignatureCheck = skip
+	exec.depositStakeTxExec.skipSignatureCheck = skip
+	exec.withdrawStakeTxExec.skipSignatureCheck = skip
+	exec.redelegateStakeTxExec.skipSignatureCheck = skip
+	exec.governanceTxExec.skipSignatureCheck = skip
+	exec.stakeRewardDistributionTxExec.skipSignatureCheck = skip
+}
+
+// GasUsedInfoKey is the key of the gas used by a smart contract transaction in the info of the
+// result returned when it is processed
+const GasUsedInfoKey = "gasUsed"
+
+// GetGasUsed returns the gas used by a transaction from the result returned when it was
+// processed. It returns zero for the transactions which do not consume gas.
+func GetGasUsed(res result.Result) uint64 {
+	gasUsed, _ := res.Info[GasUsedInfoKey].(uint64)
+	return gasUsed
+}
+
+// EvmRetInfoKey and EvmErrInfoKey are the keys of the return value and the error of the EVM
+// execution of a smart contract transaction in the info of the result returned when it is processed
+const (
+	EvmRetInfoKey = "evmRet"
+	EvmErrInfoKey = "evmErr"
+)
+
+// GetEvmResult returns the return value and the error of the EVM execution of a smart contract
+// transaction from the result returned when it was processed
+func GetEvmResult(res result.Result) (evmRet common.Bytes, evmErr error) {
+	evmRet, _ = res.Info[EvmRetInfoKey].(common.Bytes)
+	evmErr, _ = res.Info[EvmErrInfoKey].(error)
+	return evmRet, evmErr
+}
+
+// LastSmartContractGasUsed returns the gas used by the last smart contract transaction
+// processed. Should only be used on executors created for simulations.
+func (exec *Executor) LastSmartContractGasUsed() uint64 {
+	return atomic.LoadUint64(&exec.smartContractTxExec.lastGasUsed)
+}
+
 // ExecuteTx executes the given transaction
 func (exec *Executor) ExecuteTx(tx types.Tx) (common.Hash, result.Result) {
 	return exec.processTx(tx, core.DeliveredView)
 }
 
+// ProcessTxOnView processes the given transaction on the given view instead of one of the
+// views of the ledger state. The view must be at the same height as the ledger state, and
+// viewSel decides whether the transaction is processed as in ExecuteTx or as in CheckTx.
+// The executor can be used by multiple goroutines at the same time, as long as each
+// goroutine uses its own view.
+func (exec *Executor) ProcessTxOnView(tx types.Tx, view *st.StoreView, viewSel core.ViewSelector) (common.Hash, result.Result) {
+	return exec.processTxOnView(tx, view, viewSel)
+}
+
 // CheckTx checks the validity of the given transaction
 func (exec *Executor) CheckTx(tx types.Tx) (common.Hash, result.Result) {
 	return exec.processTx(tx, core.CheckedView)
@@ -108,7 +186,6 @@ func (exec *Executor) GetTxInfo(tx types.Tx) (*core.TxInfo, result.Result) {
 
 // processTx contains the main logic to process the transaction. If the tx is invalid, a TMSP error will be returned.
 func (exec *Executor) processTx(tx types.Tx, viewSel core.ViewSelector) (common.Hash, result.Result) {
-	chainID := exec.state.GetChainID()
 	var view *st.StoreView
 	switch viewSel {
 	case core.DeliveredView:
@@ -118,7 +195,11 @@ func (exec *Executor) processTx(tx types.Tx, viewSel core.ViewSelector) (common.
 	default:
 		view = exec.state.Screened()
 	}
+	return exec.processTxOnView(tx, view, viewSel)
+}
 
+func (exec *Executor) processTxOnView(tx types.Tx, view *st.StoreView, viewSel core.ViewSelector) (common.Hash, result.Result) {
+	chainID := exec.state.GetChainID()
 	sanityCheckResult := exec.sanityCheck(chainID, view, viewSel, tx)
 	if sanityCheckResult.IsError() {
 		return common.Hash{}, sanityCheckResult
@@ -133,10 +214,17 @@ func (exec *Executor) sanityCheck(chainID string, view *st.StoreView, viewSel co
 		return result.OK
 	}
 
-	if !exec.isTxTypeSupported(view, tx) {
+	if !exec.isTxTypeSupported(chainID, view, tx) {
 		return result.Error("tx type not supported yet")
 	}
 
+	if wtx, ok := tx.(types.TxWithValidityWindow); ok {
+		blockHeight := view.Height() + 1
+		if res := wtx.GetValidityWindow().CheckValidityWindow(blockHeight); res.IsError() {
+			return res
+		}
+	}
+
 	var sanityCheckResult result.Result
 	txExecutor := exec.getTxExecutor(tx)
 	if txExecutor != nil {
@@ -152,7 +240,7 @@ func (exec *Executor) process(chainID string, view *st.StoreView, viewSel core.V
 	var processResult result.Result
 	var txHash common.Hash
 
-	if !exec.isTxTypeSupported(view, tx) {
+	if !exec.isTxTypeSupported(chainID, view, tx) {
 		return txHash, result.Error("tx type not supported yet")
 	}
 
@@ -169,16 +257,50 @@ func (exec *Executor) process(chainID string, view *st.StoreView, viewSel core.V
 	return txHash, processResult
 }
 
-func (exec *Executor) isTxTypeSupported(view *st.StoreView, tx types.Tx) bool {
+func (exec *Executor) isTxTypeSupported(chainID string, view *st.StoreView, tx types.Tx) bool {
 	blockHeight := view.Height() + 1
+	forks := common.GetForkSchedule(chainID)
+
+	// Txs with a validity window use the versioned tx encoding
+	if wtx, ok := tx.(types.TxWithValidityWindow); ok && wtx.GetValidityWindow().HasValidityWindow() {
+		if blockHeight < forks.EnableTxValidityWindow {
+			return false
+		}
+	}
+	if dtx, ok := tx.(types.TxWithFeeDelegation); ok && dtx.GetFeeDelegation().HasFeePayer() {
+		if blockHeight < forks.EnableFeeDelegation {
+			return false
+		}
+	}
+	if ftx, ok := tx.(types.TxWithDynamicFee); ok && ftx.GetDynamicFee().HasGasTipCap() {
+		if blockHeight < forks.EnableDynamicBaseFee {
+			return false
+		}
+	}
 
 	switch tx.(type) {
 	case *types.SmartContractTx:
-		if blockHeight < common.HeightEnableSmartContract {
+		if blockHeight < forks.EnableSmartContract {
 			return false
 		}
 	case *types.StakeRewardDistributionTx:
-		if blockHeight < common.HeightEnableTheta3 {
+		if blockHeight < forks.EnableTheta3 {
+			return false
+		}
+	case *types.RedelegateStakeTx:
+		if blockHeight < forks.EnableStakeRedelegation {
+			return false
+		}
+	case *types.WithdrawStakeTxV2:
+		if blockHeight < forks.EnablePartialStakeWithdrawal {
+			return false
+		}
+	case *types.GovernanceProposalTx, *types.GovernanceVoteTx, *types.GovernanceExecuteTx:
+		if blockHeight < forks.EnableGovernance {
+			return false
+		}
+	case *types.EvidenceTx:
+		if blockHeight < forks.EnableEquivocationSlashing {
 			return false
 		}
 	default:
@@ -211,10 +333,18 @@ func (exec *Executor) getTxExecutor(tx types.Tx) TxExecutor {
 		txExecutor = exec.depositStakeTxExec
 	case *types.WithdrawStakeTx:
 		txExecutor = exec.withdrawStakeTxExec
+	case *types.WithdrawStakeTxV2:
+		txExecutor = exec.withdrawStakeTxExec
 	case *types.DepositStakeTxV2:
 		txExecutor = exec.depositStakeTxExec
 	case *types.StakeRewardDistributionTx:
 		txExecutor = exec.stakeRewardDistributionTxExec
+	case *types.RedelegateStakeTx:
+		txExecutor = exec.redelegateStakeTxExec
+	case *types.GovernanceProposalTx, *types.GovernanceVoteTx, *types.GovernanceExecuteTx:
+		txExecutor = exec.governanceTxExec
+	case *types.EvidenceTx:
+		txExecutor = exec.evidenceTxExec
 	default:
 		txExecutor = nil
 	}
@@ -29,6 +29,13 @@ type TestConsensusEngine struct {
 
 func (tce *TestConsensusEngine) ID() string                                               { return tce.privKey.PublicKey().Address().Hex() }
 func (tce *TestConsensusEngine) PrivateKey() *crypto.PrivateKey                           { return tce.privKey }
+func (tce *TestConsensusEngine) SignTx(chainID string, rawTx common.Bytes) (*crypto.Signature, error) {
+	tx, err := types.TxFromBytes(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	return tce.privKey.Sign(tx.SignBytes(chainID))
+}
 func (tce *TestConsensusEngine) GetTip(bool) *core.ExtendedBlock                          { return nil }
 func (tce *TestConsensusEngine) GetEpoch() uint64                                         { return 100 }
 func (tce *TestConsensusEngine) AddMessage(msg interface{})                               {}
@@ -98,7 +98,7 @@ func (exec *CoinbaseTxExecutor) sanityCheck(chainID string, view *st.StoreView,
 	guardianVotes := currentBlock.GuardianVotes
 	eliteEdgeNodeVotes := currentBlock.EliteEdgeNodeVotes
 	guardianPool, eliteEdgeNodePool := RetrievePools(exec.consensus.GetLedger(), exec.chain, exec.db, tx.BlockHeight, guardianVotes, eliteEdgeNodeVotes)
-	expectedRewards = CalculateReward(exec.consensus.GetLedger(), view, validatorSet, guardianVotes, guardianPool, eliteEdgeNodeVotes, eliteEdgeNodePool)
+	expectedRewards = CalculateReward(chainID, exec.consensus.GetLedger(), view, validatorSet, guardianVotes, guardianPool, eliteEdgeNodeVotes, eliteEdgeNodePool)
 
 	if len(expectedRewards) != len(tx.Outputs) {
 		return result.Error("Number of rewarded account is incorrect")
@@ -145,10 +145,11 @@ func RetrievePools(ledger core.Ledger, chain *blockchain.Chain, db database.Data
 	guardianPool = nil
 	eliteEdgeNodePool = nil
 
-	if blockHeight < common.HeightEnableTheta2 {
+	forks := common.GetForkSchedule(chain.ChainID)
+	if blockHeight < forks.EnableTheta2 {
 		guardianPool = nil
 		eliteEdgeNodePool = nil
-	} else if blockHeight < common.HeightEnableTheta3 {
+	} else if blockHeight < forks.EnableTheta3 {
 		if guardianVotes != nil {
 			guradianVoteBlock, err := chain.FindBlock(guardianVotes.Block)
 			if err != nil {
@@ -157,7 +158,7 @@ func RetrievePools(ledger core.Ledger, chain *blockchain.Chain, db database.Data
 			storeView := st.NewStoreView(guradianVoteBlock.Height, guradianVoteBlock.StateHash, db)
 			guardianPool = storeView.GetGuardianCandidatePool()
 		}
-	} else { // blockHeight >= common.HeightEnableTheta3
+	} else { // blockHeight >= forks.EnableTheta3
 		// won't reward the elite edge nodes without the guardian votes, since we need to guardian votes to confirm that
 		// the edge nodes vote for the correct checkpoint
 		if guardianVotes != nil {
@@ -185,20 +186,21 @@ func RetrievePools(ledger core.Ledger, chain *blockchain.Chain, db database.Data
 }
 
 // CalculateReward calculates the block reward for each account
-func CalculateReward(ledger core.Ledger, view *st.StoreView, validatorSet *core.ValidatorSet,
+func CalculateReward(chainID string, ledger core.Ledger, view *st.StoreView, validatorSet *core.ValidatorSet,
 	guardianVotes *core.AggregatedVotes, guardianPool *core.GuardianCandidatePool,
 	eliteEdgeNodeVotes *core.AggregatedEENVotes, eliteEdgeNodePool core.EliteEdgeNodePool) map[string]types.Coins {
 	accountReward := map[string]types.Coins{}
 	blockHeight := view.Height() + 1 // view points to the parent block
-	if blockHeight < common.HeightEnableValidatorReward {
+	forks := common.GetForkSchedule(chainID)
+	if blockHeight < forks.EnableValidatorReward {
 		grantValidatorsWithZeroReward(validatorSet, &accountReward)
-	} else if blockHeight < common.HeightEnableTheta2 || guardianVotes == nil || guardianPool == nil {
+	} else if blockHeight < forks.EnableTheta2 || guardianVotes == nil || guardianPool == nil {
 		grantValidatorReward(ledger, view, validatorSet, &accountReward, blockHeight)
-	} else if blockHeight < common.HeightEnableTheta3 {
-		grantValidatorAndGuardianReward(ledger, view, validatorSet, guardianVotes, guardianPool, &accountReward, blockHeight)
-	} else { // blockHeight >= common.HeightEnableTheta3
-		grantValidatorAndGuardianReward(ledger, view, validatorSet, guardianVotes, guardianPool, &accountReward, blockHeight)
-		grantEliteEdgeNodeReward(ledger, view, guardianVotes, eliteEdgeNodeVotes, eliteEdgeNodePool, &accountReward, blockHeight)
+	} else if blockHeight < forks.EnableTheta3 {
+		grantValidatorAndGuardianReward(chainID, ledger, view, validatorSet, guardianVotes, guardianPool, &accountReward, blockHeight)
+	} else { // blockHeight >= forks.EnableTheta3
+		grantValidatorAndGuardianReward(chainID, ledger, view, validatorSet, guardianVotes, guardianPool, &accountReward, blockHeight)
+		grantEliteEdgeNodeReward(chainID, ledger, view, guardianVotes, eliteEdgeNodeVotes, eliteEdgeNodePool, &accountReward, blockHeight)
 	}
 
 	addrs := []string{}
@@ -281,7 +283,7 @@ func grantValidatorReward(ledger core.Ledger, view *st.StoreView, validatorSet *
 }
 
 // grant block rewards to both the validators and active guardians (they are both theta stakers)
-func grantValidatorAndGuardianReward(ledger core.Ledger, view *st.StoreView, validatorSet *core.ValidatorSet, guardianVotes *core.AggregatedVotes,
+func grantValidatorAndGuardianReward(chainID string, ledger core.Ledger, view *st.StoreView, validatorSet *core.ValidatorSet, guardianVotes *core.AggregatedVotes,
 	guardianPool *core.GuardianCandidatePool, accountReward *map[string]types.Coins, blockHeight uint64) {
 	if !common.IsCheckPointHeight(blockHeight) {
 		return
@@ -351,12 +353,13 @@ func grantValidatorAndGuardianReward(ledger core.Ledger, view *st.StoreView, val
 
 	totalReward := big.NewInt(1).Mul(tfuelRewardPerBlock, big.NewInt(common.CheckpointInterval))
 
+	forks := common.GetForkSchedule(chainID)
 	var srdsr *st.StakeRewardDistributionRuleSet
-	if blockHeight >= common.HeightEnableTheta3 {
+	if blockHeight >= forks.EnableTheta3 {
 		srdsr = state.NewStakeRewardDistributionRuleSet(view)
 	}
 
-	if blockHeight < common.HeightSampleStakingReward {
+	if blockHeight < forks.SampleStakingReward {
 		// the source of the stake divides the block reward proportional to their stake
 		issueFixedReward(effectiveStakes, totalStake, accountReward, totalReward, srdsr, "Block")
 	} else {
@@ -367,7 +370,7 @@ func grantValidatorAndGuardianReward(ledger core.Ledger, view *st.StoreView, val
 }
 
 // grant uptime mining rewards to active elite edge nodes (they are the tfuel stakers)
-func grantEliteEdgeNodeReward(ledger core.Ledger, view *st.StoreView, guardianVotes *core.AggregatedVotes, eliteEdgeNodeVotes *core.AggregatedEENVotes,
+func grantEliteEdgeNodeReward(chainID string, ledger core.Ledger, view *st.StoreView, guardianVotes *core.AggregatedVotes, eliteEdgeNodeVotes *core.AggregatedEENVotes,
 	eliteEdgeNodePool core.EliteEdgeNodePool, accountReward *map[string]types.Coins, blockHeight uint64) {
 	if !common.IsCheckPointHeight(blockHeight) {
 		return
@@ -434,7 +437,7 @@ func grantEliteEdgeNodeReward(ledger core.Ledger, view *st.StoreView, guardianVo
 	logger.Debugf("grantEliteEdgeNodeReward: totalEffectiveStake = %v, totalReward = %v", totalEffectiveStake, totalReward)
 
 	var srdsr *st.StakeRewardDistributionRuleSet
-	if blockHeight >= common.HeightEnableTheta3 {
+	if blockHeight >= common.GetForkSchedule(chainID).EnableTheta3 {
 		srdsr = state.NewStakeRewardDistributionRuleSet(view)
 	}
 
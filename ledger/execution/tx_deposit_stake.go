@@ -19,6 +19,8 @@ var _ TxExecutor = (*DepositStakeExecutor)(nil)
 // DepositStakeExecutor implements the TxExecutor interface
 type DepositStakeExecutor struct {
 	state *st.LedgerState
+
+	skipSignatureCheck bool // only used for simulations
 }
 
 // NewDepositStakeExecutor creates a new instance of DepositStakeExecutor
@@ -31,7 +33,7 @@ func NewDepositStakeExecutor(state *st.LedgerState) *DepositStakeExecutor {
 func (exec *DepositStakeExecutor) sanityCheck(chainID string, view *st.StoreView, viewSel core.ViewSelector, transaction types.Tx) result.Result {
 	// Feature block height check
 	blockHeight := view.Height() + 1 // the view points to the parent of the current block
-	if _, ok := transaction.(*types.DepositStakeTxV2); ok && blockHeight < common.HeightEnableTheta2 {
+	if _, ok := transaction.(*types.DepositStakeTxV2); ok && blockHeight < common.GetForkSchedule(chainID).EnableTheta2 {
 		return result.Error("Feature guardian is not active yet")
 	}
 
@@ -48,13 +50,13 @@ func (exec *DepositStakeExecutor) sanityCheck(chainID string, view *st.StoreView
 	}
 
 	signBytes := tx.SignBytes(chainID)
-	res = validateInputAdvanced(sourceAccount, signBytes, tx.Source, blockHeight)
+	res = validateInputAdvanced(chainID, sourceAccount, signBytes, tx.Source, blockHeight, exec.skipSignatureCheck)
 	if res.IsError() {
 		logger.Debugf(fmt.Sprintf("validateSourceAdvanced failed on %v: %v", tx.Source.Address.Hex(), res))
 		return res
 	}
 
-	if minTxFee, success := sanityCheckForFee(tx.Fee, blockHeight); !success {
+	if minTxFee, success := sanityCheckForFee(chainID, view, tx.Fee, blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
@@ -82,21 +84,19 @@ func (exec *DepositStakeExecutor) sanityCheck(chainID string, view *st.StoreView
 
 	// Minimum stake deposit requirement to avoid spamming
 	if tx.Purpose == core.StakeForValidator {
-		minValidatorStake := core.MinValidatorStakeDeposit
-		if blockHeight >= common.HeightValidatorStakeChangedTo200K {
-			minValidatorStake = core.MinValidatorStakeDeposit200K
-		}
+		minValidatorStake := view.GetProtocolParam(chainID, types.ParamMinimumValidatorStake, blockHeight)
 		if stake.ThetaWei.Cmp(minValidatorStake) < 0 {
 			return result.Error("Insufficient amount of stake, at least %v ThetaWei is required for each validator deposit", minValidatorStake).
 				WithErrorCode(result.CodeInsufficientStake)
 		}
+		if view.IsValidatorJailed(tx.Holder.Address, blockHeight) {
+			return result.Error("Validator %v is jailed for equivocation", tx.Holder.Address).
+				WithErrorCode(result.CodeValidatorJailed)
+		}
 	}
 
 	if tx.Purpose == core.StakeForGuardian {
-		minGuardianStake := core.MinGuardianStakeDeposit
-		if blockHeight >= common.HeightLowerGNStakeThresholdTo1000 {
-			minGuardianStake = core.MinGuardianStakeDeposit1000
-		}
+		minGuardianStake := view.GetProtocolParam(chainID, types.ParamMinimumGuardianStake, blockHeight)
 		if stake.ThetaWei.Cmp(minGuardianStake) < 0 {
 			return result.Error("Insufficient amount of stake, at least %v ThetaWei is required for each guardian deposit", minGuardianStake).
 				WithErrorCode(result.CodeInsufficientStake)
@@ -104,11 +104,12 @@ func (exec *DepositStakeExecutor) sanityCheck(chainID string, view *st.StoreView
 	}
 
 	if tx.Purpose == core.StakeForEliteEdgeNode {
-		if blockHeight < common.HeightEnableTheta3 {
-			return result.Error(fmt.Sprintf("Elite Edge Node staking not enabled yet, please wait until block height %v", common.HeightEnableTheta3)).WithErrorCode(result.CodeGenericError)
+		enableHeight := common.GetForkSchedule(chainID).EnableTheta3
+		if blockHeight < enableHeight {
+			return result.Error(fmt.Sprintf("Elite Edge Node staking not enabled yet, please wait until block height %v", enableHeight)).WithErrorCode(result.CodeGenericError)
 		}
 
-		minEliteEdgeNodeStake := core.MinEliteEdgeNodeStakeDeposit
+		minEliteEdgeNodeStake := view.GetProtocolParam(chainID, types.ParamMinimumEliteEdgeNodeStake, blockHeight)
 		maxEliteEdgeNodeStake := core.MaxEliteEdgeNodeStakeDeposit
 
 		if stake.ThetaWei.Cmp(big.NewInt(0)) > 0 {
@@ -166,7 +167,7 @@ func (exec *DepositStakeExecutor) process(chainID string, view *st.StoreView, vi
 		sourceAccount.Balance = sourceAccount.Balance.Minus(stake)
 		stakeAmount := stake.ThetaWei
 		vcp := view.GetValidatorCandidatePool()
-		err := vcp.DepositStake(sourceAddress, holderAddress, stakeAmount, blockHeight)
+		err := vcp.DepositStake(chainID, sourceAddress, holderAddress, stakeAmount, blockHeight)
 		if err != nil {
 			return common.Hash{}, result.Error("Failed to deposit stake, err: %v", err)
 		}
@@ -183,7 +184,7 @@ func (exec *DepositStakeExecutor) process(chainID string, view *st.StoreView, vi
 			}
 		}
 
-		err := gcp.DepositStake(sourceAddress, holderAddress, stakeAmount, tx.BlsPubkey, blockHeight)
+		err := gcp.DepositStake(chainID, sourceAddress, holderAddress, stakeAmount, tx.BlsPubkey, blockHeight)
 		if err != nil {
 			return common.Hash{}, result.Error("Failed to deposit stake, err: %v", err)
 		}
@@ -0,0 +1,139 @@
+package execution
+
+import (
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*EvidenceTxExecutor)(nil)
+
+// ------------------------------- Evidence Transaction -----------------------------------
+
+// EvidenceTxExecutor implements the TxExecutor interface
+type EvidenceTxExecutor struct {
+	ledger core.Ledger
+	valMgr core.ValidatorManager
+}
+
+// NewEvidenceTxExecutor creates a new instance of EvidenceTxExecutor
+func NewEvidenceTxExecutor(ledger core.Ledger, valMgr core.ValidatorManager) *EvidenceTxExecutor {
+	return &EvidenceTxExecutor{
+		ledger: ledger,
+		valMgr: valMgr,
+	}
+}
+
+func (exec *EvidenceTxExecutor) sanityCheck(chainID string, view *st.StoreView, viewSel core.ViewSelector, transaction types.Tx) result.Result {
+	tx := transaction.(*types.EvidenceTx)
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	enableHeight := common.GetForkSchedule(chainID).EnableEquivocationSlashing
+	if blockHeight < enableHeight {
+		return result.Error("Equivocation slashing not enabled yet, please wait until block height %v", enableHeight)
+	}
+
+	// Validate proposer, basic
+	res := tx.Proposer.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	// verify the proposer is one of the validators
+	validatorSet := getValidatorSet(exec.ledger, exec.valMgr)
+	res = isAValidator(tx.Proposer.Address, getValidatorAddresses(validatorSet))
+	if res.IsError() {
+		return res
+	}
+
+	proposerAccount, res := getOrMakeInput(view, tx.Proposer)
+	if res.IsError() {
+		return res
+	}
+
+	// verify the proposer's signature
+	signBytes := tx.SignBytes(chainID)
+	if !tx.Proposer.Signature.Verify(signBytes, proposerAccount.Address) {
+		return result.Error("SignBytes: %X", signBytes)
+	}
+
+	return checkEquivocationEvidence(chainID, view, tx.Evidence, blockHeight)
+}
+
+// checkEquivocationEvidence checks that the evidence is valid, recent enough, and that the
+// offender has stakes to slash and is not already jailed.
+func checkEquivocationEvidence(chainID string, view *st.StoreView, evidence *core.EquivocationEvidence, blockHeight uint64) result.Result {
+	if evidence == nil {
+		return result.Error("Evidence is missing").
+			WithErrorCode(result.CodeInvalidEquivocationEvidence)
+	}
+	if res := evidence.Validate(chainID); res.IsError() {
+		return result.Error("Invalid equivocation evidence: %v", res.Message).
+			WithErrorCode(result.CodeInvalidEquivocationEvidence)
+	}
+	if evidence.Height() >= blockHeight {
+		return result.Error("Equivocation evidence at height %v is from the future", evidence.Height()).
+			WithErrorCode(result.CodeInvalidEquivocationEvidence)
+	}
+	if blockHeight-evidence.Height() > core.MaxEquivocationEvidenceAge {
+		return result.Error("Equivocation evidence at height %v has expired", evidence.Height()).
+			WithErrorCode(result.CodeInvalidEquivocationEvidence)
+	}
+
+	offender := evidence.Offender()
+	if view.IsValidatorJailed(offender, blockHeight) {
+		return result.Error("Validator %v is already jailed", offender).
+			WithErrorCode(result.CodeValidatorJailed)
+	}
+	if view.GetValidatorCandidatePool().FindStakeDelegate(offender) == nil {
+		return result.Error("Validator %v has no stake to slash", offender).
+			WithErrorCode(result.CodeInvalidEquivocationEvidence)
+	}
+
+	return result.OK
+}
+
+func (exec *EvidenceTxExecutor) process(chainID string, view *st.StoreView, viewSel core.ViewSelector, transaction types.Tx) (common.Hash, result.Result) {
+	tx := transaction.(*types.EvidenceTx)
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
+	offender := tx.Evidence.Offender()
+	releaseHeight := blockHeight + core.EquivocationJailPeriod
+
+	vcp := view.GetValidatorCandidatePool()
+	slashedAmount, err := vcp.SlashAndJail(offender, core.EquivocationSlashPercentage, releaseHeight)
+	if err != nil {
+		return common.Hash{}, result.Error("Failed to slash validator %v: %v", offender, err)
+	}
+	view.UpdateValidatorCandidatePool(vcp)
+	view.SetValidatorJailReleaseHeight(offender, releaseHeight)
+
+	hl := view.GetStakeTransactionHeightList()
+	if hl == nil {
+		hl = &types.HeightList{}
+	}
+	hl.Append(blockHeight)
+	view.UpdateStakeTransactionHeightList(hl)
+
+	logger.Infof("Slashed %v ThetaWei from validator %v for equivocation at height %v, jailed until height %v",
+		slashedAmount, offender.Hex(), tx.Evidence.Height(), releaseHeight)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *EvidenceTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.EvidenceTx)
+	return &core.TxInfo{
+		Address:           tx.Proposer.Address,
+		Sequence:          tx.Proposer.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *EvidenceTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	return new(big.Int).SetUint64(0)
+}
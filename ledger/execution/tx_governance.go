@@ -0,0 +1,256 @@
+package execution
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*GovernanceTxExecutor)(nil)
+
+// ------------------------------- Governance Transactions -----------------------------------
+
+// GovernanceTxExecutor implements the TxExecutor interface for the GovernanceProposalTx,
+// GovernanceVoteTx and GovernanceExecuteTx transactions
+type GovernanceTxExecutor struct {
+	state *st.LedgerState
+
+	skipSignatureCheck bool // only used for simulations
+}
+
+// NewGovernanceTxExecutor creates a new instance of GovernanceTxExecutor
+func NewGovernanceTxExecutor(state *st.LedgerState) *GovernanceTxExecutor {
+	return &GovernanceTxExecutor{
+		state: state,
+	}
+}
+
+func (exec *GovernanceTxExecutor) sanityCheck(chainID string, view *st.StoreView, viewSel core.ViewSelector, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	enableHeight := common.GetForkSchedule(chainID).EnableGovernance
+	if blockHeight < enableHeight {
+		return result.Error("Governance not enabled yet, please wait until block height %v", enableHeight)
+	}
+
+	fee, input := exec.getFeeAndInput(transaction)
+	res := input.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	account, success := getInput(view, input)
+	if success.IsError() {
+		return result.Error("Failed to get the account: %v", input.Address)
+	}
+
+	signBytes := transaction.SignBytes(chainID)
+	res = validateInputAdvanced(chainID, account, signBytes, input, blockHeight, exec.skipSignatureCheck)
+	if res.IsError() {
+		logger.Debugf(fmt.Sprintf("validateSourceAdvanced failed on %v: %v", input.Address.Hex(), res))
+		return res
+	}
+
+	if minTxFee, success := sanityCheckForFee(chainID, view, fee, blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+
+	if !account.Balance.IsGTE(fee) {
+		return result.Error("Account balance is %v, but the fee is %v", account.Balance, fee).
+			WithErrorCode(result.CodeInsufficientFund)
+	}
+
+	switch tx := transaction.(type) {
+	case *types.GovernanceProposalTx:
+		if err := types.ValidateParamChanges(tx.Changes); err != nil {
+			return result.Error("Invalid governance proposal: %v", err).
+				WithErrorCode(result.CodeInvalidGovernanceProposal)
+		}
+		if getGovernanceVotingPower(view, tx.Proposer.Address).Sign() == 0 {
+			return result.Error("Only validator or guardian stake holders can submit governance proposals").
+				WithErrorCode(result.CodeNoGovernanceVotingPower)
+		}
+	case *types.GovernanceVoteTx:
+		if _, res := getOpenGovernanceProposal(view, tx.ProposalID, blockHeight); res.IsError() {
+			return res
+		}
+		if getGovernanceVotingPower(view, tx.Voter.Address).Sign() == 0 {
+			return result.Error("Only validator or guardian stake holders can vote on governance proposals").
+				WithErrorCode(result.CodeNoGovernanceVotingPower)
+		}
+	case *types.GovernanceExecuteTx:
+		proposal, res := getOpenGovernanceProposal(view, tx.ProposalID, blockHeight)
+		if res.IsError() {
+			return res
+		}
+		approved, total := tallyGovernanceVotes(view, proposal)
+		if !isGovernanceProposalApproved(approved, total) {
+			return result.Error("Governance proposal %v is approved by %v out of %v stake, more than 2/3 is required",
+				tx.ProposalID, approved, total).WithErrorCode(result.CodeGovernanceProposalRejected)
+		}
+	default:
+		return result.Error("Unsupported governance transaction type")
+	}
+
+	return result.OK
+}
+
+func (exec *GovernanceTxExecutor) process(chainID string, view *st.StoreView, viewSel core.ViewSelector, transaction types.Tx) (common.Hash, result.Result) {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
+	fee, input := exec.getFeeAndInput(transaction)
+	account, success := getInput(view, input)
+	if success.IsError() {
+		return common.Hash{}, result.Error("Failed to get the account")
+	}
+
+	if !chargeFee(account, fee) {
+		return common.Hash{}, result.Error("Failed to charge transaction fee")
+	}
+
+	switch tx := transaction.(type) {
+	case *types.GovernanceProposalTx:
+		id := view.GetGovernanceProposalCount()
+		proposal := &types.GovernanceProposal{
+			ID:          id,
+			Proposer:    tx.Proposer.Address,
+			Changes:     tx.Changes,
+			StartHeight: blockHeight,
+			EndHeight:   blockHeight + types.GovernanceVotingPeriod,
+		}
+		view.SetGovernanceProposal(proposal)
+		view.SetGovernanceProposalCount(id + 1)
+	case *types.GovernanceVoteTx:
+		proposal, res := getOpenGovernanceProposal(view, tx.ProposalID, blockHeight)
+		if res.IsError() {
+			return common.Hash{}, res
+		}
+		proposal.SetVote(tx.Voter.Address, tx.Approve)
+		view.SetGovernanceProposal(proposal)
+	case *types.GovernanceExecuteTx:
+		proposal, res := getOpenGovernanceProposal(view, tx.ProposalID, blockHeight)
+		if res.IsError() {
+			return common.Hash{}, res
+		}
+		approved, total := tallyGovernanceVotes(view, proposal)
+		if !isGovernanceProposalApproved(approved, total) {
+			return common.Hash{}, result.Error("Governance proposal %v is not approved", tx.ProposalID).
+				WithErrorCode(result.CodeGovernanceProposalRejected)
+		}
+		for _, change := range proposal.Changes {
+			view.SetGovernanceParam(change.Name, change.Value)
+		}
+		proposal.Executed = true
+		view.SetGovernanceProposal(proposal)
+		logger.Infof("Executed governance proposal %v at height %v: %v", proposal.ID, blockHeight, proposal.Changes)
+	default:
+		return common.Hash{}, result.Error("Unsupported governance transaction type")
+	}
+
+	account.Sequence++
+	view.SetAccount(input.Address, account)
+
+	txHash := types.TxID(chainID, transaction)
+	return txHash, result.OK
+}
+
+func (exec *GovernanceTxExecutor) getFeeAndInput(transaction types.Tx) (types.Coins, types.TxInput) {
+	switch tx := transaction.(type) {
+	case *types.GovernanceProposalTx:
+		return tx.Fee, tx.Proposer
+	case *types.GovernanceVoteTx:
+		return tx.Fee, tx.Voter
+	case *types.GovernanceExecuteTx:
+		return tx.Fee, tx.Source
+	default:
+		return types.Coins{}, types.TxInput{}
+	}
+}
+
+func (exec *GovernanceTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	_, input := exec.getFeeAndInput(transaction)
+	return &core.TxInfo{
+		Address:           input.Address,
+		Sequence:          input.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *GovernanceTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	fee, _ := exec.getFeeAndInput(transaction)
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.NoNil().TFuelWei, gas)
+	return effectiveGasPrice
+}
+
+// getOpenGovernanceProposal returns the proposal if it can still be voted on and executed
+// at the given block height
+func getOpenGovernanceProposal(view *st.StoreView, id uint64, blockHeight uint64) (*types.GovernanceProposal, result.Result) {
+	proposal := view.GetGovernanceProposal(id)
+	if proposal == nil {
+		return nil, result.Error("Governance proposal %v does not exist", id).
+			WithErrorCode(result.CodeGovernanceProposalNotFound)
+	}
+	if proposal.Executed {
+		return nil, result.Error("Governance proposal %v has already been executed", id).
+			WithErrorCode(result.CodeGovernanceVotingClosed)
+	}
+	if blockHeight > proposal.EndHeight {
+		return nil, result.Error("The voting period of governance proposal %v ended at height %v", id, proposal.EndHeight).
+			WithErrorCode(result.CodeGovernanceVotingClosed)
+	}
+	return proposal, result.OK
+}
+
+// getGovernanceVotingPower returns the validator and guardian stake held by the given address
+func getGovernanceVotingPower(view *st.StoreView, holder common.Address) *big.Int {
+	power := big.NewInt(0)
+	if vcp := view.GetValidatorCandidatePool(); vcp != nil {
+		for _, candidate := range vcp.SortedCandidates {
+			if candidate.Holder == holder {
+				power.Add(power, candidate.TotalStake())
+			}
+		}
+	}
+	if g := view.GetGuardianCandidatePool().GetWithHolderAddress(holder); g != nil {
+		power.Add(power, g.TotalStake())
+	}
+	return power
+}
+
+// tallyGovernanceVotes returns the stake of the voters approving the proposal, and the total
+// validator and guardian stake
+func tallyGovernanceVotes(view *st.StoreView, proposal *types.GovernanceProposal) (approved *big.Int, total *big.Int) {
+	approved = big.NewInt(0)
+	for _, vote := range proposal.Votes {
+		if vote.Approve {
+			approved.Add(approved, getGovernanceVotingPower(view, vote.Voter))
+		}
+	}
+
+	total = big.NewInt(0)
+	if vcp := view.GetValidatorCandidatePool(); vcp != nil {
+		for _, candidate := range vcp.SortedCandidates {
+			total.Add(total, candidate.TotalStake())
+		}
+	}
+	for _, g := range view.GetGuardianCandidatePool().SortedGuardians {
+		total.Add(total, g.TotalStake())
+	}
+	return approved, total
+}
+
+// isGovernanceProposalApproved checks whether more than 2/3 of the total stake approved the proposal
+func isGovernanceProposalApproved(approved *big.Int, total *big.Int) bool {
+	if total.Sign() == 0 {
+		return false
+	}
+	lhs := new(big.Int).Mul(approved, big.NewInt(3))
+	rhs := new(big.Int).Mul(total, big.NewInt(2))
+	return lhs.Cmp(rhs) > 0
+}
@@ -0,0 +1,184 @@
+package execution
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*RedelegateStakeExecutor)(nil)
+
+// ------------------------------- RedelegateStake Transaction -----------------------------------
+
+// RedelegateStakeExecutor implements the TxExecutor interface
+type RedelegateStakeExecutor struct {
+	state *st.LedgerState
+
+	skipSignatureCheck bool // only used for simulations
+}
+
+// NewRedelegateStakeExecutor creates a new instance of RedelegateStakeExecutor
+func NewRedelegateStakeExecutor(state *st.LedgerState) *RedelegateStakeExecutor {
+	return &RedelegateStakeExecutor{
+		state: state,
+	}
+}
+
+func (exec *RedelegateStakeExecutor) sanityCheck(chainID string, view *st.StoreView, viewSel core.ViewSelector, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	tx := transaction.(*types.RedelegateStakeTx)
+
+	enableHeight := common.GetForkSchedule(chainID).EnableStakeRedelegation
+	if blockHeight < enableHeight {
+		return result.Error("Stake redelegation not enabled yet, please wait until block height %v", enableHeight)
+	}
+
+	res := tx.Source.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	sourceAccount, success := getInput(view, tx.Source)
+	if success.IsError() {
+		return result.Error("Failed to get the source account: %v", tx.Source.Address)
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(chainID, sourceAccount, signBytes, tx.Source, blockHeight, exec.skipSignatureCheck)
+	if res.IsError() {
+		logger.Debugf(fmt.Sprintf("validateSourceAdvanced failed on %v: %v", tx.Source.Address.Hex(), res))
+		return res
+	}
+
+	if minTxFee, success := sanityCheckForFee(chainID, view, tx.Fee, blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+
+	if !(tx.Purpose == core.StakeForGuardian || tx.Purpose == core.StakeForEliteEdgeNode) {
+		return result.Error("Only guardian or elite edge node stakes can be redelegated!").
+			WithErrorCode(result.CodeInvalidStakePurpose)
+	}
+
+	if tx.FromHolder.Address == tx.ToHolder.Address {
+		return result.Error("Cannot redelegate stake to the same holder").
+			WithErrorCode(result.CodeInvalidStake)
+	}
+
+	if lastHeight, ok := view.GetStakeRedelegationHeight(tx.Source.Address); ok && blockHeight < lastHeight+core.RedelegationCooldown {
+		return result.Error("Stake redelegated at height %v, the next redelegation is allowed from height %v",
+			lastHeight, lastHeight+core.RedelegationCooldown).WithErrorCode(result.CodeStakeRedelegationTooFrequent)
+	}
+
+	minimalBalance := tx.Fee
+	if !sourceAccount.Balance.IsGTE(minimalBalance) {
+		logger.Infof(fmt.Sprintf("RedelegateStake: Source did not have enough balance %v", tx.Source.Address.Hex()))
+		return result.Error("RedelegateStake: Source balance is %v, but required minimal balance is %v",
+			sourceAccount.Balance, minimalBalance)
+	}
+
+	return result.OK
+}
+
+func (exec *RedelegateStakeExecutor) process(chainID string, view *st.StoreView, viewSel core.ViewSelector, transaction types.Tx) (common.Hash, result.Result) {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
+	tx := transaction.(*types.RedelegateStakeTx)
+
+	sourceAccount, success := getInput(view, tx.Source)
+	if success.IsError() {
+		return common.Hash{}, result.Error("Failed to get the source account")
+	}
+
+	if !chargeFee(sourceAccount, tx.Fee) {
+		return common.Hash{}, result.Error("Failed to charge transaction fee")
+	}
+
+	sourceAddress := tx.Source.Address
+	fromHolderAddress := tx.FromHolder.Address
+	toHolderAddress := tx.ToHolder.Address
+
+	if tx.Purpose == core.StakeForGuardian {
+		gcp := view.GetGuardianCandidatePool()
+		if !gcp.Contains(toHolderAddress) {
+			checkBLSRes := exec.checkBLSSummary(tx)
+			if checkBLSRes.IsError() {
+				return common.Hash{}, checkBLSRes
+			}
+		}
+
+		_, err := gcp.RedelegateStake(chainID, sourceAddress, fromHolderAddress, toHolderAddress, tx.BlsPubkey, blockHeight)
+		if err != nil {
+			return common.Hash{}, result.Error("Failed to redelegate stake, err: %v", err)
+		}
+		view.UpdateGuardianCandidatePool(gcp)
+	} else if tx.Purpose == core.StakeForEliteEdgeNode {
+		eenp := state.NewEliteEdgeNodePool(view, false)
+		if !eenp.Contains(toHolderAddress) {
+			checkBLSRes := exec.checkBLSSummary(tx)
+			if checkBLSRes.IsError() {
+				return common.Hash{}, checkBLSRes
+			}
+		}
+
+		_, err := eenp.RedelegateStake(sourceAddress, fromHolderAddress, toHolderAddress, tx.BlsPubkey)
+		if err != nil {
+			return common.Hash{}, result.Error("Failed to redelegate stake, err: %v", err)
+		}
+	} else {
+		return common.Hash{}, result.Error("Invalid staking purpose").WithErrorCode(result.CodeInvalidStakePurpose)
+	}
+
+	view.SetStakeRedelegationHeight(sourceAddress, blockHeight)
+
+	sourceAccount.Sequence++
+	view.SetAccount(sourceAddress, sourceAccount)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *RedelegateStakeExecutor) checkBLSSummary(tx *types.RedelegateStakeTx) result.Result {
+	if tx.BlsPubkey.IsEmpty() {
+		return result.Error("Must provide BLS Pubkey")
+	}
+	if tx.BlsPop.IsEmpty() {
+		return result.Error("Must provide BLS POP")
+	}
+	if tx.HolderSig == nil || tx.HolderSig.IsEmpty() {
+		return result.Error("Must provide Holder Signature")
+	}
+
+	if !tx.HolderSig.Verify(tx.BlsPop.ToBytes(), tx.ToHolder.Address) {
+		return result.Error("BLS key info is not properly signed")
+	}
+
+	if !tx.BlsPop.PopVerify(tx.BlsPubkey) {
+		return result.Error("BLS pop is invalid")
+	}
+
+	return result.OK
+}
+
+func (exec *RedelegateStakeExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.RedelegateStakeTx)
+	return &core.TxInfo{
+		Address:           tx.Source.Address,
+		Sequence:          tx.Source.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *RedelegateStakeExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.RedelegateStakeTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
@@ -18,6 +18,8 @@ var _ TxExecutor = (*ReleaseFundTxExecutor)(nil)
 // ReleaseFundTxExecutor implements the TxExecutor interface
 type ReleaseFundTxExecutor struct {
 	state *st.LedgerState
+
+	skipSignatureCheck bool // only used for simulations
 }
 
 // NewReleaseFundTxExecutor creates a new instance of ReleaseFundTxExecutor
@@ -45,13 +47,13 @@ func (exec *ReleaseFundTxExecutor) sanityCheck(chainID string, view *st.StoreVie
 
 	// Validate input, advanced
 	signBytes := tx.SignBytes(chainID)
-	res = validateInputAdvanced(sourceAccount, signBytes, tx.Source, blockHeight)
+	res = validateInputAdvanced(chainID, sourceAccount, signBytes, tx.Source, blockHeight, exec.skipSignatureCheck)
 	if res.IsError() {
 		logger.Debugf(fmt.Sprintf("validateSourceAdvanced failed on %v: %v", tx.Source.Address.Hex(), res))
 		return res
 	}
 
-	if minTxFee, success := sanityCheckForFee(tx.Fee, blockHeight); !success {
+	if minTxFee, success := sanityCheckForFee(chainID, view, tx.Fee, blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
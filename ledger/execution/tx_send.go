@@ -18,6 +18,8 @@ var _ TxExecutor = (*SendTxExecutor)(nil)
 // SendTxExecutor implements the TxExecutor interface
 type SendTxExecutor struct {
 	state *st.LedgerState
+
+	skipSignatureCheck bool // only used for simulations
 }
 
 // NewSendTxExecutor creates a new instance of SendTxExecutor
@@ -44,7 +46,7 @@ func (exec *SendTxExecutor) sanityCheck(chainID string, view *st.StoreView, view
 		return result.Error("Invalid sendTx, Inputs and/or Outputs are empty")
 	}
 
-	numAccountsAffected := uint64(len(tx.Inputs) + len(tx.Outputs))
+	numAccountsAffected := getNumAccountsAffected(tx)
 	if numAccountsAffected > types.MaxAccountsAffectedPerTx {
 		return result.Error("Trasaction modifying too many accounts. At most %v accounts are allowed per transaction",
 			types.MaxAccountsAffectedPerTx)
@@ -63,7 +65,7 @@ func (exec *SendTxExecutor) sanityCheck(chainID string, view *st.StoreView, view
 	}
 
 	blockHeight := view.Height() + 1
-	if blockHeight >= common.HeightEnableSmartContract {
+	if blockHeight >= common.GetForkSchedule(chainID).EnableSmartContract {
 		for _, outAcc := range accounts {
 			if outAcc.IsASmartContract() {
 				return result.Error(
@@ -74,17 +76,32 @@ func (exec *SendTxExecutor) sanityCheck(chainID string, view *st.StoreView, view
 
 	// Validate inputs and outputs, advanced
 	signBytes := tx.SignBytes(chainID)
-	inTotal, res := validateInputsAdvanced(accounts, signBytes, tx.Inputs, blockHeight)
+	inTotal, res := validateInputsAdvanced(chainID, accounts, signBytes, tx.Inputs, blockHeight, exec.skipSignatureCheck)
 	if res.IsError() {
 		return res
 	}
 
-	if minTxFee, success := sanityCheckForSendTxFee(tx.Fee, numAccountsAffected, blockHeight); !success {
+	if minTxFee, success := sanityCheckForSendTxFee(chainID, view, tx.Fee, numAccountsAffected, blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
 
 	outTotal := sumOutputs(tx.Outputs)
+	if tx.HasFeePayer() {
+		// The fee is paid by the fee payer, so the inputs only cover the outputs
+		signers := []common.Address{}
+		for _, in := range tx.Inputs {
+			signers = append(signers, in.Address)
+		}
+		if _, res := validateFeePayer(chainID, view, tx, signers, tx.Fee, blockHeight, exec.skipSignatureCheck); res.IsError() {
+			return res
+		}
+		if !inTotal.IsEqual(outTotal) {
+			return result.Error("Input total (%v) != output total (%v)", inTotal, outTotal)
+		}
+		return result.OK
+	}
+
 	outPlusFees := outTotal
 	outPlusFees = outTotal.Plus(tx.Fee)
 	if !inTotal.IsEqual(outPlusFees) {
@@ -107,6 +124,22 @@ func (exec *SendTxExecutor) process(chainID string, view *st.StoreView, viewSel
 		return common.Hash{}, res
 	}
 
+	if tx.HasFeePayer() {
+		// The fee is not part of the inputs, charge it to the fee payer, which could be one of the outputs
+		feePayer := *tx.FeePayer
+		feePayerAccount, ok := accounts[string(feePayer[:])]
+		if !ok {
+			feePayerAccount, res = getAccount(view, feePayer)
+			if res.IsError() {
+				return common.Hash{}, res
+			}
+		}
+		if !chargeFee(feePayerAccount, tx.Fee) {
+			return common.Hash{}, result.Error("failed to charge transaction fee")
+		}
+		view.SetAccount(feePayer, feePayerAccount)
+	}
+
 	adjustByInputs(view, accounts, tx.Inputs)
 	adjustByOutputs(view, accounts, tx.Outputs)
 
@@ -126,7 +159,7 @@ func (exec *SendTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
 func (exec *SendTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
 	tx := transaction.(*types.SendTx)
 	fee := tx.Fee
-	numAccountsAffected := uint64(len(tx.Inputs) + len(tx.Outputs))
+	numAccountsAffected := getNumAccountsAffected(tx)
 
 	gasSendTxPerAccount := getRegularTxGas(exec.state) / 2
 	gasUint64 := gasSendTxPerAccount * numAccountsAffected
@@ -137,3 +170,12 @@ func (exec *SendTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *bi
 	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
 	return effectiveGasPrice
 }
+
+// getNumAccountsAffected returns the number of accounts the tx updates, which determines its fee
+func getNumAccountsAffected(tx *types.SendTx) uint64 {
+	numAccountsAffected := uint64(len(tx.Inputs) + len(tx.Outputs))
+	if tx.HasFeePayer() {
+		numAccountsAffected++
+	}
+	return numAccountsAffected
+}
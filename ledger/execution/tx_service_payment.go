@@ -18,6 +18,8 @@ var _ TxExecutor = (*ServicePaymentTxExecutor)(nil)
 // ServicePaymentTxExecutor implements the TxExecutor interface
 type ServicePaymentTxExecutor struct {
 	state *st.LedgerState
+
+	skipSignatureCheck bool // only used for simulations
 }
 
 // NewServicePaymentTxExecutor creates a new instance of ServicePaymentTxExecutor
@@ -62,27 +64,35 @@ func (exec *ServicePaymentTxExecutor) sanityCheck(chainID string, view *st.Store
 		return result.Error("Cannot send ThetaWei as service payment!")
 	}
 
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
 	// Verify source
 	sourceSignBytes := tx.SourceSignBytes(chainID)
-	if !tx.Source.Signature.Verify(sourceSignBytes, sourceAccount.Address) {
+	if !exec.skipSignatureCheck && !verifySignature(chainID, tx.Source.Signature, sourceSignBytes, sourceAccount.Address, blockHeight) {
 		errMsg := fmt.Sprintf("sanityCheckForServicePaymentTx failed on source signature, addr: %v", sourceAddress.Hex())
 		logger.Infof(errMsg)
 		return result.Error(errMsg)
 	}
 
 	targetSignBytes := tx.TargetSignBytes(chainID)
-	if !tx.Target.Signature.Verify(targetSignBytes, targetAccount.Address) {
+	if !exec.skipSignatureCheck && !verifySignature(chainID, tx.Target.Signature, targetSignBytes, targetAccount.Address, blockHeight) {
 		errMsg := fmt.Sprintf("sanityCheckForServicePaymentTx failed on target signature, addr: %v", targetAddress.Hex())
 		logger.Infof(errMsg)
 		return result.Error(errMsg)
 	}
 
-	blockHeight := view.Height() + 1 // the view points to the parent of the current block
-	if minTxFee, success := sanityCheckForFee(tx.Fee, blockHeight); !success {
+	if minTxFee, success := sanityCheckForFee(chainID, view, tx.Fee, blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
 
+	if tx.HasFeePayer() {
+		signers := []common.Address{sourceAddress, targetAddress}
+		if _, res := validateFeePayer(chainID, view, tx, signers, tx.Fee, blockHeight, exec.skipSignatureCheck); res.IsError() {
+			return res
+		}
+	}
+
 	transferAmount := tx.Source.Coins
 	currentBlockHeight := view.Height()
 	reserveSequence := tx.ReserveSequence
@@ -143,7 +153,23 @@ func (exec *ServicePaymentTxExecutor) process(chainID string, view *st.StoreView
 	if shouldSlash {
 		//view.AddSlashIntent(slashIntent)
 	}
-	if !chargeFee(targetAccount, tx.Fee) {
+	feePayerAccount := targetAccount
+	if tx.HasFeePayer() {
+		// The fee payer could be one of the split rule participants
+		feePayerAccount = nil
+		for account := range accCoinsMap {
+			if account.Address == *tx.FeePayer {
+				feePayerAccount = account
+			}
+		}
+		if feePayerAccount == nil {
+			feePayerAccount, res = getAccount(view, *tx.FeePayer)
+			if res.IsError() {
+				return common.Hash{}, res
+			}
+		}
+	}
+	if !chargeFee(feePayerAccount, tx.Fee) {
 		// should charge after transfer the fund, so an empty address has some fund to pay the tx fee
 		return common.Hash{}, result.Error("failed to charge transaction fee")
 	}
@@ -153,6 +179,9 @@ func (exec *ServicePaymentTxExecutor) process(chainID string, view *st.StoreView
 	for account := range accCoinsMap {
 		view.SetAccount(account.Address, account)
 	}
+	if tx.HasFeePayer() {
+		view.SetAccount(feePayerAccount.Address, feePayerAccount)
+	}
 
 	txHash := types.TxID(chainID, tx)
 	return txHash, result.OK
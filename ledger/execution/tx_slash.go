@@ -81,7 +81,8 @@ func (exec *SlashTxExecutor) sanityCheck(chainID string, view *st.StoreView, vie
 	}
 
 	overspendingProofBytes := tx.SlashProof
-	slashProofVerified := exec.verifySlashProof(chainID, slashedAccount, overspendingProofBytes)
+	blockHeight := view.Height() + 1
+	slashProofVerified := exec.verifySlashProof(chainID, slashedAccount, overspendingProofBytes, blockHeight)
 	if !slashProofVerified {
 		return result.Error("Invalid slash proof: %v", overspendingProofBytes)
 	}
@@ -137,7 +138,7 @@ func (exec *SlashTxExecutor) process(chainID string, view *st.StoreView, viewSel
 	return txHash, result.OK
 }
 
-func (exec *SlashTxExecutor) verifySlashProof(chainID string, slashedAccount *types.Account, overspendingProofBytes []byte) bool {
+func (exec *SlashTxExecutor) verifySlashProof(chainID string, slashedAccount *types.Account, overspendingProofBytes []byte, blockHeight uint64) bool {
 	var overspendingProof types.OverspendingProof
 	err := types.FromBytes(overspendingProofBytes, &overspendingProof)
 	if err != nil {
@@ -166,7 +167,7 @@ func (exec *SlashTxExecutor) verifySlashProof(chainID string, slashedAccount *ty
 			}
 
 			sourceSignedBytes := servicePaymentTx.SourceSignBytes(chainID)
-			if !servicePaymentTx.Source.Signature.Verify(sourceSignedBytes, slashedAccount.Address) {
+			if !verifySignature(chainID, servicePaymentTx.Source.Signature, sourceSignedBytes, slashedAccount.Address, blockHeight) {
 				return false // servicePaymentTx not signed by the slashed account
 			}
 
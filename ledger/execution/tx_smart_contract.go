@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"sync/atomic"
 
 	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/common"
@@ -21,9 +22,15 @@ var _ TxExecutor = (*SmartContractTxExecutor)(nil)
 
 // SmartContractTxExecutor implements the TxExecutor interface
 type SmartContractTxExecutor struct {
+	lastGasUsed uint64 // gas used by the last processed tx, only used for simulations. Accessed atomically, kept first for 64-bit alignment
+
 	state  *st.LedgerState
 	chain  *blockchain.Chain
 	ledger core.Ledger
+	tracer vm.Tracer // optional, only used for debug tracing
+
+	skipSignatureCheck bool // only used for simulations
+	skipTxReceipts     bool // only used for replays
 }
 
 // NewSmartContractTxExecutor creates a new instance of SmartContractTxExecutor
@@ -47,14 +54,14 @@ func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreV
 
 	// Check signatures
 	signBytes := tx.SignBytes(chainID)
-	nativeSignatureValid := tx.From.Signature.Verify(signBytes, tx.From.Address)
-	if blockHeight >= common.HeightTxWrapperExtension {
+	nativeSignatureValid := verifySignature(chainID, tx.From.Signature, signBytes, tx.From.Address, blockHeight)
+	if blockHeight >= common.GetForkSchedule(chainID).TxWrapperExtension {
 		signBytesV2 := types.ChangeEthereumTxWrapper(signBytes, 2)
-		nativeSignatureValid = nativeSignatureValid || tx.From.Signature.Verify(signBytesV2, tx.From.Address)
+		nativeSignatureValid = nativeSignatureValid || verifySignature(chainID, tx.From.Signature, signBytesV2, tx.From.Address, blockHeight)
 	}
 
-	if !nativeSignatureValid {
-		if blockHeight < common.HeightRPCCompatibility {
+	if !nativeSignatureValid && !exec.skipSignatureCheck {
+		if blockHeight < common.GetForkSchedule(chainID).RPCCompatibility {
 			return result.Error("Signature verification failed, SignBytes: %v",
 				hex.EncodeToString(signBytes)).WithErrorCode(result.CodeInvalidSignature)
 		}
@@ -64,6 +71,15 @@ func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreV
 			return result.Error("Sending Theta with ETH transaction is not allowed") // extra check, since ETH transaction only signs the TFuel part (i.e., value, gasPrice, gasLimit, etc)
 		}
 
+		// The ETH tx signature does not cover the validity window nor the fee payer, so they can only be set
+		// on txs with a native signature
+		if tx.HasValidityWindow() {
+			return result.Error("ETH transaction cannot carry a validity window").WithErrorCode(result.CodeInvalidSignature)
+		}
+		if tx.HasFeePayer() {
+			return result.Error("ETH transaction cannot carry a fee payer").WithErrorCode(result.CodeInvalidSignature)
+		}
+
 		ethSigningHash := tx.EthSigningHash(chainID, blockHeight)
 		err := crypto.ValidateEthSignature(tx.From.Address, ethSigningHash, tx.From.Signature)
 		if err != nil {
@@ -99,19 +115,30 @@ func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreV
 			WithErrorCode(result.CodeInvalidValueToTransfer)
 	}
 
-	if !sanityCheckForGasPrice(tx.GasPrice, blockHeight) {
-		minimumGasPrice := types.GetMinimumGasPrice(blockHeight)
+	if baseFee := getBaseFee(exec.state); baseFee != nil {
+		// The gas price is the max fee per gas the sender is willing to pay
+		if tx.GasPrice == nil || tx.GasPrice.Cmp(baseFee) < 0 {
+			return result.Error("Insufficient gas price. Gas price needs to be at least the base fee %v TFuelWei", baseFee).
+				WithErrorCode(result.CodeInvalidGasPrice)
+		}
+		if tx.HasGasTipCap() && (tx.GasTipCap.Sign() < 0 || tx.GasTipCap.Cmp(tx.GasPrice) > 0) {
+			return result.Error("Invalid gas tip cap %v, it needs to be between 0 and the gas price %v", tx.GasTipCap, tx.GasPrice).
+				WithErrorCode(result.CodeInvalidGasPrice)
+		}
+	}
+	if !sanityCheckForGasPrice(chainID, view, tx.GasPrice, blockHeight) {
+		minimumGasPrice := view.GetProtocolParam(chainID, types.ParamMinimumGasPrice, blockHeight)
 		return result.Error("Insufficient gas price. Gas price needs to be at least %v TFuelWei", minimumGasPrice).
 			WithErrorCode(result.CodeInvalidGasPrice)
 	}
 
-	maxGasLimit := types.GetMaxGasLimit(blockHeight)
+	maxGasLimit := view.GetProtocolParam(chainID, types.ParamMaximumTxGasLimit, blockHeight)
 	if new(big.Int).SetUint64(tx.GasLimit).Cmp(maxGasLimit) > 0 {
 		return result.Error("Invalid gas limit. Gas limit needs to be at most %v", maxGasLimit).
 			WithErrorCode(result.CodeInvalidGasLimit)
 	}
 
-	if vm.SupportWrappedTheta(blockHeight) {
+	if vm.SupportWrappedTheta(chainID, blockHeight) {
 		err := exec.checkIntrinsicGas(tx)
 		if err != nil {
 			return result.Error("Intrinsic gas check failed: %v", err).
@@ -128,10 +155,23 @@ func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreV
 			WithErrorCode(result.CodeFeeLimitTooHigh)
 	}
 
+	if tx.HasFeePayer() {
+		// The fee is paid by the fee payer, so the source only needs to cover the value
+		fee := types.Coins{
+			ThetaWei: zero,
+			TFuelWei: new(big.Int).Set(feeLimit),
+		}
+		signers := []common.Address{tx.From.Address}
+		if _, res := validateFeePayer(chainID, view, tx, signers, fee, blockHeight, exec.skipSignatureCheck); res.IsError() {
+			return res
+		}
+		feeLimit = big.NewInt(0)
+	}
+
 	var minimalBalance types.Coins
 	value := coins.TFuelWei      // NoNil() already guarantees value is NOT nil
 	thetaValue := coins.ThetaWei // NoNil() already guarantees value is NOT nil
-	if !vm.SupportThetaTransferInEVM(blockHeight) {
+	if !vm.SupportThetaTransferInEVM(chainID, blockHeight) {
 		minimalBalance = types.Coins{
 			ThetaWei: zero,
 			TFuelWei: feeLimit.Add(feeLimit, value),
@@ -163,7 +203,13 @@ func (exec *SmartContractTxExecutor) process(chainID string, view *st.StoreView,
 	//       Otherwise, the fromAccount returned by getInput() will have incorrect balance.
 	pb := exec.state.ParentBlock()
 	parentBlockInfo := vm.NewBlockInfo(pb.Height, pb.Timestamp, pb.ChainID)
-	evmRet, contractAddr, gasUsed, evmErr := vm.Execute(parentBlockInfo, tx, view)
+	vmConfig := vm.Config{}
+	if exec.tracer != nil {
+		vmConfig.Debug = true
+		vmConfig.Tracer = exec.tracer
+	}
+	evmRet, contractAddr, gasUsed, evmErr := vm.ExecuteWithConfig(parentBlockInfo, tx, view, vmConfig)
+	atomic.StoreUint64(&exec.lastGasUsed, gasUsed) // the block txs might be executed in parallel
 
 	fromAddress := tx.From.Address
 	fromAccount, success := getInput(view, tx.From)
@@ -171,12 +217,21 @@ func (exec *SmartContractTxExecutor) process(chainID string, view *st.StoreView,
 		return common.Hash{}, result.Error("Failed to get the from account")
 	}
 
-	feeAmount := new(big.Int).Mul(tx.GasPrice, new(big.Int).SetUint64(gasUsed))
+	// The whole fee is burned, including the part above the base fee
+	gasPrice := types.EffectiveGasPrice(tx.GasPrice, tx.GasTipCap, getBaseFee(exec.state))
+	feeAmount := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasUsed))
 	fee := types.Coins{
 		ThetaWei: big.NewInt(int64(0)),
 		TFuelWei: feeAmount,
 	}
-	if !chargeFee(fromAccount, fee) {
+	feePayerAccount := fromAccount
+	if tx.HasFeePayer() {
+		feePayerAccount, success = getAccount(view, *tx.FeePayer)
+		if success.IsError() {
+			return common.Hash{}, result.Error("Failed to get the fee payer account")
+		}
+	}
+	if !chargeFee(feePayerAccount, fee) {
 		return common.Hash{}, result.Error("failed to charge transaction fee")
 	}
 
@@ -185,6 +240,9 @@ func (exec *SmartContractTxExecutor) process(chainID string, view *st.StoreView,
 		fromAccount.Sequence++
 	}
 	view.SetAccount(fromAddress, fromAccount)
+	if tx.HasFeePayer() {
+		view.SetAccount(feePayerAccount.Address, feePayerAccount)
+	}
 
 	txHash := types.TxID(chainID, tx)
 
@@ -196,12 +254,21 @@ func (exec *SmartContractTxExecutor) process(chainID string, view *st.StoreView,
 		logs = nil
 		balanceChanges = nil
 	}
+	if tx.HasFeePayer() && feeAmount.Sign() > 0 {
+		// The fee is not charged to the sender, so record which account paid for it
+		balanceChanges = append(balanceChanges, &types.BalanceChange{
+			Address:    feePayerAccount.Address,
+			TokenType:  1,
+			IsNegative: true,
+			Delta:      new(big.Int).Set(feeAmount),
+		})
+	}
 
-	if viewSel == core.DeliveredView { // only record the receipt for the delivered views
+	if viewSel == core.DeliveredView && !exec.skipTxReceipts { // only record the receipt for the delivered views
 		exec.chain.AddTxReceipt(exec.ledger.GetCurrentBlock(), tx, logs, balanceChanges, evmRet, contractAddr, gasUsed, evmErr)
 	}
 
-	return txHash, result.OK
+	return txHash, result.OKWith(result.Info{GasUsedInfoKey: gasUsed, EvmRetInfoKey: evmRet, EvmErrInfoKey: evmErr})
 }
 
 func (exec *SmartContractTxExecutor) checkIntrinsicGas(tx *types.SmartContractTx) error {
@@ -231,5 +298,5 @@ func (exec *SmartContractTxExecutor) getTxInfo(transaction types.Tx) *core.TxInf
 
 func (exec *SmartContractTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
 	tx := transaction.(*types.SmartContractTx)
-	return tx.GasPrice
+	return types.EffectiveGasPrice(tx.GasPrice, tx.GasTipCap, getBaseFee(exec.state))
 }
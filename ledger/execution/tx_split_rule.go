@@ -18,6 +18,8 @@ var _ TxExecutor = (*SplitRuleTxExecutor)(nil)
 // SplitRuleTxExecutor implements the TxExecutor interface
 type SplitRuleTxExecutor struct {
 	state *st.LedgerState
+
+	skipSignatureCheck bool // only used for simulations
 }
 
 // NewSplitRuleTxExecutor creates a new instance of SplitRuleTxExecutor
@@ -44,12 +46,12 @@ func (exec *SplitRuleTxExecutor) sanityCheck(chainID string, view *st.StoreView,
 
 	// Validate inputs and outputs, advanced
 	signBytes := tx.SignBytes(chainID)
-	res = validateInputAdvanced(initiatorAccount, signBytes, tx.Initiator, blockHeight)
+	res = validateInputAdvanced(chainID, initiatorAccount, signBytes, tx.Initiator, blockHeight, exec.skipSignatureCheck)
 	if res.IsError() {
 		return res
 	}
 
-	if minTxFee, success := sanityCheckForFee(tx.Fee, blockHeight); !success {
+	if minTxFee, success := sanityCheckForFee(chainID, view, tx.Fee, blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
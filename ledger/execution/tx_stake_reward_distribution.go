@@ -19,6 +19,8 @@ var _ TxExecutor = (*StakeRewardDistributionTxExecutor)(nil)
 // StakeRewardDistributionTxExecutor implements the TxExecutor interface
 type StakeRewardDistributionTxExecutor struct {
 	state *st.LedgerState
+
+	skipSignatureCheck bool // only used for simulations
 }
 
 // NewStakeRewardDistributionTxExecutor creates a new instance of StakeRewardDistributionTxExecutor
@@ -46,7 +48,7 @@ func (exec *StakeRewardDistributionTxExecutor) sanityCheck(chainID string, view
 
 	// Validate inputs and outputs, advanced
 	signBytes := tx.SignBytes(chainID)
-	res = validateInputAdvanced(stakeHolderAccount, signBytes, tx.Holder, blockHeight)
+	res = validateInputAdvanced(chainID, stakeHolderAccount, signBytes, tx.Holder, blockHeight, exec.skipSignatureCheck)
 	if res.IsError() {
 		return res
 	}
@@ -99,7 +101,7 @@ func (exec *StakeRewardDistributionTxExecutor) sanityCheck(chainID string, view
 	// 	return result.Error("Invalid purpose: %v", tx.Purpose)
 	// }
 
-	if minTxFee, success := sanityCheckForFee(tx.Fee, blockHeight); !success {
+	if minTxFee, success := sanityCheckForFee(chainID, view, tx.Fee, blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
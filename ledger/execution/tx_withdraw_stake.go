@@ -19,6 +19,8 @@ var _ TxExecutor = (*WithdrawStakeExecutor)(nil)
 // WithdrawStakeExecutor implements the TxExecutor interface
 type WithdrawStakeExecutor struct {
 	state *st.LedgerState
+
+	skipSignatureCheck bool // only used for simulations
 }
 
 // NewWithdrawStakeExecutor creates a new instance of WithdrawStakeExecutor
@@ -30,7 +32,13 @@ func NewWithdrawStakeExecutor(state *st.LedgerState) *WithdrawStakeExecutor {
 
 func (exec *WithdrawStakeExecutor) sanityCheck(chainID string, view *st.StoreView, viewSel core.ViewSelector, transaction types.Tx) result.Result {
 	blockHeight := view.Height() + 1 // the view points to the parent of the current block
-	tx := transaction.(*types.WithdrawStakeTx)
+	_, isPartial := transaction.(*types.WithdrawStakeTxV2)
+	enableHeight := common.GetForkSchedule(chainID).EnablePartialStakeWithdrawal
+	if isPartial && blockHeight < enableHeight {
+		return result.Error("Partial stake withdrawal not enabled yet, please wait until block height %v", enableHeight)
+	}
+
+	tx := exec.castTx(transaction)
 
 	res := tx.Source.ValidateBasic()
 	if res.IsError() {
@@ -42,14 +50,14 @@ func (exec *WithdrawStakeExecutor) sanityCheck(chainID string, view *st.StoreVie
 		return result.Error("Failed to get the source account: %v", tx.Source.Address)
 	}
 
-	signBytes := tx.SignBytes(chainID)
-	res = validateInputAdvanced(sourceAccount, signBytes, tx.Source, blockHeight)
+	signBytes := transaction.SignBytes(chainID)
+	res = validateInputAdvanced(chainID, sourceAccount, signBytes, tx.Source, blockHeight, exec.skipSignatureCheck)
 	if res.IsError() {
 		logger.Debugf(fmt.Sprintf("validateSourceAdvanced failed on %v: %v", tx.Source.Address.Hex(), res))
 		return res
 	}
 
-	if minTxFee, success := sanityCheckForFee(tx.Fee, blockHeight); !success {
+	if minTxFee, success := sanityCheckForFee(chainID, view, tx.Fee, blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
@@ -59,6 +67,23 @@ func (exec *WithdrawStakeExecutor) sanityCheck(chainID string, view *st.StoreVie
 			WithErrorCode(result.CodeInvalidStakePurpose)
 	}
 
+	if isPartial {
+		amount := tx.Amount.NoNil()
+		if !amount.IsValid() || !amount.IsNonnegative() {
+			return result.Error("Invalid amount for stake withdrawal!").
+				WithErrorCode(result.CodeInvalidStake)
+		}
+		if tx.Purpose == core.StakeForEliteEdgeNode {
+			if amount.ThetaWei.Sign() != 0 || amount.TFuelWei.Sign() <= 0 {
+				return result.Error("Only a positive amount of TFuel can be withdrawn from elite edge nodes!").
+					WithErrorCode(result.CodeInvalidStake)
+			}
+		} else if amount.TFuelWei.Sign() != 0 || amount.ThetaWei.Sign() <= 0 {
+			return result.Error("Only a positive amount of Theta can be withdrawn from validators or guardians!").
+				WithErrorCode(result.CodeInvalidStake)
+		}
+	}
+
 	minimalBalance := tx.Fee
 	if !sourceAccount.Balance.IsGTE(minimalBalance) {
 		logger.Infof(fmt.Sprintf("WithdrawStake: Source did not have enough balance %v", tx.Source.Address.Hex()))
@@ -73,7 +98,8 @@ func (exec *WithdrawStakeExecutor) sanityCheck(chainID string, view *st.StoreVie
 //       the ReturnHeight of the withdrawn stake. The stake will be returned to the source when
 //       the block height reaches the ReturnHeigth
 func (exec *WithdrawStakeExecutor) process(chainID string, view *st.StoreView, viewSel core.ViewSelector, transaction types.Tx) (common.Hash, result.Result) {
-	tx := transaction.(*types.WithdrawStakeTx)
+	_, isPartial := transaction.(*types.WithdrawStakeTxV2)
+	tx := exec.castTx(transaction)
 
 	sourceAccount, success := getInput(view, tx.Source)
 	if success.IsError() {
@@ -86,11 +112,19 @@ func (exec *WithdrawStakeExecutor) process(chainID string, view *st.StoreView, v
 
 	sourceAddress := tx.Source.Address
 	holderAddress := tx.Holder.Address
+	amount := tx.Amount.NoNil()
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
 
 	if tx.Purpose == core.StakeForValidator {
 		vcp := view.GetValidatorCandidatePool()
 		currentHeight := exec.state.Height()
-		err := vcp.WithdrawStake(sourceAddress, holderAddress, currentHeight)
+		var err error
+		if isPartial {
+			minValidatorStake := view.GetProtocolParam(chainID, types.ParamMinimumValidatorStake, blockHeight)
+			err = vcp.WithdrawPartialStake(sourceAddress, holderAddress, amount.ThetaWei, minValidatorStake, currentHeight)
+		} else {
+			err = vcp.WithdrawStake(sourceAddress, holderAddress, currentHeight)
+		}
 		if err != nil {
 			return common.Hash{}, result.Error("Failed to withdraw stake, err: %v", err)
 		}
@@ -98,7 +132,13 @@ func (exec *WithdrawStakeExecutor) process(chainID string, view *st.StoreView, v
 	} else if tx.Purpose == core.StakeForGuardian {
 		gcp := view.GetGuardianCandidatePool()
 		currentHeight := exec.state.Height()
-		err := gcp.WithdrawStake(sourceAddress, holderAddress, currentHeight)
+		var err error
+		if isPartial {
+			minGuardianStake := view.GetProtocolParam(chainID, types.ParamMinimumGuardianStake, blockHeight)
+			err = gcp.WithdrawPartialStake(sourceAddress, holderAddress, amount.ThetaWei, minGuardianStake, currentHeight)
+		} else {
+			err = gcp.WithdrawStake(sourceAddress, holderAddress, currentHeight)
+		}
 		if err != nil {
 			return common.Hash{}, result.Error("Failed to withdraw stake, err: %v", err)
 		}
@@ -106,7 +146,14 @@ func (exec *WithdrawStakeExecutor) process(chainID string, view *st.StoreView, v
 	} else if tx.Purpose == core.StakeForEliteEdgeNode {
 		eenp := state.NewEliteEdgeNodePool(view, false)
 		currentHeight := exec.state.Height()
-		withdrawnStake, err := eenp.WithdrawStake(sourceAddress, holderAddress, currentHeight)
+		var withdrawnStake *core.Stake
+		var err error
+		if isPartial {
+			minEliteEdgeNodeStake := view.GetProtocolParam(chainID, types.ParamMinimumEliteEdgeNodeStake, blockHeight)
+			withdrawnStake, err = eenp.WithdrawPartialStake(sourceAddress, holderAddress, amount.TFuelWei, minEliteEdgeNodeStake, currentHeight)
+		} else {
+			withdrawnStake, err = eenp.WithdrawStake(sourceAddress, holderAddress, currentHeight)
+		}
 		if err != nil || withdrawnStake == nil {
 			return common.Hash{}, result.Error("Failed to withdraw stake, err: %v", err)
 		}
@@ -121,7 +168,6 @@ func (exec *WithdrawStakeExecutor) process(chainID string, view *st.StoreView, v
 		if hl == nil {
 			hl = &types.HeightList{}
 		}
-		blockHeight := view.Height() + 1 // the view points to the parent of the current block
 		hl.Append(blockHeight)
 		view.UpdateStakeTransactionHeightList(hl)
 	}
@@ -129,12 +175,12 @@ func (exec *WithdrawStakeExecutor) process(chainID string, view *st.StoreView, v
 	sourceAccount.Sequence++
 	view.SetAccount(sourceAddress, sourceAccount)
 
-	txHash := types.TxID(chainID, tx)
+	txHash := types.TxID(chainID, transaction)
 	return txHash, result.OK
 }
 
 func (exec *WithdrawStakeExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
-	tx := transaction.(*types.WithdrawStakeTx)
+	tx := exec.castTx(transaction)
 	return &core.TxInfo{
 		Address:           tx.Source.Address,
 		Sequence:          tx.Source.Sequence,
@@ -143,13 +189,32 @@ func (exec *WithdrawStakeExecutor) getTxInfo(transaction types.Tx) *core.TxInfo
 }
 
 func (exec *WithdrawStakeExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
-	tx := transaction.(*types.WithdrawStakeTx)
+	tx := exec.castTx(transaction)
 	fee := tx.Fee
 	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
 	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
 	return effectiveGasPrice
 }
 
+// castTx returns the partial withdrawal form of the transaction. The amount is left empty for
+// a WithdrawStakeTx, which withdraws the whole stake.
+func (exec *WithdrawStakeExecutor) castTx(transaction types.Tx) *types.WithdrawStakeTxV2 {
+	if tx, ok := transaction.(*types.WithdrawStakeTxV2); ok {
+		return tx
+	}
+	if tx, ok := transaction.(*types.WithdrawStakeTx); ok {
+		return &types.WithdrawStakeTxV2{
+			Fee:     tx.Fee,
+			Source:  tx.Source,
+			Holder:  tx.Holder,
+			Purpose: tx.Purpose,
+
+			TxValidityWindow: tx.TxValidityWindow,
+		}
+	}
+	panic("Unreachable code")
+}
+
 func updateEliteEdgeNodeStakeReturns(view *st.StoreView, eenAddress common.Address, withdrawnStake core.Stake) {
 	returnHeight := withdrawnStake.ReturnHeight
 	stakesToBeReturned := view.GetEliteEdgeNodeStakeReturns(returnHeight)
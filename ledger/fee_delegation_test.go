@@ -0,0 +1,209 @@
+package ledger
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common/result"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func setTestAccount(view *st.StoreView, acc types.PrivAccount, balance types.Coins) {
+	account := types.NewAccount(acc.Address)
+	account.Balance = balance
+	view.SetAccount(acc.Address, account)
+}
+
+func signFeePayer(chainID string, tx types.TxWithFeeDelegation, feePayer types.PrivAccount) {
+	sig, err := feePayer.PrivKey.Sign(types.FeePayerSignBytes(chainID, tx))
+	if err != nil {
+		panic(err)
+	}
+	tx.GetFeeDelegation().SetFeePayerSignature(sig)
+}
+
+func newSponsoredSendTx(chainID string, sender, recipient, feePayer types.PrivAccount) *types.SendTx {
+	fee := types.NewCoins(0, 0)
+	fee.TFuelWei = types.GetSendTxMinimumTransactionFeeTFuelWei(chainID, 3, testForks.EnableFeeDelegation)
+	tx := &types.SendTx{
+		Fee: fee,
+		Inputs: []types.TxInput{{
+			Address:  sender.Address,
+			Coins:    types.NewCoins(100, 0),
+			Sequence: 1,
+		}},
+		Outputs: []types.TxOutput{{
+			Address: recipient.Address,
+			Coins:   types.NewCoins(100, 0),
+		}},
+	}
+	tx.SetFeePayer(feePayer.Address)
+	sig, err := sender.PrivKey.Sign(tx.SignBytes(chainID))
+	if err != nil {
+		panic(err)
+	}
+	tx.SetSignature(sender.Address, sig)
+	return tx
+}
+
+func TestFeeDelegationSendTx(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	resetTestLedgerToHeight(ledger, testForks.EnableFeeDelegation-1)
+
+	sender := types.MakeAcc("fee_delegation_sender")
+	recipient := types.MakeAcc("fee_delegation_recipient")
+	feePayer := types.MakeAcc("fee_delegation_payer")
+	setTestAccount(ledger.state.Delivered(), sender, types.NewCoins(1000, 0))
+	setTestAccount(ledger.state.Delivered(), feePayer, types.NewCoins(0, 1e18))
+
+	// Not signed by the fee payer
+	tx := newSponsoredSendTx(chainID, sender, recipient, feePayer)
+	_, res := ledger.executor.ExecuteTx(tx)
+	assert.Equal(result.CodeInvalidSignature, res.Code)
+
+	// The fee payer is covered by the signature of the sender
+	tx = newSponsoredSendTx(chainID, sender, recipient, feePayer)
+	tx.SetFeePayer(recipient.Address)
+	signFeePayer(chainID, tx, recipient)
+	_, res = ledger.executor.ExecuteTx(tx)
+	assert.Equal(result.CodeInvalidSignature, res.Code)
+
+	tx = newSponsoredSendTx(chainID, sender, recipient, feePayer)
+	signFeePayer(chainID, tx, feePayer)
+
+	// Round trip through the versioned tx encoding
+	raw, err := types.TxToBytes(tx)
+	require.Nil(err)
+	decoded, err := types.TxFromBytes(raw)
+	require.Nil(err)
+
+	_, res = ledger.executor.ExecuteTx(decoded)
+	require.True(res.IsOK(), res.Message)
+
+	view := ledger.state.Delivered()
+	senderAccount := view.GetAccount(sender.Address)
+	assert.Equal(uint64(1), senderAccount.Sequence)
+	assert.Equal(types.NewCoins(900, 0), senderAccount.Balance)
+	assert.Equal(big.NewInt(100), view.GetAccount(recipient.Address).Balance.ThetaWei)
+
+	expectedBalance := new(big.Int).Sub(big.NewInt(1e18), tx.Fee.TFuelWei)
+	feePayerAccount := view.GetAccount(feePayer.Address)
+	assert.Equal(expectedBalance, feePayerAccount.Balance.TFuelWei)
+	assert.Equal(uint64(0), feePayerAccount.Sequence)
+}
+
+func TestFeeDelegationInvalidFeePayer(t *testing.T) {
+	assert := assert.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	resetTestLedgerToHeight(ledger, testForks.EnableFeeDelegation-1)
+
+	sender := types.MakeAcc("fee_delegation_sender")
+	recipient := types.MakeAcc("fee_delegation_recipient")
+	feePayer := types.MakeAcc("fee_delegation_payer")
+	setTestAccount(ledger.state.Delivered(), sender, types.NewCoins(1000, 1e18))
+	setTestAccount(ledger.state.Delivered(), feePayer, types.NewCoins(0, 1))
+
+	// Insufficient balance of the fee payer
+	tx := newSponsoredSendTx(chainID, sender, recipient, feePayer)
+	signFeePayer(chainID, tx, feePayer)
+	_, res := ledger.executor.ExecuteTx(tx)
+	assert.Equal(result.CodeInsufficientFund, res.Code)
+
+	// The sender cannot be the fee payer
+	tx = newSponsoredSendTx(chainID, sender, recipient, sender)
+	signFeePayer(chainID, tx, sender)
+	_, res = ledger.executor.ExecuteTx(tx)
+	assert.True(res.IsError())
+
+	// Not active yet
+	resetTestLedgerToHeight(ledger, testForks.EnableFeeDelegation-2)
+	setTestAccount(ledger.state.Delivered(), sender, types.NewCoins(1000, 1e18))
+	setTestAccount(ledger.state.Delivered(), feePayer, types.NewCoins(0, 1e18))
+	tx = newSponsoredSendTx(chainID, sender, recipient, feePayer)
+	signFeePayer(chainID, tx, feePayer)
+	_, res = ledger.executor.ExecuteTx(tx)
+	assert.True(res.IsError())
+}
+
+func TestFeeDelegationSmartContractTx(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	resetTestLedgerToHeight(ledger, testForks.EnableFeeDelegation-1)
+
+	sender := types.MakeAcc("fee_delegation_sender")
+	recipient := types.MakeAcc("fee_delegation_recipient")
+	feePayer := types.MakeAcc("fee_delegation_payer")
+	setTestAccount(ledger.state.Checked(), sender, types.NewCoins(0, 1000))
+	setTestAccount(ledger.state.Checked(), feePayer, types.NewCoins(0, 1e18))
+
+	gasPrice := types.GetMinimumGasPrice(chainID, testForks.EnableFeeDelegation)
+	tx := &types.SmartContractTx{
+		From: types.TxInput{
+			Address:  sender.Address,
+			Coins:    types.NewCoins(0, 1000),
+			Sequence: 1,
+		},
+		To:       types.TxOutput{Address: recipient.Address},
+		GasLimit: 100000,
+		GasPrice: gasPrice,
+	}
+	tx.SetFeePayer(feePayer.Address)
+	sig, err := sender.PrivKey.Sign(tx.SignBytes(chainID))
+	require.Nil(err)
+	tx.SetSignature(sender.Address, sig)
+	signFeePayer(chainID, tx, feePayer)
+
+	_, res := ledger.executor.CheckTx(tx)
+	require.True(res.IsOK(), res.Message)
+
+	view := ledger.state.Checked()
+	gasUsed := ledger.executor.LastSmartContractGasUsed()
+	assert.True(gasUsed > 0)
+	fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasUsed))
+	assert.Equal(new(big.Int).Sub(big.NewInt(1e18), fee), view.GetAccount(feePayer.Address).Balance.TFuelWei)
+	assert.Equal(big.NewInt(0), view.GetAccount(sender.Address).Balance.TFuelWei)
+	assert.Equal(uint64(1), view.GetAccount(sender.Address).Sequence)
+	assert.Equal(big.NewInt(1000), view.GetAccount(recipient.Address).Balance.TFuelWei)
+}
+
+func TestFeeDelegationEthSignature(t *testing.T) {
+	assert := assert.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	blockHeight := testForks.EnableFeeDelegation + 100
+	resetTestLedgerToHeight(ledger, blockHeight-1)
+
+	sender := types.MakeAcc("fee_delegation_sender")
+	recipient := types.MakeAcc("fee_delegation_recipient")
+	feePayer := types.MakeAcc("fee_delegation_payer")
+	setTestAccount(ledger.state.Checked(), sender, types.NewCoins(0, 1000))
+	setTestAccount(ledger.state.Checked(), feePayer, types.NewCoins(0, 1e18))
+
+	// The ETH signature of the sender does not cover the fee payer, so the fee payer cannot be set
+	tx := &types.SmartContractTx{
+		From: types.TxInput{
+			Address:  sender.Address,
+			Coins:    types.NewCoins(0, 1000),
+			Sequence: 1,
+		},
+		To:       types.TxOutput{Address: recipient.Address},
+		GasLimit: 100000,
+		GasPrice: types.GetMinimumGasPrice(chainID, blockHeight),
+	}
+	tx.SetFeePayer(feePayer.Address)
+	ethSign(chainID, tx, sender, blockHeight)
+	signFeePayer(chainID, tx, feePayer)
+
+	_, res := ledger.executor.CheckTx(tx)
+	assert.Equal(result.CodeInvalidSignature, res.Code)
+	assert.Equal(big.NewInt(1e18), ledger.state.Checked().GetAccount(feePayer.Address).Balance.TFuelWei)
+}
@@ -0,0 +1,159 @@
+package ledger
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto/bls"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func newGovernanceTxFee(chainID string) types.Coins {
+	return types.Coins{
+		ThetaWei: big.NewInt(0),
+		TFuelWei: types.GetMinimumTransactionFeeTFuelWei(chainID, testForks.EnableGovernance),
+	}
+}
+
+func newGovernanceProposalTx(chainID string, proposer types.PrivAccount, sequence uint64, changes []types.ParamChange) *types.GovernanceProposalTx {
+	tx := &types.GovernanceProposalTx{
+		Fee:      newGovernanceTxFee(chainID),
+		Proposer: types.TxInput{Address: proposer.Address, Sequence: sequence},
+		Changes:  changes,
+	}
+	tx.Proposer.Signature = proposer.Sign(tx.SignBytes(chainID))
+	return tx
+}
+
+func newGovernanceVoteTx(chainID string, voter types.PrivAccount, sequence uint64, proposalID uint64, approve bool) *types.GovernanceVoteTx {
+	tx := &types.GovernanceVoteTx{
+		Fee:        newGovernanceTxFee(chainID),
+		Voter:      types.TxInput{Address: voter.Address, Sequence: sequence},
+		ProposalID: proposalID,
+		Approve:    approve,
+	}
+	tx.Voter.Signature = voter.Sign(tx.SignBytes(chainID))
+	return tx
+}
+
+func newGovernanceExecuteTx(chainID string, source types.PrivAccount, sequence uint64, proposalID uint64) *types.GovernanceExecuteTx {
+	tx := &types.GovernanceExecuteTx{
+		Fee:        newGovernanceTxFee(chainID),
+		Source:     types.TxInput{Address: source.Address, Sequence: sequence},
+		ProposalID: proposalID,
+	}
+	tx.Source.Signature = source.Sign(tx.SignBytes(chainID))
+	return tx
+}
+
+func TestGovernanceParamChange(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	resetTestLedgerToHeight(ledger, testForks.EnableGovernance-1)
+	view := ledger.state.Checked()
+	blockHeight := testForks.EnableGovernance
+
+	guardian1 := types.MakeAcc("governance_guardian1")
+	guardian2 := types.MakeAcc("governance_guardian2")
+	outsider := types.MakeAcc("governance_outsider")
+	setTestAccount(view, guardian1, types.NewCoins(0, 1e18))
+	setTestAccount(view, guardian2, types.NewCoins(0, 1e18))
+	setTestAccount(view, outsider, types.NewCoins(0, 1e18))
+
+	// guardian1 holds 3/4 of the stake
+	minStake := core.MinGuardianStakeDeposit1000
+	blsPriv1, _ := bls.RandKey()
+	blsPriv2, _ := bls.RandKey()
+	gcp := core.NewGuardianCandidatePool()
+	require.Nil(gcp.DepositStake(chainID, guardian1.Address, guardian1.Address, new(big.Int).Mul(minStake, big.NewInt(3)), blsPriv1.PublicKey(), blockHeight))
+	require.Nil(gcp.DepositStake(chainID, guardian2.Address, guardian2.Address, minStake, blsPriv2.PublicKey(), blockHeight))
+	view.UpdateGuardianCandidatePool(gcp)
+	view.UpdateValidatorCandidatePool(&core.ValidatorCandidatePool{})
+
+	minGasPrice := new(big.Int).Mul(types.GetMinimumGasPrice(chainID, blockHeight), big.NewInt(2))
+	changes := []types.ParamChange{{Name: types.ParamMinimumGasPrice, Value: minGasPrice}}
+
+	// Only stake holders can submit proposals
+	_, res := ledger.executor.CheckTx(newGovernanceProposalTx(chainID, outsider, 1, changes))
+	assert.Equal(result.CodeNoGovernanceVotingPower, res.Code)
+
+	// The parameters need to be governable and within their ranges
+	_, res = ledger.executor.CheckTx(newGovernanceProposalTx(chainID, guardian2, 1, []types.ParamChange{{Name: "checkpoint_interval", Value: big.NewInt(50)}}))
+	assert.Equal(result.CodeInvalidGovernanceProposal, res.Code)
+	_, res = ledger.executor.CheckTx(newGovernanceProposalTx(chainID, guardian2, 1, []types.ParamChange{{Name: types.ParamMinimumGuardianStake, Value: big.NewInt(1)}}))
+	assert.Equal(result.CodeInvalidGovernanceProposal, res.Code)
+
+	_, res = ledger.executor.CheckTx(newGovernanceProposalTx(chainID, guardian2, 1, changes))
+	require.True(res.IsOK(), res.Message)
+	proposal := view.GetGovernanceProposal(0)
+	require.NotNil(proposal)
+	assert.Equal(guardian2.Address, proposal.Proposer)
+	assert.Equal(blockHeight+types.GovernanceVotingPeriod, proposal.EndHeight)
+	assert.Equal(uint64(1), view.GetGovernanceProposalCount())
+
+	// The votes of 1/4 of the stake are not enough
+	_, res = ledger.executor.CheckTx(newGovernanceVoteTx(chainID, guardian2, 2, 0, true))
+	require.True(res.IsOK(), res.Message)
+	_, res = ledger.executor.CheckTx(newGovernanceExecuteTx(chainID, outsider, 1, 0))
+	assert.Equal(result.CodeGovernanceProposalRejected, res.Code)
+
+	_, res = ledger.executor.CheckTx(newGovernanceVoteTx(chainID, outsider, 1, 0, true))
+	assert.Equal(result.CodeNoGovernanceVotingPower, res.Code)
+
+	// A later vote replaces the earlier one of the same voter
+	_, res = ledger.executor.CheckTx(newGovernanceVoteTx(chainID, guardian1, 1, 0, false))
+	require.True(res.IsOK(), res.Message)
+	_, res = ledger.executor.CheckTx(newGovernanceExecuteTx(chainID, outsider, 1, 0))
+	assert.Equal(result.CodeGovernanceProposalRejected, res.Code)
+	_, res = ledger.executor.CheckTx(newGovernanceVoteTx(chainID, guardian1, 2, 0, true))
+	require.True(res.IsOK(), res.Message)
+	assert.Equal(2, len(view.GetGovernanceProposal(0).Votes))
+
+	assert.Equal(types.GetMinimumGasPrice(chainID, blockHeight), view.GetProtocolParam(chainID, types.ParamMinimumGasPrice, blockHeight))
+	_, res = ledger.executor.CheckTx(newGovernanceExecuteTx(chainID, outsider, 1, 0))
+	require.True(res.IsOK(), res.Message)
+	assert.Equal(minGasPrice, view.GetProtocolParam(chainID, types.ParamMinimumGasPrice, blockHeight))
+	assert.Equal(types.GetMaxGasLimit(chainID, blockHeight), view.GetProtocolParam(chainID, types.ParamMaximumTxGasLimit, blockHeight))
+	assert.True(view.GetGovernanceProposal(0).Executed)
+
+	// A proposal can only be executed once
+	_, res = ledger.executor.CheckTx(newGovernanceExecuteTx(chainID, outsider, 2, 0))
+	assert.Equal(result.CodeGovernanceVotingClosed, res.Code)
+	_, res = ledger.executor.CheckTx(newGovernanceVoteTx(chainID, guardian1, 3, 1, true))
+	assert.Equal(result.CodeGovernanceProposalNotFound, res.Code)
+}
+
+func TestGovernanceMinimumTransactionFee(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	resetTestLedgerToHeight(ledger, testForks.EnableGovernance-1)
+	view := ledger.state.Checked()
+
+	guardian := types.MakeAcc("governance_guardian")
+	setTestAccount(view, guardian, types.NewCoins(0, 1e18))
+	blsPriv, _ := bls.RandKey()
+	gcp := core.NewGuardianCandidatePool()
+	require.Nil(gcp.DepositStake(chainID, guardian.Address, guardian.Address, core.MinGuardianStakeDeposit1000, blsPriv.PublicKey(), testForks.EnableGovernance))
+	view.UpdateGuardianCandidatePool(gcp)
+
+	// Raise the minimum transaction fee above the default one
+	minTxFee := new(big.Int).Mul(types.GetMinimumTransactionFeeTFuelWei(chainID, testForks.EnableGovernance), big.NewInt(2))
+	view.SetGovernanceParam(types.ParamMinimumTransactionFeeTFuelWei, minTxFee)
+
+	tx := newGovernanceVoteTx(chainID, guardian, 1, 0, true)
+	_, res := ledger.executor.CheckTx(tx)
+	assert.Equal(result.CodeInvalidFee, res.Code)
+
+	tx.Fee.TFuelWei = minTxFee
+	tx.Voter.Signature = guardian.Sign(tx.SignBytes(chainID))
+	_, res = ledger.executor.CheckTx(tx)
+	assert.Equal(result.CodeGovernanceProposalNotFound, res.Code)
+}
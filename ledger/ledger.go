@@ -1,8 +1,10 @@
 package ledger
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -44,6 +46,12 @@ type Ledger struct {
 	mu       *sync.RWMutex // Lock for accessing ledger state.
 	state    *st.LedgerState
 	executor *exec.Executor
+
+	parallelTxExecution  bool // whether ApplyBlockTxs executes the block txs in parallel
+	numParallelTxWorkers int
+
+	evidenceMu *sync.Mutex // Lock for accessing the pending equivocation evidences.
+	evidences  map[common.Hash]*core.EquivocationEvidence
 }
 
 // NewLedger creates an instance of Ledger
@@ -57,6 +65,12 @@ func NewLedger(chainID string, db database.Database, tagger st.Tagger, chain *bl
 		mempool:   mempool,
 		mu:        &sync.RWMutex{},
 		state:     state,
+
+		parallelTxExecution:  viper.GetBool(common.CfgLedgerParallelTxExecutionEnabled),
+		numParallelTxWorkers: viper.GetInt(common.CfgLedgerParallelTxExecutionWorkers),
+
+		evidenceMu: &sync.Mutex{},
+		evidences:  make(map[common.Hash]*core.EquivocationEvidence),
 	}
 	executor := exec.NewExecutor(db, chain, state, consensus, valMgr, ledger)
 	ledger.SetExecutor(executor)
@@ -94,6 +108,19 @@ func (ledger *Ledger) GetDeliveredSnapshot() (*st.StoreView, error) {
 	return ledger.state.Delivered().Copy()
 }
 
+// GetDeliveredSnapshotWithParentBlock returns a snapshot of delivered ledger state along with the
+// block the state is on top of, read under the same lock so that they are consistent
+func (ledger *Ledger) GetDeliveredSnapshotWithParentBlock() (*core.Block, *st.StoreView, error) {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+
+	view, err := ledger.state.Delivered().Copy()
+	if err != nil {
+		return nil, nil, err
+	}
+	return ledger.state.ParentBlock(), view, nil
+}
+
 // GetFinalizedSnapshot returns a snapshot of finalized ledger state to query about accounts, etc.
 func (ledger *Ledger) GetFinalizedSnapshot() (*st.StoreView, error) {
 	ledger.mu.Lock()
@@ -173,6 +200,28 @@ func (ledger *Ledger) GetGuardianCandidatePool(blockHash common.Hash) (*core.Gua
 	}
 }
 
+// ProveValidatorCandidatePool returns the merkle proof of the validator candidate pool against the
+// state root of the given block, with which the light clients verify the validator set.
+func (ledger *Ledger) ProveValidatorCandidatePool(blockHash common.Hash) (*core.VCPProof, error) {
+	db := ledger.state.DB()
+	store := kvstore.NewKVStore(db)
+
+	block, err := findBlock(store, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	storeView := st.NewStoreView(block.Height, block.StateHash, db)
+	if storeView == nil {
+		return nil, fmt.Errorf("the state of block %v is not available", blockHash.Hex())
+	}
+
+	vp := &core.VCPProof{}
+	if err := storeView.ProveVCP(st.ValidatorCandidatePoolKey(), vp); err != nil {
+		return nil, err
+	}
+	return vp, nil
+}
+
 // GetEliteEdgeNodePoolOfLastCheckpoint returns the elite edge node pool of the given block.
 func (ledger *Ledger) GetEliteEdgeNodePoolOfLastCheckpoint(blockHash common.Hash) (core.EliteEdgeNodePool, error) {
 	db := ledger.state.DB()
@@ -270,6 +319,10 @@ func (ledger *Ledger) ProposeBlockTxs(block *core.Block, shouldIncludeValidatorU
 
 	view := ledger.state.Checked()
 
+	if block.Height >= common.GetForkSchedule(block.ChainID).EnableDynamicBaseFee {
+		block.BaseFee = types.CalculateBaseFee(ledger.state.ParentBlock().BlockHeader)
+	}
+
 	logger.Debugf("ProposeBlockTxs: Start adding block transactions, block.height = %v", block.Height)
 	preparationTime := time.Since(start)
 	start = time.Now()
@@ -289,6 +342,7 @@ func (ledger *Ledger) ProposeBlockTxs(block *core.Block, shouldIncludeValidatorU
 	start = time.Now()
 
 	blockRawTxs = []common.Bytes{}
+	blockGasUsed := uint64(0)
 	for _, rawTxCandidate := range rawTxCandidates {
 		tx, err := types.TxFromBytes(rawTxCandidate)
 		if err != nil {
@@ -303,6 +357,12 @@ func (ledger *Ledger) ProposeBlockTxs(block *core.Block, shouldIncludeValidatorU
 			if _, ok := tx.(*types.WithdrawStakeTx); ok {
 				continue
 			}
+			if _, ok := tx.(*types.WithdrawStakeTxV2); ok {
+				continue
+			}
+			if _, ok := tx.(*types.EvidenceTx); ok {
+				continue
+			}
 		}
 
 		_, res := ledger.executor.CheckTx(tx)
@@ -311,6 +371,11 @@ func (ledger *Ledger) ProposeBlockTxs(block *core.Block, shouldIncludeValidatorU
 			continue
 		}
 		blockRawTxs = append(blockRawTxs, rawTxCandidate)
+		blockGasUsed += exec.GetGasUsed(res)
+	}
+
+	if block.Height >= common.GetForkSchedule(block.ChainID).EnableDynamicBaseFee {
+		block.GasUsed = blockGasUsed
 	}
 
 	logger.Debugf("ProposeBlockTxs: block transactions executed, block.height = %v", block.Height)
@@ -356,34 +421,71 @@ func (ledger *Ledger) ApplyBlockTxs(block *core.Block) result.Result {
 		panic(fmt.Sprintf("Failed to find the parent block: %v, err: %v", block.Parent.Hex(), err))
 	}
 	parentBlock := extParentBlock.Block
+
+	if block.Height >= common.GetForkSchedule(block.ChainID).EnableDynamicBaseFee {
+		expectedBaseFee := types.CalculateBaseFee(parentBlock.BlockHeader)
+		if block.BaseFee == nil || block.BaseFee.Cmp(expectedBaseFee) != 0 {
+			ledger.resetState(parentBlock)
+			return result.Error("Base fee mismatch! base fee: %v, expected: %v", block.BaseFee, expectedBaseFee)
+		}
+	}
+
 	logger.Debugf("ApplyBlockTxs: Start applying block transactions, block.height = %v", block.Height)
 
 	hasValidatorUpdate := false
+	blockGasUsed := uint64(0)
 	txProcessTime := []time.Duration{}
-	for _, rawTx := range blockRawTxs {
+	if ledger.parallelTxExecution {
 		start := time.Now()
-		tx, err := types.TxFromBytes(rawTx)
+		txs, err := parseBlockTxs(blockRawTxs)
 		if err != nil {
-			//ledger.resetState(currHeight, currStateRoot)
 			ledger.resetState(parentBlock)
-			return result.Error("Failed to parse transaction: %v", hex.EncodeToString(rawTx))
+			return result.Error("%v", err)
 		}
-		if dtx, ok := tx.(*types.DepositStakeTx); ok && dtx.Purpose == core.StakeForValidator {
-			hasValidatorUpdate = true
-		} else if wtx, ok := tx.(*types.WithdrawStakeTx); ok && wtx.Purpose == core.StakeForValidator {
-			hasValidatorUpdate = true
+		for _, tx := range txs {
+			if txUpdatesValidators(tx) {
+				hasValidatorUpdate = true
+			}
 		}
-		_, res := ledger.executor.ExecuteTx(tx)
+		numReprocessed, res := executeTxsInParallel(ledger.executor, view, core.DeliveredView, txs, ledger.numParallelTxWorkers)
 		if res.IsError() {
-			//ledger.resetState(currHeight, currStateRoot)
 			ledger.resetState(parentBlock)
 			return res
 		}
+		blockGasUsed = exec.GetGasUsed(res)
 		txProcessTime = append(txProcessTime, time.Since(start))
+		logger.Debugf("ApplyBlockTxs: Executed block transactions in parallel, block.height = %v, numTxs = %v, numReprocessed = %v",
+			block.Height, len(txs), numReprocessed)
+	} else {
+		for _, rawTx := range blockRawTxs {
+			start := time.Now()
+			tx, err := types.TxFromBytes(rawTx)
+			if err != nil {
+				//ledger.resetState(currHeight, currStateRoot)
+				ledger.resetState(parentBlock)
+				return result.Error("Failed to parse transaction: %v", hex.EncodeToString(rawTx))
+			}
+			if txUpdatesValidators(tx) {
+				hasValidatorUpdate = true
+			}
+			_, res := ledger.executor.ExecuteTx(tx)
+			if res.IsError() {
+				//ledger.resetState(currHeight, currStateRoot)
+				ledger.resetState(parentBlock)
+				return res
+			}
+			blockGasUsed += exec.GetGasUsed(res)
+			txProcessTime = append(txProcessTime, time.Since(start))
+		}
 	}
 
 	logger.Debugf("ApplyBlockTxs: Finish applying block transactions, block.height=%v, txProcessTime=%v", block.Height, txProcessTime)
 
+	if block.Height >= common.GetForkSchedule(block.ChainID).EnableDynamicBaseFee && blockGasUsed != block.GasUsed {
+		ledger.resetState(parentBlock)
+		return result.Error("Gas used mismatch! gas used: %v, expected: %v", blockGasUsed, block.GasUsed)
+	}
+
 	start := time.Now()
 	ledger.handleDelayedStateUpdates(view)
 	handleDelayedUpdateTime := time.Since(start)
@@ -449,9 +551,7 @@ func (ledger *Ledger) ApplyBlockTxsForChainCorrection(block *core.Block) (common
 			ledger.resetState(parentBlock)
 			return common.Hash{}, result.Error("Failed to parse transaction: %v", hex.EncodeToString(rawTx))
 		}
-		if dtx, ok := tx.(*types.DepositStakeTx); ok && dtx.Purpose == core.StakeForValidator {
-			hasValidatorUpdate = true
-		} else if wtx, ok := tx.(*types.WithdrawStakeTx); ok && wtx.Purpose == core.StakeForValidator {
+		if txUpdatesValidators(tx) {
 			hasValidatorUpdate = true
 		}
 		_, res := ledger.executor.ExecuteTx(tx)
@@ -631,6 +731,23 @@ func (ledger *Ledger) resetState(block *core.Block) result.Result {
 	return result.OK
 }
 
+// txUpdatesValidators returns whether the transaction may change the validator set, i.e. whether it
+// changes a validator stake, or reports an equivocation of a validator
+func txUpdatesValidators(tx types.Tx) bool {
+	switch tx := tx.(type) {
+	case *types.DepositStakeTx:
+		return tx.Purpose == core.StakeForValidator
+	case *types.WithdrawStakeTx:
+		return tx.Purpose == core.StakeForValidator
+	case *types.WithdrawStakeTxV2:
+		return tx.Purpose == core.StakeForValidator
+	case *types.EvidenceTx:
+		return true
+	default:
+		return false
+	}
+}
+
 // CheckTx() should skip all the transactions that can only be initiated by the validators
 // i.e., if a regular user submits a coinbaseTx or slashTx, it should be skipped so it will not
 // get into the mempool
@@ -640,6 +757,8 @@ func (ledger *Ledger) shouldSkipCheckTx(tx types.Tx) bool {
 		return true
 	case *types.SlashTx:
 		return true
+	case *types.EvidenceTx:
+		return true
 	default:
 		return false
 	}
@@ -652,7 +771,7 @@ func (ledger *Ledger) handleDelayedStateUpdates(view *st.StoreView) {
 	ledger.handleGuardianStakeReturn(view)
 
 	blockHeight := view.Height() + 1
-	if blockHeight >= common.HeightEnableTheta3 {
+	if blockHeight >= common.GetForkSchedule(ledger.state.GetChainID()).EnableTheta3 {
 		ledger.handleEliteEdgeNodeStakeReturns(view)
 	}
 }
@@ -772,6 +891,9 @@ func (ledger *Ledger) addSpecialTransactions(block *core.Block, view *st.StoreVi
 
 	ledger.addCoinbaseTx(view, &proposer, validatorSet, rawTxs)
 	//ledger.addSlashTxs(view, &proposer, &validators, rawTxs)
+	if block.Height >= common.GetForkSchedule(block.ChainID).EnableEquivocationSlashing {
+		ledger.addEvidenceTxs(view, &proposer, block.Height, rawTxs)
+	}
 }
 
 // addCoinbaseTx adds a Coinbase transaction
@@ -788,11 +910,11 @@ func (ledger *Ledger) addCoinbaseTx(view *st.StoreView, proposer *core.Validator
 	guardianVotes := currentBlock.GuardianVotes
 	eliteEdgeNodeVotes := currentBlock.EliteEdgeNodeVotes
 
-	if guardianVotes != nil && ch >= common.HeightEnableTheta2 && common.IsCheckPointHeight(ch) {
+	if guardianVotes != nil && ch >= common.GetForkSchedule(currentBlock.ChainID).EnableTheta2 && common.IsCheckPointHeight(ch) {
 		guardianPool, eliteEdgeNodePool := exec.RetrievePools(ledger, ledger.chain, ledger.db, ch, guardianVotes, eliteEdgeNodeVotes)
-		accountRewardMap = exec.CalculateReward(ledger, view, validatorSet, guardianVotes, guardianPool, eliteEdgeNodeVotes, eliteEdgeNodePool)
-	} else { // for compatibility with lower versions (e.g. blockHeight < common.HeightEnableValidatorReward)
-		accountRewardMap = exec.CalculateReward(ledger, view, validatorSet, nil, nil, nil, nil)
+		accountRewardMap = exec.CalculateReward(currentBlock.ChainID, ledger, view, validatorSet, guardianVotes, guardianPool, eliteEdgeNodeVotes, eliteEdgeNodePool)
+	} else { // for compatibility with lower versions (e.g. blockHeight < EnableValidatorReward)
+		accountRewardMap = exec.CalculateReward(currentBlock.ChainID, ledger, view, validatorSet, nil, nil, nil, nil)
 	}
 
 	coinbaseTxOutputs := []types.TxOutput{}
@@ -861,11 +983,88 @@ func (ledger *Ledger) addSlashTxs(view *st.StoreView, proposer *core.Validator,
 	view.ClearSlashIntents()
 }
 
+// AddEquivocationEvidence adds the evidence of conflicting votes detected by the consensus
+// engine, which is included in the blocks proposed by the node until it is processed or expires
+func (ledger *Ledger) AddEquivocationEvidence(evidence *core.EquivocationEvidence) {
+	ledger.evidenceMu.Lock()
+	defer ledger.evidenceMu.Unlock()
+
+	ledger.evidences[evidence.Hash()] = evidence
+}
+
+// addEvidenceTxs adds Evidence transactions for the pending equivocation evidences, and drops
+// the ones which can no longer be processed. The evidences are selected by height then hash, so
+// the oldest ones are included first and the selection does not depend on the map iteration order
+func (ledger *Ledger) addEvidenceTxs(view *st.StoreView, proposer *core.Validator, blockHeight uint64, rawTxs *[]common.Bytes) {
+	ledger.evidenceMu.Lock()
+	defer ledger.evidenceMu.Unlock()
+
+	chainID := ledger.state.GetChainID()
+	proposerAddress := proposer.Address
+	offenders := make(map[common.Address]bool)
+	numEvidenceTxs := 0
+
+	hashes := make([]common.Hash, 0, len(ledger.evidences))
+	for hash := range ledger.evidences {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		heightI, heightJ := ledger.evidences[hashes[i]].Height(), ledger.evidences[hashes[j]].Height()
+		if heightI != heightJ {
+			return heightI < heightJ
+		}
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+
+	for _, hash := range hashes {
+		evidence := ledger.evidences[hash]
+		if evidence.Height()+core.MaxEquivocationEvidenceAge < blockHeight ||
+			view.IsValidatorJailed(evidence.Offender(), blockHeight) {
+			delete(ledger.evidences, hash)
+			continue
+		}
+		if numEvidenceTxs >= core.MaxNumEvidenceTxsPerBlock || offenders[evidence.Offender()] {
+			continue
+		}
+		if res := evidence.Validate(chainID); res.IsError() {
+			delete(ledger.evidences, hash)
+			continue
+		}
+
+		evidenceTx := &types.EvidenceTx{
+			Proposer: types.TxInput{
+				Address: proposerAddress,
+			},
+			Evidence: evidence,
+		}
+
+		signature, err := ledger.signTransaction(evidenceTx)
+		if err != nil {
+			logger.Errorf("Failed to add evidence transaction: %v", err)
+			continue
+		}
+		evidenceTx.SetSignature(proposerAddress, signature)
+		evidenceTxBytes, err := types.TxToBytes(evidenceTx)
+		if err != nil {
+			logger.Errorf("Failed to add evidence transaction: %v", err)
+			continue
+		}
+
+		*rawTxs = append(*rawTxs, evidenceTxBytes)
+		offenders[evidence.Offender()] = true
+		numEvidenceTxs++
+		logger.Debugf("Adding evidence transction: tx: %v, bytes: %v", evidenceTx, hex.EncodeToString(evidenceTxBytes))
+	}
+}
+
 // signTransaction signs the given transaction
 func (ledger *Ledger) signTransaction(tx types.Tx) (*crypto.Signature, error) {
 	chainID := ledger.state.GetChainID()
-	signBytes := tx.SignBytes(chainID)
-	signature, err := ledger.consensus.PrivateKey().Sign(signBytes)
+	rawTx, err := types.TxToBytes(tx)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := ledger.consensus.SignTx(chainID, rawTx)
 	if err != nil {
 		return nil, err
 	}
@@ -20,6 +20,25 @@ import (
 	"github.com/thetatoken/theta/store/database/backend"
 )
 
+// testForks is the fork schedule of the test chain created by newTestLedger. It schedules the
+// forks not activated on the mainnet yet, so that the tests can cross them.
+var testForks = func() common.ForkSchedule {
+	schedule := common.MainnetForkSchedule
+	schedule.EnableNativeMultisig = 40000000
+	schedule.EnableTxValidityWindow = 40000000
+	schedule.EnableFeeDelegation = 40000000
+	schedule.EnableDynamicBaseFee = 40000000
+	schedule.EnableStakeRedelegation = 40000000
+	schedule.EnablePartialStakeWithdrawal = 40000000
+	schedule.EnableGovernance = 40000000
+	schedule.EnableEquivocationSlashing = 40000000
+	return schedule
+}()
+
+func init() {
+	common.RegisterForkSchedule("test_chain_id", testForks)
+}
+
 func TestLedgerSetup(t *testing.T) {
 	assert := assert.New(t)
 
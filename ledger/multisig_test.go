@@ -0,0 +1,188 @@
+package ledger
+
+import (
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+type multisigTestAccount struct {
+	policy   *types.MultisigPolicy
+	privKeys []*crypto.PrivateKey
+}
+
+func newMultisigTestAccount(threshold uint64, numKeys int) *multisigTestAccount {
+	privKeys := []*crypto.PrivateKey{}
+	pubKeys := []*crypto.PublicKey{}
+	for i := 0; i < numKeys; i++ {
+		privKey, pubKey, _ := crypto.TEST_GenerateKeyPairWithSeed("multisig_" + strconv.Itoa(i))
+		privKeys = append(privKeys, privKey)
+		pubKeys = append(pubKeys, pubKey)
+	}
+	policy, err := types.NewMultisigPolicy(threshold, pubKeys)
+	if err != nil {
+		panic(err)
+	}
+	return &multisigTestAccount{policy: policy, privKeys: privKeys}
+}
+
+// sign returns the signature bundle of the given message, signed by the keys at the given indices
+func (acc *multisigTestAccount) sign(msg common.Bytes, signers ...int) *crypto.Signature {
+	multisig := types.NewMultisigSignature(acc.policy)
+	for _, idx := range signers {
+		sig, err := acc.privKeys[idx].Sign(msg)
+		if err != nil {
+			panic(err)
+		}
+		if err := multisig.AddSignature(acc.privKeys[idx].PublicKey(), sig); err != nil {
+			panic(err)
+		}
+	}
+	sig, err := multisig.ToSignature()
+	if err != nil {
+		panic(err)
+	}
+	return sig
+}
+
+func resetTestLedgerToHeight(ledger *Ledger, height uint64) {
+	ledger.ResetState(&core.Block{
+		BlockHeader: &core.BlockHeader{
+			ChainID: ledger.state.GetChainID(),
+			Height:  height,
+		},
+	})
+}
+
+func TestMultisigSendTx(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	resetTestLedgerToHeight(ledger, testForks.EnableNativeMultisig-1)
+
+	multisigAcc := newMultisigTestAccount(2, 3)
+	multisigAddr := multisigAcc.policy.Address()
+	multisigAccount := types.NewAccount(multisigAddr)
+	multisigAccount.Balance = types.NewCoins(1000, 1e18)
+	ledger.state.Delivered().SetAccount(multisigAddr, multisigAccount)
+	recipient := types.MakeAcc("multisig_recipient")
+
+	fee := types.NewCoins(0, 0)
+	fee.TFuelWei = types.GetSendTxMinimumTransactionFeeTFuelWei(chainID, 2, testForks.EnableNativeMultisig)
+	newSendTx := func() *types.SendTx {
+		return &types.SendTx{
+			Fee: fee,
+			Inputs: []types.TxInput{{
+				Address:  multisigAddr,
+				Coins:    types.NewCoins(100, 0).Plus(fee),
+				Sequence: 1,
+			}},
+			Outputs: []types.TxOutput{{
+				Address: recipient.Address,
+				Coins:   types.NewCoins(100, 0),
+			}},
+		}
+	}
+
+	// Below the threshold
+	tx := newSendTx()
+	tx.SetSignature(multisigAddr, multisigAcc.sign(tx.SignBytes(chainID), 1))
+	_, res := ledger.executor.ExecuteTx(tx)
+	assert.True(res.IsError())
+	assert.Equal(result.CodeInvalidSignature, res.Code)
+
+	tx = newSendTx()
+	tx.SetSignature(multisigAddr, multisigAcc.sign(tx.SignBytes(chainID), 0, 2))
+	_, res = ledger.executor.ExecuteTx(tx)
+	require.True(res.IsOK(), res.Message)
+
+	multisigAccount = ledger.state.Delivered().GetAccount(multisigAddr)
+	assert.Equal(uint64(1), multisigAccount.Sequence)
+	assert.Equal(big.NewInt(900), multisigAccount.Balance.ThetaWei)
+	assert.Equal(big.NewInt(100), ledger.state.Delivered().GetAccount(recipient.Address).Balance.ThetaWei)
+}
+
+func TestMultisigNotActive(t *testing.T) {
+	assert := assert.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+
+	multisigAcc := newMultisigTestAccount(1, 2)
+	multisigAddr := multisigAcc.policy.Address()
+	multisigAccount := types.NewAccount(multisigAddr)
+	multisigAccount.Balance = types.NewCoins(1000, 1e18)
+	ledger.state.Delivered().SetAccount(multisigAddr, multisigAccount)
+	recipient := types.MakeAcc("multisig_recipient")
+
+	fee := types.NewCoins(0, getMinimumTxFee())
+	tx := &types.SendTx{
+		Fee: fee,
+		Inputs: []types.TxInput{{
+			Address:  multisigAddr,
+			Coins:    types.NewCoins(100, 0).Plus(fee),
+			Sequence: 1,
+		}},
+		Outputs: []types.TxOutput{{
+			Address: recipient.Address,
+			Coins:   types.NewCoins(100, 0),
+		}},
+	}
+	tx.SetSignature(multisigAddr, multisigAcc.sign(tx.SignBytes(chainID), 0, 1))
+	_, res := ledger.executor.ExecuteTx(tx)
+	assert.True(res.IsError())
+	assert.Equal(result.CodeInvalidSignature, res.Code)
+	assert.Equal(uint64(0), ledger.state.Delivered().GetAccount(multisigAddr).Sequence)
+}
+
+func TestMultisigDepositStakeTx(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	resetTestLedgerToHeight(ledger, testForks.EnableNativeMultisig-1)
+
+	multisigAcc := newMultisigTestAccount(2, 3)
+	multisigAddr := multisigAcc.policy.Address()
+	balance := types.NewCoins(0, 1e18)
+	balance.ThetaWei = new(big.Int).Mul(core.MinValidatorStakeDeposit200K, big.NewInt(2))
+	multisigAccount := types.NewAccount(multisigAddr)
+	multisigAccount.Balance = balance
+	ledger.state.Delivered().SetAccount(multisigAddr, multisigAccount)
+	ledger.state.Delivered().UpdateValidatorCandidatePool(&core.ValidatorCandidatePool{})
+	validator := types.MakeAcc("multisig_validator")
+
+	fee := types.NewCoins(0, 0)
+	fee.TFuelWei = types.GetMinimumTransactionFeeTFuelWei(chainID, testForks.EnableNativeMultisig)
+	stake := types.NewCoins(0, 0)
+	stake.ThetaWei = core.MinValidatorStakeDeposit200K
+	tx := &types.DepositStakeTx{
+		Fee: fee,
+		Source: types.TxInput{
+			Address:  multisigAddr,
+			Coins:    stake,
+			Sequence: 1,
+		},
+		Holder:  types.TxOutput{Address: validator.Address},
+		Purpose: core.StakeForValidator,
+	}
+	tx.SetSignature(multisigAddr, multisigAcc.sign(tx.SignBytes(chainID), 1, 2))
+	_, res := ledger.executor.ExecuteTx(tx)
+	require.True(res.IsOK(), res.Message)
+
+	vcp := ledger.state.Delivered().GetValidatorCandidatePool()
+	require.NotNil(vcp)
+	candidate := vcp.FindStakeDelegate(validator.Address)
+	require.NotNil(candidate)
+	require.Equal(1, len(candidate.Stakes))
+	assert.Equal(multisigAddr, candidate.Stakes[0].Source)
+	assert.Equal(core.MinValidatorStakeDeposit200K, candidate.Stakes[0].Amount)
+}
@@ -0,0 +1,173 @@
+package ledger
+
+import (
+	"encoding/hex"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	exec "github.com/thetatoken/theta/ledger/execution"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// SetParallelTxExecution sets whether ApplyBlockTxs executes the block transactions in
+// parallel, and the number of goroutines used to do so. Zero workers means one per CPU.
+func (ledger *Ledger) SetParallelTxExecution(enabled bool, numWorkers int) {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+
+	ledger.parallelTxExecution = enabled
+	ledger.numParallelTxWorkers = numWorkers
+}
+
+// ReplayBlockTxs replays the transactions of the given block on top of the state of its parent
+// block, in parallel if parallel is set, and returns the resulting state root hash. It is used
+// to check the parallel execution against the serial one. The replay happens on a separate
+// ledger state, so it neither modifies the ledger state nor records tx receipts.
+func (ledger *Ledger) ReplayBlockTxs(block *core.Block, parallel bool) (common.Hash, error) {
+	extParentBlock, err := ledger.chain.FindBlock(block.Parent)
+	if extParentBlock == nil || err != nil {
+		return common.Hash{}, fmt.Errorf("failed to find the parent block %v: %v", block.Parent.Hex(), err)
+	}
+	parentBlock := extParentBlock.Block
+
+	replayState := st.NewLedgerState(ledger.state.GetChainID(), ledger.db, nil)
+	if res := replayState.ResetState(parentBlock); res.IsError() {
+		return common.Hash{}, fmt.Errorf("the state of block %v is not available, it might have been pruned", parentBlock.Hash().Hex())
+	}
+
+	executor := exec.NewExecutor(ledger.db, ledger.chain, replayState, ledger.consensus, ledger.valMgr, ledger)
+	executor.SetSkipSanityCheck(true) // the block has already been validated
+
+	txs, err := parseBlockTxs(block.Txs)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	// Use the checked view so the replay does not record tx receipts
+	view := replayState.Checked()
+	if parallel {
+		if _, res := executeTxsInParallel(executor, view, core.CheckedView, txs, 0); res.IsError() {
+			return common.Hash{}, fmt.Errorf("failed to replay the transactions of block %v: %v", block.Hash().Hex(), res.Message)
+		}
+	} else {
+		for idx, tx := range txs {
+			if _, res := executor.CheckTx(tx); res.IsError() {
+				return common.Hash{}, fmt.Errorf("failed to replay transaction %v of block %v: %v", idx, block.Hash().Hex(), res.Message)
+			}
+		}
+	}
+
+	ledger.handleDelayedStateUpdates(view)
+	return view.Hash(), nil
+}
+
+func parseBlockTxs(rawTxs []common.Bytes) ([]types.Tx, error) {
+	txs := make([]types.Tx, 0, len(rawTxs))
+	for _, rawTx := range rawTxs {
+		tx, err := types.TxFromBytes(rawTx)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse transaction: %v", hex.EncodeToString(rawTx))
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// executeTxsInParallel processes the given transactions on the view with optimistic parallelism,
+// and leaves the view in the same state as processing them one by one in block order would. It
+// returns the number of transactions which had to be processed again.
+//
+// Each transaction is first processed speculatively on its own copy of the view, recording the
+// keys it reads and writes. The results are then committed in block order. A transaction none
+// of whose keys were written by the transactions before it has seen the same state as in the
+// serial execution, so its writes are copied to the view. Otherwise it is processed again,
+// directly on the view. The coinbase and slash transactions are always processed directly on
+// the view, since they depend on the per block flags of the view.
+//
+// The speculative executions commit the contract storage in memory only, and the commits are
+// written to the database when their writes are copied to the view. Like in the serial execution,
+// they record the tx receipts if viewSel is core.DeliveredView. The receipts recorded by the
+// speculative executions which are discarded get overwritten once the transactions are processed
+// again.
+//
+// The returned result carries the total gas used by the transactions, see exec.GetGasUsed.
+func executeTxsInParallel(executor *exec.Executor, view *st.StoreView, viewSel core.ViewSelector,
+	txs []types.Tx, numWorkers int) (numReprocessed int, res result.Result) {
+	if numWorkers <= 0 {
+		numWorkers = runtime.NumCPU()
+	}
+
+	overlays := make([]*st.StoreView, len(txs))
+	indices := make(chan int, len(txs))
+	for idx, tx := range txs {
+		if !canProcessSpeculatively(tx) {
+			continue
+		}
+		overlay, err := view.Copy()
+		if err != nil {
+			return 0, result.Error("Failed to copy the view: %v", err)
+		}
+		overlay.DeferStorageCommits()
+		overlays[idx] = overlay
+		indices <- idx
+	}
+	close(indices)
+
+	results := make([]result.Result, len(txs))
+	accesses := make([]*st.AccessSet, len(txs))
+	wg := &sync.WaitGroup{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indices {
+				overlays[idx].TrackAccesses()
+				_, results[idx] = executor.ProcessTxOnView(txs[idx], overlays[idx], viewSel)
+				accesses[idx] = overlays[idx].StopTrackingAccesses()
+			}
+		}()
+	}
+	wg.Wait()
+
+	view.TrackAccesses()
+	defer view.StopTrackingAccesses()
+
+	gasUsed := uint64(0)
+	for idx, tx := range txs {
+		if overlays[idx] != nil {
+			if !accesses[idx].ConflictsWith(view.Accesses()) {
+				if results[idx].IsError() {
+					return numReprocessed, results[idx]
+				}
+				view.ApplyWrites(overlays[idx], accesses[idx].WrittenKeys())
+				gasUsed += exec.GetGasUsed(results[idx])
+				continue
+			}
+			numReprocessed++
+		}
+
+		_, res := executor.ProcessTxOnView(tx, view, viewSel)
+		if res.IsError() {
+			return numReprocessed, res
+		}
+		gasUsed += exec.GetGasUsed(res)
+	}
+
+	return numReprocessed, result.OKWith(result.Info{exec.GasUsedInfoKey: gasUsed})
+}
+
+func canProcessSpeculatively(tx types.Tx) bool {
+	switch tx.(type) {
+	case *types.CoinbaseTx:
+		return false
+	case *types.SlashTx:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,127 @@
+package ledger
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func newParallelTestLedgers(numAccs int) (chainID string, serial *Ledger, parallel *Ledger, accs []types.PrivAccount) {
+	chainID, serial, _ = newTestLedger()
+	_, parallel, _ = newTestLedger()
+
+	txFee := getMinimumTxFee()
+	for i := 0; i < numAccs; i++ {
+		acc := types.MakeAccWithInitBalance("parallel_secret_"+strconv.Itoa(i), types.NewCoins(900000, 50000*txFee))
+		accs = append(accs, acc)
+		serial.state.Delivered().SetAccount(acc.Address, &acc.Account)
+		parallel.state.Delivered().SetAccount(acc.Address, &acc.Account)
+	}
+	return chainID, serial, parallel, accs
+}
+
+func executeTxsSerially(ledger *Ledger, rawTxs []common.Bytes) result.Result {
+	txs, err := parseBlockTxs(rawTxs)
+	if err != nil {
+		return result.Error("%v", err)
+	}
+	for _, tx := range txs {
+		if _, res := ledger.executor.ExecuteTx(tx); res.IsError() {
+			return res
+		}
+	}
+	return result.OK
+}
+
+func TestParallelTxExecution(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, serial, parallel, accs := newParallelTestLedgers(8)
+
+	rawTxs := []common.Bytes{}
+	for i := 0; i < 4; i++ {
+		// Independent transfers
+		rawTxs = append(rawTxs, newRawSendTx(chainID, 1, true, accs[i+4], accs[i], false))
+	}
+	// Conflicts with the first transfer through the sender
+	rawTxs = append(rawTxs, newRawSendTx(chainID, 2, true, accs[5], accs[0], false))
+	// Conflicts with the second transfer through the recipient
+	rawTxs = append(rawTxs, newRawSendTx(chainID, 1, true, accs[7], accs[5], false))
+
+	serialRes := executeTxsSerially(serial, rawTxs)
+	require.True(serialRes.IsOK(), serialRes.Message)
+
+	txs, err := parseBlockTxs(rawTxs)
+	require.Nil(err)
+	numReprocessed, res := executeTxsInParallel(parallel.executor, parallel.state.Delivered(), core.DeliveredView, txs, 4)
+	require.True(res.IsOK(), res.Message)
+	assert.Equal(2, numReprocessed)
+	assert.Equal(serial.state.Delivered().Hash(), parallel.state.Delivered().Hash())
+	assert.Nil(parallel.state.Delivered().Accesses())
+
+	expectedBal := types.NewCoins(900000+15+15-15, 50000*getMinimumTxFee()-getMinimumTxFee())
+	assert.Equal(expectedBal, parallel.state.Delivered().GetAccount(accs[5].Address).Balance)
+}
+
+func TestParallelTxExecutionFailedTx(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, serial, parallel, accs := newParallelTestLedgers(4)
+
+	rawTxs := []common.Bytes{
+		newRawSendTx(chainID, 1, true, accs[1], accs[0], false),
+		newRawSendTx(chainID, 1, true, accs[3], accs[2], false),
+		newRawSendTx(chainID, 1, true, accs[2], accs[0], false), // invalid sequence
+	}
+	serialRes := executeTxsSerially(serial, rawTxs)
+	require.True(serialRes.IsError())
+
+	txs, err := parseBlockTxs(rawTxs)
+	require.Nil(err)
+	_, res := executeTxsInParallel(parallel.executor, parallel.state.Delivered(), core.DeliveredView, txs, 4)
+	require.True(res.IsError())
+	assert.Equal(serialRes.Message, res.Message)
+	assert.Nil(parallel.state.Delivered().Accesses())
+}
+
+// TestParallelTxExecutionRandomBlocks compares the parallel execution with the serial one over
+// blocks of random transfers between a small set of accounts, so most of the transfers conflict
+func TestParallelTxExecutionRandomBlocks(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	rnd := rand.New(rand.NewSource(1))
+	for _, numWorkers := range []int{1, 3, 16} {
+		chainID, serial, parallel, accs := newParallelTestLedgers(12)
+		sequences := make([]int, len(accs))
+
+		for block := 0; block < 8; block++ {
+			rawTxs := []common.Bytes{}
+			for i := 0; i < 32; i++ {
+				from := rnd.Intn(len(accs))
+				to := (from + 1 + rnd.Intn(len(accs)-1)) % len(accs)
+				sequences[from]++
+				rawTxs = append(rawTxs, newRawSendTx(chainID, sequences[from], true, accs[to], accs[from], false))
+			}
+
+			serialRes := executeTxsSerially(serial, rawTxs)
+			require.True(serialRes.IsOK(), serialRes.Message)
+
+			txs, err := parseBlockTxs(rawTxs)
+			require.Nil(err)
+			_, res := executeTxsInParallel(parallel.executor, parallel.state.Delivered(), core.DeliveredView, txs, numWorkers)
+			require.True(res.IsOK(), res.Message)
+			assert.Equal(serial.state.Delivered().Hash(), parallel.state.Delivered().Hash(),
+				"state root mismatch, block: %v, numWorkers: %v", block, numWorkers)
+		}
+	}
+}
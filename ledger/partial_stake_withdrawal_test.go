@@ -0,0 +1,154 @@
+package ledger
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto/bls"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func newWithdrawStakeTxV2(chainID string, source types.PrivAccount, sequence uint64, holder common.Address, purpose uint8, amount types.Coins) *types.WithdrawStakeTxV2 {
+	tx := &types.WithdrawStakeTxV2{
+		Fee: types.Coins{
+			ThetaWei: big.NewInt(0),
+			TFuelWei: types.GetMinimumTransactionFeeTFuelWei(chainID, testForks.EnablePartialStakeWithdrawal),
+		},
+		Source: types.TxInput{
+			Address:  source.Address,
+			Sequence: sequence,
+		},
+		Holder:  types.TxOutput{Address: holder},
+		Purpose: purpose,
+		Amount:  amount,
+	}
+	tx.Source.Signature = source.Sign(tx.SignBytes(chainID))
+	return tx
+}
+
+func TestPartialGuardianStakeWithdrawal(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	resetTestLedgerToHeight(ledger, testForks.EnablePartialStakeWithdrawal-1)
+	view := ledger.state.Checked()
+
+	source := types.MakeAcc("partial_withdrawal_source")
+	holder := types.MakeAcc("partial_withdrawal_guardian")
+	setTestAccount(view, source, types.NewCoins(0, 1e18))
+
+	blsPriv, _ := bls.RandKey()
+	minStake := core.MinGuardianStakeDeposit1000
+	stake := new(big.Int).Mul(minStake, big.NewInt(3))
+	gcp := core.NewGuardianCandidatePool()
+	require.Nil(gcp.DepositStake(chainID, source.Address, holder.Address, stake, blsPriv.PublicKey(), testForks.EnablePartialStakeWithdrawal))
+	view.UpdateGuardianCandidatePool(gcp)
+
+	// The remaining stake can not drop below the minimum guardian stake
+	tooMuch := new(big.Int).Sub(stake, big.NewInt(1))
+	_, res := ledger.executor.CheckTx(newWithdrawStakeTxV2(chainID, source, 1, holder.Address, core.StakeForGuardian, types.Coins{ThetaWei: tooMuch, TFuelWei: big.NewInt(0)}))
+	assert.True(res.IsError())
+
+	// Guardian stakes are withdrawn in Theta
+	_, res = ledger.executor.CheckTx(newWithdrawStakeTxV2(chainID, source, 1, holder.Address, core.StakeForGuardian, types.Coins{ThetaWei: big.NewInt(0), TFuelWei: minStake}))
+	assert.True(res.IsError())
+
+	_, res = ledger.executor.CheckTx(newWithdrawStakeTxV2(chainID, source, 1, holder.Address, core.StakeForGuardian, types.Coins{ThetaWei: minStake, TFuelWei: big.NewInt(0)}))
+	require.True(res.IsOK(), res.Message)
+
+	g := view.GetGuardianCandidatePool().GetWithHolderAddress(holder.Address)
+	require.NotNil(g)
+	assert.Equal(new(big.Int).Mul(minStake, big.NewInt(2)), g.TotalStake())
+	require.Equal(2, len(g.Stakes))
+
+	// More can be withdrawn from the remaining stake while the withdrawn part is locked
+	_, res = ledger.executor.CheckTx(newWithdrawStakeTxV2(chainID, source, 2, holder.Address, core.StakeForGuardian, types.Coins{ThetaWei: minStake, TFuelWei: big.NewInt(0)}))
+	require.True(res.IsOK(), res.Message)
+
+	// The withdrawn amounts are returned after the locking period, the rest stays staked
+	gcp = view.GetGuardianCandidatePool()
+	returnHeight := ledger.state.Height() + core.ReturnLockingPeriod
+	assert.Equal(0, len(gcp.ReturnStakes(returnHeight-1)))
+	returnedStakes := gcp.ReturnStakes(returnHeight)
+	require.Equal(2, len(returnedStakes))
+	assert.Equal(minStake, returnedStakes[0].Amount)
+	assert.Equal(minStake, returnedStakes[1].Amount)
+	g = gcp.GetWithHolderAddress(holder.Address)
+	require.NotNil(g)
+	assert.Equal(minStake, g.TotalStake())
+}
+
+func TestPartialEliteEdgeNodeStakeWithdrawal(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	resetTestLedgerToHeight(ledger, testForks.EnablePartialStakeWithdrawal-1)
+	view := ledger.state.Checked()
+
+	source := types.MakeAcc("partial_withdrawal_source")
+	holder := types.MakeAcc("partial_withdrawal_een")
+	setTestAccount(view, source, types.NewCoins(0, 1e18))
+
+	blsPriv, _ := bls.RandKey()
+	minStake := core.MinEliteEdgeNodeStakeDeposit
+	stake := new(big.Int).Mul(minStake, big.NewInt(2))
+	eenp := st.NewEliteEdgeNodePool(view, false)
+	require.Nil(eenp.DepositStake(source.Address, holder.Address, stake, blsPriv.PublicKey(), testForks.EnablePartialStakeWithdrawal))
+
+	_, res := ledger.executor.CheckTx(newWithdrawStakeTxV2(chainID, source, 1, holder.Address, core.StakeForEliteEdgeNode, types.Coins{ThetaWei: big.NewInt(0), TFuelWei: minStake}))
+	require.True(res.IsOK(), res.Message)
+	assert.Equal(minStake, view.GetTotalEENStake())
+
+	// The withdrawn amount goes through the elite edge node stake return queue
+	returnHeight := ledger.state.Height() + core.ReturnLockingPeriod
+	stakeReturns := view.GetEliteEdgeNodeStakeReturns(returnHeight)
+	require.Equal(1, len(stakeReturns))
+	assert.Equal(holder.Address, stakeReturns[0].Holder)
+	assert.Equal(minStake, stakeReturns[0].Stake.Amount)
+
+	require.Nil(eenp.ReturnStake(returnHeight, holder.Address, stakeReturns[0].Stake))
+	een := eenp.Get(holder.Address)
+	require.NotNil(een)
+	require.Equal(1, len(een.Stakes))
+	assert.False(een.Stakes[0].Withdrawn)
+	assert.Equal(minStake, een.TotalStake())
+}
+
+func TestPartialStakeWithdrawalGovernedMinimum(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	resetTestLedgerToHeight(ledger, testForks.EnablePartialStakeWithdrawal-1)
+	view := ledger.state.Checked()
+
+	source := types.MakeAcc("partial_withdrawal_source")
+	holder := types.MakeAcc("partial_withdrawal_guardian")
+	setTestAccount(view, source, types.NewCoins(0, 1e18))
+
+	blsPriv, _ := bls.RandKey()
+	minStake := core.MinGuardianStakeDeposit1000
+	gcp := core.NewGuardianCandidatePool()
+	require.Nil(gcp.DepositStake(chainID, source.Address, holder.Address, new(big.Int).Mul(minStake, big.NewInt(3)), blsPriv.PublicKey(), testForks.EnablePartialStakeWithdrawal))
+	view.UpdateGuardianCandidatePool(gcp)
+
+	// The remaining stake needs to meet the minimum guardian stake set by the governance
+	governedMinStake := new(big.Int).Mul(minStake, big.NewInt(2))
+	view.SetGovernanceParam(types.ParamMinimumGuardianStake, governedMinStake)
+
+	_, res := ledger.executor.CheckTx(newWithdrawStakeTxV2(chainID, source, 1, holder.Address, core.StakeForGuardian, types.Coins{ThetaWei: governedMinStake, TFuelWei: big.NewInt(0)}))
+	assert.True(res.IsError())
+
+	_, res = ledger.executor.CheckTx(newWithdrawStakeTxV2(chainID, source, 1, holder.Address, core.StakeForGuardian, types.Coins{ThetaWei: minStake, TFuelWei: big.NewInt(0)}))
+	require.True(res.IsOK(), res.Message)
+	g := view.GetGuardianCandidatePool().GetWithHolderAddress(holder.Address)
+	require.NotNil(g)
+	assert.Equal(governedMinStake, g.TotalStake())
+}
@@ -0,0 +1,131 @@
+package ledger
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto/bls"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func newRedelegateStakeTx(chainID string, source types.PrivAccount, sequence uint64, fromHolder, toHolder common.Address, purpose uint8) *types.RedelegateStakeTx {
+	tx := &types.RedelegateStakeTx{
+		Fee: types.Coins{
+			ThetaWei: big.NewInt(0),
+			TFuelWei: types.GetMinimumTransactionFeeTFuelWei(chainID, testForks.EnableStakeRedelegation),
+		},
+		Source: types.TxInput{
+			Address:  source.Address,
+			Sequence: sequence,
+		},
+		FromHolder: types.TxOutput{Address: fromHolder},
+		ToHolder:   types.TxOutput{Address: toHolder},
+		Purpose:    purpose,
+	}
+	tx.Source.Signature = source.Sign(tx.SignBytes(chainID))
+	return tx
+}
+
+func addRedelegationBLSSummary(chainID string, tx *types.RedelegateStakeTx, source, toHolder types.PrivAccount) {
+	blsPriv, _ := bls.RandKey()
+	tx.BlsPubkey = blsPriv.PublicKey()
+	tx.BlsPop = blsPriv.PopProve()
+	tx.HolderSig = toHolder.Sign(tx.BlsPop.ToBytes())
+	tx.Source.Signature = source.Sign(tx.SignBytes(chainID))
+}
+
+func TestRedelegateGuardianStake(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	resetTestLedgerToHeight(ledger, testForks.EnableStakeRedelegation-1)
+	view := ledger.state.Checked()
+
+	source := types.MakeAcc("redelegation_source")
+	fromHolder := types.MakeAcc("redelegation_from_guardian")
+	toHolder := types.MakeAcc("redelegation_to_guardian")
+	setTestAccount(view, source, types.NewCoins(0, 1e18))
+
+	blsPriv, _ := bls.RandKey()
+	amount := new(big.Int).Set(core.MinGuardianStakeDeposit1000)
+	gcp := core.NewGuardianCandidatePool()
+	require.Nil(gcp.DepositStake(chainID, fromHolder.Address, fromHolder.Address, amount, blsPriv.PublicKey(), testForks.EnableStakeRedelegation))
+	require.Nil(gcp.DepositStake(chainID, source.Address, fromHolder.Address, amount, nil, testForks.EnableStakeRedelegation))
+	view.UpdateGuardianCandidatePool(gcp)
+
+	// Only guardian and elite edge node stakes can be redelegated
+	_, res := ledger.executor.CheckTx(newRedelegateStakeTx(chainID, source, 1, fromHolder.Address, toHolder.Address, core.StakeForValidator))
+	assert.Equal(result.CodeInvalidStakePurpose, res.Code)
+
+	// The new guardian needs a BLS summary
+	tx := newRedelegateStakeTx(chainID, source, 1, fromHolder.Address, toHolder.Address, core.StakeForGuardian)
+	_, res = ledger.executor.CheckTx(tx)
+	assert.True(res.IsError())
+	assert.Equal("Must provide BLS Pubkey", res.Message)
+
+	addRedelegationBLSSummary(chainID, tx, source, toHolder)
+	_, res = ledger.executor.CheckTx(tx)
+	require.True(res.IsOK(), res.Message)
+
+	gcp = view.GetGuardianCandidatePool()
+	from := gcp.GetWithHolderAddress(fromHolder.Address)
+	require.NotNil(from)
+	assert.Equal(amount, from.TotalStake())
+	to := gcp.GetWithHolderAddress(toHolder.Address)
+	require.NotNil(to)
+	assert.Equal(amount, to.TotalStake())
+	assert.Equal(source.Address, to.Stakes[0].Source)
+	assert.False(to.Stakes[0].Withdrawn)
+
+	// A second redelegation within the cooldown period is rejected
+	_, res = ledger.executor.CheckTx(newRedelegateStakeTx(chainID, source, 2, toHolder.Address, fromHolder.Address, core.StakeForGuardian))
+	assert.Equal(result.CodeStakeRedelegationTooFrequent, res.Code)
+
+	redelegationHeight, ok := view.GetStakeRedelegationHeight(source.Address)
+	require.True(ok)
+	assert.Equal(testForks.EnableStakeRedelegation, redelegationHeight)
+}
+
+func TestRedelegateEliteEdgeNodeStake(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	resetTestLedgerToHeight(ledger, testForks.EnableStakeRedelegation-1)
+	view := ledger.state.Checked()
+
+	source := types.MakeAcc("redelegation_source")
+	fromHolder := types.MakeAcc("redelegation_from_een")
+	toHolder := types.MakeAcc("redelegation_to_een")
+	setTestAccount(view, source, types.NewCoins(0, 1e18))
+
+	fromBlsPriv, _ := bls.RandKey()
+	toBlsPriv, _ := bls.RandKey()
+	amount := new(big.Int).Set(core.MinEliteEdgeNodeStakeDeposit)
+	eenp := st.NewEliteEdgeNodePool(view, false)
+	require.Nil(eenp.DepositStake(source.Address, fromHolder.Address, amount, fromBlsPriv.PublicKey(), testForks.EnableStakeRedelegation))
+	require.Nil(eenp.DepositStake(toHolder.Address, toHolder.Address, amount, toBlsPriv.PublicKey(), testForks.EnableStakeRedelegation))
+	totalStake := view.GetTotalEENStake()
+
+	// The stake can not be moved to the holder it is already with
+	_, res := ledger.executor.CheckTx(newRedelegateStakeTx(chainID, source, 1, fromHolder.Address, fromHolder.Address, core.StakeForEliteEdgeNode))
+	assert.True(res.IsError())
+
+	// No BLS summary is needed for an existing elite edge node
+	_, res = ledger.executor.CheckTx(newRedelegateStakeTx(chainID, source, 1, fromHolder.Address, toHolder.Address, core.StakeForEliteEdgeNode))
+	require.True(res.IsOK(), res.Message)
+
+	eenp = st.NewEliteEdgeNodePool(view, true)
+	assert.False(eenp.Contains(fromHolder.Address))
+	to := eenp.Get(toHolder.Address)
+	require.NotNil(to)
+	assert.Equal(new(big.Int).Mul(amount, big.NewInt(2)), to.TotalStake())
+	assert.Equal(totalStake, view.GetTotalEENStake())
+}
@@ -0,0 +1,144 @@
+package ledger
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	exec "github.com/thetatoken/theta/ledger/execution"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// TxSimulationResult is the outcome of a simulated transaction
+type TxSimulationResult struct {
+	BlockHeight    uint64 // height of the block the transaction was simulated in
+	Result         result.Result
+	Fee            types.Coins
+	BalanceChanges []*types.BalanceChange
+}
+
+// SimulateTx runs the given transaction through the sanity check and the processing of its
+// executor, on top of a copy of the screened view, or the delivered view if useDeliveredView
+// is set. If height is not zero, the transaction is simulated on top of the state of the
+// finalized block at that height instead. The signature checks are skipped if
+// skipSignatureCheck is set. The simulation happens on a separate ledger state, so nothing
+// is committed, and no tx receipts are recorded.
+func (ledger *Ledger) SimulateTx(rawTx common.Bytes, height uint64, useDeliveredView bool, skipSignatureCheck bool) (*TxSimulationResult, error) {
+	tx, err := types.TxFromBytes(rawTx)
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding tx: %v", err)
+	}
+
+	parentBlock, view, err := ledger.getSimulationView(height, useDeliveredView)
+	if err != nil {
+		return nil, err
+	}
+
+	simState := st.NewLedgerState(ledger.state.GetChainID(), ledger.db, nil)
+	if res := simState.ResetStateWithView(parentBlock, view); res.IsError() {
+		return nil, fmt.Errorf("%v", res.Message)
+	}
+	simState.Checked().TrackBalanceChanges()
+
+	executor := exec.NewExecutor(ledger.db, ledger.chain, simState, ledger.consensus, ledger.valMgr, ledger)
+	executor.SetSkipSignatureCheck(skipSignatureCheck)
+
+	// Use the checked view so the simulation does not record tx receipts
+	_, res := executor.CheckTx(tx)
+
+	simResult := &TxSimulationResult{
+		BlockHeight:    simState.Height() + 1,
+		Result:         res,
+		Fee:            types.NewCoins(0, 0),
+		BalanceChanges: simState.Checked().GetNetBalanceChanges(),
+	}
+	if !res.IsError() {
+		var baseFee *big.Int
+		if simResult.BlockHeight >= common.GetForkSchedule(parentBlock.ChainID).EnableDynamicBaseFee {
+			baseFee = types.CalculateBaseFee(parentBlock.BlockHeader)
+		}
+		simResult.Fee = getSimulatedTxFee(tx, executor.LastSmartContractGasUsed(), baseFee)
+	}
+	return simResult, nil
+}
+
+// getSimulationView returns a copy of the view to simulate transactions on, and the block
+// the view corresponds to
+func (ledger *Ledger) getSimulationView(height uint64, useDeliveredView bool) (*core.Block, *st.StoreView, error) {
+	if height == 0 {
+		ledger.mu.Lock()
+		defer ledger.mu.Unlock()
+
+		view := ledger.state.Screened()
+		if useDeliveredView {
+			view = ledger.state.Delivered()
+		}
+		copiedView, err := view.Copy()
+		if err != nil {
+			return nil, nil, err
+		}
+		return ledger.state.ParentBlock(), copiedView, nil
+	}
+
+	var block *core.ExtendedBlock
+	for _, b := range ledger.chain.FindBlocksByHeight(height) {
+		if b.Status.IsFinalized() {
+			block = b
+			break
+		}
+	}
+	if block == nil {
+		return nil, nil, fmt.Errorf("Finalized block at height %v is not available on current node", height)
+	}
+	view := st.NewStoreView(block.Height, block.StateHash, ledger.db)
+	if view == nil {
+		return nil, nil, fmt.Errorf("the state for height %v is not available, it might have been pruned", height)
+	}
+	return block.Block, view, nil
+}
+
+// getSimulatedTxFee returns the fee charged for the given successfully processed transaction.
+// baseFee is nil before the dynamic base fee is enabled.
+func getSimulatedTxFee(tx types.Tx, smartContractGasUsed uint64, baseFee *big.Int) types.Coins {
+	switch tx := tx.(type) {
+	case *types.SendTx:
+		return tx.Fee
+	case *types.ReserveFundTx:
+		return tx.Fee
+	case *types.ReleaseFundTx:
+		return tx.Fee
+	case *types.ServicePaymentTx:
+		return tx.Fee
+	case *types.SplitRuleTx:
+		return tx.Fee
+	case *types.DepositStakeTx:
+		return tx.Fee
+	case *types.DepositStakeTxV2:
+		return tx.Fee
+	case *types.WithdrawStakeTx:
+		return tx.Fee
+	case *types.WithdrawStakeTxV2:
+		return tx.Fee
+	case *types.StakeRewardDistributionTx:
+		return tx.Fee
+	case *types.RedelegateStakeTx:
+		return tx.Fee
+	case *types.GovernanceProposalTx:
+		return tx.Fee
+	case *types.GovernanceVoteTx:
+		return tx.Fee
+	case *types.GovernanceExecuteTx:
+		return tx.Fee
+	case *types.SmartContractTx:
+		gasPrice := types.EffectiveGasPrice(tx.GasPrice, tx.GasTipCap, baseFee)
+		return types.Coins{
+			ThetaWei: big.NewInt(0),
+			TFuelWei: new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(smartContractGasUsed)),
+		}
+	default:
+		return types.NewCoins(0, 0)
+	}
+}
@@ -0,0 +1,69 @@
+package ledger
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func TestLedgerSimulateTx(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+
+	txFee := getMinimumTxFee()
+	accOut := types.MakeAccWithInitBalance("accOut", types.NewCoins(700000, 3))
+	accIn := types.MakeAccWithInitBalance("in_secret_0", types.NewCoins(900000, 50000*txFee))
+	screened := ledger.state.Screened()
+	screened.SetAccount(accOut.Address, &accOut.Account)
+	screened.SetAccount(accIn.Address, &accIn.Account)
+	screenedRoot := screened.Hash()
+
+	sendTxBytes := newRawSendTx(chainID, 1, true, accOut, accIn, false)
+	simResult, err := ledger.SimulateTx(sendTxBytes, 0, false, false)
+	require.Nil(err)
+	require.True(simResult.Result.IsOK(), simResult.Result.Message)
+	assert.Equal(types.NewCoins(0, txFee), simResult.Fee)
+
+	changes := make(map[common.Address]map[uint]*big.Int)
+	for _, bc := range simResult.BalanceChanges {
+		if changes[bc.Address] == nil {
+			changes[bc.Address] = make(map[uint]*big.Int)
+		}
+		delta := new(big.Int).Set(bc.Delta)
+		if bc.IsNegative {
+			delta.Neg(delta)
+		}
+		changes[bc.Address][bc.TokenType] = delta
+	}
+	assert.Equal(2, len(changes))
+	assert.Equal(big.NewInt(-15), changes[accIn.Address][0])
+	assert.Equal(big.NewInt(-txFee), changes[accIn.Address][1])
+	assert.Equal(big.NewInt(15), changes[accOut.Address][0])
+	assert.Nil(changes[accOut.Address][1])
+
+	// Nothing is committed to the screened view
+	assert.Equal(screenedRoot, ledger.state.Screened().Hash())
+	assert.Equal(uint64(0), ledger.state.Screened().GetAccount(accIn.Address).Sequence)
+
+	// The accounts only exist in the screened view
+	simResult, err = ledger.SimulateTx(sendTxBytes, 0, true, false)
+	require.Nil(err)
+	assert.True(simResult.Result.IsError())
+	assert.Equal(0, len(simResult.BalanceChanges))
+
+	// Signatures for another chain are rejected, unless the signature check is skipped
+	badSigTxBytes := newRawSendTx("other_chain_id", 1, true, accOut, accIn, false)
+	simResult, err = ledger.SimulateTx(badSigTxBytes, 0, false, false)
+	require.Nil(err)
+	assert.True(simResult.Result.IsError())
+
+	simResult, err = ledger.SimulateTx(badSigTxBytes, 0, false, true)
+	require.Nil(err)
+	assert.True(simResult.Result.IsOK(), simResult.Result.Message)
+}
@@ -0,0 +1,93 @@
+package state
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/thetatoken/theta/common"
+)
+
+//
+// ------------------------- AccessSet -------------------------
+//
+
+// AccessSet records the keys of the state tree read and written through a StoreView. It
+// covers the account, split rule, code and stake related keys. The storage slots of a
+// contract are covered by the key of the contract account, since every storage update
+// changes the storage root kept in the account.
+type AccessSet struct {
+	reads        map[string]bool
+	readPrefixes []common.Bytes // prefixes of the traversed key ranges
+	writes       map[string]bool
+}
+
+// NewAccessSet creates an empty AccessSet
+func NewAccessSet() *AccessSet {
+	return &AccessSet{
+		reads:  make(map[string]bool),
+		writes: make(map[string]bool),
+	}
+}
+
+func (as *AccessSet) addRead(key common.Bytes) {
+	as.reads[string(key)] = true
+}
+
+func (as *AccessSet) addReadPrefix(prefix common.Bytes) {
+	as.readPrefixes = append(as.readPrefixes, common.CopyBytes(prefix))
+}
+
+func (as *AccessSet) addWrite(key common.Bytes) {
+	as.writes[string(key)] = true
+}
+
+// Merge adds the keys accessed in other to the set
+func (as *AccessSet) Merge(other *AccessSet) {
+	for key := range other.reads {
+		as.reads[key] = true
+	}
+	as.readPrefixes = append(as.readPrefixes, other.readPrefixes...)
+	for key := range other.writes {
+		as.writes[key] = true
+	}
+}
+
+// ConflictsWith returns true if any key read or written in the set was written in other.
+// Write-write overlaps count as conflicts too: a transaction reverted by the VM leaves
+// the keys it wrote at their original values, and copying those values over would undo
+// the writes of an earlier transaction.
+func (as *AccessSet) ConflictsWith(other *AccessSet) bool {
+	for key := range other.writes {
+		if as.reads[key] || as.writes[key] {
+			return true
+		}
+		for _, prefix := range as.readPrefixes {
+			if bytes.HasPrefix([]byte(key), prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WrittenKeys returns the keys written, in ascending order
+func (as *AccessSet) WrittenKeys() []common.Bytes {
+	keys := make([]common.Bytes, 0, len(as.writes))
+	for key := range as.writes {
+		keys = append(keys, common.Bytes(key))
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i], keys[j]) < 0
+	})
+	return keys
+}
+
+// NumReads returns the number of distinct keys read, not counting the traversals
+func (as *AccessSet) NumReads() int {
+	return len(as.reads)
+}
+
+// NumWrites returns the number of distinct keys written
+func (as *AccessSet) NumWrites() int {
+	return len(as.writes)
+}
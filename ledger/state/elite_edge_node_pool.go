@@ -286,6 +286,78 @@ func (eenp *EliteEdgeNodePool) WithdrawStake(source common.Address, holder commo
 	return withdrawnStake, nil
 }
 
+// WithdrawPartialStake withdraws the given amount from the stake of the source, leaving at least
+// the given minimum stake. Like a fully withdrawn stake, the withdrawn amount is returned after
+// the locking period.
+func (eenp *EliteEdgeNodePool) WithdrawPartialStake(source common.Address, holder common.Address, amount *big.Int, minEliteEdgeNodeStake *big.Int, currentHeight uint64) (*core.Stake, error) {
+	if eenp.readOnly {
+		log.Panicf("EliteEdgeNodePool.WithdrawPartialStake: the pool is read-only")
+	}
+
+	een := eenp.Get(holder)
+	if een == nil {
+		return nil, fmt.Errorf("No matched stake holder address found: %v", holder)
+	}
+
+	withdrawnStake, err := een.WithdrawPartialStake(source, amount, minEliteEdgeNodeStake, currentHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	eenp.Upsert(een)
+
+	// Update total eenp stake
+	totalStake := eenp.sv.GetTotalEENStake()
+	totalStake.Sub(totalStake, withdrawnStake.Amount)
+	eenp.sv.SetTotalEENStake(totalStake)
+
+	return withdrawnStake, nil
+}
+
+// RedelegateStake moves the stake of the source from one elite edge node to another without
+// going through the withdrawal locking period. The pubkey is only used if the new holder is
+// not in the pool yet. The total EEN stake is unchanged.
+func (eenp *EliteEdgeNodePool) RedelegateStake(source common.Address, fromHolder common.Address, toHolder common.Address, pubkey *bls.PublicKey) (*big.Int, error) {
+	if eenp.readOnly {
+		log.Panicf("EliteEdgeNodePool.RedelegateStake: the pool is read-only")
+	}
+
+	from := eenp.Get(fromHolder)
+	if from == nil {
+		return nil, fmt.Errorf("No matched stake holder address found: %v", fromHolder)
+	}
+	stake, err := from.RemoveStake(source)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := stake.Amount
+	to := eenp.Get(toHolder)
+	if to == nil {
+		to = core.NewEliteEdgeNode(
+			core.NewStakeHolder(toHolder, []*core.Stake{core.NewStake(source, amount)}),
+			pubkey)
+	} else {
+		expectedStake := big.NewInt(0).Add(to.TotalStake(), amount)
+		if expectedStake.Cmp(core.MaxEliteEdgeNodeStakeDeposit) > 0 {
+			return nil, fmt.Errorf("Elite edge node stake would exceed the cap: %v", expectedStake)
+		}
+		err = to.DepositStake(source, amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(from.Stakes) == 0 {
+		eenp.Remove(from)
+	} else {
+		eenp.Upsert(from)
+	}
+	eenp.Upsert(to)
+
+	return amount, nil
+}
+
 func (eenp *EliteEdgeNodePool) ReturnStake(currentHeight uint64, holder common.Address, returnedStake core.Stake) error {
 	een := eenp.Get(holder)
 	if een == nil {
@@ -293,23 +365,17 @@ func (eenp *EliteEdgeNodePool) ReturnStake(currentHeight uint64, holder common.A
 	}
 
 	sourceAddress := returnedStake.Source
-	numStakes := len(een.Stakes)
-
-	// need to iterate in the reverse order, since we may delete elemements from the slice while iterating through it
-	for sidx := numStakes - 1; sidx >= 0; sidx-- {
-		stake := een.Stakes[sidx]
-
-		if stake.Source == sourceAddress {
-			if stake.Withdrawn == false || stake.ReturnHeight != currentHeight {
-				log.Panicf("Returned stake mismatch: eenAddr = %v, sourceAddr = %v, currentHeight = %v, stake.Withdrawn = %v, stake.ReturnHeight = %v",
-					holder, sourceAddress, currentHeight, stake.Withdrawn, stake.ReturnHeight)
-			}
 
+	// After a partial withdrawal, the source can have multiple stakes on the same elite edge node,
+	// so the stake to be returned is matched by its return height and amount as well
+	var sourceStake *core.Stake
+	for sidx, stake := range een.Stakes {
+		if stake.Source != sourceAddress {
+			continue
+		}
+		if stake.Withdrawn && stake.ReturnHeight == currentHeight && stake.Amount.Cmp(returnedStake.Amount) == 0 {
 			logger.Infof("Stake to be returned: source = %v, amount = %v", stake.Source, stake.Amount)
-			_, err := een.ReturnStake(sourceAddress, currentHeight)
-			if err != nil {
-				return err
-			}
+			een.Stakes = append(een.Stakes[:sidx], een.Stakes[sidx+1:]...)
 
 			if len(een.Stakes) == 0 { // the candidate's stake becomes zero, no need to keep track of the candidate anymore
 				eenp.Remove(een)
@@ -317,8 +383,14 @@ func (eenp *EliteEdgeNodePool) ReturnStake(currentHeight uint64, holder common.A
 				eenp.Upsert(een)
 			}
 
-			break // only one stake to be returned
+			return nil // only one stake to be returned
 		}
+		sourceStake = stake
+	}
+
+	if sourceStake != nil {
+		log.Panicf("Returned stake mismatch: eenAddr = %v, sourceAddr = %v, currentHeight = %v, stake.Withdrawn = %v, stake.ReturnHeight = %v",
+			holder, sourceAddress, currentHeight, sourceStake.Withdrawn, sourceStake.ReturnHeight)
 	}
 
 	return nil
@@ -84,6 +84,32 @@ func StakeRewardDistributionRuleSetKey(addr common.Address) common.Bytes {
 	return append(prefix, addr[:]...)
 }
 
+// StakeRedelegationHeightKey returns the state key of the height of the last stake
+// redelegation of the given source
+func StakeRedelegationHeightKey(source common.Address) common.Bytes {
+	return append(common.Bytes("ls/srh/"), source[:]...)
+}
+
+// ValidatorJailKey returns the state key of the height the validator is released from jail at
+func ValidatorJailKey(holder common.Address) common.Bytes {
+	return append(common.Bytes("ls/vj/"), holder[:]...)
+}
+
+// GovernanceParamKey returns the state key of the governable protocol parameter with the given name
+func GovernanceParamKey(name string) common.Bytes {
+	return common.Bytes("ls/gp/" + name)
+}
+
+// GovernanceProposalKey returns the state key of the governance proposal with the given ID
+func GovernanceProposalKey(id uint64) common.Bytes {
+	return common.Bytes("ls/gprop/" + strconv.FormatUint(id, 10))
+}
+
+// GovernanceProposalCountKey returns the state key of the number of governance proposals submitted
+func GovernanceProposalCountKey() common.Bytes {
+	return common.Bytes("ls/gpc")
+}
+
 //EliteEdgeNodeStakeReturnsKeyPrefix returns the prefix of the elite edge node stake return key
 func EliteEdgeNodeStakeReturnsKeyPrefix() common.Bytes {
 	return common.Bytes("ls/eensrk/")
@@ -0,0 +1,152 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rlp"
+	"github.com/thetatoken/theta/store/trie"
+)
+
+//
+// ------------------------- Merkle Proofs -------------------------
+//
+
+// AccountProof proves the account (or its absence) against the state root of a block
+type AccountProof struct {
+	Address common.Address  `json:"address"`
+	Account *types.Account  `json:"account"` // nil if the account does not exist
+	Proof   []common.Bytes  `json:"proof"`
+	Storage []*StorageProof `json:"storage_proofs"`
+}
+
+// StorageProof proves the value of a storage slot against the storage root of an account
+type StorageProof struct {
+	Key   common.Hash    `json:"key"`
+	Value common.Hash    `json:"value"`
+	Proof []common.Bytes `json:"proof"`
+}
+
+// ProveAccount constructs the merkle proof of the given account, and of the given storage
+// slots of the account
+func (sv *StoreView) ProveAccount(addr common.Address, storageKeys []common.Hash) (*AccountProof, error) {
+	accountProof := &AccountProof{
+		Address: addr,
+		Account: sv.GetAccount(addr),
+		Storage: []*StorageProof{},
+	}
+
+	proof := trie.ProofList{}
+	if err := sv.store.Prove(AccountKey(addr), 0, &proof); err != nil {
+		return nil, err
+	}
+	accountProof.Proof = proof
+
+	for _, key := range storageKeys {
+		storageProof := &StorageProof{
+			Key:   key,
+			Proof: []common.Bytes{},
+		}
+		if accountProof.Account != nil && !isEmptyStorageRoot(accountProof.Account.Root) {
+			storage := sv.getAccountStorage(accountProof.Account)
+			if storage == nil {
+				return nil, fmt.Errorf("failed to load the storage of account %v", addr.Hex())
+			}
+			proof := trie.ProofList{}
+			if err := storage.Prove(key[:], 0, &proof); err != nil {
+				return nil, err
+			}
+			storageProof.Proof = proof
+			storageProof.Value = sv.GetState(addr, key)
+		}
+		accountProof.Storage = append(accountProof.Storage, storageProof)
+	}
+
+	return accountProof, nil
+}
+
+// VerifyAccountProof verifies the account proof, including its storage proofs, against the
+// state root in the given block header. It only relies on the header, so it can be used by
+// clients which do not maintain the state.
+func VerifyAccountProof(header *core.BlockHeader, accountProof *AccountProof) error {
+	account, err := verifyAccount(header.StateHash, accountProof.Address, accountProof.Proof)
+	if err != nil {
+		return err
+	}
+
+	if account == nil || accountProof.Account == nil {
+		if account != accountProof.Account {
+			return fmt.Errorf("account existence mismatch for %v", accountProof.Address.Hex())
+		}
+	} else {
+		expected, err := types.ToBytes(account)
+		if err != nil {
+			return err
+		}
+		claimed, err := types.ToBytes(accountProof.Account)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(expected, claimed) {
+			return fmt.Errorf("account mismatch for %v", accountProof.Address.Hex())
+		}
+	}
+
+	for _, storageProof := range accountProof.Storage {
+		value := common.Hash{}
+		if account != nil && !isEmptyStorageRoot(account.Root) {
+			value, err = verifyStorage(account.Root, storageProof.Key, storageProof.Proof)
+			if err != nil {
+				return err
+			}
+		}
+		if value != storageProof.Value {
+			return fmt.Errorf("storage value mismatch for key %v, proven: %v, claimed: %v",
+				storageProof.Key.Hex(), value.Hex(), storageProof.Value.Hex())
+		}
+	}
+
+	return nil
+}
+
+// verifyAccount returns the account proven by the proof against the state root, or nil if the
+// proof shows the account does not exist
+func verifyAccount(stateRoot common.Hash, addr common.Address, proof []common.Bytes) (*types.Account, error) {
+	data, _, err := trie.VerifyProof(stateRoot, AccountKey(addr), trie.ProofList(proof))
+	if err != nil {
+		return nil, fmt.Errorf("invalid account proof for %v: %v", addr.Hex(), err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	account := &types.Account{}
+	if err := types.FromBytes(data, account); err != nil {
+		return nil, fmt.Errorf("failed to decode account %v: %v", addr.Hex(), err)
+	}
+	return account, nil
+}
+
+// verifyStorage returns the storage value proven by the proof against the storage root
+func verifyStorage(storageRoot common.Hash, key common.Hash, proof []common.Bytes) (common.Hash, error) {
+	enc, _, err := trie.VerifyProof(storageRoot, key[:], trie.ProofList(proof))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid storage proof for key %v: %v", key.Hex(), err)
+	}
+	if len(enc) == 0 {
+		return common.Hash{}, nil
+	}
+
+	_, content, _, err := rlp.Split(enc)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to decode storage value for key %v: %v", key.Hex(), err)
+	}
+	return common.BytesToHash(content), nil
+}
+
+func isEmptyStorageRoot(root common.Hash) bool {
+	return root == common.Hash{} || root == core.EmptyRootHash
+}
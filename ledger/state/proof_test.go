@@ -0,0 +1,69 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestAccountProof(t *testing.T) {
+	assert := assert.New(t)
+
+	db := backend.NewMemDatabase()
+	sv := NewStoreView(uint64(1), common.Hash{}, db)
+
+	contractAddr := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	otherAddr := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	missingAddr := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	contract := types.NewAccount(contractAddr)
+	contract.Balance = types.NewCoins(0, 1000)
+	sv.SetAccount(contractAddr, contract)
+	other := types.NewAccount(otherAddr)
+	other.Balance = types.NewCoins(10, 20)
+	sv.SetAccount(otherAddr, other)
+
+	slot1 := common.BigToHash(big.NewInt(1))
+	slot2 := common.BigToHash(big.NewInt(2))
+	emptySlot := common.BigToHash(big.NewInt(3))
+	sv.SetState(contractAddr, slot1, common.BigToHash(big.NewInt(12345)))
+	sv.SetState(contractAddr, slot2, common.HexToHash("0xabcd"))
+
+	stateRoot := sv.Save()
+	header := &core.BlockHeader{StateHash: stateRoot}
+
+	sv = NewStoreView(uint64(1), stateRoot, db)
+	accountProof, err := sv.ProveAccount(contractAddr, []common.Hash{slot1, slot2, emptySlot})
+	assert.Nil(err)
+	assert.NotNil(accountProof.Account)
+	assert.Equal(common.BigToHash(big.NewInt(12345)), accountProof.Storage[0].Value)
+	assert.Equal(common.HexToHash("0xabcd"), accountProof.Storage[1].Value)
+	assert.Equal(common.Hash{}, accountProof.Storage[2].Value)
+	assert.Nil(VerifyAccountProof(header, accountProof))
+
+	// Tampered storage value
+	accountProof.Storage[1].Value = common.HexToHash("0xabce")
+	assert.NotNil(VerifyAccountProof(header, accountProof))
+	accountProof.Storage[1].Value = common.HexToHash("0xabcd")
+
+	// Tampered account
+	accountProof.Account.Balance = types.NewCoins(0, 1001)
+	assert.NotNil(VerifyAccountProof(header, accountProof))
+
+	// Wrong state root
+	assert.NotNil(VerifyAccountProof(&core.BlockHeader{StateHash: common.HexToHash("0x1234")}, accountProof))
+
+	// Proof of absence
+	absenceProof, err := sv.ProveAccount(missingAddr, []common.Hash{slot1})
+	assert.Nil(err)
+	assert.Nil(absenceProof.Account)
+	assert.Nil(VerifyAccountProof(header, absenceProof))
+
+	absenceProof.Account = other
+	assert.NotNil(VerifyAccountProof(header, absenceProof))
+}
@@ -77,6 +77,28 @@ func (s *LedgerState) ResetState(block *core.Block) result.Result {
 	return result.OK
 }
 
+// ResetStateWithView resets the ledger state to a copy of the given view, which may contain
+// uncommitted changes. The checked and screened views are copies of the view as well.
+func (s *LedgerState) ResetStateWithView(block *core.Block, view *StoreView) result.Result {
+	s.parentBlock = block
+
+	var err error
+	s.delivered, err = view.Copy()
+	if err != nil {
+		return result.Error(fmt.Sprintf("Failed to copy to the delivered view: %v", err))
+	}
+	s.checked, err = view.Copy()
+	if err != nil {
+		return result.Error(fmt.Sprintf("Failed to copy to the checked view: %v", err))
+	}
+	s.screened, err = view.Copy()
+	if err != nil {
+		return result.Error(fmt.Sprintf("Failed to copy to the screened view: %v", err))
+	}
+
+	return result.OK
+}
+
 // Finalize updates the finalized view.
 func (s *LedgerState) Finalize(height uint64, stateRootHash common.Hash) result.Result {
 	storeview := NewStoreView(height, stateRootHash, s.db)
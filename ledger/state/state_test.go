@@ -14,12 +14,16 @@ import (
 	"github.com/thetatoken/theta/store/database/backend"
 )
 
+type noopTagger struct{}
+
+func (t *noopTagger) Tag(height uint64, root common.Hash) {}
+
 func TestLedgerStateBasics(t *testing.T) {
 	assert := assert.New(t)
 
 	chainID := "testchain"
 	db := backend.NewMemDatabase()
-	ls := NewLedgerState(chainID, db)
+	ls := NewLedgerState(chainID, db, &noopTagger{})
 
 	initHeight := uint64(127)
 	initRootHash := common.Hash{}
@@ -66,7 +70,7 @@ func TestLedgerStateAccountCommit(t *testing.T) {
 
 	chainID := "testchain"
 	db := backend.NewMemDatabase()
-	ls := NewLedgerState(chainID, db)
+	ls := NewLedgerState(chainID, db, &noopTagger{})
 
 	initHeight := uint64(127)
 	initRootHash := common.Hash{}
@@ -130,7 +134,7 @@ func TestLedgerStateSplitRuleCommit(t *testing.T) {
 
 	chainID := "testchain"
 	db := backend.NewMemDatabase()
-	ls := NewLedgerState(chainID, db)
+	ls := NewLedgerState(chainID, db, &noopTagger{})
 
 	initHeight := uint64(127)
 	initRootHash := common.Hash{}
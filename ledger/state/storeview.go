@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"math/big"
+	"sort"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/thetatoken/theta/common"
@@ -30,6 +31,18 @@ type StoreView struct {
 	refund                      uint64                 // Gas refund during smart contract execution
 	logs                        []*types.Log           // Temporary store of events during smart contract execution
 	balanceChanges              []*types.BalanceChange // Temporary store of balance changes during smart contract execution
+
+	originalBalances map[common.Address]types.Coins // Balances of the accounts before their first update, only tracked for simulations
+	accesses         *AccessSet                     // Keys accessed since TrackAccesses was called, only tracked for parallel tx execution
+
+	storageTries   map[common.Hash]*treestore.TreeStore // Account storage tries committed in memory only, by their roots, see DeferStorageCommits
+	storageCommits []storageCommit                      // Storage trie commits deferred until the writes of the view are applied
+}
+
+// storageCommit is an account storage trie commit deferred by a view
+type storageCommit struct {
+	tree *treestore.TreeStore
+	root common.Hash
 }
 
 // NewStoreView creates an instance of the StoreView
@@ -97,6 +110,9 @@ func (sv *StoreView) Save() common.Hash {
 
 // Get returns the value corresponding to the key
 func (sv *StoreView) Get(key common.Bytes) common.Bytes {
+	if sv.accesses != nil {
+		sv.accesses.addRead(key)
+	}
 	value := sv.store.Get(key)
 	return value
 }
@@ -104,6 +120,9 @@ func (sv *StoreView) Get(key common.Bytes) common.Bytes {
 // Traverse traverses the trie and calls cb callback func on every key/value pair
 // with key having prefix
 func (sv *StoreView) Traverse(prefix common.Bytes, cb func(k, v common.Bytes) bool) bool {
+	if sv.accesses != nil {
+		sv.accesses.addReadPrefix(prefix)
+	}
 	return sv.store.Traverse(prefix, cb)
 }
 
@@ -113,14 +132,65 @@ func (sv *StoreView) ProveVCP(vcpKey []byte, vp *core.VCPProof) error {
 
 // Delete removes the value corresponding to the key
 func (sv *StoreView) Delete(key common.Bytes) {
+	if sv.accesses != nil {
+		sv.accesses.addWrite(key)
+	}
 	sv.store.Delete(key)
 }
 
 // Set returns the value corresponding to the key
 func (sv *StoreView) Set(key common.Bytes, value common.Bytes) {
+	if sv.accesses != nil {
+		sv.accesses.addWrite(key)
+	}
 	sv.store.Set(key, value)
 }
 
+// TrackAccesses starts recording the keys read and written through the StoreView,
+// discarding the keys recorded so far
+func (sv *StoreView) TrackAccesses() {
+	sv.accesses = NewAccessSet()
+}
+
+// Accesses returns the keys recorded since TrackAccesses was called, or nil if the accesses
+// are not tracked
+func (sv *StoreView) Accesses() *AccessSet {
+	return sv.accesses
+}
+
+// StopTrackingAccesses stops recording the accessed keys, and returns the keys recorded
+// since TrackAccesses was called
+func (sv *StoreView) StopTrackingAccesses() *AccessSet {
+	accesses := sv.accesses
+	sv.accesses = nil
+	return accesses
+}
+
+// DeferStorageCommits makes the StoreView commit the account storage tries in memory only.
+// The deferred commits are written to the database when the writes of the view are applied
+// to another view with ApplyWrites, so that the speculative executions whose results are
+// discarded leave neither trie nodes nor reference counts behind in the database.
+func (sv *StoreView) DeferStorageCommits() {
+	sv.storageTries = make(map[common.Hash]*treestore.TreeStore)
+}
+
+// ApplyWrites copies the current values of the given keys from the source view, deleting
+// the keys which do not exist in the source view. The storage commits deferred by the source
+// view are written to the database first.
+func (sv *StoreView) ApplyWrites(source *StoreView, keys []common.Bytes) {
+	if err := source.flushStorageCommits(); err != nil {
+		log.Panic(err)
+	}
+	for _, key := range keys {
+		value := source.store.Get(key)
+		if len(value) == 0 {
+			sv.Delete(key)
+		} else {
+			sv.Set(key, value)
+		}
+	}
+}
+
 // AddSlashIntent adds slashIntent
 func (sv *StoreView) AddSlashIntent(slashIntent types.SlashIntent) {
 	sv.slashIntents = append(sv.slashIntents, slashIntent)
@@ -181,6 +251,8 @@ func (sv *StoreView) setAccountWithoutStateTreeRefCountUpdate(addr common.Addres
 }
 
 func (sv *StoreView) setAccount(addr common.Address, acc *types.Account, updateRefCountForAccountStateTree bool) {
+	sv.recordOriginalBalance(addr)
+
 	accBytes, err := types.ToBytes(acc)
 	if err != nil {
 		log.Panicf("Error writing account %v error: %v",
@@ -197,7 +269,7 @@ func (sv *StoreView) setAccount(addr common.Address, acc *types.Account, updateR
 	}
 
 	tree := sv.getAccountStorage(acc)
-	_, err = tree.Commit() // update the reference count of the account state trie root
+	_, err = sv.commitAccountStorage(tree) // update the reference count of the account state trie root
 	if err != nil {
 		log.Panic(err)
 	}
@@ -205,6 +277,7 @@ func (sv *StoreView) setAccount(addr common.Address, acc *types.Account, updateR
 
 // DeleteAccount deletes an account.
 func (sv *StoreView) DeleteAccount(addr common.Address) {
+	sv.recordOriginalBalance(addr)
 	sv.Delete(AccountKey(addr))
 }
 
@@ -261,6 +334,9 @@ func (sv *StoreView) SetSplitRule(resourceID string, splitRule *types.SplitRule)
 // DeleteSplitRule deletes a split rule.
 func (sv *StoreView) DeleteSplitRule(resourceID string) bool {
 	key := SplitRuleKey(resourceID)
+	if sv.accesses != nil {
+		sv.accesses.addWrite(key)
+	}
 	deleted := sv.store.Delete(key)
 	return deleted
 }
@@ -268,6 +344,9 @@ func (sv *StoreView) DeleteSplitRule(resourceID string) bool {
 // DeleteExpiredSplitRules deletes a split rule.
 func (sv *StoreView) DeleteExpiredSplitRules(currentBlockHeight uint64) bool {
 	prefix := SplitRuleKeyPrefix()
+	if sv.accesses != nil {
+		sv.accesses.addReadPrefix(prefix)
+	}
 
 	expiredKeys := []common.Bytes{}
 	sv.store.Traverse(prefix, func(key, value common.Bytes) bool {
@@ -285,6 +364,9 @@ func (sv *StoreView) DeleteExpiredSplitRules(currentBlockHeight uint64) bool {
 	})
 
 	for _, key := range expiredKeys {
+		if sv.accesses != nil {
+			sv.accesses.addWrite(key)
+		}
 		deleted := sv.store.Delete(key)
 		if !deleted {
 			logger.Errorf("Failed to delete expired split rules")
@@ -418,6 +500,153 @@ func (sv *StoreView) SetTotalEENStake(amount *big.Int) {
 	sv.Set(EliteEdgeNodesTotalActiveStakeKey(), amount.Bytes())
 }
 
+// GetStakeRedelegationHeight returns the height of the last stake redelegation of the source,
+// and false if the source has never redelegated its stake
+func (sv *StoreView) GetStakeRedelegationHeight(source common.Address) (uint64, bool) {
+	data := sv.Get(StakeRedelegationHeightKey(source))
+	if data == nil || len(data) == 0 {
+		return 0, false
+	}
+	var height uint64
+	err := types.FromBytes(data, &height)
+	if err != nil {
+		log.Panicf("Error reading stake redelegation height %X, error: %v",
+			data, err.Error())
+	}
+	return height, true
+}
+
+// SetStakeRedelegationHeight saves the height of the last stake redelegation of the source
+func (sv *StoreView) SetStakeRedelegationHeight(source common.Address, height uint64) {
+	heightBytes, err := types.ToBytes(height)
+	if err != nil {
+		log.Panicf("Error writing stake redelegation height %v, error: %v",
+			height, err.Error())
+	}
+	sv.Set(StakeRedelegationHeightKey(source), heightBytes)
+}
+
+// GetValidatorJailReleaseHeight returns the height the validator is released from jail at,
+// and false if the validator has never been jailed
+func (sv *StoreView) GetValidatorJailReleaseHeight(holder common.Address) (uint64, bool) {
+	data := sv.Get(ValidatorJailKey(holder))
+	if data == nil || len(data) == 0 {
+		return 0, false
+	}
+	var height uint64
+	err := types.FromBytes(data, &height)
+	if err != nil {
+		log.Panicf("Error reading validator jail release height %X, error: %v",
+			data, err.Error())
+	}
+	return height, true
+}
+
+// SetValidatorJailReleaseHeight saves the height the validator is released from jail at
+func (sv *StoreView) SetValidatorJailReleaseHeight(holder common.Address, height uint64) {
+	heightBytes, err := types.ToBytes(height)
+	if err != nil {
+		log.Panicf("Error writing validator jail release height %v, error: %v",
+			height, err.Error())
+	}
+	sv.Set(ValidatorJailKey(holder), heightBytes)
+}
+
+// IsValidatorJailed returns whether the validator is in jail at the given height
+func (sv *StoreView) IsValidatorJailed(holder common.Address, height uint64) bool {
+	releaseHeight, ok := sv.GetValidatorJailReleaseHeight(holder)
+	return ok && height < releaseHeight
+}
+
+// GetGovernanceParam returns the value of the governable protocol parameter set by the
+// governance proposals, and false if no proposal has changed it yet
+func (sv *StoreView) GetGovernanceParam(name string) (*big.Int, bool) {
+	data := sv.Get(GovernanceParamKey(name))
+	if data == nil || len(data) == 0 {
+		return nil, false
+	}
+	value := new(big.Int)
+	err := types.FromBytes(data, value)
+	if err != nil {
+		log.Panicf("Error reading governance parameter %v %X, error: %v",
+			name, data, err.Error())
+	}
+	return value, true
+}
+
+// SetGovernanceParam saves the value of the governable protocol parameter
+func (sv *StoreView) SetGovernanceParam(name string, value *big.Int) {
+	valueBytes, err := types.ToBytes(value)
+	if err != nil {
+		log.Panicf("Error writing governance parameter %v %v, error: %v",
+			name, value, err.Error())
+	}
+	sv.Set(GovernanceParamKey(name), valueBytes)
+}
+
+// GetProtocolParam returns the active value of the governable protocol parameter at the given
+// block height of the given chain, i.e. the value set by the governance proposals if any, or
+// the hard-coded value
+func (sv *StoreView) GetProtocolParam(chainID string, name string, blockHeight uint64) *big.Int {
+	if blockHeight >= common.GetForkSchedule(chainID).EnableGovernance {
+		if value, ok := sv.GetGovernanceParam(name); ok {
+			return value
+		}
+	}
+	return types.GetDefaultGovernanceParam(chainID, name, blockHeight)
+}
+
+// GetGovernanceProposal returns the governance proposal with the given ID, or nil if it does not exist
+func (sv *StoreView) GetGovernanceProposal(id uint64) *types.GovernanceProposal {
+	data := sv.Get(GovernanceProposalKey(id))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	proposal := &types.GovernanceProposal{}
+	err := types.FromBytes(data, proposal)
+	if err != nil {
+		log.Panicf("Error reading governance proposal %X, error: %v",
+			data, err.Error())
+	}
+	return proposal
+}
+
+// SetGovernanceProposal saves the governance proposal
+func (sv *StoreView) SetGovernanceProposal(proposal *types.GovernanceProposal) {
+	proposalBytes, err := types.ToBytes(proposal)
+	if err != nil {
+		log.Panicf("Error writing governance proposal %v, error: %v",
+			proposal, err.Error())
+	}
+	sv.Set(GovernanceProposalKey(proposal.ID), proposalBytes)
+}
+
+// GetGovernanceProposalCount returns the number of governance proposals submitted so far,
+// which is also the ID of the next proposal
+func (sv *StoreView) GetGovernanceProposalCount() uint64 {
+	data := sv.Get(GovernanceProposalCountKey())
+	if data == nil || len(data) == 0 {
+		return 0
+	}
+	var count uint64
+	err := types.FromBytes(data, &count)
+	if err != nil {
+		log.Panicf("Error reading governance proposal count %X, error: %v",
+			data, err.Error())
+	}
+	return count
+}
+
+// SetGovernanceProposalCount saves the number of governance proposals submitted so far
+func (sv *StoreView) SetGovernanceProposalCount(count uint64) {
+	countBytes, err := types.ToBytes(count)
+	if err != nil {
+		log.Panicf("Error writing governance proposal count %v, error: %v",
+			count, err.Error())
+	}
+	sv.Set(GovernanceProposalCountKey(), countBytes)
+}
+
 func (sv *StoreView) GetStore() *treestore.TreeStore {
 	return sv.store
 }
@@ -442,6 +671,65 @@ func (sv *StoreView) PopBalanceChanges() []*types.BalanceChange {
 	return ret
 }
 
+// TrackBalanceChanges makes the view remember the balances of the accounts before they are
+// first updated, so that the net balance changes can be retrieved with GetNetBalanceChanges.
+// Unlike PopBalanceChanges, it covers all the transaction types, including the fees charged.
+// Should only be used on views created for simulations.
+func (sv *StoreView) TrackBalanceChanges() {
+	sv.originalBalances = make(map[common.Address]types.Coins)
+}
+
+func (sv *StoreView) recordOriginalBalance(addr common.Address) {
+	if sv.originalBalances == nil {
+		return
+	}
+	if _, ok := sv.originalBalances[addr]; ok {
+		return
+	}
+	balance := types.NewCoins(0, 0)
+	if account := sv.GetAccount(addr); account != nil {
+		balance = account.Balance.NoNil()
+	}
+	sv.originalBalances[addr] = balance
+}
+
+// GetNetBalanceChanges returns the net balance changes of the accounts updated since
+// TrackBalanceChanges was called, sorted by address
+func (sv *StoreView) GetNetBalanceChanges() []*types.BalanceChange {
+	addrs := []common.Address{}
+	for addr := range sv.originalBalances {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+
+	balanceChanges := []*types.BalanceChange{}
+	for _, addr := range addrs {
+		original := sv.originalBalances[addr]
+		current := types.NewCoins(0, 0)
+		if account := sv.GetAccount(addr); account != nil {
+			current = account.Balance.NoNil()
+		}
+		deltas := []*big.Int{
+			new(big.Int).Sub(current.ThetaWei, original.ThetaWei), // token type 0
+			new(big.Int).Sub(current.TFuelWei, original.TFuelWei), // token type 1
+		}
+		for tokenType, delta := range deltas {
+			if delta.Sign() == 0 {
+				continue
+			}
+			balanceChanges = append(balanceChanges, &types.BalanceChange{
+				Address:    addr,
+				TokenType:  uint(tokenType),
+				IsNegative: delta.Sign() < 0,
+				Delta:      new(big.Int).Abs(delta),
+			})
+		}
+	}
+	return balanceChanges
+}
+
 //
 // ---------- Implement vm.StateDB interface -----------
 //
@@ -665,9 +953,46 @@ func (sv *StoreView) GetCommittedState(addr common.Address, key common.Hash) com
 }
 
 func (sv *StoreView) getAccountStorage(account *types.Account) *treestore.TreeStore {
+	if tree, ok := sv.storageTries[account.Root]; ok {
+		// The storage trie is only committed in memory
+		reverted, err := tree.Revert(account.Root)
+		if err != nil {
+			log.Panic(err)
+		}
+		return reverted
+	}
 	return treestore.NewTreeStore(account.Root, sv.store.GetDB())
 }
 
+// commitAccountStorage commits the account storage trie and returns its root, which also updates
+// the reference counts of the trie nodes. If the view defers the storage commits, the trie is
+// committed in memory, and the commit to the database is recorded to be replayed later.
+func (sv *StoreView) commitAccountStorage(tree *treestore.TreeStore) (common.Hash, error) {
+	if sv.storageTries == nil {
+		return tree.Commit()
+	}
+	root, err := tree.Trie.Commit(nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	sv.storageTries[root] = tree
+	sv.storageCommits = append(sv.storageCommits, storageCommit{tree: tree, root: root})
+	return root, nil
+}
+
+// flushStorageCommits writes the deferred storage trie commits to the database, in the order they
+// were made, which results in the same trie nodes and reference counts as committing them directly.
+func (sv *StoreView) flushStorageCommits() error {
+	for _, commit := range sv.storageCommits {
+		if err := commit.tree.Trie.GetDB().Commit(commit.root, true); err != nil {
+			return err
+		}
+	}
+	sv.storageTries = nil
+	sv.storageCommits = nil
+	return nil
+}
+
 func (sv *StoreView) GetState(addr common.Address, key common.Hash) common.Hash {
 	account := sv.GetAccount(addr)
 	if account == nil {
@@ -697,7 +1022,7 @@ func (sv *StoreView) SetState(addr common.Address, key, val common.Hash) {
 	tree := sv.getAccountStorage(account)
 	if (val == common.Hash{}) {
 		tree.TryDelete(key[:])
-		root, err := tree.Commit()
+		root, err := sv.commitAccountStorage(tree)
 		if err != nil {
 			log.Panic(err)
 		}
@@ -709,7 +1034,7 @@ func (sv *StoreView) SetState(addr common.Address, key, val common.Hash) {
 	// Encoding []byte cannot fail, ok to ignore the error.
 	v, _ := rlp.EncodeToBytes(bytes.TrimLeft(val[:], "\x00"))
 	tree.TryUpdate(key[:], v)
-	root, err := tree.Commit()
+	root, err := sv.commitAccountStorage(tree)
 	if err != nil {
 		log.Panic(err)
 	}
@@ -109,6 +109,59 @@ func TestStoreViewAccountAccess(t *testing.T) {
 	log.Infof("Balance: %v\n", accRetrieved.Balance)
 }
 
+func TestStoreViewDeferStorageCommits(t *testing.T) {
+	assert := assert.New(t)
+
+	addr := common.HexToAddress("0x1234")
+	key1, key2 := common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(2))
+	val1, val2 := common.BigToHash(big.NewInt(10)), common.BigToHash(big.NewInt(20))
+
+	// The reference view commits the storage directly
+	refDB := backend.NewMemDatabase()
+	refView := NewStoreView(uint64(1), common.Hash{}, refDB)
+	refView.SetAccount(addr, types.NewAccount(addr))
+	refView.SetState(addr, key1, val1)
+	refView.SetState(addr, key2, val2)
+	storageRoot := refView.GetAccount(addr).Root
+
+	db := backend.NewMemDatabase()
+	sv := NewStoreView(uint64(1), common.Hash{}, db)
+	sv.SetAccount(addr, types.NewAccount(addr))
+
+	// A discarded overlay leaves nothing in the database
+	discarded, err := sv.Copy()
+	assert.Nil(err)
+	discarded.DeferStorageCommits()
+	discarded.SetState(addr, key1, val2)
+	discardedRoot := discarded.GetAccount(addr).Root
+	has, err := db.Has(discardedRoot[:])
+	assert.Nil(err)
+	assert.False(has)
+
+	overlay, err := sv.Copy()
+	assert.Nil(err)
+	overlay.DeferStorageCommits()
+	overlay.TrackAccesses()
+	overlay.SetState(addr, key1, val1)
+	overlay.SetState(addr, key2, val2)
+	assert.Equal(storageRoot, overlay.GetAccount(addr).Root)
+	assert.Equal(val1, overlay.GetState(addr, key1))
+	has, err = db.Has(storageRoot[:])
+	assert.Nil(err)
+	assert.False(has)
+
+	// The storage is written with the same reference counts once the writes are applied
+	sv.ApplyWrites(overlay, overlay.StopTrackingAccesses().WrittenKeys())
+	assert.Equal(refView.Hash(), sv.Hash())
+	assert.Equal(val1, sv.GetState(addr, key1))
+	assert.Equal(val2, sv.GetState(addr, key2))
+	refCount, err := refDB.CountReference(storageRoot[:])
+	assert.Nil(err)
+	count, err := db.CountReference(storageRoot[:])
+	assert.Nil(err)
+	assert.Equal(refCount, count)
+}
+
 func TestStoreViewSplitRuleAccess(t *testing.T) {
 	assert := assert.New(t)
 
@@ -280,18 +333,18 @@ func TestGetAndUpdateValidatorCandidatePool(t *testing.T) {
 
 	vcp := &core.ValidatorCandidatePool{}
 
-	assert.Nil(vcp.DepositStake(sourceAddr1, holderAddr1, stake1Amount1))
-	assert.Nil(vcp.DepositStake(sourceAddr2, holderAddr1, stake2Amount1))
-	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr1, stake3Amount2))
+	assert.Nil(vcp.DepositStake("test_chain_id", sourceAddr1, holderAddr1, stake1Amount1, 0))
+	assert.Nil(vcp.DepositStake("test_chain_id", sourceAddr2, holderAddr1, stake2Amount1, 0))
+	assert.Nil(vcp.DepositStake("test_chain_id", sourceAddr3, holderAddr1, stake3Amount2, 0))
 
-	assert.Nil(vcp.DepositStake(sourceAddr1, holderAddr2, stake1Amount2))
-	assert.Nil(vcp.DepositStake(sourceAddr2, holderAddr2, stake2Amount2))
-	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr2, stake3Amount2))
+	assert.Nil(vcp.DepositStake("test_chain_id", sourceAddr1, holderAddr2, stake1Amount2, 0))
+	assert.Nil(vcp.DepositStake("test_chain_id", sourceAddr2, holderAddr2, stake2Amount2, 0))
+	assert.Nil(vcp.DepositStake("test_chain_id", sourceAddr3, holderAddr2, stake3Amount2, 0))
 
-	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr3, stake3Amount1))
+	assert.Nil(vcp.DepositStake("test_chain_id", sourceAddr3, holderAddr3, stake3Amount1, 0))
 
-	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr4, stake3Amount3))
-	assert.Nil(vcp.DepositStake(sourceAddr4, holderAddr4, stake4Amount1))
+	assert.Nil(vcp.DepositStake("test_chain_id", sourceAddr3, holderAddr4, stake3Amount3, 0))
+	assert.Nil(vcp.DepositStake("test_chain_id", sourceAddr4, holderAddr4, stake4Amount1, 0))
 
 	db := backend.NewMemDatabase()
 	sv := NewStoreView(uint64(1), common.Hash{}, db)
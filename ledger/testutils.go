@@ -54,7 +54,11 @@ func newExecSim(chainID string, db database.Database, snapshot mockSnapshot, val
 	dispatcher := dp.NewDispatcher(messenger, nil)
 
 	valMgr := consensus.NewFixedValidatorManager()
-	consensus := consensus.NewConsensusEngine(valPrivAcc.PrivKey, store, chain, dispatcher, valMgr)
+	signer, err := consensus.NewLocalSigner(valPrivAcc.PrivKey, nil)
+	if err != nil {
+		panic(err)
+	}
+	consensus := consensus.NewConsensusEngine(signer, store, chain, dispatcher, valMgr)
 	valMgr.SetConsensusEngine(consensus)
 
 	mempool := mp.CreateMempool(dispatcher, consensus)
@@ -69,6 +73,9 @@ func newExecSim(chainID string, db database.Database, snapshot mockSnapshot, val
 		mempool:   mempool,
 		mu:        &sync.RWMutex{},
 		state:     ledgerState,
+
+		evidenceMu: &sync.Mutex{},
+		evidences:  make(map[common.Hash]*core.EquivocationEvidence),
 	}
 	executor := exec.NewExecutor(db, chain, ledgerState, consensus, valMgr, ledger)
 	ledger.SetExecutor(executor)
@@ -149,10 +156,10 @@ func genSimSnapshot(chainID string, db database.Database) (snapshot mockSnapshot
 	stakeAmount4 := new(big.Int).Mul(new(big.Int).SetUint64(4), core.MinValidatorStakeDeposit)
 
 	vcp := &core.ValidatorCandidatePool{}
-	vcp.DepositStake(src1Acc.Address, val1Acc.Address, stakeAmount1, 0)
-	vcp.DepositStake(src2Acc.Address, val2Acc.Address, stakeAmount2, 0)
-	vcp.DepositStake(src3Acc.Address, val3Acc.Address, stakeAmount3, 0)
-	vcp.DepositStake(src4Acc.Address, val4Acc.Address, stakeAmount4, 0)
+	vcp.DepositStake(chainID, src1Acc.Address, val1Acc.Address, stakeAmount1, 0)
+	vcp.DepositStake(chainID, src2Acc.Address, val2Acc.Address, stakeAmount2, 0)
+	vcp.DepositStake(chainID, src3Acc.Address, val3Acc.Address, stakeAmount3, 0)
+	vcp.DepositStake(chainID, src4Acc.Address, val4Acc.Address, stakeAmount4, 0)
 
 	sv := state.NewStoreView(initHeight, common.Hash{}, db)
 	sv.UpdateValidatorCandidatePool(vcp)
@@ -0,0 +1,77 @@
+package ledger
+
+import (
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	exec "github.com/thetatoken/theta/ledger/execution"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/vm"
+)
+
+// TracerProvider returns the tracer to be attached to the execution of the given
+// transaction of a replayed block, or nil if the transaction should not be traced
+type TracerProvider func(txIndex int, tx types.Tx) vm.Tracer
+
+// TxReplayResult is the outcome of a transaction replayed by TraceBlockTxs
+type TxReplayResult struct {
+	GasUsed uint64
+	EvmRet  common.Bytes
+	EvmErr  string // empty if the EVM execution succeeded, as in the tx receipts
+}
+
+// TraceBlockTxs replays the first numTxs transactions of the given block on top of the state
+// of its parent block, attaching the tracers returned by getTracer. The transactions are
+// executed on the delivered view as in ApplyBlockTxs, but the replay happens on a separate
+// ledger state, so it neither modifies the ledger state nor records tx receipts.
+func (ledger *Ledger) TraceBlockTxs(block *core.Block, numTxs int, getTracer TracerProvider) ([]TxReplayResult, error) {
+	if numTxs > len(block.Txs) {
+		return nil, fmt.Errorf("block %v only has %v transactions", block.Hash().Hex(), len(block.Txs))
+	}
+
+	extParentBlock, err := ledger.chain.FindBlock(block.Parent)
+	if extParentBlock == nil || err != nil {
+		return nil, fmt.Errorf("failed to find the parent block %v: %v", block.Parent.Hex(), err)
+	}
+	parentBlock := extParentBlock.Block
+
+	replayState := st.NewLedgerState(ledger.state.GetChainID(), ledger.db, nil)
+	if res := replayState.ResetState(parentBlock); res.IsError() {
+		return nil, fmt.Errorf("the state of block %v is not available, it might have been pruned", parentBlock.Hash().Hex())
+	}
+
+	executor := exec.NewExecutor(ledger.db, ledger.chain, replayState, ledger.consensus, ledger.valMgr, ledger)
+	// The block has already been validated. Besides, some of the sanity checks read the block
+	// currently applied by the ledger, which is not the replayed block
+	executor.SetSkipSanityCheck(true)
+	executor.SetSkipTxReceipts(true) // the receipts were recorded when the block was applied
+
+	results := make([]TxReplayResult, 0, numTxs)
+	for idx, rawTx := range block.Txs[:numTxs] {
+		tx, err := types.TxFromBytes(rawTx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse transaction %v of block %v: %v", idx, block.Hash().Hex(), err)
+		}
+
+		executor.SetTracer(getTracer(idx, tx))
+
+		_, res := executor.ExecuteTx(tx)
+		if res.IsError() {
+			return nil, fmt.Errorf("failed to replay transaction %v of block %v: %v", idx, block.Hash().Hex(), res.Message)
+		}
+
+		evmRet, evmErr := exec.GetEvmResult(res)
+		result := TxReplayResult{
+			GasUsed: exec.GetGasUsed(res),
+			EvmRet:  evmRet,
+		}
+		if evmErr != nil {
+			result.EvmErr = evmErr.Error()
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
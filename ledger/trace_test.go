@@ -0,0 +1,143 @@
+package ledger
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	exec "github.com/thetatoken/theta/ledger/execution"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/vm"
+	"github.com/thetatoken/theta/store/database/backend"
+	"github.com/thetatoken/theta/store/kvstore"
+)
+
+type traceTestTagger struct{}
+
+func (t *traceTestTagger) Tag(height uint64, root common.Hash) {}
+
+func newTraceTestContractCall(chainID string, sender types.PrivAccount, sequence uint64, contract common.Address, height uint64) common.Bytes {
+	tx := &types.SmartContractTx{
+		From: types.TxInput{
+			Address:  sender.Address,
+			Coins:    types.NewCoins(0, 0),
+			Sequence: sequence,
+		},
+		To:       types.TxOutput{Address: contract},
+		GasLimit: 100000,
+		GasPrice: types.GetMinimumGasPrice(chainID, height),
+	}
+	sig, err := sender.PrivKey.Sign(tx.SignBytes(chainID))
+	if err != nil {
+		panic(err)
+	}
+	tx.SetSignature(sender.Address, sig)
+	raw, err := types.TxToBytes(tx)
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+func TestTraceBlockTxs(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	height := testForks.EnableSmartContract + 10
+
+	// ApplyBlockTxs commits the ledger state, which requires a tagger
+	ledger.state = st.NewLedgerState(chainID, ledger.db, &traceTestTagger{})
+	resetTestLedgerToHeight(ledger, height)
+
+	// PUSH1 1 PUSH1 0 SSTORE PUSH1 42 PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	returnContract := common.HexToAddress("0x2000")
+	// PUSH1 7 PUSH1 0 MSTORE PUSH1 32 PUSH1 0 REVERT
+	revertContract := common.HexToAddress("0x3000")
+	sender := types.MakeAcc("trace_sender")
+
+	view := ledger.state.Delivered()
+	setTestAccount(view, sender, types.NewCoins(0, 5e18))
+	for address, code := range map[common.Address]string{
+		returnContract: "6001600055602a60005260206000f3",
+		revertContract: "600760005260206000fd",
+	} {
+		raw, err := hex.DecodeString(code)
+		require.Nil(err)
+		view.CreateAccount(address)
+		view.SetCode(address, raw)
+	}
+	parent := &core.Block{
+		BlockHeader: &core.BlockHeader{
+			ChainID:   chainID,
+			Height:    height,
+			StateHash: view.Save(),
+		},
+	}
+	ledger.ResetState(parent)
+
+	// The receipts are recorded by the chain when the block is applied
+	ledger.chain = blockchain.NewChain(chainID, kvstore.NewKVStore(backend.NewMemDatabase()), parent)
+	ledger.SetExecutor(exec.NewExecutor(ledger.db, ledger.chain, ledger.state, ledger.consensus, ledger.valMgr, ledger))
+
+	rawTxs := []common.Bytes{
+		newTraceTestContractCall(chainID, sender, 1, returnContract, height+1),
+		newTraceTestContractCall(chainID, sender, 2, revertContract, height+1),
+	}
+	for _, rawTx := range rawTxs {
+		tx, err := types.TxFromBytes(rawTx)
+		require.Nil(err)
+		_, res := ledger.executor.CheckTx(tx)
+		require.True(res.IsOK(), res.Message)
+	}
+	ledger.handleDelayedStateUpdates(ledger.state.Checked())
+
+	block := &core.Block{
+		BlockHeader: &core.BlockHeader{
+			ChainID:   chainID,
+			Height:    height + 1,
+			Parent:    parent.Hash(),
+			StateHash: ledger.state.Checked().Hash(),
+		},
+		Txs: rawTxs,
+	}
+	res := ledger.ApplyBlockTxs(block)
+	require.True(res.IsOK(), res.Message)
+	stateRoot := ledger.state.Delivered().Hash()
+
+	tracers := []*vm.StructLogger{}
+	replays, err := ledger.TraceBlockTxs(block, len(rawTxs), func(txIndex int, tx types.Tx) vm.Tracer {
+		tracer := vm.NewStructLogger(nil)
+		tracers = append(tracers, tracer)
+		return tracer
+	})
+	require.Nil(err)
+	require.Equal(len(rawTxs), len(replays))
+
+	for idx, replay := range replays {
+		receipt, found := ledger.chain.FindTxReceiptByHash(block.Hash(), crypto.Keccak256Hash(rawTxs[idx]))
+		require.True(found)
+		assert.True(replay.GasUsed > 0)
+		assert.Equal(receipt.GasUsed, replay.GasUsed)
+		assert.Equal(receipt.EvmRet, replay.EvmRet)
+		assert.Equal(receipt.EvmErr, replay.EvmErr)
+		assert.NotEmpty(tracers[idx].StructLogs())
+	}
+	assert.Equal(common.Bytes(common.BigToHash(big.NewInt(42)).Bytes()), replays[0].EvmRet)
+	assert.Equal("", replays[0].EvmErr)
+	assert.Equal(common.Bytes(common.BigToHash(big.NewInt(7)).Bytes()), replays[1].EvmRet)
+	assert.Equal("evm: execution reverted", replays[1].EvmErr)
+
+	// The replay does not modify the ledger state
+	assert.Equal(stateRoot, ledger.state.Delivered().Hash())
+
+	_, err = ledger.TraceBlockTxs(block, len(rawTxs)+1, func(txIndex int, tx types.Tx) vm.Tracer { return nil })
+	assert.NotNil(err)
+}
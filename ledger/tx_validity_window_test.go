@@ -0,0 +1,151 @@
+package ledger
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/crypto/secp256k1"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func newWindowSendTx(chainID string, sender, recipient types.PrivAccount, validFromHeight, validUntilHeight uint64) *types.SendTx {
+	fee := types.NewCoins(0, 0)
+	fee.TFuelWei = types.GetSendTxMinimumTransactionFeeTFuelWei(chainID, 2, testForks.EnableTxValidityWindow)
+	tx := &types.SendTx{
+		Fee: fee,
+		Inputs: []types.TxInput{{
+			Address:  sender.Address,
+			Coins:    types.NewCoins(100, 0).Plus(fee),
+			Sequence: 1,
+		}},
+		Outputs: []types.TxOutput{{
+			Address: recipient.Address,
+			Coins:   types.NewCoins(100, 0),
+		}},
+	}
+	tx.SetValidityWindow(validFromHeight, validUntilHeight)
+	sig, err := sender.PrivKey.Sign(tx.SignBytes(chainID))
+	if err != nil {
+		panic(err)
+	}
+	tx.SetSignature(sender.Address, sig)
+	return tx
+}
+
+// ethSign signs the smart contract tx the way an ETH wallet does, i.e. the signature covers the
+// ETH signing hash of the tx instead of its sign bytes
+func ethSign(chainID string, tx *types.SmartContractTx, sender types.PrivAccount, blockHeight uint64) {
+	hash := tx.EthSigningHash(chainID, blockHeight)
+	sigBytes, err := secp256k1.Sign(hash[:], sender.PrivKey.ToBytes())
+	if err != nil {
+		panic(err)
+	}
+	sigBytes[64] += 27
+	sig, err := crypto.SignatureFromBytes(sigBytes)
+	if err != nil {
+		panic(err)
+	}
+	tx.SetSignature(sender.Address, sig)
+}
+
+func toRawTx(tx types.Tx) common.Bytes {
+	raw, err := types.TxToBytes(tx)
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+func TestTxValidityWindow(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	blockHeight := testForks.EnableTxValidityWindow + 100
+	resetTestLedgerToHeight(ledger, blockHeight-1)
+
+	sender := types.MakeAcc("window_sender")
+	senderAccount := types.NewAccount(sender.Address)
+	senderAccount.Balance = types.NewCoins(1000, 1e18)
+	ledger.state.Screened().SetAccount(sender.Address, senderAccount)
+	recipient := types.MakeAcc("window_recipient")
+
+	_, res := ledger.ScreenTx(toRawTx(newWindowSendTx(chainID, sender, recipient, 0, blockHeight-1)))
+	assert.Equal(result.CodeTxExpired, res.Code)
+
+	_, res = ledger.ScreenTx(toRawTx(newWindowSendTx(chainID, sender, recipient, blockHeight+1, 0)))
+	assert.Equal(result.CodeTxNotYetValid, res.Code)
+
+	// The window is covered by the signature
+	tx := newWindowSendTx(chainID, sender, recipient, 0, blockHeight-1)
+	tx.SetValidityWindow(0, blockHeight+10)
+	_, res = ledger.ScreenTx(toRawTx(tx))
+	assert.Equal(result.CodeInvalidSignature, res.Code)
+
+	tx = newWindowSendTx(chainID, sender, recipient, blockHeight-10, blockHeight)
+	_, res = ledger.ScreenTx(toRawTx(tx))
+	require.True(res.IsOK(), res.Message)
+}
+
+func TestTxValidityWindowNotActive(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	resetTestLedgerToHeight(ledger, testForks.EnableTxValidityWindow-2)
+
+	sender := types.MakeAcc("window_sender")
+	senderAccount := types.NewAccount(sender.Address)
+	senderAccount.Balance = types.NewCoins(1000, 1e18)
+	ledger.state.Screened().SetAccount(sender.Address, senderAccount)
+	recipient := types.MakeAcc("window_recipient")
+
+	_, res := ledger.ScreenTx(toRawTx(newWindowSendTx(chainID, sender, recipient, 0, testForks.EnableTxValidityWindow+10)))
+	assert.True(res.IsError())
+
+	_, res = ledger.ScreenTx(toRawTx(newWindowSendTx(chainID, sender, recipient, 0, 0)))
+	require.True(res.IsOK(), res.Message)
+}
+
+func TestTxValidityWindowEthSignature(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID, ledger, _ := newTestLedger()
+	blockHeight := testForks.EnableTxValidityWindow + 100
+	resetTestLedgerToHeight(ledger, blockHeight-1)
+
+	sender := types.MakeAcc("window_sender")
+	recipient := types.MakeAcc("window_recipient")
+	setTestAccount(ledger.state.Checked(), sender, types.NewCoins(0, 1e18))
+
+	newTx := func() *types.SmartContractTx {
+		return &types.SmartContractTx{
+			From: types.TxInput{
+				Address:  sender.Address,
+				Coins:    types.NewCoins(0, 1000),
+				Sequence: 1,
+			},
+			To:       types.TxOutput{Address: recipient.Address},
+			GasLimit: 100000,
+			GasPrice: new(big.Int).Set(types.GetMinimumGasPrice(chainID, blockHeight)),
+		}
+	}
+
+	// The ETH signature does not cover the validity window, so the window cannot be set
+	tx := newTx()
+	tx.SetValidityWindow(0, blockHeight+10)
+	ethSign(chainID, tx, sender, blockHeight)
+	_, res := ledger.executor.CheckTx(tx)
+	assert.Equal(result.CodeInvalidSignature, res.Code)
+
+	tx = newTx()
+	ethSign(chainID, tx, sender, blockHeight)
+	_, res = ledger.executor.CheckTx(tx)
+	require.True(res.IsOK(), res.Message)
+}
@@ -58,7 +58,7 @@ func TestInvalidCoin(t *testing.T) {
 	assert.True(ret2.ThetaWei.Cmp(big.NewInt(456)) == 0)
 }
 
-func TestcCoinsRLPNil(t *testing.T) {
+func TestCoinsRLPNil(t *testing.T) {
 	assert := assert.New(t)
 
 	a := Coins{}
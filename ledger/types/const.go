@@ -38,6 +38,9 @@ const (
 
 	// MaxAccountsAffectedPerTx specifies the max number of accounts one transaction is allowed to modify to avoid spamming
 	MaxAccountsAffectedPerTx = 512
+
+	// MaxMultisigPubKeys specifies the max number of public keys of a native multisig account
+	MaxMultisigPubKeys = 16
 )
 
 const (
@@ -92,24 +95,24 @@ const (
 	ReservedFundFreezePeriodDuration uint64 = 5
 )
 
-func GetMinimumGasPrice(blockHeight uint64) *big.Int {
-	if blockHeight < common.HeightJune2021FeeAdjustment {
+func GetMinimumGasPrice(chainID string, blockHeight uint64) *big.Int {
+	if blockHeight < common.GetForkSchedule(chainID).June2021FeeAdjustment {
 		return new(big.Int).SetUint64(MinimumGasPrice)
 	}
 
 	return new(big.Int).SetUint64(MinimumGasPriceJune2021)
 }
 
-func GetMaxGasLimit(blockHeight uint64) *big.Int {
-	if blockHeight < common.HeightJune2021FeeAdjustment {
+func GetMaxGasLimit(chainID string, blockHeight uint64) *big.Int {
+	if blockHeight < common.GetForkSchedule(chainID).June2021FeeAdjustment {
 		return new(big.Int).SetUint64(MaximumTxGasLimit)
 	}
 
 	return new(big.Int).SetUint64(MaximumTxGasLimitJune2021)
 }
 
-func GetMinimumTransactionFeeTFuelWei(blockHeight uint64) *big.Int {
-	if blockHeight < common.HeightJune2021FeeAdjustment {
+func GetMinimumTransactionFeeTFuelWei(chainID string, blockHeight uint64) *big.Int {
+	if blockHeight < common.GetForkSchedule(chainID).June2021FeeAdjustment {
 		return new(big.Int).SetUint64(MinimumTransactionFeeTFuelWei)
 	}
 
@@ -117,8 +120,8 @@ func GetMinimumTransactionFeeTFuelWei(blockHeight uint64) *big.Int {
 }
 
 // Special handling for many-to-many SendTx
-func GetSendTxMinimumTransactionFeeTFuelWei(numAccountsAffected uint64, blockHeight uint64) *big.Int {
-	if blockHeight < common.HeightJune2021FeeAdjustment {
+func GetSendTxMinimumTransactionFeeTFuelWei(chainID string, numAccountsAffected uint64, blockHeight uint64) *big.Int {
+	if blockHeight < common.GetForkSchedule(chainID).June2021FeeAdjustment {
 		return new(big.Int).SetUint64(MinimumTransactionFeeTFuelWei) // backward compatiblity
 	}
 
@@ -0,0 +1,112 @@
+package types
+
+import (
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+)
+
+// ** Dynamic Fee: the per-block base fee of the smart contract transactions **
+//
+
+const (
+	// BlockGasTarget is the amount of gas used by a block at which the base fee stays unchanged
+	BlockGasTarget uint64 = 20e6
+
+	// BaseFeeElasticityMultiplier bounds the gas used by a block considered for the base fee
+	// adjustment to a multiple of BlockGasTarget
+	BaseFeeElasticityMultiplier uint64 = 2
+
+	// BaseFeeChangeDenominator bounds the base fee change between two consecutive blocks to
+	// 1/BaseFeeChangeDenominator of the parent base fee
+	BaseFeeChangeDenominator uint64 = 8
+)
+
+// CalculateBaseFee returns the base fee per gas of the block following the given parent block.
+// It goes up when the parent block used more gas than BlockGasTarget and down when it used less,
+// and never drops below the minimum gas price. There is no block gas limit, so the gas used by
+// the parent block is capped at BlockGasTarget * BaseFeeElasticityMultiplier for the adjustment.
+func CalculateBaseFee(parent *core.BlockHeader) *big.Int {
+	blockHeight := parent.Height + 1
+	minimumBaseFee := GetMinimumGasPrice(parent.ChainID, blockHeight)
+	if parent.Height < common.GetForkSchedule(parent.ChainID).EnableDynamicBaseFee || parent.BaseFee == nil || parent.BaseFee.Cmp(minimumBaseFee) < 0 {
+		return minimumBaseFee // the initial base fee
+	}
+
+	gasUsed := parent.GasUsed
+	if maxGasUsed := BlockGasTarget * BaseFeeElasticityMultiplier; gasUsed > maxGasUsed {
+		gasUsed = maxGasUsed
+	}
+	if gasUsed == BlockGasTarget {
+		return new(big.Int).Set(parent.BaseFee)
+	}
+
+	var gasUsedDelta uint64
+	if gasUsed > BlockGasTarget {
+		gasUsedDelta = gasUsed - BlockGasTarget
+	} else {
+		gasUsedDelta = BlockGasTarget - gasUsed
+	}
+	baseFeeDelta := new(big.Int).Mul(parent.BaseFee, new(big.Int).SetUint64(gasUsedDelta))
+	baseFeeDelta.Div(baseFeeDelta, new(big.Int).SetUint64(BlockGasTarget))
+	baseFeeDelta.Div(baseFeeDelta, new(big.Int).SetUint64(BaseFeeChangeDenominator))
+
+	if gasUsed > BlockGasTarget {
+		if baseFeeDelta.Sign() == 0 {
+			baseFeeDelta.SetUint64(1)
+		}
+		return baseFeeDelta.Add(parent.BaseFee, baseFeeDelta)
+	}
+
+	baseFee := baseFeeDelta.Sub(parent.BaseFee, baseFeeDelta)
+	if baseFee.Cmp(minimumBaseFee) < 0 {
+		return minimumBaseFee
+	}
+	return baseFee
+}
+
+// TxDynamicFee is embedded in the smart contract transactions. With a tip cap set, the gas
+// price of the transaction is the maximum fee per gas the sender is willing to pay, and the
+// transaction is charged the base fee of the block plus at most the tip cap. Like the validity
+// window, the tip cap is carried by the versioned tx encoding. The whole fee is burned, the tip
+// only raises the priority of the transaction in the mempool.
+type TxDynamicFee struct {
+	GasTipCap *big.Int `json:"gas_tip_cap,omitempty"`
+}
+
+// TxWithDynamicFee is implemented by the transaction types which can carry a tip cap
+type TxWithDynamicFee interface {
+	Tx
+	GetDynamicFee() *TxDynamicFee
+}
+
+// GetDynamicFee returns the dynamic fee parameters of the transaction
+func (d *TxDynamicFee) GetDynamicFee() *TxDynamicFee {
+	return d
+}
+
+// SetGasTipCap sets the maximum tip per gas of the transaction. It needs to be called before
+// the transaction is signed.
+func (d *TxDynamicFee) SetGasTipCap(gasTipCap *big.Int) {
+	d.GasTipCap = gasTipCap
+}
+
+// HasGasTipCap returns true if the transaction is a max fee/tip cap transaction
+func (d *TxDynamicFee) HasGasTipCap() bool {
+	return d.GasTipCap != nil
+}
+
+// EffectiveGasPrice returns the gas price charged to a transaction with the given gas price and
+// tip cap, i.e. min(gasPrice, baseFee + gasTipCap). Transactions without a tip cap pay their
+// gas price.
+func EffectiveGasPrice(gasPrice, gasTipCap, baseFee *big.Int) *big.Int {
+	if gasTipCap == nil || baseFee == nil {
+		return gasPrice
+	}
+	effectiveGasPrice := new(big.Int).Add(baseFee, gasTipCap)
+	if effectiveGasPrice.Cmp(gasPrice) > 0 {
+		return gasPrice
+	}
+	return effectiveGasPrice
+}
@@ -0,0 +1,181 @@
+package types
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/rlp"
+)
+
+// testDynamicFeeForks is the fork schedule of the test chain, with the dynamic base fee fork
+// scheduled
+var testDynamicFeeForks = func() common.ForkSchedule {
+	schedule := common.MainnetForkSchedule
+	schedule.EnableDynamicBaseFee = 40000000
+	return schedule
+}()
+
+func init() {
+	common.RegisterForkSchedule(chainID, testDynamicFeeForks)
+}
+
+func TestCalculateBaseFee(t *testing.T) {
+	assert := assert.New(t)
+
+	height := testDynamicFeeForks.EnableDynamicBaseFee
+	minimumBaseFee := GetMinimumGasPrice(chainID, height+1)
+
+	// The first blocks after the fork start from the minimum gas price
+	assert.Equal(minimumBaseFee, CalculateBaseFee(&core.BlockHeader{ChainID: chainID, Height: height - 1}))
+	assert.Equal(minimumBaseFee, CalculateBaseFee(&core.BlockHeader{ChainID: chainID, Height: height}))
+
+	baseFee := new(big.Int).Mul(minimumBaseFee, big.NewInt(8))
+	parent := &core.BlockHeader{ChainID: chainID, Height: height, BaseFee: baseFee}
+
+	parent.GasUsed = BlockGasTarget
+	assert.Equal(baseFee, CalculateBaseFee(parent))
+
+	// A full block raises the base fee by 1/8, and more gas does not raise it further
+	expected := new(big.Int).Mul(minimumBaseFee, big.NewInt(9))
+	parent.GasUsed = 2 * BlockGasTarget
+	assert.Equal(expected, CalculateBaseFee(parent))
+	parent.GasUsed = 10 * BlockGasTarget
+	assert.Equal(expected, CalculateBaseFee(parent))
+
+	// An empty block lowers the base fee by 1/8
+	parent.GasUsed = 0
+	assert.Equal(new(big.Int).Mul(minimumBaseFee, big.NewInt(7)), CalculateBaseFee(parent))
+
+	// The base fee never drops below the minimum gas price
+	parent.BaseFee = new(big.Int).Add(minimumBaseFee, big.NewInt(1))
+	assert.Equal(minimumBaseFee, CalculateBaseFee(parent))
+}
+
+func TestEffectiveGasPrice(t *testing.T) {
+	assert := assert.New(t)
+
+	gasPrice := big.NewInt(100)
+	baseFee := big.NewInt(60)
+
+	assert.Equal(gasPrice, EffectiveGasPrice(gasPrice, nil, baseFee))
+	assert.Equal(gasPrice, EffectiveGasPrice(gasPrice, big.NewInt(10), nil))
+	assert.Equal(big.NewInt(70), EffectiveGasPrice(gasPrice, big.NewInt(10), baseFee))
+	assert.Equal(gasPrice, EffectiveGasPrice(gasPrice, big.NewInt(50), baseFee))
+}
+
+func newTestDynamicFeeSmartContractTx() *SmartContractTx {
+	return &SmartContractTx{
+		From: TxInput{
+			Address:  common.HexToAddress("0x2e833968e5bb786ae419c4d13189fb081cc43bab"),
+			Coins:    NewCoins(0, 0),
+			Sequence: 1,
+		},
+		To:       TxOutput{Address: common.HexToAddress("0x9f1233798e905e173560071255140b4a8abd3ec6")},
+		GasLimit: 50000,
+		GasPrice: big.NewInt(5e12),
+		Data:     common.Hex2Bytes("a9059cbb"),
+	}
+}
+
+func TestTxDynamicFeeEncoding(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tx := newTestDynamicFeeSmartContractTx()
+	plainSignBytes := tx.SignBytes(chainID)
+
+	tx.SetGasTipCap(big.NewInt(1e12))
+	versionedBytes, err := TxToBytes(tx)
+	require.Nil(err)
+	assert.False(bytes.Equal(plainSignBytes, tx.SignBytes(chainID)))
+
+	decoded, err := TxFromBytes(versionedBytes)
+	require.Nil(err)
+	decodedTx := decoded.(*SmartContractTx)
+	assert.Equal(big.NewInt(1e12), decodedTx.GasTipCap)
+	assert.False(decodedTx.HasFeePayer())
+	reencoded, err := TxToBytes(decodedTx)
+	require.Nil(err)
+	assert.Equal(versionedBytes, reencoded)
+
+	// Along with a fee payer
+	tx.SetFeePayer(common.HexToAddress("0x1111111111111111111111111111111111111111"))
+	versionedBytes, err = TxToBytes(tx)
+	require.Nil(err)
+	decoded, err = TxFromBytes(versionedBytes)
+	require.Nil(err)
+	decodedTx = decoded.(*SmartContractTx)
+	assert.Equal(big.NewInt(1e12), decodedTx.GasTipCap)
+	assert.Equal(*tx.FeePayer, *decodedTx.FeePayer)
+
+	// A fee payer signature without a fee payer is not canonical
+	plainBytes, err := TxToBytes(newTestDynamicFeeSmartContractTx())
+	require.Nil(err)
+	sig, err := crypto.SignatureFromBytes(common.Hex2Bytes("01"))
+	require.Nil(err)
+	typeBytes, err := rlp.EncodeToBytes(TxVersioned)
+	require.Nil(err)
+	vtxBytes, err := rlp.EncodeToBytes(&versionedTxV3{Version: TxVersionV3, FeePayerSignature: sig, GasTipCap: big.NewInt(1), Tx: plainBytes})
+	require.Nil(err)
+	_, err = TxFromBytes(append(typeBytes, vtxBytes...))
+	assert.NotNil(err)
+}
+
+func TestTranslateEthDynamicFeeTx(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	privKey, _, err := crypto.GenerateKeyPair()
+	require.Nil(err)
+
+	blockHeight := testDynamicFeeForks.EnableDynamicBaseFee
+	ethChainID := MapChainID(chainID, blockHeight)
+	to := common.HexToAddress("0x9f1233798e905e173560071255140b4a8abd3ec6")
+	ethTx := &EthDynamicFeeTx{
+		ChainID:   ethChainID,
+		Nonce:     3,
+		GasTipCap: big.NewInt(1e12),
+		GasFeeCap: big.NewInt(8e12),
+		Gas:       50000,
+		To:        &to,
+		Value:     big.NewInt(1000),
+		Data:      common.Hex2Bytes("a9059cbb"),
+	}
+	payload, err := rlp.EncodeToBytes([]interface{}{ethTx.ChainID, ethTx.Nonce, ethTx.GasTipCap, ethTx.GasFeeCap,
+		ethTx.Gas, ethTx.To, ethTx.Value, ethTx.Data, ethTx.AccessList})
+	require.Nil(err)
+	sig, err := privKey.Sign(append([]byte{EthDynamicFeeTxType}, payload...))
+	require.Nil(err)
+	sigBytes := sig.ToBytes()
+	ethTx.R = new(big.Int).SetBytes(sigBytes[:32])
+	ethTx.S = new(big.Int).SetBytes(sigBytes[32:64])
+	ethTx.V = new(big.Int).SetUint64(uint64(sigBytes[64]))
+
+	ethTxBytes, err := rlp.EncodeToBytes(ethTx)
+	require.Nil(err)
+	tx, err := TranslateEthTx(hex.EncodeToString(append([]byte{EthDynamicFeeTxType}, ethTxBytes...)))
+	require.Nil(err)
+
+	assert.Equal(privKey.PublicKey().Address(), tx.From.Address)
+	assert.Equal(uint64(4), tx.From.Sequence)
+	assert.Equal(big.NewInt(8e12), tx.GasPrice)
+	assert.Equal(big.NewInt(1e12), tx.GasTipCap)
+	assert.Equal(to, tx.To.Address)
+
+	// The ETH signature can be verified against the translated tx
+	assert.Nil(crypto.ValidateEthSignature(tx.From.Address, tx.EthSigningHash(chainID, blockHeight), tx.From.Signature))
+
+	// The access list can not be carried by the translated tx
+	ethTx.AccessList = []rlp.RawValue{common.Hex2Bytes("c0")}
+	ethTxBytes, err = rlp.EncodeToBytes(ethTx)
+	require.Nil(err)
+	_, err = TranslateEthTx(hex.EncodeToString(append([]byte{EthDynamicFeeTxType}, ethTxBytes...)))
+	assert.NotNil(err)
+}
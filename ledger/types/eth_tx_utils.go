@@ -18,6 +18,8 @@ package types
 
 import (
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"math/big"
 	"strings"
 
@@ -39,6 +41,10 @@ func TranslateEthTx(ethTxStr string) (*SmartContractTx, error) {
 		return nil, err
 	}
 
+	if len(ethTxBytes) > 0 && ethTxBytes[0] <= 0x7f { // typed transaction envelope, see EIP-2718
+		return translateEthTypedTx(ethTxBytes)
+	}
+
 	err = rlp.DecodeBytes(ethTxBytes, &ethTx)
 	if err != nil {
 		return nil, err
@@ -104,6 +110,82 @@ func TranslateEthTx(ethTxStr string) (*SmartContractTx, error) {
 	return &thetaTx, nil
 }
 
+// translateEthTypedTx translates an ETH typed transaction to a Theta smart contract transaction.
+// Only the dynamic fee transactions without an access list are supported, since the access
+// list can not be carried by the smart contract transaction.
+func translateEthTypedTx(ethTxBytes []byte) (*SmartContractTx, error) {
+	if ethTxBytes[0] != EthDynamicFeeTxType {
+		return nil, fmt.Errorf("unsupported ETH transaction type: %v", ethTxBytes[0])
+	}
+
+	var ethTx EthDynamicFeeTx
+	err := rlp.DecodeBytes(ethTxBytes[1:], &ethTx)
+	if err != nil {
+		return nil, err
+	}
+	if len(ethTx.AccessList) != 0 {
+		return nil, errors.New("ETH transactions with an access list are not supported")
+	}
+	if ethTx.V.BitLen() > 1 {
+		return nil, errors.New("invalid y parity of the ETH transaction signature")
+	}
+
+	ethSigningHash := PrefixedRLPHash(EthDynamicFeeTxType, []interface{}{
+		ethTx.ChainID,
+		ethTx.Nonce,
+		ethTx.GasTipCap,
+		ethTx.GasFeeCap,
+		ethTx.Gas,
+		ethTx.To,
+		ethTx.Value,
+		ethTx.Data,
+		ethTx.AccessList,
+	})
+
+	logger.Debugf("ethTx.ethSigningHash: %v", ethSigningHash.Hex())
+
+	// The signature of the typed transactions carries the y parity instead of V
+	v := new(big.Int).Add(ethTx.V, big.NewInt(27))
+	sig, err := crypto.EncodeSignature(ethTx.R, ethTx.S, v)
+	if err != nil {
+		return nil, err
+	}
+
+	fromAddr, err := crypto.HomesteadSignerSender(ethSigningHash, sig)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("ethTx.recoveredFromAddress: %v", fromAddr.Hex())
+
+	to := common.Address{}
+	if ethTx.To != nil {
+		to = *ethTx.To
+	}
+
+	thetaTx := SmartContractTx{
+		From: TxInput{
+			Address: fromAddr,
+			Coins: Coins{
+				ThetaWei: big.NewInt(0),
+				TFuelWei: ethTx.Value,
+			},
+			Sequence:  ethTx.Nonce + 1, // off-by-one, ETH tx nonce starts from 0, while Theta tx sequence starts from 1
+			Signature: sig,
+		},
+		To: TxOutput{
+			Address: to,
+			Coins:   NewCoins(0, 0),
+		},
+		GasLimit: ethTx.Gas,
+		GasPrice: ethTx.GasFeeCap,
+		Data:     ethTx.Data,
+	}
+	thetaTx.SetGasTipCap(ethTx.GasTipCap)
+
+	return &thetaTx, nil
+}
+
 // TxData is the underlying data of a transaction.
 //
 // This is implemented by EthTransaction and AccessListTx.
@@ -123,6 +205,23 @@ type TxData interface {
 	setSignatureValues(chainID, v, r, s *big.Int)
 }
 
+// EthDynamicFeeTxType is the type of the ETH dynamic fee (EIP-1559) transactions
+const EthDynamicFeeTxType byte = 0x02
+
+// EthDynamicFeeTx is the transaction data of the ETH dynamic fee (EIP-1559) transactions.
+type EthDynamicFeeTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int        // max priority fee per gas
+	GasFeeCap  *big.Int        // max fee per gas
+	Gas        uint64          // gas limit
+	To         *common.Address `rlp:"nil"` // nil means contract creation
+	Value      *big.Int        // wei amount
+	Data       []byte          // contract invocation input data
+	AccessList []rlp.RawValue
+	V, R, S    *big.Int // signature values, V is the y parity
+}
+
 // EthTransaction is the transaction data of regular Ethereum transactions.
 type EthTransaction struct {
 	Nonce    uint64          // nonce of sender account
@@ -0,0 +1,189 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/vm/params"
+)
+
+// ** Governance: the protocol parameters adjustable by on-chain proposals **
+//
+// Each governable parameter has a default value, which is what the parameter had before it
+// became governable and still follows the hard-coded fork heights, and a range an approved
+// proposal can move it within. Parameters that every node needs to switch at the same block,
+// e.g. common.CheckpointInterval, are not governable and still go through a hard fork.
+
+// Names of the governable protocol parameters
+const (
+	ParamMinimumTransactionFeeTFuelWei = "min_tx_fee_tfuel_wei"
+	ParamMinimumGasPrice               = "min_gas_price"
+	ParamMaximumTxGasLimit             = "max_tx_gas_limit"
+	ParamMinimumValidatorStake         = "min_validator_stake"
+	ParamMinimumGuardianStake          = "min_guardian_stake"
+	ParamMinimumEliteEdgeNodeStake     = "min_elite_edge_node_stake"
+	ParamMaxCodeSize                   = "max_code_size"
+)
+
+const (
+	// GovernanceVotingPeriod is the number of blocks a proposal stays open for voting
+	GovernanceVotingPeriod uint64 = 100800 // approximately 7 days
+
+	// MaxParamChangesPerProposal is the maximum number of parameters a proposal can change
+	MaxParamChangesPerProposal = 16
+)
+
+// GovernanceParamSpec describes a governable protocol parameter
+type GovernanceParamSpec struct {
+	Min     *big.Int                                          // the smallest value a proposal can set
+	Max     *big.Int                                          // the largest value a proposal can set
+	Default func(chainID string, blockHeight uint64) *big.Int // the value before any proposal changed it
+}
+
+var governanceParamSpecs = map[string]GovernanceParamSpec{
+	ParamMinimumTransactionFeeTFuelWei: {
+		Min:     new(big.Int).SetUint64(MinimumTransactionFeeTFuelWei),
+		Max:     new(big.Int).Mul(new(big.Int).SetUint64(MinimumTransactionFeeTFuelWeiJune2021), big.NewInt(100)),
+		Default: GetMinimumTransactionFeeTFuelWei,
+	},
+	ParamMinimumGasPrice: {
+		Min:     new(big.Int).SetUint64(MinimumGasPrice),
+		Max:     new(big.Int).Mul(new(big.Int).SetUint64(MinimumGasPriceJune2021), big.NewInt(100)),
+		Default: GetMinimumGasPrice,
+	},
+	ParamMaximumTxGasLimit: {
+		Min:     new(big.Int).SetUint64(MaximumTxGasLimit),
+		Max:     new(big.Int).SetUint64(BlockGasTarget * BaseFeeElasticityMultiplier),
+		Default: GetMaxGasLimit,
+	},
+	// The stake thresholds can only be raised, since the lower hard-coded thresholds
+	// are still enforced by the candidate pools
+	ParamMinimumValidatorStake: {
+		Min: core.MinValidatorStakeDeposit200K,
+		Max: core.MinValidatorStakeDeposit,
+		Default: func(chainID string, blockHeight uint64) *big.Int {
+			if blockHeight < common.GetForkSchedule(chainID).ValidatorStakeChangedTo200K {
+				return new(big.Int).Set(core.MinValidatorStakeDeposit)
+			}
+			return new(big.Int).Set(core.MinValidatorStakeDeposit200K)
+		},
+	},
+	ParamMinimumGuardianStake: {
+		Min: core.MinGuardianStakeDeposit1000,
+		Max: core.MinGuardianStakeDeposit,
+		Default: func(chainID string, blockHeight uint64) *big.Int {
+			if blockHeight < common.GetForkSchedule(chainID).LowerGNStakeThresholdTo1000 {
+				return new(big.Int).Set(core.MinGuardianStakeDeposit)
+			}
+			return new(big.Int).Set(core.MinGuardianStakeDeposit1000)
+		},
+	},
+	ParamMinimumEliteEdgeNodeStake: {
+		Min: core.MinEliteEdgeNodeStakeDeposit,
+		Max: core.MaxEliteEdgeNodeStakeDeposit,
+		Default: func(chainID string, blockHeight uint64) *big.Int {
+			return new(big.Int).Set(core.MinEliteEdgeNodeStakeDeposit)
+		},
+	},
+	ParamMaxCodeSize: {
+		Min: big.NewInt(params.MaxCodeSize),
+		Max: big.NewInt(4 * params.MaxCodeSizeForMetachain),
+		Default: func(chainID string, blockHeight uint64) *big.Int {
+			if blockHeight < common.GetForkSchedule(chainID).EnableMetachainSupport {
+				return big.NewInt(params.MaxCodeSize)
+			}
+			return big.NewInt(params.MaxCodeSizeForMetachain)
+		},
+	},
+}
+
+// GetGovernanceParamSpec returns the spec of the governable parameter with the given name
+func GetGovernanceParamSpec(name string) (GovernanceParamSpec, bool) {
+	spec, ok := governanceParamSpecs[name]
+	return spec, ok
+}
+
+// GetDefaultGovernanceParam returns the hard-coded value of the governable parameter at the
+// given block height of the given chain
+func GetDefaultGovernanceParam(chainID string, name string, blockHeight uint64) *big.Int {
+	spec, ok := governanceParamSpecs[name]
+	if !ok {
+		panic(fmt.Sprintf("Unknown governance parameter: %v", name))
+	}
+	return spec.Default(chainID, blockHeight)
+}
+
+// ParamChange sets a governable parameter to a new value
+type ParamChange struct {
+	Name  string   `json:"name"`
+	Value *big.Int `json:"value"`
+}
+
+func (pc ParamChange) String() string {
+	return fmt.Sprintf("%v=%v", pc.Name, pc.Value)
+}
+
+// ValidateParamChanges checks that the changes touch known parameters at most once each and
+// keep them within their ranges
+func ValidateParamChanges(changes []ParamChange) error {
+	if len(changes) == 0 {
+		return fmt.Errorf("No parameter changes proposed")
+	}
+	if len(changes) > MaxParamChangesPerProposal {
+		return fmt.Errorf("At most %v parameters can be changed by a proposal", MaxParamChangesPerProposal)
+	}
+
+	names := make(map[string]bool)
+	for _, change := range changes {
+		spec, ok := governanceParamSpecs[change.Name]
+		if !ok {
+			return fmt.Errorf("Parameter %v is not governable", change.Name)
+		}
+		if names[change.Name] {
+			return fmt.Errorf("Parameter %v is changed more than once", change.Name)
+		}
+		names[change.Name] = true
+
+		if change.Value == nil || change.Value.Cmp(spec.Min) < 0 || change.Value.Cmp(spec.Max) > 0 {
+			return fmt.Errorf("Parameter %v needs to be within [%v, %v]", change.Name, spec.Min, spec.Max)
+		}
+	}
+
+	return nil
+}
+
+// GovernanceVote is the vote of a validator or guardian stake holder on a proposal
+type GovernanceVote struct {
+	Voter   common.Address `json:"voter"`
+	Approve bool           `json:"approve"`
+}
+
+// GovernanceProposal is a set of parameter changes voted on by the stake holders. The votes
+// are weighted by the stake of the voters at the time the proposal is executed.
+type GovernanceProposal struct {
+	ID          uint64           `json:"id"`
+	Proposer    common.Address   `json:"proposer"`
+	Changes     []ParamChange    `json:"changes"`
+	StartHeight uint64           `json:"start_height"`
+	EndHeight   uint64           `json:"end_height"` // the last height votes and the execution are accepted
+	Votes       []GovernanceVote `json:"votes"`
+	Executed    bool             `json:"executed"`
+}
+
+// SetVote records the vote of the voter, replacing its earlier vote if any
+func (p *GovernanceProposal) SetVote(voter common.Address, approve bool) {
+	for i := range p.Votes {
+		if p.Votes[i].Voter == voter {
+			p.Votes[i].Approve = approve
+			return
+		}
+	}
+	p.Votes = append(p.Votes, GovernanceVote{Voter: voter, Approve: approve})
+}
+
+func (p *GovernanceProposal) String() string {
+	return fmt.Sprintf("GovernanceProposal{id: %v, proposer: %v, changes: %v, start: %v, end: %v, votes: %v, executed: %v}",
+		p.ID, p.Proposer, p.Changes, p.StartHeight, p.EndHeight, len(p.Votes), p.Executed)
+}
@@ -0,0 +1,160 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/rlp"
+)
+
+// ** Native Multisig Account: an account controlled by a set of public keys **
+//
+
+// multisigAddressPrefix is hashed together with the policy to derive the address of a multisig
+// account, so the address cannot collide with the address of a public key
+var multisigAddressPrefix = []byte("theta_multisig_account")
+
+// multisigSignaturePrefix marks the signature bytes which hold a multisig signature bundle,
+// instead of a regular 65-byte signature
+var multisigSignaturePrefix = []byte{0xff, 'm', 's', 'i', 'g'}
+
+// MultisigPolicy defines a native multisig account. The account is controlled by the holders of
+// the public keys, and a transaction spending from the account needs the signatures of at least
+// Threshold of them. The address of the account is derived from the policy, so the order of the
+// public keys matters.
+type MultisigPolicy struct {
+	Threshold uint64
+	PubKeys   []*crypto.PublicKey
+}
+
+// NewMultisigPolicy creates a new instance of MultisigPolicy
+func NewMultisigPolicy(threshold uint64, pubKeys []*crypto.PublicKey) (*MultisigPolicy, error) {
+	policy := &MultisigPolicy{
+		Threshold: threshold,
+		PubKeys:   pubKeys,
+	}
+	if res := policy.ValidateBasic(); res.IsError() {
+		return nil, errors.New(res.Message)
+	}
+	return policy, nil
+}
+
+// ValidateBasic checks the threshold and the public keys of the policy
+func (p *MultisigPolicy) ValidateBasic() result.Result {
+	numPubKeys := len(p.PubKeys)
+	if numPubKeys == 0 || numPubKeys > MaxMultisigPubKeys {
+		return result.Error("A multisig policy needs between 1 and %v public keys, got %v", MaxMultisigPubKeys, numPubKeys)
+	}
+	if p.Threshold == 0 || p.Threshold > uint64(numPubKeys) {
+		return result.Error("Invalid multisig threshold %v for %v public keys", p.Threshold, numPubKeys)
+	}
+	addresses := make(map[common.Address]bool)
+	for _, pubKey := range p.PubKeys {
+		if pubKey == nil || pubKey.IsEmpty() {
+			return result.Error("Empty public key in the multisig policy")
+		}
+		address := pubKey.Address()
+		if addresses[address] {
+			return result.Error("Duplicated public key in the multisig policy: %v", address.Hex())
+		}
+		addresses[address] = true
+	}
+	return result.OK
+}
+
+// Address returns the address of the multisig account defined by the policy
+func (p *MultisigPolicy) Address() common.Address {
+	policyBytes, err := rlp.EncodeToBytes(p)
+	if err != nil {
+		logger.Panicf("Failed to encode the multisig policy: %v", err)
+	}
+	return common.BytesToAddress(crypto.Keccak256(multisigAddressPrefix, policyBytes)[12:])
+}
+
+func (p *MultisigPolicy) String() string {
+	return fmt.Sprintf("MultisigPolicy{%v-of-%v, %v}", p.Threshold, len(p.PubKeys), p.Address().Hex())
+}
+
+// MultisigSignature is the signature bundle of a multisig account. It carries the policy of the
+// account along with the signatures, since only the address of the account is kept on chain.
+type MultisigSignature struct {
+	Policy     MultisigPolicy
+	Signatures []*crypto.Signature // signature of each public key of the policy, empty for the keys which did not sign
+}
+
+// NewMultisigSignature creates an empty signature bundle for the given policy
+func NewMultisigSignature(policy *MultisigPolicy) *MultisigSignature {
+	return &MultisigSignature{
+		Policy:     *policy,
+		Signatures: make([]*crypto.Signature, len(policy.PubKeys)),
+	}
+}
+
+// AddSignature adds the signature of one of the public keys of the policy to the bundle
+func (ms *MultisigSignature) AddSignature(pubKey *crypto.PublicKey, sig *crypto.Signature) error {
+	for i, pk := range ms.Policy.PubKeys {
+		if bytes.Equal(pk.ToBytes(), pubKey.ToBytes()) {
+			ms.Signatures[i] = sig
+			return nil
+		}
+	}
+	return fmt.Errorf("public key %v is not part of the multisig policy", pubKey.Address().Hex())
+}
+
+// Verify returns true if the bundle holds at least the threshold number of valid signatures of
+// the message, and the policy corresponds to the given address. Any invalid signature makes
+// the bundle invalid, so the bundle cannot be padded with junk signatures.
+func (ms *MultisigSignature) Verify(msg common.Bytes, addr common.Address) bool {
+	if ms.Policy.ValidateBasic().IsError() {
+		return false
+	}
+	if len(ms.Signatures) != len(ms.Policy.PubKeys) {
+		return false
+	}
+	if ms.Policy.Address() != addr {
+		return false
+	}
+
+	numSigned := uint64(0)
+	for i, sig := range ms.Signatures {
+		if sig == nil || sig.IsEmpty() {
+			continue
+		}
+		if !sig.Verify(msg, ms.Policy.PubKeys[i].Address()) {
+			return false
+		}
+		numSigned++
+	}
+	return numSigned >= ms.Policy.Threshold
+}
+
+// ToSignature encodes the bundle so it can be set as the signature of a TxInput
+func (ms *MultisigSignature) ToSignature() (*crypto.Signature, error) {
+	raw, err := rlp.EncodeToBytes(ms)
+	if err != nil {
+		return nil, err
+	}
+	sigBytes := append(common.CopyBytes(multisigSignaturePrefix), raw...)
+	return crypto.SignatureFromBytes(sigBytes)
+}
+
+// MultisigSignatureFromSignature decodes the signature bundle of a multisig account from the
+// signature of a TxInput. It returns false if the signature is not a multisig signature bundle.
+func MultisigSignatureFromSignature(sig *crypto.Signature) (*MultisigSignature, bool) {
+	if sig == nil {
+		return nil, false
+	}
+	sigBytes := sig.ToBytes()
+	if !bytes.HasPrefix(sigBytes, multisigSignaturePrefix) {
+		return nil, false
+	}
+	ms := &MultisigSignature{}
+	if err := rlp.DecodeBytes(sigBytes[len(multisigSignaturePrefix):], ms); err != nil {
+		return nil, false
+	}
+	return ms, true
+}
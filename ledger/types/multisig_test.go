@@ -0,0 +1,94 @@
+package types
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+)
+
+func newTestMultisigKeys(n int) ([]*crypto.PrivateKey, []*crypto.PublicKey) {
+	privKeys := []*crypto.PrivateKey{}
+	pubKeys := []*crypto.PublicKey{}
+	for i := 0; i < n; i++ {
+		privKey, pubKey, _ := crypto.TEST_GenerateKeyPairWithSeed("multisig_" + strconv.Itoa(i))
+		privKeys = append(privKeys, privKey)
+		pubKeys = append(pubKeys, pubKey)
+	}
+	return privKeys, pubKeys
+}
+
+func TestMultisigPolicy(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	_, pubKeys := newTestMultisigKeys(3)
+
+	policy, err := NewMultisigPolicy(2, pubKeys)
+	require.Nil(err)
+
+	samePolicy, err := NewMultisigPolicy(2, pubKeys)
+	require.Nil(err)
+	assert.Equal(policy.Address(), samePolicy.Address())
+
+	otherThreshold, err := NewMultisigPolicy(3, pubKeys)
+	require.Nil(err)
+	assert.NotEqual(policy.Address(), otherThreshold.Address())
+
+	for _, pubKey := range pubKeys {
+		assert.NotEqual(pubKey.Address(), policy.Address())
+	}
+
+	_, err = NewMultisigPolicy(0, pubKeys)
+	assert.NotNil(err)
+	_, err = NewMultisigPolicy(4, pubKeys)
+	assert.NotNil(err)
+	_, err = NewMultisigPolicy(1, []*crypto.PublicKey{pubKeys[0], pubKeys[0]})
+	assert.NotNil(err)
+	_, err = NewMultisigPolicy(1, nil)
+	assert.NotNil(err)
+}
+
+func TestMultisigSignature(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	privKeys, pubKeys := newTestMultisigKeys(3)
+	policy, err := NewMultisigPolicy(2, pubKeys)
+	require.Nil(err)
+
+	msg := common.Bytes("multisig message")
+	multisig := NewMultisigSignature(policy)
+
+	sig0, err := privKeys[0].Sign(msg)
+	require.Nil(err)
+	require.Nil(multisig.AddSignature(pubKeys[0], sig0))
+	assert.False(multisig.Verify(msg, policy.Address()))
+
+	sig2, err := privKeys[2].Sign(msg)
+	require.Nil(err)
+	require.Nil(multisig.AddSignature(pubKeys[2], sig2))
+	assert.True(multisig.Verify(msg, policy.Address()))
+	assert.False(multisig.Verify(common.Bytes("other message"), policy.Address()))
+	assert.False(multisig.Verify(msg, pubKeys[0].Address()))
+
+	// Round trip through the signature of a TxInput
+	sig, err := multisig.ToSignature()
+	require.Nil(err)
+	decoded, ok := MultisigSignatureFromSignature(sig)
+	require.True(ok)
+	assert.True(decoded.Verify(msg, policy.Address()))
+
+	// A regular signature is not a multisig signature bundle
+	_, ok = MultisigSignatureFromSignature(sig0)
+	assert.False(ok)
+
+	// Signatures by the wrong key are rejected, even if the threshold is met otherwise
+	_, outsiderPubKeys := newTestMultisigKeys(4)
+	require.NotNil(multisig.AddSignature(outsiderPubKeys[3], sig0))
+	multisig.Signatures[1] = sig0
+	assert.False(multisig.Verify(msg, policy.Address()))
+}
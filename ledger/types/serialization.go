@@ -37,6 +37,13 @@ const (
 	TxWithdrawStake
 	TxDepositStakeV2
 	TxStakeRewardDistribution
+	TxVersioned
+	TxRedelegateStake
+	TxWithdrawStakeV2
+	TxGovernanceProposal
+	TxGovernanceVote
+	TxGovernanceExecute
+	TxEvidence
 )
 
 func Fuzz(data []byte) int {
@@ -114,6 +121,32 @@ func TxFromBytes(raw []byte) (Tx, error) {
 		data := &StakeRewardDistributionTx{}
 		err = s.Decode(data)
 		return data, err
+	} else if txType == TxVersioned {
+		return decodeVersionedTx(s)
+	} else if txType == TxRedelegateStake {
+		data := &RedelegateStakeTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxWithdrawStakeV2 {
+		data := &WithdrawStakeTxV2{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxGovernanceProposal {
+		data := &GovernanceProposalTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxGovernanceVote {
+		data := &GovernanceVoteTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxGovernanceExecute {
+		data := &GovernanceExecuteTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxEvidence {
+		data := &EvidenceTx{}
+		err = s.Decode(data)
+		return data, err
 	} else {
 		return nil, fmt.Errorf("Unknown TX type: %v", txType)
 	}
@@ -147,6 +180,18 @@ func TxToBytes(t Tx) ([]byte, error) {
 		txType = TxDepositStakeV2
 	case *StakeRewardDistributionTx:
 		txType = TxStakeRewardDistribution
+	case *RedelegateStakeTx:
+		txType = TxRedelegateStake
+	case *WithdrawStakeTxV2:
+		txType = TxWithdrawStakeV2
+	case *GovernanceProposalTx:
+		txType = TxGovernanceProposal
+	case *GovernanceVoteTx:
+		txType = TxGovernanceVote
+	case *GovernanceExecuteTx:
+		txType = TxGovernanceExecute
+	case *EvidenceTx:
+		txType = TxEvidence
 	default:
 		return nil, errors.New("Unsupported message type")
 	}
@@ -158,5 +203,8 @@ func TxToBytes(t Tx) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if needsVersionedEncoding(t) {
+		return encodeVersionedTx(t, buf.Bytes())
+	}
 	return buf.Bytes(), nil
 }
@@ -35,6 +35,12 @@ Transaction Types:
  - SplitRuleTx             Payment split rule
  - DepositStakeTx          Deposit stake to a target address (e.g. a validator)
  - WithdrawStakeTx         Withdraw stake from a target address (e.g. a validator)
+ - WithdrawStakeTxV2       Withdraw part of the stake from a target address
+ - RedelegateStakeTx       Move stake from a guardian/elite edge node to another one
+ - GovernanceProposalTx    Propose changes to the governable protocol parameters
+ - GovernanceVoteTx        Vote on a governance proposal
+ - GovernanceExecuteTx     Apply the changes of an approved governance proposal
+ - EvidenceTx              Slash and jail a validator who cast conflicting votes
  - SmartContractTx         Execute smart contract
  - StakeRewardDistribution Defines how stake reward is distributed
 */
@@ -350,6 +356,9 @@ type SendTx struct {
 	Fee     Coins      `json:"fee"` // Fee
 	Inputs  []TxInput  `json:"inputs"`
 	Outputs []TxOutput `json:"outputs"`
+
+	TxValidityWindow `rlp:"-"`
+	TxFeeDelegation  `rlp:"-"`
 }
 
 func (_ *SendTx) AssertIsTx() {}
@@ -361,6 +370,8 @@ func (tx *SendTx) SignBytes(chainID string) []byte {
 		sigz[i] = tx.Inputs[i].Signature
 		tx.Inputs[i].Signature = nil
 	}
+	feePayerSig := tx.FeePayerSignature
+	tx.FeePayerSignature = nil
 	txBytes, _ := TxToBytes(tx)
 	signBytes = append(signBytes, txBytes...)
 	signBytes = addPrefixForSignBytes(signBytes)
@@ -368,6 +379,7 @@ func (tx *SendTx) SignBytes(chainID string) []byte {
 	for i := range tx.Inputs {
 		tx.Inputs[i].Signature = sigz[i]
 	}
+	tx.FeePayerSignature = feePayerSig
 	return signBytes
 }
 
@@ -393,6 +405,8 @@ type ReserveFundTx struct {
 	Collateral  Coins    // Collateral for the micropayment pool
 	ResourceIDs []string // List of resource ID
 	Duration    uint64
+
+	TxValidityWindow `rlp:"-"`
 }
 
 type ReserveFundTxJSON struct {
@@ -401,6 +415,8 @@ type ReserveFundTxJSON struct {
 	Collateral  Coins             `json:"collateral"`   // Collateral for the micropayment pool
 	ResourceIDs []string          `json:"resource_ids"` // List of resource ID
 	Duration    common.JSONUint64 `json:"duration"`
+
+	TxValidityWindow
 }
 
 func NewReserveFundTxJSON(a ReserveFundTx) ReserveFundTxJSON {
@@ -410,6 +426,8 @@ func NewReserveFundTxJSON(a ReserveFundTx) ReserveFundTxJSON {
 		Collateral:  a.Collateral,
 		ResourceIDs: a.ResourceIDs,
 		Duration:    common.JSONUint64(a.Duration),
+
+		TxValidityWindow: a.TxValidityWindow,
 	}
 }
 
@@ -420,6 +438,8 @@ func (a ReserveFundTxJSON) ReserveFundTx() ReserveFundTx {
 		Collateral:  a.Collateral,
 		ResourceIDs: a.ResourceIDs,
 		Duration:    uint64(a.Duration),
+
+		TxValidityWindow: a.TxValidityWindow,
 	}
 }
 
@@ -469,12 +489,16 @@ type ReleaseFundTx struct {
 	Fee             Coins   // Fee
 	Source          TxInput // source account
 	ReserveSequence uint64
+
+	TxValidityWindow `rlp:"-"`
 }
 
 type ReleaseFundTxJSON struct {
 	Fee             Coins             `json:"fee"`    // Fee
 	Source          TxInput           `json:"source"` // source account
 	ReserveSequence common.JSONUint64 `json:"reserve_sequence"`
+
+	TxValidityWindow
 }
 
 func NewReleaseFundTxJSON(a ReleaseFundTx) ReleaseFundTxJSON {
@@ -482,6 +506,8 @@ func NewReleaseFundTxJSON(a ReleaseFundTx) ReleaseFundTxJSON {
 		Fee:             a.Fee,
 		Source:          a.Source,
 		ReserveSequence: common.JSONUint64(a.ReserveSequence),
+
+		TxValidityWindow: a.TxValidityWindow,
 	}
 }
 
@@ -490,6 +516,8 @@ func (a ReleaseFundTxJSON) ReleaseFundTx() ReleaseFundTx {
 		Fee:             a.Fee,
 		Source:          a.Source,
 		ReserveSequence: uint64(a.ReserveSequence),
+
+		TxValidityWindow: a.TxValidityWindow,
 	}
 }
 
@@ -541,6 +569,9 @@ type ServicePaymentTx struct {
 	PaymentSequence uint64  // each on-chain settlement needs to increase the payment sequence by 1
 	ReserveSequence uint64  // ReserveSequence to locate the ReservedFund
 	ResourceID      string  // The corresponding resourceID
+
+	TxValidityWindow `rlp:"-"`
+	TxFeeDelegation  `rlp:"-"`
 }
 
 type ServicePaymentTxJSON struct {
@@ -550,6 +581,9 @@ type ServicePaymentTxJSON struct {
 	PaymentSequence common.JSONUint64 `json:"payment_sequence"` // each on-chain settlement needs to increase the payment sequence by 1
 	ReserveSequence common.JSONUint64 `json:"reserve_sequence"` // ReserveSequence to locate the ReservedFund
 	ResourceID      string            `json:"resource_id"`      // The corresponding resourceID
+
+	TxValidityWindow
+	TxFeeDelegation
 }
 
 func NewServicePaymentTxJSON(a ServicePaymentTx) ServicePaymentTxJSON {
@@ -560,6 +594,9 @@ func NewServicePaymentTxJSON(a ServicePaymentTx) ServicePaymentTxJSON {
 		PaymentSequence: common.JSONUint64(a.PaymentSequence),
 		ReserveSequence: common.JSONUint64(a.ReserveSequence),
 		ResourceID:      a.ResourceID,
+
+		TxValidityWindow: a.TxValidityWindow,
+		TxFeeDelegation:  a.TxFeeDelegation,
 	}
 }
 
@@ -571,6 +608,9 @@ func (a ServicePaymentTxJSON) ServicePaymentTx() ServicePaymentTx {
 		PaymentSequence: uint64(a.PaymentSequence),
 		ReserveSequence: uint64(a.ReserveSequence),
 		ResourceID:      a.ResourceID,
+
+		TxValidityWindow: a.TxValidityWindow,
+		TxFeeDelegation:  a.TxFeeDelegation,
 	}
 }
 
@@ -596,9 +636,12 @@ func (tx *ServicePaymentTx) SourceSignBytes(chainID string) []byte {
 	target := tx.Target
 	fee := tx.Fee
 
+	feePayerSig := tx.FeePayerSignature
+
 	tx.Source = TxInput{Address: source.Address, Coins: source.Coins}
 	tx.Target = TxInput{Address: target.Address}
 	tx.Fee = NewCoins(0, 0)
+	tx.FeePayerSignature = nil
 
 	txBytes, _ := TxToBytes(tx)
 	signBytes = append(signBytes, txBytes...)
@@ -606,6 +649,7 @@ func (tx *ServicePaymentTx) SourceSignBytes(chainID string) []byte {
 	tx.Source = source
 	tx.Target = target
 	tx.Fee = fee
+	tx.FeePayerSignature = feePayerSig
 
 	signBytes = addPrefixForSignBytes(signBytes)
 
@@ -620,14 +664,17 @@ func (tx *ServicePaymentTx) TargetSignBytes(chainID string) []byte {
 	// TODO: remove chainID from all Tx sign bytes.
 	signBytes := encodeToBytes(chainID)
 	targetSig := tx.Target.Signature
+	feePayerSig := tx.FeePayerSignature
 
 	tx.Target.Signature = nil
+	tx.FeePayerSignature = nil
 
 	txBytes, _ := TxToBytes(tx)
 	signBytes = append(signBytes, txBytes...)
 	signBytes = addPrefixForSignBytes(signBytes)
 
 	tx.Target.Signature = targetSig
+	tx.FeePayerSignature = feePayerSig
 
 	return signBytes
 }
@@ -662,6 +709,8 @@ type SplitRuleTx struct {
 	Initiator  TxInput // Initiator of the split rule
 	Splits     []Split // Agreed splits
 	Duration   uint64  // Duration of the payment split in terms of blocks
+
+	TxValidityWindow `rlp:"-"`
 }
 
 type SplitRuleTxJSON struct {
@@ -670,6 +719,8 @@ type SplitRuleTxJSON struct {
 	Initiator  TxInput           `json:"initiator"`   // Initiator of the split rule
 	Splits     []Split           `json:"splits"`      // Agreed splits
 	Duration   common.JSONUint64 `json:"duration"`    // Duration of the payment split in terms of blocks
+
+	TxValidityWindow
 }
 
 func NewSplitRuleTxJSON(a SplitRuleTx) SplitRuleTxJSON {
@@ -679,6 +730,8 @@ func NewSplitRuleTxJSON(a SplitRuleTx) SplitRuleTxJSON {
 		Initiator:  a.Initiator,
 		Splits:     a.Splits,
 		Duration:   common.JSONUint64(a.Duration),
+
+		TxValidityWindow: a.TxValidityWindow,
 	}
 }
 
@@ -689,6 +742,8 @@ func (a SplitRuleTxJSON) SplitRuleTx() SplitRuleTx {
 		Initiator:  a.Initiator,
 		Splits:     a.Splits,
 		Duration:   uint64(a.Duration),
+
+		TxValidityWindow: a.TxValidityWindow,
 	}
 }
 
@@ -740,23 +795,35 @@ type SmartContractTx struct {
 	GasLimit uint64
 	GasPrice *big.Int
 	Data     common.Bytes
+
+	TxValidityWindow `rlp:"-"`
+	TxFeeDelegation  `rlp:"-"`
+	TxDynamicFee     `rlp:"-"`
 }
 
 type SmartContractTxJSON struct {
-	From     TxInput           `json:"from"`
-	To       TxOutput          `json:"to"`
-	GasLimit common.JSONUint64 `json:"gas_limit"`
-	GasPrice *common.JSONBig   `json:"gas_price"`
-	Data     common.Bytes      `json:"data"`
+	From      TxInput           `json:"from"`
+	To        TxOutput          `json:"to"`
+	GasLimit  common.JSONUint64 `json:"gas_limit"`
+	GasPrice  *common.JSONBig   `json:"gas_price"`
+	GasTipCap *common.JSONBig   `json:"gas_tip_cap,omitempty"`
+	Data      common.Bytes      `json:"data"`
+
+	TxValidityWindow
+	TxFeeDelegation
 }
 
 func NewSmartContractTxJSON(a SmartContractTx) SmartContractTxJSON {
 	return SmartContractTxJSON{
-		From:     a.From,
-		To:       a.To,
-		GasLimit: common.JSONUint64(a.GasLimit),
-		GasPrice: (*common.JSONBig)(a.GasPrice),
-		Data:     a.Data,
+		From:      a.From,
+		To:        a.To,
+		GasLimit:  common.JSONUint64(a.GasLimit),
+		GasPrice:  (*common.JSONBig)(a.GasPrice),
+		GasTipCap: (*common.JSONBig)(a.GasTipCap),
+		Data:      a.Data,
+
+		TxValidityWindow: a.TxValidityWindow,
+		TxFeeDelegation:  a.TxFeeDelegation,
 	}
 }
 
@@ -767,6 +834,10 @@ func (a SmartContractTxJSON) SmartContractTx() SmartContractTx {
 		GasLimit: uint64(a.GasLimit),
 		GasPrice: (*big.Int)(a.GasPrice),
 		Data:     a.Data,
+
+		TxValidityWindow: a.TxValidityWindow,
+		TxFeeDelegation:  a.TxFeeDelegation,
+		TxDynamicFee:     TxDynamicFee{GasTipCap: (*big.Int)(a.GasTipCap)},
 	}
 }
 
@@ -788,12 +859,15 @@ func (_ *SmartContractTx) AssertIsTx() {}
 func (tx *SmartContractTx) SignBytes(chainID string) []byte {
 	signBytes := encodeToBytes(chainID)
 	sig := tx.From.Signature
+	feePayerSig := tx.FeePayerSignature
 	tx.From.Signature = nil
+	tx.FeePayerSignature = nil
 	txBytes, _ := TxToBytes(tx)
 	signBytes = append(signBytes, txBytes...)
 	signBytes = addPrefixForSignBytes(signBytes)
 
 	tx.From.Signature = sig
+	tx.FeePayerSignature = feePayerSig
 	return signBytes
 }
 
@@ -814,6 +888,18 @@ func RLPHash(x interface{}) (h common.Hash) {
 	return h
 }
 
+// PrefixedRLPHash writes the prefix into the hasher before RLP encoding x, as for the signing
+// hash of the typed ETH transactions.
+func PrefixedRLPHash(prefix byte, x interface{}) (h common.Hash) {
+	sha := hasherPool.Get().(crypto.KeccakState)
+	defer hasherPool.Put(sha)
+	sha.Reset()
+	sha.Write([]byte{prefix})
+	rlp.Encode(sha, x)
+	sha.Read(h[:])
+	return h
+}
+
 func (tx *SmartContractTx) EthSigningHash(chainID string, blockHeight uint64) common.Hash {
 	ethChainID := MapChainID(chainID, blockHeight)
 
@@ -822,6 +908,21 @@ func (tx *SmartContractTx) EthSigningHash(chainID string, blockHeight uint64) co
 		toAddress = &tx.To.Address
 	}
 
+	if tx.HasGasTipCap() {
+		// Translated from an ETH dynamic fee (EIP-1559) transaction with an empty access list
+		return PrefixedRLPHash(EthDynamicFeeTxType, []interface{}{
+			ethChainID,
+			tx.From.Sequence - 1,
+			tx.GasTipCap,
+			tx.GasPrice,
+			tx.GasLimit,
+			toAddress,
+			tx.From.Coins.NoNil().TFuelWei,
+			tx.Data,
+			[]interface{}{},
+		})
+	}
+
 	ethSigningHash := RLPHash([]interface{}{
 		tx.From.Sequence - 1, // off-by-one, ETH tx nonce starts from 0, while Theta tx sequence starts from 1
 		tx.GasPrice,
@@ -855,6 +956,8 @@ type DepositStakeTx struct {
 	Source  TxInput  `json:"source"`  // source staker account
 	Holder  TxOutput `json:"holder"`  // stake holder account
 	Purpose uint8    `json:"purpose"` // purpose e.g. stake for validator/guardian
+
+	TxValidityWindow `rlp:"-"`
 }
 
 func (_ *DepositStakeTx) AssertIsTx() {}
@@ -893,6 +996,8 @@ type DepositStakeTxV2 struct {
 	BlsPubkey *bls.PublicKey    `rlp:"nil"`
 	BlsPop    *bls.Signature    `rlp:"nil"`
 	HolderSig *crypto.Signature `rlp:"nil"`
+
+	TxValidityWindow `rlp:"-"`
 }
 
 func (_ *DepositStakeTxV2) AssertIsTx() {}
@@ -907,6 +1012,8 @@ func (tx *DepositStakeTxV2) SignBytes(chainID string) []byte {
 			Source:  tx.Source,
 			Holder:  tx.Holder,
 			Purpose: tx.Purpose,
+
+			TxValidityWindow: tx.TxValidityWindow,
 		}
 		txBytes, _ = TxToBytes(tmp)
 	} else if tx.Purpose == core.StakeForGuardian {
@@ -943,6 +1050,8 @@ type WithdrawStakeTx struct {
 	Source  TxInput  `json:"source"`  // source staker account
 	Holder  TxOutput `json:"holder"`  // stake holder account
 	Purpose uint8    `json:"purpose"` // purpose e.g. stake for validator/guardian/elite edge node
+
+	TxValidityWindow `rlp:"-"`
 }
 
 func (_ *WithdrawStakeTx) AssertIsTx() {}
@@ -974,6 +1083,265 @@ func (tx *WithdrawStakeTx) String() string {
 
 //-----------------------------------------------------------------------------
 
+//
+// WithdrawStakeTxV2 withdraws the given amount from the stake of the source, i.e. ThetaWei for
+// the validator/guardian stakes, and TFuelWei for the elite edge node stakes. The remaining
+// stake needs to meet the minimum stake deposit of its purpose. Like the stakes withdrawn by
+// WithdrawStakeTx, the amount is returned after the locking period.
+//
+type WithdrawStakeTxV2 struct {
+	Fee     Coins    `json:"fee"`     // Fee
+	Source  TxInput  `json:"source"`  // source staker account
+	Holder  TxOutput `json:"holder"`  // stake holder account
+	Purpose uint8    `json:"purpose"` // purpose e.g. stake for validator/guardian/elite edge node
+	Amount  Coins    `json:"amount"`  // amount of stake to withdraw
+
+	TxValidityWindow `rlp:"-"`
+}
+
+func (_ *WithdrawStakeTxV2) AssertIsTx() {}
+
+func (tx *WithdrawStakeTxV2) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Source.Signature
+	tx.Source.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Source.Signature = sig
+	return signBytes
+}
+
+func (tx *WithdrawStakeTxV2) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Source.Address == addr {
+		tx.Source.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *WithdrawStakeTxV2) String() string {
+	return fmt.Sprintf("WithdrawStakeTxV2{%v <- %v, amount: %v, purpose: %v}",
+		tx.Source.Address, tx.Holder.Address, tx.Amount, tx.Purpose)
+}
+
+//-----------------------------------------------------------------------------
+
+//
+// RedelegateStakeTx moves the whole stake of the source from one guardian/elite edge node to
+// another one of the same purpose, without going through the withdrawal locking period. The
+// BLS summary is only required if the new holder is not in the guardian candidate pool/elite
+// edge node pool yet. A source can redelegate its stake at most once per
+// core.RedelegationCooldown blocks.
+//
+type RedelegateStakeTx struct {
+	Fee        Coins    `json:"fee"`         // Fee
+	Source     TxInput  `json:"source"`      // source staker account
+	FromHolder TxOutput `json:"from_holder"` // current stake holder account
+	ToHolder   TxOutput `json:"to_holder"`   // new stake holder account
+	Purpose    uint8    `json:"purpose"`     // purpose e.g. stake for guardian/elite edge node
+
+	BlsPubkey *bls.PublicKey    `rlp:"nil"`
+	BlsPop    *bls.Signature    `rlp:"nil"`
+	HolderSig *crypto.Signature `rlp:"nil"` // signature of the new holder over BlsPop
+
+	TxValidityWindow `rlp:"-"`
+}
+
+func (_ *RedelegateStakeTx) AssertIsTx() {}
+
+func (tx *RedelegateStakeTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Source.Signature
+	tx.Source.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Source.Signature = sig
+	return signBytes
+}
+
+func (tx *RedelegateStakeTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Source.Address == addr {
+		tx.Source.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *RedelegateStakeTx) String() string {
+	return fmt.Sprintf("RedelegateStakeTx{%v: %v -> %v, purpose: %v, BlsPubkey: %v, BlsPop: %v}",
+		tx.Source.Address, tx.FromHolder.Address, tx.ToHolder.Address, tx.Purpose, tx.BlsPubkey, tx.BlsPop)
+}
+
+//-----------------------------------------------------------------------------
+
+//
+// GovernanceProposalTx submits a proposal to change a set of governable protocol parameters.
+// The proposer needs to be a validator or guardian stake holder. The proposal is open for
+// voting for GovernanceVotingPeriod blocks.
+//
+type GovernanceProposalTx struct {
+	Fee      Coins         `json:"fee"`      // Fee
+	Proposer TxInput       `json:"proposer"` // validator or guardian stake holder account
+	Changes  []ParamChange `json:"changes"`  // proposed parameter changes
+
+	TxValidityWindow `rlp:"-"`
+}
+
+func (_ *GovernanceProposalTx) AssertIsTx() {}
+
+func (tx *GovernanceProposalTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Proposer.Signature
+	tx.Proposer.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Proposer.Signature = sig
+	return signBytes
+}
+
+func (tx *GovernanceProposalTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Proposer.Address == addr {
+		tx.Proposer.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *GovernanceProposalTx) String() string {
+	return fmt.Sprintf("GovernanceProposalTx{%v, changes: %v}", tx.Proposer.Address, tx.Changes)
+}
+
+//-----------------------------------------------------------------------------
+
+//
+// GovernanceVoteTx votes on a governance proposal. The voter needs to be a validator or guardian
+// stake holder, and its vote is weighted by the stake it holds when the proposal is executed.
+// Voting again replaces the earlier vote.
+//
+type GovernanceVoteTx struct {
+	Fee        Coins   `json:"fee"`         // Fee
+	Voter      TxInput `json:"voter"`       // validator or guardian stake holder account
+	ProposalID uint64  `json:"proposal_id"` // ID of the proposal
+	Approve    bool    `json:"approve"`     // whether the voter approves the proposal
+
+	TxValidityWindow `rlp:"-"`
+}
+
+func (_ *GovernanceVoteTx) AssertIsTx() {}
+
+func (tx *GovernanceVoteTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Voter.Signature
+	tx.Voter.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Voter.Signature = sig
+	return signBytes
+}
+
+func (tx *GovernanceVoteTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Voter.Address == addr {
+		tx.Voter.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *GovernanceVoteTx) String() string {
+	return fmt.Sprintf("GovernanceVoteTx{%v, proposal: %v, approve: %v}", tx.Voter.Address, tx.ProposalID, tx.Approve)
+}
+
+//-----------------------------------------------------------------------------
+
+//
+// GovernanceExecuteTx applies the parameter changes of an approved proposal. Anyone can submit
+// it before the voting period of the proposal ends. The proposal is approved if the voters in
+// favor hold more than 2/3 of the total validator and guardian stake. The new parameter values
+// take effect from the next block.
+//
+type GovernanceExecuteTx struct {
+	Fee        Coins   `json:"fee"`         // Fee
+	Source     TxInput `json:"source"`      // account submitting the execution
+	ProposalID uint64  `json:"proposal_id"` // ID of the proposal
+
+	TxValidityWindow `rlp:"-"`
+}
+
+func (_ *GovernanceExecuteTx) AssertIsTx() {}
+
+func (tx *GovernanceExecuteTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Source.Signature
+	tx.Source.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Source.Signature = sig
+	return signBytes
+}
+
+func (tx *GovernanceExecuteTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Source.Address == addr {
+		tx.Source.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *GovernanceExecuteTx) String() string {
+	return fmt.Sprintf("GovernanceExecuteTx{%v, proposal: %v}", tx.Source.Address, tx.ProposalID)
+}
+
+//-----------------------------------------------------------------------------
+
+//
+// EvidenceTx submits the evidence that a validator voted for two different blocks at the same
+// height. It can only be included by the block proposer. EquivocationSlashPercentage of all
+// the stakes deposited to the offender is burned, and the rest is locked until the offender is
+// released from jail, EquivocationJailPeriod blocks later.
+//
+type EvidenceTx struct {
+	Proposer TxInput                    `json:"proposer"` // proposer of the block including the tx
+	Evidence *core.EquivocationEvidence `json:"evidence"` // the conflicting votes
+}
+
+func (_ *EvidenceTx) AssertIsTx() {}
+
+func (tx *EvidenceTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Proposer.Signature
+	tx.Proposer.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Proposer.Signature = sig
+	return signBytes
+}
+
+func (tx *EvidenceTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Proposer.Address == addr {
+		tx.Proposer.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *EvidenceTx) String() string {
+	return fmt.Sprintf("EvidenceTx{%v, evidence: %v}", tx.Proposer.Address, tx.Evidence)
+}
+
+//-----------------------------------------------------------------------------
+
 //
 // StakeRewardDistributionTx needs to be signed and submitted by the "stake holders", i.e. a guardian or an elite edge node.
 // It allows the stake holder to specify a "beneficiary" to receive a fraction of the Theta/TFuel staking reward. The split fraction
@@ -991,6 +1359,8 @@ type StakeRewardDistributionTx struct {
 	Beneficiary     TxOutput `json:"beneficiary"`       // the beneficiary to split the reward as the hosting service fee
 	SplitBasisPoint uint     `json:"split_basis_point"` // An integer between 0 and 10000, representing the fraction of the reward the beneficiary should get (in terms of 1/10000), https://en.wikipedia.org/wiki/Basis_point
 	//Purpose         uint8    `json:"purpose"`           // purpose e.g. stake for guardian/elite edge node
+
+	TxValidityWindow `rlp:"-"`
 }
 
 func (_ *StakeRewardDistributionTx) AssertIsTx() {}
@@ -1096,15 +1466,16 @@ func ChangeEthereumTxWrapper(origSignBytes common.Bytes, wrapperVersion uint) co
 const CHAIN_ID_OFFSET int64 = 360
 
 func MapChainID(chainIDStr string, blockHeight uint64) *big.Int {
+	forks := common.GetForkSchedule(chainIDStr)
 	chainIDWithoutOffset := mapChainIDWithoutOffset(chainIDStr)
-	if blockHeight < common.HeightRPCCompatibility {
+	if blockHeight < forks.RPCCompatibility {
 		return chainIDWithoutOffset
 	}
 
 	// For replay attack protection, should NOT use the same chainID as Ethereum
 	chainID := big.NewInt(1).Add(big.NewInt(CHAIN_ID_OFFSET), chainIDWithoutOffset)
 
-	if blockHeight < common.HeightEnableMetachainSupport {
+	if blockHeight < forks.EnableMetachainSupport {
 		return chainID
 	} else {
 		// attempt to extract the IDs for subchains. ChainID in the form of tsub[1-9][0-9]* is considered as a Theta Subchain
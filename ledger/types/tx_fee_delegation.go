@@ -0,0 +1,67 @@
+package types
+
+import (
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+)
+
+// ** Fee Delegation: the fee of a transaction paid by a sponsor account **
+//
+
+// feePayerSignBytesPrefix separates the bytes signed by the fee payer from the sign bytes of
+// the other signers of a transaction
+var feePayerSignBytesPrefix = []byte("theta_fee_payer")
+
+// TxFeeDelegation is embedded in the transactions whose fee can be paid by a sponsor account
+// instead of the sender. The fee payer address is covered by the signatures of the other
+// signers, so they agree on who pays the fee. The fee payer signs last, over the whole
+// transaction (see FeePayerSignBytes). Like the validity window, the fee payer is carried by
+// the versioned tx encoding.
+type TxFeeDelegation struct {
+	FeePayer          *common.Address   `json:"fee_payer,omitempty"`
+	FeePayerSignature *crypto.Signature `json:"fee_payer_signature,omitempty"`
+}
+
+// TxWithFeeDelegation is implemented by the transaction types whose fee can be paid by a sponsor
+type TxWithFeeDelegation interface {
+	Tx
+	GetFeeDelegation() *TxFeeDelegation
+}
+
+// GetFeeDelegation returns the fee delegation of the transaction
+func (d *TxFeeDelegation) GetFeeDelegation() *TxFeeDelegation {
+	return d
+}
+
+// SetFeePayer sets the account paying the fee of the transaction. It needs to be called before
+// the transaction is signed.
+func (d *TxFeeDelegation) SetFeePayer(feePayer common.Address) {
+	d.FeePayer = &feePayer
+}
+
+// SetFeePayerSignature sets the signature of the fee payer
+func (d *TxFeeDelegation) SetFeePayerSignature(sig *crypto.Signature) {
+	d.FeePayerSignature = sig
+}
+
+// HasFeePayer returns true if the fee of the transaction is paid by a sponsor
+func (d *TxFeeDelegation) HasFeePayer() bool {
+	return d.FeePayer != nil
+}
+
+// FeePayerSignBytes returns the bytes to be signed by the fee payer of the transaction. Unlike
+// the sign bytes of the other signers, they include the signatures of the other signers.
+func FeePayerSignBytes(chainID string, tx TxWithFeeDelegation) []byte {
+	delegation := tx.GetFeeDelegation()
+	sig := delegation.FeePayerSignature
+	delegation.FeePayerSignature = nil
+
+	signBytes := encodeToBytes(chainID)
+	signBytes = append(signBytes, feePayerSignBytesPrefix...)
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	delegation.FeePayerSignature = sig
+	return signBytes
+}
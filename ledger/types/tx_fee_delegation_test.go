@@ -0,0 +1,63 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/rlp"
+)
+
+func TestFeeDelegationEncoding(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	feePayer := common.HexToAddress("0x350ddb6b7dd3e5e2ba0ea8f7e8eb6ebdb1d0e0b4")
+	feePayerSig, err := crypto.SignatureFromBytes(common.Hex2Bytes("ab16b5e3e6a3b7fc0d3f5e1a62e3b47a4d0a1a94e5b4c76b2a87a0ee8e7e1d6b07d3a5d2fd1a36c7d8e4fe8a4c1fd79b4e8fc0b3ddd8f7d8a3e4e23f6c7bb3f501"))
+	require.Nil(err)
+
+	tx := newTestWindowSendTx()
+	plainSignBytes := tx.SignBytes(chainID)
+
+	tx.SetFeePayer(feePayer)
+	signBytes := tx.SignBytes(chainID)
+	assert.False(bytes.Equal(plainSignBytes, signBytes))
+	assert.False(bytes.Equal(signBytes, FeePayerSignBytes(chainID, tx)))
+
+	// The signature of the fee payer is not covered by the sign bytes
+	tx.SetFeePayerSignature(feePayerSig)
+	assert.Equal(signBytes, tx.SignBytes(chainID))
+
+	tx.SetValidityWindow(0, 500)
+	raw, err := TxToBytes(tx)
+	require.Nil(err)
+	decoded, err := TxFromBytes(raw)
+	require.Nil(err)
+	decodedTx := decoded.(*SendTx)
+	require.True(decodedTx.HasFeePayer())
+	assert.Equal(feePayer, *decodedTx.FeePayer)
+	assert.Equal(feePayerSig.ToBytes(), decodedTx.FeePayerSignature.ToBytes())
+	assert.Equal(uint64(500), decodedTx.ValidUntilHeight)
+	assert.Equal(FeePayerSignBytes(chainID, tx), FeePayerSignBytes(chainID, decodedTx))
+
+	// Fee delegation is not supported by all the tx types
+	withdrawBytes, err := TxToBytes(&WithdrawStakeTx{})
+	require.Nil(err)
+	typeBytes, err := rlp.EncodeToBytes(TxVersioned)
+	require.Nil(err)
+	vtxBytes, err := rlp.EncodeToBytes(&versionedTxV2{Version: TxVersionV2, FeePayer: feePayer, Tx: withdrawBytes})
+	require.Nil(err)
+	_, err = TxFromBytes(append(typeBytes, vtxBytes...))
+	assert.NotNil(err)
+
+	// A V2 payload needs a fee payer
+	plainBytes, err := TxToBytes(newTestWindowSendTx())
+	require.Nil(err)
+	vtxBytes, err = rlp.EncodeToBytes(&versionedTxV2{Version: TxVersionV2, ValidUntilHeight: 10, Tx: plainBytes})
+	require.Nil(err)
+	_, err = TxFromBytes(append(typeBytes, vtxBytes...))
+	assert.NotNil(err)
+}
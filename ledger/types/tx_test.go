@@ -26,19 +26,19 @@ func TestChainID(t *testing.T) {
 
 	chainIDStrMainnet := "mainnet"
 	chainIDStr := chainIDStrMainnet
-	chainID := MapChainID(chainIDStr, common.HeightRPCCompatibility+1)
+	chainID := MapChainID(chainIDStr, common.MainnetForkSchedule.RPCCompatibility+1)
 	assert.True(t, chainID.Cmp(big.NewInt(361)) == 0, "mapped chainID for %v is %v", chainIDStr, chainID)
 	fmt.Printf("extracted chainID for %v: %v\n", chainIDStr, chainID)
 
 	chainIDStrTestnet := "testnet"
 	chainIDStr = chainIDStrTestnet
-	chainID = MapChainID(chainIDStr, common.HeightRPCCompatibility+1)
+	chainID = MapChainID(chainIDStr, common.MainnetForkSchedule.RPCCompatibility+1)
 	assert.True(t, chainID.Cmp(big.NewInt(365)) == 0, "mapped chainID for %v is %v", chainIDStr, chainID)
 	fmt.Printf("extracted chainID for %v: %v\n", chainIDStr, chainID)
 
 	chainIDStrPrivatenet := "privatenet"
 	chainIDStr = chainIDStrPrivatenet
-	chainID = MapChainID(chainIDStr, common.HeightRPCCompatibility+1)
+	chainID = MapChainID(chainIDStr, common.MainnetForkSchedule.RPCCompatibility+1)
 	assert.True(t, chainID.Cmp(big.NewInt(366)) == 0, "mapped chainID for %v is %v", chainIDStr, chainID)
 	fmt.Printf("extracted chainID for %v: %v\n", chainIDStr, chainID)
 
@@ -51,7 +51,7 @@ func TestChainID(t *testing.T) {
 	chainIDStr = invalidSubchainID0
 	chainID, err = extractSubchainID(chainIDStr)
 	assert.True(t, err != nil, "should be an invalid subchain ID: %v", chainIDStr)
-	chainID = MapChainID(chainIDStr, common.HeightEnableMetachainSupport+1)
+	chainID = MapChainID(chainIDStr, common.MainnetForkSchedule.EnableMetachainSupport+1)
 	assert.True(t, chainID.Cmp(big.NewInt(881)) != 0, "mapped chainID for %v is %v", chainIDStr, chainID)
 	fmt.Printf("extracted chainID for %v: %v\n", chainIDStr, chainID)
 
@@ -59,7 +59,7 @@ func TestChainID(t *testing.T) {
 	chainIDStr = invalidSubchainID1
 	chainID, err = extractSubchainID(chainIDStr)
 	assert.True(t, err != nil, "should be an invalid subchain ID: %v", chainIDStr)
-	chainID = MapChainID(chainIDStr, common.HeightEnableMetachainSupport+1)
+	chainID = MapChainID(chainIDStr, common.MainnetForkSchedule.EnableMetachainSupport+1)
 	assert.True(t, chainID.Cmp(big.NewInt(881)) != 0, "mapped chainID for %v is %v", chainIDStr, chainID)
 	fmt.Printf("extracted chainID for %v: %v\n", chainIDStr, chainID)
 
@@ -67,7 +67,7 @@ func TestChainID(t *testing.T) {
 	chainIDStr = invalidSubchainID2
 	chainID, err = extractSubchainID(chainIDStr)
 	assert.True(t, err != nil, "should be an invalid subchain ID: %v", chainIDStr)
-	chainID = MapChainID(chainIDStr, common.HeightEnableMetachainSupport+1)
+	chainID = MapChainID(chainIDStr, common.MainnetForkSchedule.EnableMetachainSupport+1)
 	assert.True(t, chainID.Cmp(big.NewInt(9998)) != 0, "mapped chainID for %v is %v", chainIDStr, chainID)
 	fmt.Printf("extracted chainID for %v: %v\n", chainIDStr, chainID)
 
@@ -75,7 +75,7 @@ func TestChainID(t *testing.T) {
 	chainIDStr = invalidSubchainID3
 	chainID, err = extractSubchainID(chainIDStr)
 	assert.True(t, err != nil, "should be an invalid subchain ID: %v", chainIDStr)
-	chainID = MapChainID(chainIDStr, common.HeightEnableMetachainSupport+1)
+	chainID = MapChainID(chainIDStr, common.MainnetForkSchedule.EnableMetachainSupport+1)
 	assert.True(t, chainID.Cmp(big.NewInt(43977)) != 0, "mapped chainID for %v is %v", chainIDStr, chainID)
 	fmt.Printf("extracted chainID for %v: %v\n", chainIDStr, chainID)
 
@@ -83,7 +83,7 @@ func TestChainID(t *testing.T) {
 	chainIDStr = invalidSubchainID4
 	chainID, err = extractSubchainID(chainIDStr)
 	assert.True(t, err != nil, "should be an invalid subchain ID: %v", chainIDStr)
-	chainID = MapChainID(chainIDStr, common.HeightEnableMetachainSupport+1)
+	chainID = MapChainID(chainIDStr, common.MainnetForkSchedule.EnableMetachainSupport+1)
 	assert.True(t, chainID.Cmp(big.NewInt(999)) != 0, "mapped chainID for %v is %v", chainIDStr, chainID)
 	fmt.Printf("extracted chainID for %v: %v\n", chainIDStr, chainID)
 
@@ -91,7 +91,7 @@ func TestChainID(t *testing.T) {
 	chainIDStr = invalidSubchainID5
 	chainID, err = extractSubchainID(chainIDStr)
 	assert.True(t, err != nil, "should be an invalid subchain ID: %v", chainIDStr)
-	chainID = MapChainID(chainIDStr, common.HeightEnableMetachainSupport+1)
+	chainID = MapChainID(chainIDStr, common.MainnetForkSchedule.EnableMetachainSupport+1)
 	cid, _ := big.NewInt(0).SetString("34535873957238957239573985728957283957923528357238572893572983457238957238495893", 10)
 	assert.True(t, chainID.Cmp(cid) != 0, "mapped chainID for %v is %v", chainIDStr, chainID)
 	fmt.Printf("extracted chainID for %v: %v\n", chainIDStr, chainID)
@@ -102,13 +102,13 @@ func TestChainID(t *testing.T) {
 
 	validSubchainID1 := "tsub1991"
 	chainIDStr = validSubchainID1
-	chainID = MapChainID(chainIDStr, common.HeightEnableMetachainSupport+1)
+	chainID = MapChainID(chainIDStr, common.MainnetForkSchedule.EnableMetachainSupport+1)
 	assert.True(t, chainID.Cmp(big.NewInt(1991)) == 0, "mapped chainID for %v is %v", chainIDStr, chainID)
 	fmt.Printf("extracted chainID for %v: %v\n", chainIDStr, chainID)
 
 	validSubchainID2 := "tsub4546325235"
 	chainIDStr = validSubchainID2
-	chainID = MapChainID(chainIDStr, common.HeightEnableMetachainSupport+1)
+	chainID = MapChainID(chainIDStr, common.MainnetForkSchedule.EnableMetachainSupport+1)
 	assert.True(t, chainID.Cmp(big.NewInt(4546325235)) == 0, "mapped chainID for %v is %v", chainIDStr, chainID)
 	fmt.Printf("extracted chainID for %v: %v\n", chainIDStr, chainID)
 
@@ -135,7 +135,7 @@ func TestCoinbaseTxSignable(t *testing.T) {
 	}
 	signBytes := coinbaseTx.SignBytes(chainID)
 	signBytesHex := fmt.Sprintf("%X", signBytes)
-	expected := "F87F80808094000000000000000000000000000000000000000080B8648D746573745F636861696E5F696480F853DA94B23369B1225E72332462A75C1B7F509A805E3D6EC280800180F6DA9476616C696461746F723100000000000000000000C482014D80DA9476616C696461746F723100000000000000000000C48201BC800A"
+	expected := "F87F80808094000000000000000000000000000000000000000080B8648D746573745F636861696E5F696480F853DA9417FDFEE61ADD5CF0F0E5CF3D24CB4A137152434CC280800180F6DA9476616C696461746F723100000000000000000000C482014D80DA9476616C696461746F723100000000000000000000C48201BC800A"
 
 	assert.Equal(t, expected, signBytesHex,
 		"Got unexpected sign string for CoinbaseTx. Expected:\n%v\nGot:\n%v", expected, signBytesHex)
@@ -270,7 +270,7 @@ func TestSlashTxSignable(t *testing.T) {
 	}
 	signBytes := slashTx.SignBytes(chainID)
 	signBytesHex := fmt.Sprintf("%X", signBytes)
-	expected := "F86280808094000000000000000000000000000000000000000080B8478A746573745F636861696E01F839DA94B23369B1225E72332462A75C1B7F509A805E3D6EC280800180943031344641420000000000000000000000000000018732333435414243"
+	expected := "F86280808094000000000000000000000000000000000000000080B8478A746573745F636861696E01F839DA9417FDFEE61ADD5CF0F0E5CF3D24CB4A137152434CC280800180943031344641420000000000000000000000000000018732333435414243"
 
 	assert.Equal(t, expected, signBytesHex,
 		"Got unexpected sign string for CoinbaseTx. Expected:\n%v\nGot:\n%v", expected, signBytesHex)
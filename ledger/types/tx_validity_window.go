@@ -0,0 +1,64 @@
+package types
+
+import (
+	"github.com/thetatoken/theta/common/result"
+)
+
+// ** Tx Validity Window: the range of block heights in which a transaction can be included **
+//
+
+// TxValidityWindow is embedded in the transactions signed by the users. It bounds the block
+// heights at which the transaction can be included, so a signed transaction does not stay
+// valid forever. A zero height means the corresponding side of the window is unbounded. The
+// window is not part of the RLP encoding of the transaction itself, instead a transaction with
+// a window is wrapped into the versioned tx encoding (see TxToBytes), which is covered by the
+// signatures since the sign bytes are derived from TxToBytes.
+type TxValidityWindow struct {
+	ValidFromHeight  uint64 `json:"valid_from_height,omitempty"`  // the tx cannot be included before this height
+	ValidUntilHeight uint64 `json:"valid_until_height,omitempty"` // the tx cannot be included after this height
+}
+
+// TxWithValidityWindow is implemented by the transaction types which can carry a validity window
+type TxWithValidityWindow interface {
+	Tx
+	GetValidityWindow() *TxValidityWindow
+}
+
+// GetValidityWindow returns the validity window of the transaction
+func (w *TxValidityWindow) GetValidityWindow() *TxValidityWindow {
+	return w
+}
+
+// SetValidityWindow sets the validity window of the transaction. It needs to be called before
+// the transaction is signed.
+func (w *TxValidityWindow) SetValidityWindow(validFromHeight, validUntilHeight uint64) {
+	w.ValidFromHeight = validFromHeight
+	w.ValidUntilHeight = validUntilHeight
+}
+
+// HasValidityWindow returns true if either side of the window is bounded
+func (w *TxValidityWindow) HasValidityWindow() bool {
+	return w.ValidFromHeight != 0 || w.ValidUntilHeight != 0
+}
+
+// IsExpired returns true if the transaction can no longer be included at the given block height
+func (w *TxValidityWindow) IsExpired(blockHeight uint64) bool {
+	return w.ValidUntilHeight != 0 && blockHeight > w.ValidUntilHeight
+}
+
+// CheckValidityWindow checks if the transaction can be included at the given block height
+func (w *TxValidityWindow) CheckValidityWindow(blockHeight uint64) result.Result {
+	if w.ValidUntilHeight != 0 && w.ValidFromHeight > w.ValidUntilHeight {
+		return result.Error("Invalid tx validity window, valid_from_height %v is greater than valid_until_height %v",
+			w.ValidFromHeight, w.ValidUntilHeight)
+	}
+	if w.IsExpired(blockHeight) {
+		return result.Error("Tx expired at height %v, current block height: %v", w.ValidUntilHeight, blockHeight).
+			WithErrorCode(result.CodeTxExpired)
+	}
+	if blockHeight < w.ValidFromHeight {
+		return result.Error("Tx is not valid until height %v, current block height: %v", w.ValidFromHeight, blockHeight).
+			WithErrorCode(result.CodeTxNotYetValid)
+	}
+	return result.OK
+}
@@ -0,0 +1,117 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/rlp"
+)
+
+func newTestWindowSendTx() *SendTx {
+	return &SendTx{
+		Fee: NewCoins(0, 1e12),
+		Inputs: []TxInput{{
+			Address:  common.HexToAddress("0x2e833968e5bb786ae419c4d13189fb081cc43bab"),
+			Coins:    NewCoins(10, 1e12),
+			Sequence: 3,
+		}},
+		Outputs: []TxOutput{{
+			Address: common.HexToAddress("0x9f1233798e905e173560071255140b4a8abd3ec6"),
+			Coins:   NewCoins(10, 0),
+		}},
+	}
+}
+
+func TestTxValidityWindowEncoding(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tx := newTestWindowSendTx()
+	plainBytes, err := TxToBytes(tx)
+	require.Nil(err)
+	plainSignBytes := tx.SignBytes(chainID)
+
+	tx.SetValidityWindow(100, 200)
+	versionedBytes, err := TxToBytes(tx)
+	require.Nil(err)
+	assert.False(bytes.Equal(plainBytes, versionedBytes))
+	assert.False(bytes.Equal(plainSignBytes, tx.SignBytes(chainID)))
+
+	decoded, err := TxFromBytes(versionedBytes)
+	require.Nil(err)
+	decodedTx, ok := decoded.(*SendTx)
+	require.True(ok)
+	assert.Equal(uint64(100), decodedTx.ValidFromHeight)
+	assert.Equal(uint64(200), decodedTx.ValidUntilHeight)
+	assert.Equal(tx.SignBytes(chainID), decodedTx.SignBytes(chainID))
+
+	reencoded, err := TxToBytes(decodedTx)
+	require.Nil(err)
+	assert.Equal(versionedBytes, reencoded)
+
+	// A tx without a window keeps the regular encoding
+	decoded, err = TxFromBytes(plainBytes)
+	require.Nil(err)
+	assert.False(decoded.(*SendTx).HasValidityWindow())
+}
+
+func TestTxValidityWindowInvalidEncoding(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	plainBytes, err := TxToBytes(newTestWindowSendTx())
+	require.Nil(err)
+
+	encode := func(vtx *versionedTx) []byte {
+		typeBytes, err := rlp.EncodeToBytes(TxVersioned)
+		require.Nil(err)
+		vtxBytes, err := rlp.EncodeToBytes(vtx)
+		require.Nil(err)
+		return append(typeBytes, vtxBytes...)
+	}
+
+	// Empty window
+	_, err = TxFromBytes(encode(&versionedTx{Version: TxVersionV1, Tx: plainBytes}))
+	assert.NotNil(err)
+
+	// Unknown version
+	_, err = TxFromBytes(encode(&versionedTx{Version: 2, ValidUntilHeight: 10, Tx: plainBytes}))
+	assert.NotNil(err)
+
+	// Nested versioned tx
+	tx := newTestWindowSendTx()
+	tx.SetValidityWindow(0, 10)
+	versionedBytes, err := TxToBytes(tx)
+	require.Nil(err)
+	_, err = TxFromBytes(encode(&versionedTx{Version: TxVersionV1, ValidUntilHeight: 20, Tx: versionedBytes}))
+	assert.NotNil(err)
+
+	// Tx type without the validity window
+	coinbaseBytes, err := TxToBytes(&CoinbaseTx{})
+	require.Nil(err)
+	_, err = TxFromBytes(encode(&versionedTx{Version: TxVersionV1, ValidUntilHeight: 20, Tx: coinbaseBytes}))
+	assert.NotNil(err)
+}
+
+func TestTxValidityWindowCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	window := TxValidityWindow{}
+	assert.True(window.CheckValidityWindow(1).IsOK())
+
+	window.SetValidityWindow(100, 200)
+	assert.Equal(result.CodeTxNotYetValid, window.CheckValidityWindow(99).Code)
+	assert.True(window.CheckValidityWindow(100).IsOK())
+	assert.True(window.CheckValidityWindow(200).IsOK())
+	assert.Equal(result.CodeTxExpired, window.CheckValidityWindow(201).Code)
+
+	window.SetValidityWindow(0, 200)
+	assert.True(window.CheckValidityWindow(1).IsOK())
+
+	window.SetValidityWindow(300, 200)
+	assert.True(window.CheckValidityWindow(250).IsError())
+}
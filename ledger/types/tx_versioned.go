@@ -0,0 +1,241 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/rlp"
+)
+
+// ** Versioned Tx: the encoding of the transactions which carry optional fields **
+//
+// The optional fields, e.g. the validity window, are not part of the RLP encoding of the
+// transaction types, so the encoding of the transactions without them stays unchanged. A
+// transaction with any of them set is encoded as TxVersioned followed by one of the payloads
+// below, which wrap the regular encoding of the transaction.
+
+const (
+	TxVersionV1 uint64 = 1 // carries the validity window
+	TxVersionV2 uint64 = 2 // carries the validity window and the fee payer
+	TxVersionV3 uint64 = 3 // carries the validity window, the optional fee payer and the tip cap
+)
+
+type versionedTx struct {
+	Version          uint64
+	ValidFromHeight  uint64
+	ValidUntilHeight uint64
+	Tx               []byte
+}
+
+type versionedTxV2 struct {
+	Version           uint64
+	ValidFromHeight   uint64
+	ValidUntilHeight  uint64
+	FeePayer          common.Address
+	FeePayerSignature *crypto.Signature
+	Tx                []byte
+}
+
+type versionedTxV3 struct {
+	Version           uint64
+	ValidFromHeight   uint64
+	ValidUntilHeight  uint64
+	FeePayer          common.Address // zero if the fee is paid by the sender
+	FeePayerSignature *crypto.Signature
+	GasTipCap         *big.Int
+	Tx                []byte
+}
+
+func getValidityWindow(t Tx) *TxValidityWindow {
+	if wtx, ok := t.(TxWithValidityWindow); ok {
+		return wtx.GetValidityWindow()
+	}
+	return nil
+}
+
+func getFeeDelegation(t Tx) *TxFeeDelegation {
+	if dtx, ok := t.(TxWithFeeDelegation); ok {
+		return dtx.GetFeeDelegation()
+	}
+	return nil
+}
+
+func getDynamicFee(t Tx) *TxDynamicFee {
+	if ftx, ok := t.(TxWithDynamicFee); ok {
+		return ftx.GetDynamicFee()
+	}
+	return nil
+}
+
+// needsVersionedEncoding returns true if any of the optional fields of the transaction is set
+func needsVersionedEncoding(t Tx) bool {
+	if window := getValidityWindow(t); window != nil && window.HasValidityWindow() {
+		return true
+	}
+	if delegation := getFeeDelegation(t); delegation != nil && delegation.HasFeePayer() {
+		return true
+	}
+	if dynamicFee := getDynamicFee(t); dynamicFee != nil && dynamicFee.HasGasTipCap() {
+		return true
+	}
+	return false
+}
+
+// encodeVersionedTx wraps the regular encoding of a transaction with its optional fields. It
+// picks the lowest version which can carry the fields that are set.
+func encodeVersionedTx(t Tx, txBytes []byte) ([]byte, error) {
+	window := TxValidityWindow{}
+	if w := getValidityWindow(t); w != nil {
+		window = *w
+	}
+
+	var payload interface{}
+	delegation := getFeeDelegation(t)
+	hasFeePayer := delegation != nil && delegation.HasFeePayer()
+	if dynamicFee := getDynamicFee(t); dynamicFee != nil && dynamicFee.HasGasTipCap() {
+		vtx := &versionedTxV3{
+			Version:          TxVersionV3,
+			ValidFromHeight:  window.ValidFromHeight,
+			ValidUntilHeight: window.ValidUntilHeight,
+			GasTipCap:        dynamicFee.GasTipCap,
+			Tx:               txBytes,
+		}
+		if hasFeePayer {
+			vtx.FeePayer = *delegation.FeePayer
+			vtx.FeePayerSignature = delegation.FeePayerSignature
+		}
+		payload = vtx
+	} else if hasFeePayer {
+		payload = &versionedTxV2{
+			Version:           TxVersionV2,
+			ValidFromHeight:   window.ValidFromHeight,
+			ValidUntilHeight:  window.ValidUntilHeight,
+			FeePayer:          *delegation.FeePayer,
+			FeePayerSignature: delegation.FeePayerSignature,
+			Tx:                txBytes,
+		}
+	} else {
+		payload = &versionedTx{
+			Version:          TxVersionV1,
+			ValidFromHeight:  window.ValidFromHeight,
+			ValidUntilHeight: window.ValidUntilHeight,
+			Tx:               txBytes,
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := rlp.Encode(&buf, TxVersioned); err != nil {
+		return nil, err
+	}
+	if err := rlp.Encode(&buf, payload); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeVersionedTx decodes the payload of the TxVersioned encoding, and sets the optional
+// fields of the wrapped transaction. Each transaction has a single encoding, so a payload which
+// could have been encoded with a lower version is rejected.
+func decodeVersionedTx(s *rlp.Stream) (Tx, error) {
+	raw, err := s.Raw()
+	if err != nil {
+		return nil, err
+	}
+	version, err := peekTxVersion(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var window TxValidityWindow
+	var delegation TxFeeDelegation
+	var dynamicFee TxDynamicFee
+	var txBytes []byte
+	switch version {
+	case TxVersionV1:
+		vtx := &versionedTx{}
+		if err := rlp.DecodeBytes(raw, vtx); err != nil {
+			return nil, err
+		}
+		window.SetValidityWindow(vtx.ValidFromHeight, vtx.ValidUntilHeight)
+		if !window.HasValidityWindow() {
+			return nil, errors.New("Versioned tx without a validity window")
+		}
+		txBytes = vtx.Tx
+	case TxVersionV2:
+		vtx := &versionedTxV2{}
+		if err := rlp.DecodeBytes(raw, vtx); err != nil {
+			return nil, err
+		}
+		window.SetValidityWindow(vtx.ValidFromHeight, vtx.ValidUntilHeight)
+		if (vtx.FeePayer == common.Address{}) {
+			return nil, errors.New("Versioned tx without a fee payer")
+		}
+		delegation.SetFeePayer(vtx.FeePayer)
+		delegation.SetFeePayerSignature(vtx.FeePayerSignature)
+		txBytes = vtx.Tx
+	case TxVersionV3:
+		vtx := &versionedTxV3{}
+		if err := rlp.DecodeBytes(raw, vtx); err != nil {
+			return nil, err
+		}
+		window.SetValidityWindow(vtx.ValidFromHeight, vtx.ValidUntilHeight)
+		if (vtx.FeePayer != common.Address{}) {
+			delegation.SetFeePayer(vtx.FeePayer)
+			delegation.SetFeePayerSignature(vtx.FeePayerSignature)
+		} else if vtx.FeePayerSignature != nil && !vtx.FeePayerSignature.IsEmpty() {
+			return nil, errors.New("Versioned tx with a fee payer signature but without a fee payer")
+		}
+		dynamicFee.SetGasTipCap(vtx.GasTipCap)
+		txBytes = vtx.Tx
+	default:
+		return nil, fmt.Errorf("Unsupported tx version: %v", version)
+	}
+
+	var txType TxType
+	if err := rlp.NewStream(bytes.NewReader(txBytes), maxTxSize).Decode(&txType); err != nil {
+		return nil, err
+	}
+	if txType == TxVersioned {
+		return nil, errors.New("Versioned tx cannot be nested")
+	}
+	tx, err := TxFromBytes(txBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if window.HasValidityWindow() {
+		w := getValidityWindow(tx)
+		if w == nil {
+			return nil, fmt.Errorf("Tx type %v does not support the validity window", txType)
+		}
+		*w = window
+	}
+	if delegation.HasFeePayer() {
+		d := getFeeDelegation(tx)
+		if d == nil {
+			return nil, fmt.Errorf("Tx type %v does not support fee delegation", txType)
+		}
+		*d = delegation
+	}
+	if dynamicFee.HasGasTipCap() {
+		f := getDynamicFee(tx)
+		if f == nil {
+			return nil, fmt.Errorf("Tx type %v does not support the tip cap", txType)
+		}
+		*f = dynamicFee
+	}
+	return tx, nil
+}
+
+// peekTxVersion returns the version of a versioned tx payload, i.e. its first element
+func peekTxVersion(raw []byte) (uint64, error) {
+	s := rlp.NewStream(bytes.NewReader(raw), uint64(len(raw)))
+	if _, err := s.List(); err != nil {
+		return 0, err
+	}
+	return s.Uint()
+}
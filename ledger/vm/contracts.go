@@ -33,7 +33,7 @@ import (
 // requires a deterministic gas count based on the input size of the Run method of the
 // contract.
 type PrecompiledContract interface {
-	RequiredGas(input []byte, blockHeight uint64) uint64                                       // RequiredPrice calculates the contract gas use
+	RequiredGas(input []byte, chainID string, blockHeight uint64) uint64                       // RequiredPrice calculates the contract gas use
 	Run(evm *EVM, input []byte, callerAddr common.Address, contract *Contract) ([]byte, error) // Run runs the precompiled contract
 }
 
@@ -99,7 +99,7 @@ var PrecompiledContractsWrappedThetaSupport = map[common.Address]PrecompiledCont
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
 func RunPrecompiledContract(evm *EVM, p PrecompiledContract, input []byte, contract *Contract) (ret []byte, err error) {
 	blockHeight := evm.StateDB.GetBlockHeight()
-	gas := p.RequiredGas(input, blockHeight)
+	gas := p.RequiredGas(input, evm.ChainID, blockHeight)
 	if contract.UseGas(gas) {
 		callerAddr := contract.CallerAddress
 		return p.Run(evm, input, callerAddr, contract)
@@ -110,7 +110,7 @@ func RunPrecompiledContract(evm *EVM, p PrecompiledContract, input []byte, contr
 // ECRECOVER implemented as a native contract.
 type ecrecover struct{}
 
-func (c *ecrecover) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *ecrecover) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return params.EcrecoverGas
 }
 
@@ -147,7 +147,7 @@ type sha256hash struct{}
 //
 // This method does not require any overflow checking as the input size gas costs
 // required for anything significant is so high it's impossible to pay for.
-func (c *sha256hash) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *sha256hash) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return uint64(len(input)+31)/32*params.Sha256PerWordGas + params.Sha256BaseGas
 }
 func (c *sha256hash) Run(evm *EVM, input []byte, callerAddr common.Address, contract *Contract) ([]byte, error) {
@@ -162,7 +162,7 @@ type ripemd160hash struct{}
 //
 // This method does not require any overflow checking as the input size gas costs
 // required for anything significant is so high it's impossible to pay for.
-func (c *ripemd160hash) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *ripemd160hash) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return uint64(len(input)+31)/32*params.Ripemd160PerWordGas + params.Ripemd160BaseGas
 }
 func (c *ripemd160hash) Run(evm *EVM, input []byte, callerAddr common.Address, contract *Contract) ([]byte, error) {
@@ -178,7 +178,7 @@ type dataCopy struct{}
 //
 // This method does not require any overflow checking as the input size gas costs
 // required for anything significant is so high it's impossible to pay for.
-func (c *dataCopy) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *dataCopy) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return uint64(len(input)+31)/32*params.IdentityPerWordGas + params.IdentityBaseGas
 }
 func (c *dataCopy) Run(evm *EVM, in []byte, callerAddr common.Address, contract *Contract) ([]byte, error) {
@@ -203,7 +203,7 @@ var (
 )
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *bigModExp) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *bigModExp) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	var (
 		baseLen = new(big.Int).SetBytes(getData(input, 0, 32))
 		expLen  = new(big.Int).SetBytes(getData(input, 32, 32))
@@ -314,8 +314,8 @@ func newTwistPoint(blob []byte) (*bn256.G2, error) {
 type bn256Add struct{}
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *bn256Add) RequiredGas(input []byte, blockHeight uint64) uint64 {
-	if blockHeight < common.HeightJune2021FeeAdjustment {
+func (c *bn256Add) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
+	if blockHeight < common.GetForkSchedule(chainID).June2021FeeAdjustment {
 		return params.Bn256AddGas
 	}
 	return params.Bn256AddGasIstanbul
@@ -339,8 +339,8 @@ func (c *bn256Add) Run(evm *EVM, input []byte, callerAddr common.Address, contra
 type bn256ScalarMul struct{}
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *bn256ScalarMul) RequiredGas(input []byte, blockHeight uint64) uint64 {
-	if blockHeight < common.HeightJune2021FeeAdjustment {
+func (c *bn256ScalarMul) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
+	if blockHeight < common.GetForkSchedule(chainID).June2021FeeAdjustment {
 		return params.Bn256ScalarMulGas
 	}
 
@@ -372,8 +372,8 @@ var (
 type bn256Pairing struct{}
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *bn256Pairing) RequiredGas(input []byte, blockHeight uint64) uint64 {
-	if blockHeight < common.HeightJune2021FeeAdjustment {
+func (c *bn256Pairing) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
+	if blockHeight < common.GetForkSchedule(chainID).June2021FeeAdjustment {
 		return params.Bn256PairingBaseGas + uint64(len(input)/192)*params.Bn256PairingPerPointGas
 	}
 
@@ -414,7 +414,7 @@ type thetaBalance struct {
 }
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *thetaBalance) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *thetaBalance) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return params.ThetaBalanceGas
 }
 
@@ -431,7 +431,7 @@ type thetaStake struct {
 }
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *thetaStake) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *thetaStake) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return params.ThetaStakeGas
 }
 
@@ -448,7 +448,7 @@ type transferTheta struct {
 }
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *transferTheta) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *transferTheta) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return params.ThetaTransferGas
 }
 
@@ -470,7 +470,7 @@ type stakeToGuardian struct {
 }
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *stakeToGuardian) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *stakeToGuardian) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return params.StakeToGuardianGas
 }
 
@@ -478,7 +478,7 @@ func (c *stakeToGuardian) Run(evm *EVM, input []byte, callerAddr common.Address,
 	guardianSummary := getData(input, 0, 229)
 	thetaWeiAmount := new(big.Int).SetBytes(getData(input, 229, 32))
 
-	ok := StakeToGuardian(evm.StateDB, callerAddr, guardianSummary, thetaWeiAmount)
+	ok := StakeToGuardian(evm.ChainID, evm.StateDB, callerAddr, guardianSummary, thetaWeiAmount)
 	if !ok {
 		return common.Bytes{}, ErrInvalidStakeOperation
 	}
@@ -491,7 +491,7 @@ type unstakeFromGuardian struct {
 }
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *unstakeFromGuardian) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *unstakeFromGuardian) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return params.UnstakeFromGuardianGas
 }
 
@@ -509,7 +509,7 @@ type stakeToEEN struct {
 }
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *stakeToEEN) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *stakeToEEN) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return params.StakeToGuardianGas
 }
 
@@ -529,7 +529,7 @@ type unstakeFromEEN struct {
 }
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *unstakeFromEEN) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *unstakeFromEEN) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return params.UnstakeFromGuardianGas
 }
 
@@ -1,6 +1,7 @@
 package vm
 
 import (
+	"bytes"
 	"math"
 	"math/big"
 
@@ -25,6 +26,13 @@ func NewBlockInfo(height uint64, timestamp *big.Int, chainID string) *BlockInfo
 
 // Execute executes the given smart contract
 func Execute(parentBlockInfo *BlockInfo, tx *types.SmartContractTx, statedb StateDB) (evmRet common.Bytes,
+	contractAddr common.Address, gasUsed uint64, evmErr error) {
+	return ExecuteWithConfig(parentBlockInfo, tx, statedb, Config{})
+}
+
+// ExecuteWithConfig executes the given smart contract with the given VM config,
+// e.g. with a Tracer attached for debugging
+func ExecuteWithConfig(parentBlockInfo *BlockInfo, tx *types.SmartContractTx, statedb StateDB, config Config) (evmRet common.Bytes,
 	contractAddr common.Address, gasUsed uint64, evmErr error) {
 	context := Context{
 		CanTransfer: CanTransfer,
@@ -35,12 +43,12 @@ func Execute(parentBlockInfo *BlockInfo, tx *types.SmartContractTx, statedb Stat
 		BlockNumber: new(big.Int).SetUint64(parentBlockInfo.Height + 1),
 		Time:        parentBlockInfo.Timestamp,
 		Difficulty:  new(big.Int).SetInt64(0),
+		ChainID:     parentBlockInfo.ChainID,
 	}
 	chainIDBigInt := types.MapChainID(parentBlockInfo.ChainID, context.BlockNumber.Uint64())
 	chainConfig := &params.ChainConfig{
 		ChainID: chainIDBigInt,
 	}
-	config := Config{}
 	evm := NewEVM(context, statedb, chainConfig, config)
 
 	value := tx.From.Coins.TFuelWei
@@ -65,7 +73,7 @@ func Execute(parentBlockInfo *BlockInfo, tx *types.SmartContractTx, statedb Stat
 	// blockHeight := storeView.Height() + 1
 	blockHeight := statedb.GetBlockHeight() // GetBlockHeight() returns storeView.Height() + 1 so it is equivalent to the above commented line
 
-	maxGasLimit := types.GetMaxGasLimit(blockHeight)
+	maxGasLimit := statedb.GetProtocolParam(parentBlockInfo.ChainID, types.ParamMaximumTxGasLimit, blockHeight)
 	if new(big.Int).SetUint64(gasLimit).Cmp(maxGasLimit) > 0 {
 		return common.Bytes{}, common.Address{}, 0, ErrInvalidGasLimit
 	}
@@ -129,3 +137,31 @@ func CalculateIntrinsicGas(data []byte, createContract bool) (uint64, error) {
 	}
 	return gas, nil
 }
+
+// revertSelector is the selector of Error(string), which is used by the Solidity
+// revert() and require() statements to encode the revert reason
+var revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+// IsExecutionReverted returns whether the given EVM error is caused by the REVERT opcode
+func IsExecutionReverted(evmErr error) bool {
+	return evmErr == errExecutionReverted
+}
+
+// UnpackRevertReason extracts the revert reason from the return value of a reverted execution.
+// It returns false if the return value is not an ABI encoded Error(string)
+func UnpackRevertReason(evmRet []byte) (string, bool) {
+	if len(evmRet) < 4+32+32 || !bytes.Equal(evmRet[:4], revertSelector) {
+		return "", false
+	}
+	data := evmRet[4:]
+	offset := new(big.Int).SetBytes(data[:32])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(data)-32) {
+		return "", false
+	}
+	start := offset.Uint64() + 32
+	length := new(big.Int).SetBytes(data[offset.Uint64():start])
+	if !length.IsUint64() || length.Uint64() > uint64(len(data))-start {
+		return "", false
+	}
+	return string(data[start : start+length.Uint64()]), true
+}
@@ -55,6 +55,7 @@ type StateDB interface {
 	SetState(common.Address, common.Hash, common.Hash)
 
 	GetBlockHeight() uint64
+	GetProtocolParam(chainID string, name string, blockHeight uint64) *big.Int // GetProtocolParam returns the active value of a governable protocol parameter
 
 	Suicide(common.Address) bool
 	HasSuicided(common.Address) bool
@@ -39,12 +39,12 @@ type (
 	GetHashFunc func(uint64) common.Hash
 )
 
-func SupportThetaTransferInEVM(blockHeight uint64) bool {
-	return blockHeight >= common.HeightSupportThetaTokenInSmartContract
+func SupportThetaTransferInEVM(chainID string, blockHeight uint64) bool {
+	return blockHeight >= common.GetForkSchedule(chainID).SupportThetaTokenInSmartContract
 }
 
-func SupportWrappedTheta(blockHeight uint64) bool {
-	return blockHeight >= common.HeightSupportWrappedTheta
+func SupportWrappedTheta(chainID string, blockHeight uint64) bool {
+	return blockHeight >= common.GetForkSchedule(chainID).SupportWrappedTheta
 }
 
 // CanTransfer checks whether there are enough funds in the address' account to make a transfer.
@@ -125,7 +125,7 @@ func checkBlsSummary(blsPubkey *bls.PublicKey, blsPop *bls.Signature, holderSig
 }
 
 // StakeToGuardian stake Theta to given guardian node.
-func StakeToGuardian(db StateDB, sender common.Address, guardianSummary []byte, amount *big.Int) bool {
+func StakeToGuardian(chainID string, db StateDB, sender common.Address, guardianSummary []byte, amount *big.Int) bool {
 	// if amount.Cmp(core.MinGuardianStakeDeposit) < 0 {
 	// 	return false
 	// }
@@ -146,7 +146,7 @@ func StakeToGuardian(db StateDB, sender common.Address, guardianSummary []byte,
 		}
 	}
 
-	err := gcp.DepositStake(sender, guardianAddr, amount, blsPubkey, view.GetBlockHeight())
+	err := gcp.DepositStake(chainID, sender, guardianAddr, amount, blsPubkey, view.GetBlockHeight())
 	if err != nil {
 		return false
 	}
@@ -242,11 +242,12 @@ func UnstakeFromEEN(db StateDB, addr common.Address, eenAddr common.Address) boo
 	return true
 }
 
-func getPrecompiledContracts(blockHeight uint64) map[common.Address]PrecompiledContract {
+func getPrecompiledContracts(chainID string, blockHeight uint64) map[common.Address]PrecompiledContract {
+	forks := common.GetForkSchedule(chainID)
 	var precompiles map[common.Address]PrecompiledContract
-	if blockHeight < common.HeightSupportThetaTokenInSmartContract {
+	if blockHeight < forks.SupportThetaTokenInSmartContract {
 		precompiles = PrecompiledContractsByzantium
-	} else if blockHeight < common.HeightSupportWrappedTheta {
+	} else if blockHeight < forks.SupportWrappedTheta {
 		precompiles = PrecompiledContractsThetaSupport
 	} else {
 		precompiles = PrecompiledContractsWrappedThetaSupport
@@ -258,7 +259,7 @@ func getPrecompiledContracts(blockHeight uint64) map[common.Address]PrecompiledC
 func run(evm *EVM, contract *Contract, input []byte, readOnly bool) ([]byte, error) {
 	if contract.CodeAddr != nil {
 		blockHeight := evm.StateDB.GetBlockHeight()
-		precompiles := getPrecompiledContracts(blockHeight)
+		precompiles := getPrecompiledContracts(evm.ChainID, blockHeight)
 		if p := precompiles[*contract.CodeAddr]; p != nil {
 			return RunPrecompiledContract(evm, p, input, contract)
 		}
@@ -300,6 +301,7 @@ type Context struct {
 	BlockNumber *big.Int       // Provides information for NUMBER
 	Time        *big.Int       // Provides information for TIME
 	Difficulty  *big.Int       // Provides information for DIFFICULTY
+	ChainID     string         // Used to look up the fork schedule of the chain
 }
 
 // EVM is the Ethereum Virtual Machine base object and provides
@@ -391,7 +393,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 		return nil, gas, ErrInsufficientBalance
 	}
 
-	if SupportThetaTransferInEVM(blockHeight) && !CanTransferTheta(evm.StateDB, caller.Address(), thetaValue) {
+	if SupportThetaTransferInEVM(evm.ChainID, blockHeight) && !CanTransferTheta(evm.StateDB, caller.Address(), thetaValue) {
 		return nil, gas, ErrInsufficientThetaBlance
 	}
 
@@ -401,7 +403,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	)
 	if !evm.StateDB.Exist(addr) {
 
-		precompiles := getPrecompiledContracts(blockHeight)
+		precompiles := getPrecompiledContracts(evm.ChainID, blockHeight)
 		if precompiles[addr] == nil && value.Sign() == 0 {
 			// Calling a non existing account, don't do anything, but ping the tracer
 			if evm.vmConfig.Debug && evm.depth == 0 {
@@ -411,7 +413,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 			return nil, gas, nil
 		}
 
-		if !SupportThetaTransferInEVM(blockHeight) { // just for backward compatibility
+		if !SupportThetaTransferInEVM(evm.ChainID, blockHeight) { // just for backward compatibility
 			evm.StateDB.CreateAccount(addr)
 		} else { // should not wipe out the Theta/TFuel balance sent to the contract address prior to contract creation
 			evm.StateDB.CreateAccountWithPreviousBalance(addr)
@@ -419,7 +421,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	}
 	Transfer(evm.StateDB, caller.Address(), to.Address(), value)
 
-	if SupportThetaTransferInEVM(blockHeight) {
+	if SupportThetaTransferInEVM(evm.ChainID, blockHeight) {
 		TransferTheta(evm.StateDB, caller.Address(), to.Address(), thetaValue)
 	}
 
@@ -464,7 +466,7 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	}
 
 	blockHeight := evm.StateDB.GetBlockHeight()
-	if SupportWrappedTheta(blockHeight) && !CanTransferTheta(evm.StateDB, caller.Address(), thetaValue) {
+	if SupportWrappedTheta(evm.ChainID, blockHeight) && !CanTransferTheta(evm.StateDB, caller.Address(), thetaValue) {
 		return nil, gas, ErrInsufficientThetaBlance
 	}
 
@@ -581,7 +583,7 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	}
 
 	blockHeight := evm.StateDB.GetBlockHeight()
-	if SupportThetaTransferInEVM(blockHeight) && !CanTransferTheta(evm.StateDB, caller.Address(), thetaValue) {
+	if SupportThetaTransferInEVM(evm.ChainID, blockHeight) && !CanTransferTheta(evm.StateDB, caller.Address(), thetaValue) {
 		return nil, common.Address{}, gas, ErrInsufficientThetaBlance
 	}
 	nonce := evm.StateDB.GetNonce(caller.Address())
@@ -595,14 +597,14 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	// Create a new account on the state
 	snapshot := evm.StateDB.Snapshot()
 
-	if !SupportThetaTransferInEVM(blockHeight) { // just for backward compatibility
+	if !SupportThetaTransferInEVM(evm.ChainID, blockHeight) { // just for backward compatibility
 		evm.StateDB.CreateAccount(address)
 	} else { // should not wipe out the Theta/TFuel balance sent to the contract address prior to contract creation
 		evm.StateDB.CreateAccountWithPreviousBalance(address)
 	}
 	Transfer(evm.StateDB, caller.Address(), address, value)
 
-	if SupportThetaTransferInEVM(blockHeight) {
+	if SupportThetaTransferInEVM(evm.ChainID, blockHeight) {
 		TransferTheta(evm.StateDB, caller.Address(), address, thetaValue)
 	}
 
@@ -624,12 +626,8 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	ret, err := run(evm, contract, nil, false)
 
 	// check whether the max code size has been exceeded
-	maxCodeSize := params.MaxCodeSize
-	if blockHeight >= common.HeightEnableMetachainSupport {
-		maxCodeSize = params.MaxCodeSizeForMetachain
-	}
-
-	maxCodeSizeExceeded := len(ret) > maxCodeSize
+	maxCodeSize := evm.StateDB.GetProtocolParam(evm.ChainID, types.ParamMaxCodeSize, blockHeight)
+	maxCodeSizeExceeded := maxCodeSize.Cmp(big.NewInt(int64(len(ret)))) < 0
 	// if the contract creation ran successfully and no errors were returned
 	// calculate the gas required to store the code. If the code could not
 	// be stored due to not enough gas set an error and let it be handled
@@ -0,0 +1,472 @@
+package lightclient
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/store"
+)
+
+var logger *log.Entry = log.WithFields(log.Fields{"prefix": "lightclient"})
+
+const (
+	// MaxPendingHeight is the maximum height above the latest finalized header for which the
+	// light client keeps the received headers while waiting for them to be finalized
+	MaxPendingHeight = 1024
+
+	// MaxPendingHeadersPerHeight is the maximum number of conflicting headers kept at the same
+	// height. Together with MaxPendingHeight it bounds the number of pending headers.
+	MaxPendingHeadersPerHeight = 4
+
+	// MaxValidatorSetAge is the number of blocks after the header it is proven against within which
+	// a validator set is trusted to finalize blocks. The stakes of the validators cannot be returned
+	// in the meantime, so they can still be held accountable for the votes.
+	MaxValidatorSetAge = core.ReturnLockingPeriod
+)
+
+var (
+	// ErrValidatorSetExpired is returned when the validator set is too old to finalize more blocks.
+	// The light client needs a newer validator set proof to proceed.
+	ErrValidatorSetExpired = errors.New("ValidatorSetExpired")
+
+	// ErrVotesNotVerified is returned when no header can be finalized because the votes of the
+	// pending headers do not verify against the current validator set. The headers are dropped,
+	// since either they are forged or the validator set has changed, in which case the light
+	// client needs the validator set proof of the latest finalized header.
+	ErrVotesNotVerified = errors.New("VotesNotVerified")
+)
+
+// Checkpoint is the trusted starting point of the light client. The header has to be obtained from a
+// trusted source, while the validator set is proven against its state root by the VCP proof.
+type Checkpoint struct {
+	Header *core.BlockHeader
+	Proof  core.VCPProof
+}
+
+// clientState is the persisted state of the light client
+type clientState struct {
+	Finalized        *core.BlockHeader
+	ValidatorsHeader *core.BlockHeader
+	ValidatorsProof  core.VCPProof
+}
+
+// LightClient tracks the finalized headers of the chain starting from a trusted checkpoint, without
+// downloading the blocks or the state. A header is finalized once it is directly extended by a block
+// committed by the majority of the validators. The validator set is updated with the VCP proofs of
+// the finalized headers, and the proofs of the account and storage states can be verified against
+// the state roots of the finalized headers.
+type LightClient struct {
+	mu      *sync.RWMutex
+	chainID string
+	store   store.Store
+
+	finalized        *core.BlockHeader
+	validatorsHeader *core.BlockHeader
+	validatorsProof  core.VCPProof
+	validators       *core.ValidatorSet
+
+	pending         map[common.Hash]*core.BlockHeader // received headers not finalized yet
+	pendingAtHeight map[uint64][]common.Hash          // hashes of the pending headers at each height, oldest first
+}
+
+// NewLightClient creates a light client starting from the trusted checkpoint. The finalized headers
+// are persisted in the store.
+func NewLightClient(chainID string, db store.Store, checkpoint *Checkpoint) (*LightClient, error) {
+	if checkpoint == nil || checkpoint.Header == nil {
+		return nil, fmt.Errorf("checkpoint header is missing")
+	}
+	if checkpoint.Header.ChainID != chainID {
+		return nil, fmt.Errorf("checkpoint is for chain %v instead of %v", checkpoint.Header.ChainID, chainID)
+	}
+
+	lc := newLightClient(chainID, db)
+	if err := lc.setValidators(checkpoint.Header, &checkpoint.Proof); err != nil {
+		return nil, err
+	}
+	lc.finalized = checkpoint.Header
+	if err := lc.saveHeader(checkpoint.Header); err != nil {
+		return nil, err
+	}
+	if err := lc.saveState(); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Light client starts from checkpoint %v at height %v", checkpoint.Header.Hash().Hex(), checkpoint.Header.Height)
+	return lc, nil
+}
+
+// LoadLightClient restores the light client from the store. It returns store.ErrKeyNotFound if the
+// light client has not been created from a checkpoint yet.
+func LoadLightClient(chainID string, db store.Store) (*LightClient, error) {
+	cs := &clientState{}
+	if err := db.Get(lightClientStateKey(), cs); err != nil {
+		return nil, err
+	}
+	if cs.Finalized == nil || cs.ValidatorsHeader == nil {
+		return nil, fmt.Errorf("corrupted light client state")
+	}
+	if cs.Finalized.ChainID != chainID {
+		return nil, fmt.Errorf("light client state is for chain %v instead of %v", cs.Finalized.ChainID, chainID)
+	}
+
+	lc := newLightClient(chainID, db)
+	if err := lc.setValidators(cs.ValidatorsHeader, &cs.ValidatorsProof); err != nil {
+		return nil, err
+	}
+	lc.finalized = cs.Finalized
+	return lc, nil
+}
+
+func newLightClient(chainID string, db store.Store) *LightClient {
+	return &LightClient{
+		mu:              &sync.RWMutex{},
+		chainID:         chainID,
+		store:           db,
+		pending:         make(map[common.Hash]*core.BlockHeader),
+		pendingAtHeight: make(map[uint64][]common.Hash),
+	}
+}
+
+// ChainID returns the ID of the chain tracked by the light client.
+func (lc *LightClient) ChainID() string {
+	return lc.chainID
+}
+
+// GetLatestFinalizedHeader returns the highest finalized header.
+func (lc *LightClient) GetLatestFinalizedHeader() *core.BlockHeader {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
+	return lc.finalized
+}
+
+// GetValidatorSet returns the current validator set, and the finalized header it is proven against.
+func (lc *LightClient) GetValidatorSet() (*core.ValidatorSet, *core.BlockHeader) {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
+	return lc.validators.Copy(), lc.validatorsHeader
+}
+
+// GetFinalizedHeader returns the finalized header at the given height.
+func (lc *LightClient) GetFinalizedHeader(height uint64) (*core.BlockHeader, error) {
+	header := &core.BlockHeader{}
+	if err := lc.store.Get(lightClientHeaderKey(height), header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// GetFinalizedHeaderByHash returns the finalized header with the given hash.
+func (lc *LightClient) GetFinalizedHeaderByHash(hash common.Hash) (*core.BlockHeader, error) {
+	var height uint64
+	if err := lc.store.Get(lightClientHeaderHeightKey(hash), &height); err != nil {
+		return nil, err
+	}
+	header, err := lc.GetFinalizedHeader(height)
+	if err != nil {
+		return nil, err
+	}
+	if header.Hash() != hash {
+		return nil, store.ErrKeyNotFound
+	}
+	return header, nil
+}
+
+// AddHeaders adds the headers received from the peers, and finalizes the ones which can be proven
+// finalized by the current validator set. Returns the newly finalized headers in ascending order.
+func (lc *LightClient) AddHeaders(headers []*core.BlockHeader) ([]*core.BlockHeader, error) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	for _, header := range headers {
+		if header == nil || header.Height <= lc.finalized.Height || header.Height > lc.finalized.Height+MaxPendingHeight {
+			continue
+		}
+		if _, ok := lc.pending[header.Hash()]; ok {
+			continue
+		}
+		if res := header.Validate(lc.chainID); res.IsError() {
+			logger.WithFields(log.Fields{
+				"header.Hash":   header.Hash().Hex(),
+				"header.Height": header.Height,
+				"error":         res.Message,
+			}).Debug("Ignoring invalid header")
+			continue
+		}
+		lc.addPending(header)
+	}
+	lc.evictForks()
+
+	return lc.finalize()
+}
+
+// addPending adds the header to the pending headers. Once the limit of the headers at the same
+// height is reached, the oldest one is evicted, so that a peer sending conflicting headers cannot
+// keep the ones of the canonical chain out for good.
+func (lc *LightClient) addPending(header *core.BlockHeader) {
+	hashes := lc.pendingAtHeight[header.Height]
+	if len(hashes) >= MaxPendingHeadersPerHeight {
+		lc.removePending(hashes[0])
+	}
+	hash := header.Hash()
+	lc.pending[hash] = header
+	lc.pendingAtHeight[header.Height] = append(lc.pendingAtHeight[header.Height], hash)
+}
+
+func (lc *LightClient) removePending(hash common.Hash) {
+	header, ok := lc.pending[hash]
+	if !ok {
+		return
+	}
+	delete(lc.pending, hash)
+
+	hashes := lc.pendingAtHeight[header.Height]
+	for i, h := range hashes {
+		if h == hash {
+			hashes = append(hashes[:i:i], hashes[i+1:]...)
+			break
+		}
+	}
+	if len(hashes) == 0 {
+		delete(lc.pendingAtHeight, header.Height)
+	} else {
+		lc.pendingAtHeight[header.Height] = hashes
+	}
+}
+
+// evictForks removes the pending headers which cannot descend from the latest finalized header:
+// the ones not above it, the ones extending another finalized header, and the ones whose branch in
+// the pending headers starts right above it from a different parent. Headers whose parent has not
+// been received yet are kept.
+func (lc *LightClient) evictForks() {
+	finalizedHash := lc.finalized.Hash()
+	descends := make(map[common.Hash]bool, len(lc.pending))
+
+	var check func(hash common.Hash, header *core.BlockHeader) bool
+	check = func(hash common.Hash, header *core.BlockHeader) bool {
+		if result, ok := descends[hash]; ok {
+			return result
+		}
+		var result bool
+		if header.Height <= lc.finalized.Height {
+			result = false
+		} else if header.Parent == finalizedHash {
+			result = true
+		} else if parent, ok := lc.pending[header.Parent]; ok {
+			result = parent.Height < header.Height && check(header.Parent, parent)
+		} else {
+			var height uint64
+			result = header.Height > lc.finalized.Height+1 &&
+				lc.store.Get(lightClientHeaderHeightKey(header.Parent), &height) != nil
+		}
+		descends[hash] = result
+		return result
+	}
+
+	for hash, header := range lc.pending {
+		if !check(hash, header) {
+			lc.removePending(hash)
+		}
+	}
+}
+
+// finalize looks for the highest pending header which is proven finalized by the current validator
+// set and descends from the latest finalized header, and finalizes it along with its ancestors.
+// The headers carrying votes which fail the verification are dropped.
+func (lc *LightClient) finalize() ([]*core.BlockHeader, error) {
+	candidates := make([]*core.BlockHeader, 0, len(lc.pending))
+	for _, header := range lc.pending {
+		candidates = append(candidates, header)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Height > candidates[j].Height
+	})
+
+	var expired, rejected bool
+	for _, third := range candidates {
+		second, ok := lc.pending[third.HCC.BlockHash]
+		if !ok || third.Parent != second.Hash() {
+			continue
+		}
+		first, ok := lc.pending[second.HCC.BlockHash]
+		if !ok || second.Parent != first.Hash() {
+			continue
+		}
+		branch := lc.branchTo(first)
+		if branch == nil {
+			continue
+		}
+		if first.Height > lc.validatorsHeader.Height+MaxValidatorSetAge {
+			expired = true
+			continue
+		}
+		if err := VerifyFinalization(lc.validators, first, second, third); err != nil {
+			logger.WithFields(log.Fields{
+				"header.Hash":   first.Hash().Hex(),
+				"header.Height": first.Height,
+				"error":         err,
+			}).Debug("Failed to prove the header finalized")
+			lc.removePending(third.Hash())
+			rejected = true
+			continue
+		}
+
+		for _, header := range branch {
+			if err := lc.saveHeader(header); err != nil {
+				return nil, err
+			}
+		}
+		lc.finalized = first
+		if err := lc.saveState(); err != nil {
+			return nil, err
+		}
+		lc.evictForks()
+
+		logger.Debugf("Finalized header %v at height %v", first.Hash().Hex(), first.Height)
+		return branch, nil
+	}
+
+	if expired {
+		return nil, ErrValidatorSetExpired
+	}
+	if rejected {
+		return nil, ErrVotesNotVerified
+	}
+	return nil, nil
+}
+
+// branchTo returns the pending headers from the child of the latest finalized header to the given
+// header in ascending order, or nil if the header does not descend from the latest finalized header.
+func (lc *LightClient) branchTo(header *core.BlockHeader) []*core.BlockHeader {
+	finalizedHash := lc.finalized.Hash()
+	branch := []*core.BlockHeader{}
+	for curr := header; ; {
+		branch = append(branch, curr)
+		if curr.Parent == finalizedHash {
+			break
+		}
+		parent, ok := lc.pending[curr.Parent]
+		if !ok || parent.Height >= curr.Height {
+			return nil
+		}
+		curr = parent
+	}
+
+	for i, j := 0, len(branch)-1; i < j; i, j = i+1, j-1 {
+		branch[i], branch[j] = branch[j], branch[i]
+	}
+	return branch
+}
+
+// UpdateValidatorSet updates the validator set with the VCP proof of a finalized header. The
+// validator set proven at a header finalizes the blocks after it. Proofs for headers not higher
+// than the one of the current validator set are ignored.
+func (lc *LightClient) UpdateValidatorSet(blockHash common.Hash, proof *core.VCPProof) error {
+	header, err := lc.GetFinalizedHeaderByHash(blockHash)
+	if err != nil {
+		return fmt.Errorf("block %v is not finalized: %v", blockHash.Hex(), err)
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if header.Height <= lc.validatorsHeader.Height {
+		return nil
+	}
+	if err := lc.setValidators(header, proof); err != nil {
+		return err
+	}
+	return lc.saveState()
+}
+
+// VerifyVotes checks that the votes for the given header reach the majority of the current
+// validator set.
+func (lc *LightClient) VerifyVotes(header *core.BlockHeader, votes *core.VoteSet) error {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
+	return VerifyVotes(lc.validators, header, votes)
+}
+
+// VerifyGuardianVotes checks the guardian votes for a finalized checkpoint header, against the
+// guardian candidate pool proven by the proof against the state root of the checkpoint.
+func (lc *LightClient) VerifyGuardianVotes(votes *core.AggregatedVotes, gcpProof *core.VCPProof) error {
+	if votes == nil {
+		return fmt.Errorf("guardian votes are missing")
+	}
+	checkpoint, err := lc.GetFinalizedHeaderByHash(votes.Block)
+	if err != nil {
+		return fmt.Errorf("block %v is not finalized: %v", votes.Block.Hex(), err)
+	}
+	gcp, err := GuardianPoolFromProof(checkpoint.StateHash, gcpProof)
+	if err != nil {
+		return fmt.Errorf("invalid guardian candidate pool proof: %v", err)
+	}
+	return VerifyGuardianVotes(gcp, checkpoint, votes)
+}
+
+// VerifyAccountProof verifies the account proof, including the storage proofs of the account,
+// against the state root of the finalized header with the given hash.
+func (lc *LightClient) VerifyAccountProof(blockHash common.Hash, accountProof *state.AccountProof) error {
+	header, err := lc.GetFinalizedHeaderByHash(blockHash)
+	if err != nil {
+		return fmt.Errorf("block %v is not finalized: %v", blockHash.Hex(), err)
+	}
+	return state.VerifyAccountProof(header, accountProof)
+}
+
+func (lc *LightClient) setValidators(header *core.BlockHeader, proof *core.VCPProof) error {
+	validators, err := ValidatorSetFromVCPProof(header.StateHash, proof)
+	if err != nil {
+		return fmt.Errorf("invalid VCP proof for block %v: %v", header.Hash().Hex(), err)
+	}
+	if validators.Size() == 0 {
+		return fmt.Errorf("empty validator set at block %v", header.Hash().Hex())
+	}
+
+	lc.validatorsHeader = header
+	lc.validatorsProof = *proof
+	lc.validators = validators
+
+	logger.Debugf("Validator set at height %v: %v", header.Height, validators)
+	return nil
+}
+
+func (lc *LightClient) saveHeader(header *core.BlockHeader) error {
+	if err := lc.store.Put(lightClientHeaderKey(header.Height), header); err != nil {
+		return err
+	}
+	return lc.store.Put(lightClientHeaderHeightKey(header.Hash()), header.Height)
+}
+
+func (lc *LightClient) saveState() error {
+	cs := &clientState{
+		Finalized:        lc.finalized,
+		ValidatorsHeader: lc.validatorsHeader,
+		ValidatorsProof:  lc.validatorsProof,
+	}
+	return lc.store.Put(lightClientStateKey(), cs)
+}
+
+func lightClientStateKey() common.Bytes {
+	return common.Bytes("lc/s")
+}
+
+func lightClientHeaderKey(height uint64) common.Bytes {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, height)
+	return append(common.Bytes("lc/h/"), key...)
+}
+
+func lightClientHeaderHeightKey(hash common.Hash) common.Bytes {
+	return append(common.Bytes("lc/hh/"), hash[:]...)
+}
@@ -0,0 +1,453 @@
+package lightclient
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto/bls"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rlp"
+	"github.com/thetatoken/theta/store"
+	"github.com/thetatoken/theta/store/database"
+	"github.com/thetatoken/theta/store/database/backend"
+	"github.com/thetatoken/theta/store/kvstore"
+)
+
+const testChainID = "test_chain_id"
+
+type testChain struct {
+	db         database.Database
+	validators []types.PrivAccount
+	stateRoot  common.Hash
+}
+
+func newTestChain(t *testing.T, numValidators int) *testChain {
+	tc := &testChain{db: backend.NewMemDatabase()}
+	for i := 0; i < numValidators; i++ {
+		tc.validators = append(tc.validators, types.MakeAcc("lightclient_validator_"+string(rune('a'+i))))
+	}
+	tc.stateRoot = tc.commitValidators(t, tc.validators)
+	return tc
+}
+
+// commitValidators commits a state with the given validators, each with the minimum stake
+func (tc *testChain) commitValidators(t *testing.T, validators []types.PrivAccount) common.Hash {
+	vcp := &core.ValidatorCandidatePool{}
+	for _, v := range validators {
+		require.Nil(t, vcp.DepositStake(testChainID, v.Address, v.Address, core.MinValidatorStakeDeposit, 1))
+	}
+	sv := state.NewStoreView(1, common.Hash{}, tc.db)
+	sv.UpdateValidatorCandidatePool(vcp)
+	return sv.Save()
+}
+
+func (tc *testChain) prove(t *testing.T, stateRoot common.Hash, key common.Bytes) *core.VCPProof {
+	sv := state.NewStoreView(1, stateRoot, tc.db)
+	require.NotNil(t, sv)
+	proof := &core.VCPProof{}
+	require.Nil(t, sv.ProveVCP(key, proof))
+	return proof
+}
+
+// newHeader creates a header extending the parent, which certifies the parent with the votes
+func (tc *testChain) newHeader(parent *core.BlockHeader, stateRoot common.Hash, votes *core.VoteSet) *core.BlockHeader {
+	proposer := tc.validators[0]
+	header := &core.BlockHeader{
+		ChainID:   testChainID,
+		Epoch:     parent.Epoch + 1,
+		Height:    parent.Height + 1,
+		Parent:    parent.Hash(),
+		HCC:       core.CommitCertificate{BlockHash: parent.Hash(), Votes: votes},
+		StateHash: stateRoot,
+		Timestamp: big.NewInt(int64(parent.Height + 1)),
+		Proposer:  proposer.Address,
+	}
+	sig, err := proposer.PrivKey.Sign(header.SignBytes())
+	if err != nil {
+		panic(err)
+	}
+	header.SetSignature(sig)
+	return header
+}
+
+func newTestVotes(header *core.BlockHeader, voters []types.PrivAccount) *core.VoteSet {
+	votes := core.NewVoteSet()
+	for _, voter := range voters {
+		vote := core.Vote{Block: header.Hash(), Height: header.Height, Epoch: header.Epoch, ID: voter.Address}
+		vote.Sign(voter.PrivKey)
+		votes.AddVote(vote)
+	}
+	return votes
+}
+
+func (tc *testChain) newCheckpoint(t *testing.T, height uint64) *Checkpoint {
+	header := &core.BlockHeader{
+		ChainID:   testChainID,
+		Epoch:     height,
+		Height:    height,
+		StateHash: tc.stateRoot,
+		Timestamp: big.NewInt(int64(height)),
+	}
+	return &Checkpoint{
+		Header: header,
+		Proof:  *tc.prove(t, tc.stateRoot, state.ValidatorCandidatePoolKey()),
+	}
+}
+
+func TestLightClientFinalizeHeaders(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tc := newTestChain(t, 4)
+	checkpoint := tc.newCheckpoint(t, 10)
+	db := kvstore.NewKVStore(backend.NewMemDatabase())
+	lc, err := NewLightClient(testChainID, db, checkpoint)
+	require.Nil(err)
+
+	h11 := tc.newHeader(checkpoint.Header, tc.stateRoot, nil)
+	h12 := tc.newHeader(h11, tc.stateRoot, newTestVotes(h11, tc.validators))
+	// Two out of the four validators do not make the two-thirds majority
+	h13 := tc.newHeader(h12, tc.stateRoot, newTestVotes(h12, tc.validators[:2]))
+
+	finalized, err := lc.AddHeaders([]*core.BlockHeader{h13, h11, h12})
+	assert.Equal(ErrVotesNotVerified, err)
+	assert.Equal(0, len(finalized))
+	assert.Equal(checkpoint.Header.Hash(), lc.GetLatestFinalizedHeader().Hash())
+
+	// h13 is dropped, while its ancestors are kept
+	lc.mu.RLock()
+	assert.Equal(2, len(lc.pending))
+	assert.NotContains(lc.pending, h13.Hash())
+	lc.mu.RUnlock()
+
+	// h14, a sibling of h13, commits h12, which finalizes h11
+	h14 := tc.newHeader(h12, tc.stateRoot, newTestVotes(h12, tc.validators[:3]))
+	finalized, err = lc.AddHeaders([]*core.BlockHeader{h14})
+	require.Nil(err)
+	require.Equal(1, len(finalized))
+	assert.Equal(h11.Hash(), finalized[0].Hash())
+	assert.Equal(h11.Hash(), lc.GetLatestFinalizedHeader().Hash())
+
+	// h15 commits h14, which finalizes h12
+	h15 := tc.newHeader(h14, tc.stateRoot, newTestVotes(h14, tc.validators[1:]))
+	finalized, err = lc.AddHeaders([]*core.BlockHeader{h15})
+	require.Nil(err)
+	require.Equal(1, len(finalized))
+	assert.Equal(h12.Hash(), finalized[0].Hash())
+	assert.Equal(h12.Hash(), lc.GetLatestFinalizedHeader().Hash())
+
+	header, err := lc.GetFinalizedHeader(11)
+	require.Nil(err)
+	assert.Equal(h11.Hash(), header.Hash())
+	header, err = lc.GetFinalizedHeaderByHash(h12.Hash())
+	require.Nil(err)
+	assert.Equal(uint64(12), header.Height)
+	_, err = lc.GetFinalizedHeaderByHash(h13.Hash())
+	assert.Equal(store.ErrKeyNotFound, err)
+
+	// Votes of the validators outside of the set are rejected
+	outsider := types.MakeAcc("lightclient_outsider")
+	assert.NotNil(lc.VerifyVotes(h13, newTestVotes(h13, append(tc.validators[1:], outsider))))
+	assert.Nil(lc.VerifyVotes(h13, newTestVotes(h13, tc.validators[1:])))
+
+	// The light client resumes from the persisted state
+	restored, err := LoadLightClient(testChainID, db)
+	require.Nil(err)
+	assert.Equal(h12.Hash(), restored.GetLatestFinalizedHeader().Hash())
+	_, err = LoadLightClient("another_chain", db)
+	assert.NotNil(err)
+	_, err = LoadLightClient(testChainID, kvstore.NewKVStore(backend.NewMemDatabase()))
+	assert.Equal(store.ErrKeyNotFound, err)
+}
+
+func TestLightClientValidatorSetChange(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tc := newTestChain(t, 4)
+	checkpoint := tc.newCheckpoint(t, 10)
+	lc, err := NewLightClient(testChainID, kvstore.NewKVStore(backend.NewMemDatabase()), checkpoint)
+	require.Nil(err)
+
+	// The validator set is replaced at h11
+	newValidators := []types.PrivAccount{
+		types.MakeAcc("lightclient_new_validator_a"),
+		types.MakeAcc("lightclient_new_validator_b"),
+	}
+	newStateRoot := tc.commitValidators(t, newValidators)
+	h11 := tc.newHeader(checkpoint.Header, newStateRoot, nil)
+	h12 := tc.newHeader(h11, newStateRoot, newTestVotes(h11, tc.validators))
+	h13 := tc.newHeader(h12, newStateRoot, newTestVotes(h12, tc.validators))
+	finalized, err := lc.AddHeaders([]*core.BlockHeader{h11, h12, h13})
+	require.Nil(err)
+	require.Equal(1, len(finalized))
+
+	// The proof needs to match the state root of the header
+	assert.NotNil(lc.UpdateValidatorSet(h11.Hash(), tc.prove(t, tc.stateRoot, state.ValidatorCandidatePoolKey())))
+	// Only the proofs of the finalized headers are accepted
+	assert.NotNil(lc.UpdateValidatorSet(h12.Hash(), tc.prove(t, newStateRoot, state.ValidatorCandidatePoolKey())))
+	require.Nil(lc.UpdateValidatorSet(h11.Hash(), tc.prove(t, newStateRoot, state.ValidatorCandidatePoolKey())))
+
+	validators, validatorsHeader := lc.GetValidatorSet()
+	assert.Equal(h11.Hash(), validatorsHeader.Hash())
+	require.Equal(2, validators.Size())
+	for _, v := range newValidators {
+		_, err := validators.GetValidator(v.Address)
+		assert.Nil(err)
+	}
+
+	// The blocks after h11 are finalized by the new validators
+	h14 := tc.newHeader(h13, newStateRoot, newTestVotes(h13, tc.validators))
+	h15 := tc.newHeader(h14, newStateRoot, newTestVotes(h14, tc.validators))
+	finalized, err = lc.AddHeaders([]*core.BlockHeader{h14, h15})
+	assert.Equal(ErrVotesNotVerified, err)
+	assert.Equal(0, len(finalized))
+
+	h15 = tc.newHeader(h14, newStateRoot, newTestVotes(h14, newValidators))
+	finalized, err = lc.AddHeaders([]*core.BlockHeader{h14, h15})
+	require.Nil(err)
+	require.Equal(2, len(finalized))
+	assert.Equal(h13.Hash(), finalized[1].Hash())
+}
+
+func TestLightClientSyncAcrossValidatorSetChange(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tc := newTestChain(t, 4)
+	checkpoint := tc.newCheckpoint(t, 10)
+	lc, err := NewLightClient(testChainID, kvstore.NewKVStore(backend.NewMemDatabase()), checkpoint)
+	require.Nil(err)
+
+	// The validator set is replaced at h11, and the blocks after it are voted by the new validators
+	newValidators := []types.PrivAccount{
+		types.MakeAcc("lightclient_new_validator_a"),
+		types.MakeAcc("lightclient_new_validator_b"),
+	}
+	newStateRoot := tc.commitValidators(t, newValidators)
+	h11 := tc.newHeader(checkpoint.Header, newStateRoot, nil)
+	h12 := tc.newHeader(h11, newStateRoot, newTestVotes(h11, tc.validators))
+	h13 := tc.newHeader(h12, newStateRoot, newTestVotes(h12, tc.validators))
+	h14 := tc.newHeader(h13, newStateRoot, newTestVotes(h13, newValidators))
+	h15 := tc.newHeader(h14, newStateRoot, newTestVotes(h14, newValidators))
+	headers := []*core.BlockHeader{h11, h12, h13, h14, h15}
+
+	// The votes of the new validators do not verify against the validator set of the checkpoint,
+	// so the light client only gets up to h11 and asks for the validator set proof
+	finalized, err := lc.AddHeaders(headers)
+	require.Nil(err)
+	require.Equal(1, len(finalized))
+	assert.Equal(h11.Hash(), finalized[0].Hash())
+	finalized, err = lc.AddHeaders(headers)
+	assert.Equal(ErrVotesNotVerified, err)
+	assert.Equal(0, len(finalized))
+
+	// With the proof of h11 the light client resumes from the headers received again
+	require.Nil(lc.UpdateValidatorSet(h11.Hash(), tc.prove(t, newStateRoot, state.ValidatorCandidatePoolKey())))
+	finalized, err = lc.AddHeaders(headers)
+	require.Nil(err)
+	require.Equal(2, len(finalized))
+	assert.Equal(h12.Hash(), finalized[0].Hash())
+	assert.Equal(h13.Hash(), finalized[1].Hash())
+	assert.Equal(h13.Hash(), lc.GetLatestFinalizedHeader().Hash())
+}
+
+func TestLightClientPendingHeaders(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tc := newTestChain(t, 4)
+	checkpoint := tc.newCheckpoint(t, 10)
+	lc, err := NewLightClient(testChainID, kvstore.NewKVStore(backend.NewMemDatabase()), checkpoint)
+	require.Nil(err)
+
+	h11 := tc.newHeader(checkpoint.Header, tc.stateRoot, nil)
+	h12 := tc.newHeader(h11, tc.stateRoot, newTestVotes(h11, tc.validators))
+
+	// Conflicting headers at the same height evict the oldest ones beyond the limit
+	forks := []*core.BlockHeader{}
+	for i := 0; i < MaxPendingHeadersPerHeight+1; i++ {
+		fork := tc.newHeader(checkpoint.Header, tc.stateRoot, nil)
+		fork.Timestamp = big.NewInt(int64(1000 + i))
+		sig, err := tc.validators[0].PrivKey.Sign(fork.SignBytes())
+		require.Nil(err)
+		fork.SetSignature(sig)
+		forks = append(forks, fork)
+	}
+	_, err = lc.AddHeaders(forks)
+	require.Nil(err)
+	lc.mu.RLock()
+	assert.Equal(MaxPendingHeadersPerHeight, len(lc.pending))
+	assert.NotContains(lc.pending, forks[0].Hash())
+	lc.mu.RUnlock()
+
+	// Headers which do not extend the latest finalized header are evicted
+	orphan := tc.newHeader(h11, tc.stateRoot, nil)
+	orphan.Parent = common.HexToHash("0x1234")
+	sig, err := tc.validators[0].PrivKey.Sign(orphan.SignBytes())
+	require.Nil(err)
+	orphan.SetSignature(sig)
+	h13 := tc.newHeader(h12, tc.stateRoot, newTestVotes(h12, tc.validators))
+	_, err = lc.AddHeaders([]*core.BlockHeader{h11, h12, h13, orphan})
+	require.Nil(err)
+	assert.Equal(h11.Hash(), lc.GetLatestFinalizedHeader().Hash())
+	lc.mu.RLock()
+	assert.Equal(2, len(lc.pending))
+	assert.Contains(lc.pending, h12.Hash())
+	assert.Contains(lc.pending, h13.Hash())
+	lc.mu.RUnlock()
+
+	// Headers too far above the latest finalized header are ignored
+	far := tc.newHeader(h13, tc.stateRoot, nil)
+	far.Height = h11.Height + MaxPendingHeight + 1
+	sig, err = tc.validators[0].PrivKey.Sign(far.SignBytes())
+	require.Nil(err)
+	far.SetSignature(sig)
+	_, err = lc.AddHeaders([]*core.BlockHeader{far})
+	require.Nil(err)
+	lc.mu.RLock()
+	assert.NotContains(lc.pending, far.Hash())
+	lc.mu.RUnlock()
+}
+
+func TestLightClientValidatorSetExpiry(t *testing.T) {
+	require := require.New(t)
+
+	tc := newTestChain(t, 4)
+	checkpoint := tc.newCheckpoint(t, 10)
+	lc, err := NewLightClient(testChainID, kvstore.NewKVStore(backend.NewMemDatabase()), checkpoint)
+	require.Nil(err)
+
+	// Jump past the validator set age limit right after the checkpoint
+	first := tc.newHeader(checkpoint.Header, tc.stateRoot, nil)
+	first.Height = checkpoint.Header.Height + MaxValidatorSetAge + 1
+	sig, err := tc.validators[0].PrivKey.Sign(first.SignBytes())
+	require.Nil(err)
+	first.SetSignature(sig)
+	second := tc.newHeader(first, tc.stateRoot, newTestVotes(first, tc.validators))
+	third := tc.newHeader(second, tc.stateRoot, newTestVotes(second, tc.validators))
+
+	lc.mu.Lock()
+	for _, header := range []*core.BlockHeader{first, second, third} {
+		lc.pending[header.Hash()] = header
+	}
+	finalized, err := lc.finalize()
+	lc.mu.Unlock()
+	require.Equal(ErrValidatorSetExpired, err)
+	require.Equal(0, len(finalized))
+}
+
+func TestLightClientProofs(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tc := newTestChain(t, 4)
+
+	// Commit a checkpoint state with an account and guardians
+	sv := state.NewStoreView(1, tc.stateRoot, tc.db)
+	addr := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	account := types.NewAccount(addr)
+	account.Balance = types.NewCoins(10, 20)
+	sv.SetAccount(addr, account)
+	slot := common.BigToHash(big.NewInt(1))
+	sv.SetState(addr, slot, common.HexToHash("0xabcd"))
+
+	gcp := core.NewGuardianCandidatePool()
+	blsKeys := []*bls.SecretKey{}
+	for i, v := range tc.validators {
+		blsKey, err := bls.RandKey()
+		require.Nil(err)
+		blsKeys = append(blsKeys, blsKey)
+		require.Nil(gcp.DepositStake(testChainID, v.Address, v.Address, new(big.Int).Mul(core.MinGuardianStakeDeposit, big.NewInt(int64(i+1))), blsKey.PublicKey(), 1))
+	}
+	sv.UpdateGuardianCandidatePool(gcp)
+	tc.stateRoot = sv.Save()
+
+	checkpoint := tc.newCheckpoint(t, 101)
+	lc, err := NewLightClient(testChainID, kvstore.NewKVStore(backend.NewMemDatabase()), checkpoint)
+	require.Nil(err)
+	checkpointHash := checkpoint.Header.Hash()
+
+	// Account and storage proofs
+	sv = state.NewStoreView(1, tc.stateRoot, tc.db)
+	accountProof, err := sv.ProveAccount(addr, []common.Hash{slot})
+	require.Nil(err)
+	assert.Nil(lc.VerifyAccountProof(checkpointHash, accountProof))
+	accountProof.Storage[0].Value = common.HexToHash("0xabce")
+	assert.NotNil(lc.VerifyAccountProof(checkpointHash, accountProof))
+	assert.NotNil(lc.VerifyAccountProof(common.HexToHash("0x1234"), accountProof))
+
+	// Guardian votes
+	gcpProof := tc.prove(t, tc.stateRoot, state.GuardianCandidatePoolKey())
+	votes := core.NewAggregateVotes(checkpointHash, gcp)
+	for _, blsKey := range blsKeys[:2] {
+		votes.Sign(blsKey, gcp.WithStake().Index(blsKey.PublicKey()))
+	}
+	assert.Nil(lc.VerifyGuardianVotes(votes, gcpProof))
+	assert.NotNil(lc.VerifyGuardianVotes(votes, tc.prove(t, tc.stateRoot, state.ValidatorCandidatePoolKey())))
+
+	forged := votes.Copy()
+	forged.Multiplies[gcp.WithStake().Index(blsKeys[3].PublicKey())] = 1
+	assert.NotNil(lc.VerifyGuardianVotes(forged, gcpProof))
+	assert.NotNil(lc.VerifyGuardianVotes(core.NewAggregateVotes(checkpointHash, gcp), gcpProof))
+}
+
+func TestVerifyFinalityCertificate(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tc := newTestChain(t, 4)
+	checkpoint := tc.newCheckpoint(t, 10)
+	validators, err := ValidatorSetFromVCPProof(tc.stateRoot, &checkpoint.Proof)
+	require.Nil(err)
+
+	h11 := tc.newHeader(checkpoint.Header, tc.stateRoot, nil)
+	h12 := tc.newHeader(h11, tc.stateRoot, newTestVotes(h11, tc.validators))
+	outsider := types.MakeAcc("lightclient_outsider")
+	votes := newTestVotes(h12, append(tc.validators[1:], outsider))
+	proof := tc.prove(t, tc.stateRoot, state.ValidatorCandidatePoolKey())
+
+	cert := core.NewFinalityCertificate(h11, h12, validators, votes, proof)
+	assert.Equal(3, len(cert.Signatures)) // the outsider vote is left out
+	provenValSet, err := VerifyFinalityCertificate(validators, cert)
+	require.Nil(err)
+	assert.True(validators.Equals(provenValSet))
+
+	// The binary and JSON encodings decode to a valid certificate
+	raw, err := rlp.EncodeToBytes(cert)
+	require.Nil(err)
+	decoded := &core.FinalityCertificate{}
+	require.Nil(rlp.DecodeBytes(raw, decoded))
+	_, err = VerifyFinalityCertificate(validators, decoded)
+	assert.Nil(err)
+
+	js, err := json.Marshal(cert)
+	require.Nil(err)
+	fromJSON := &core.FinalityCertificate{}
+	require.Nil(json.Unmarshal(js, fromJSON))
+	_, err = ValidatorSetFromVCPProof(tc.stateRoot, &fromJSON.ValidatorsProof)
+	assert.Nil(err)
+
+	// Two signatures do not make the two-thirds majority
+	weak := core.NewFinalityCertificate(h11, h12, validators, newTestVotes(h12, tc.validators[:2]), proof)
+	_, err = VerifyFinalityCertificate(validators, weak)
+	assert.NotNil(err)
+
+	// The signatures are bound to the signers in the bitmap
+	forged := core.NewFinalityCertificate(h11, h12, validators, votes, proof)
+	forged.Signatures[0], forged.Signatures[1] = forged.Signatures[1], forged.Signatures[0]
+	_, err = VerifyFinalityCertificate(validators, forged)
+	assert.NotNil(err)
+
+	// The commit header needs to directly extend and certify the block
+	h13 := tc.newHeader(h12, tc.stateRoot, votes)
+	unlinked := core.NewFinalityCertificate(h11, h13, validators, newTestVotes(h13, tc.validators), proof)
+	_, err = VerifyFinalityCertificate(validators, unlinked)
+	assert.NotNil(err)
+}
@@ -0,0 +1,154 @@
+package lightclient
+
+import (
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/consensus"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/rlp"
+	"github.com/thetatoken/theta/store/trie"
+)
+
+// VerifyVotes checks that the votes are valid votes for the given block cast by the validators
+// in the set, and that the voted stake reaches the two-thirds majority of the set.
+func VerifyVotes(validatorSet *core.ValidatorSet, block *core.BlockHeader, voteSet *core.VoteSet) error {
+	if voteSet == nil {
+		return fmt.Errorf("block doesn't have votes")
+	}
+	if !validatorSet.HasMajority(voteSet) {
+		return fmt.Errorf("block doesn't have majority votes")
+	}
+	for _, vote := range voteSet.Votes() {
+		res := vote.Validate()
+		if !res.IsOK() {
+			return fmt.Errorf("vote is not valid, %v", res)
+		}
+		if vote.Block != block.Hash() {
+			return fmt.Errorf("vote is not for corresponding block")
+		}
+		_, err := validatorSet.GetValidator(vote.ID)
+		if err != nil {
+			return fmt.Errorf("can't find validator for vote")
+		}
+	}
+	return nil
+}
+
+// VerifyFinalization checks that the second block is committed by the validators, i.e. the third
+// block carries the majority votes for it. Since the second block directly extends and certifies
+// the first one, this proves that the first block is finalized.
+func VerifyFinalization(validatorSet *core.ValidatorSet, first, second, third *core.BlockHeader) error {
+	if second.Parent != first.Hash() || third.Parent != second.Hash() {
+		return fmt.Errorf("block trio has invalid Parent link")
+	}
+
+	if second.HCC.BlockHash != first.Hash() || third.HCC.BlockHash != second.Hash() {
+		return fmt.Errorf("block trio has invalid HCC link: %v, %v; %v, %v", first.Hash(), second.HCC.BlockHash,
+			second.Hash(), third.HCC.BlockHash)
+	}
+
+	// third.HCC.Votes contains the votes for the second block in the trio
+	if err := VerifyVotes(validatorSet, second, third.HCC.Votes); err != nil {
+		return fmt.Errorf("Failed to validate voteSet, %v", err)
+	}
+	return nil
+}
+
+// VerifyBlockTrio checks that the first block of the trio is finalized by the given validator set,
+// and returns the validator set proven by the VCP proof of the first block, which is the one
+// finalizing the blocks after it.
+func VerifyBlockTrio(validatorSet *core.ValidatorSet, trio *core.SnapshotBlockTrio) (*core.ValidatorSet, error) {
+	if err := VerifyFinalization(validatorSet, trio.First.Header, trio.Second.Header, trio.Third.Header); err != nil {
+		return nil, err
+	}
+	provenValSet, err := ValidatorSetFromVCPProof(trio.First.Header.StateHash, &trio.First.Proof)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve validator set from VCP proof: %v", err)
+	}
+	return provenValSet, nil
+}
+
+// VerifyFinalityCertificate checks that the block of the certificate is finalized, with the
+// validator set of its commit header, i.e. the one proven by the certificate of the block's HCC
+// block. It returns the validator set proven by the certificate, which verifies the certificate
+// of the next directly finalized block.
+func VerifyFinalityCertificate(validatorSet *core.ValidatorSet, cert *core.FinalityCertificate) (*core.ValidatorSet, error) {
+	if cert.Header == nil || cert.CommitHeader == nil {
+		return nil, fmt.Errorf("finality certificate is missing headers")
+	}
+	if cert.CommitHeader.Parent != cert.Header.Hash() || cert.CommitHeader.HCC.BlockHash != cert.Header.Hash() {
+		return nil, fmt.Errorf("commit header does not directly extend and certify block %v", cert.Header.Hash().Hex())
+	}
+
+	votes, err := cert.Votes(validatorSet)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyVotes(validatorSet, cert.CommitHeader, votes); err != nil {
+		return nil, fmt.Errorf("Failed to validate voteSet, %v", err)
+	}
+
+	provenValSet, err := ValidatorSetFromVCPProof(cert.Header.StateHash, &cert.ValidatorsProof)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve validator set from VCP proof: %v", err)
+	}
+	return provenValSet, nil
+}
+
+// ValidatorSetFromVCPProof returns the validator set selected from the validator candidate pool
+// proven against the given state root.
+func ValidatorSetFromVCPProof(stateHash common.Hash, proof *core.VCPProof) (*core.ValidatorSet, error) {
+	serializedVCP, _, err := trie.VerifyProof(stateHash, state.ValidatorCandidatePoolKey(), proof)
+	if err != nil {
+		return nil, err
+	}
+
+	vcp := &core.ValidatorCandidatePool{}
+	err = rlp.DecodeBytes(serializedVCP, vcp)
+	if err != nil {
+		return nil, err
+	}
+	return consensus.SelectTopStakeHoldersAsValidators(vcp), nil
+}
+
+// GuardianPoolFromProof returns the guardian candidate pool proven against the given state root.
+// The proof can be constructed the same way as the VCP proof, with the guardian candidate pool key.
+func GuardianPoolFromProof(stateHash common.Hash, proof *core.VCPProof) (*core.GuardianCandidatePool, error) {
+	serializedGCP, _, err := trie.VerifyProof(stateHash, state.GuardianCandidatePoolKey(), proof)
+	if err != nil {
+		return nil, err
+	}
+	if len(serializedGCP) == 0 {
+		return nil, fmt.Errorf("guardian candidate pool does not exist")
+	}
+
+	gcp := &core.GuardianCandidatePool{}
+	err = rlp.DecodeBytes(serializedGCP, gcp)
+	if err != nil {
+		return nil, err
+	}
+	return gcp, nil
+}
+
+// VerifyGuardianVotes checks that the aggregated guardian votes are signed for the given
+// checkpoint block by the guardians in the pool.
+func VerifyGuardianVotes(gcp *core.GuardianCandidatePool, checkpoint *core.BlockHeader, votes *core.AggregatedVotes) error {
+	if votes == nil {
+		return fmt.Errorf("guardian votes are missing")
+	}
+	if !common.IsCheckPointHeight(checkpoint.Height) {
+		return fmt.Errorf("block %v at height %v is not a checkpoint", checkpoint.Hash().Hex(), checkpoint.Height)
+	}
+	if votes.Block != checkpoint.Hash() {
+		return fmt.Errorf("guardian votes are for block %v instead of %v", votes.Block.Hex(), checkpoint.Hash().Hex())
+	}
+	if votes.Abs() == 0 {
+		return fmt.Errorf("guardian votes have no signer")
+	}
+	if res := votes.Validate(gcp); res.IsError() {
+		return fmt.Errorf("invalid guardian votes: %v", res.Message)
+	}
+	return nil
+}
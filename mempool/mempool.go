@@ -13,6 +13,7 @@ import (
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/clist"
 	"github.com/thetatoken/theta/common/math"
+	"github.com/thetatoken/theta/common/metrics"
 	"github.com/thetatoken/theta/common/pqueue"
 	"github.com/thetatoken/theta/common/result"
 	"github.com/thetatoken/theta/consensus"
@@ -33,6 +34,8 @@ const FastsyncSkipTxError = MempoolError("Skip tx during fastsync")
 
 const MaxMempoolTxCount int = 25600
 
+const newPendingTxsQueueSize int = 1024
+
 //
 // mempoolTransaction implements the pqueue.Element interface
 //
@@ -149,6 +152,7 @@ type Mempool struct {
 	txBookeepper     transactionBookkeeper
 	addressToTxGroup map[common.Address]*mempoolTransactionGroup
 	size             int
+	newPendingTxs    chan common.Bytes // transactions that just passed the screening, for subscribers to consume
 
 	// Life cycle
 	wg      *sync.WaitGroup
@@ -160,7 +164,7 @@ type Mempool struct {
 
 // CreateMempool creates an instance of Mempool
 func CreateMempool(dispatcher *dp.Dispatcher, engine *consensus.ConsensusEngine) *Mempool {
-	return &Mempool{
+	mempool := &Mempool{
 		mutex:            &sync.Mutex{},
 		consensus:        engine,
 		dispatcher:       dispatcher,
@@ -168,8 +172,15 @@ func CreateMempool(dispatcher *dp.Dispatcher, engine *consensus.ConsensusEngine)
 		candidateTxs:     pqueue.CreatePriorityQueue(),
 		addressToTxGroup: make(map[common.Address]*mempoolTransactionGroup),
 		txBookeepper:     createTransactionBookkeeper(defaultMaxNumTxs),
+		newPendingTxs:    make(chan common.Bytes, newPendingTxsQueueSize),
 		wg:               &sync.WaitGroup{},
 	}
+
+	metrics.NewRegisteredFunctionalGauge("mempool/size", nil, func() int64 {
+		return int64(mempool.Size())
+	})
+
+	return mempool
 }
 
 // SetLedger sets the ledger for the mempool
@@ -224,12 +235,23 @@ func (mp *Mempool) InsertTransaction(rawTx common.Bytes) error {
 		logger.Infof("Insert tx, tx.hash: 0x%v", getTransactionHash(rawTx))
 		mp.size++
 
+		select {
+		case mp.newPendingTxs <- rawTx:
+		default:
+			logger.Debugf("Failed to notify new pending tx, tx.hash: 0x%v", getTransactionHash(rawTx))
+		}
+
 		return nil
 	}
 
 	return FastsyncSkipTxError
 }
 
+// NewPendingTxs returns a channel that will be published with the transactions newly inserted into the Mempool
+func (mp *Mempool) NewPendingTxs() chan common.Bytes {
+	return mp.newPendingTxs
+}
+
 // Start needs to be called when the Mempool starts
 func (mp *Mempool) Start(ctx context.Context) error {
 	c, cancel := context.WithCancel(ctx)
@@ -335,9 +357,11 @@ func (mp *Mempool) UpdateUnsafe(committedRawTxs []common.Bytes) {
 	mp.removeTxs(committedRawTxs)
 	removeCommittedTxTime := time.Since(start)
 
-	// Remove Txs that have become obsolete.
+	// Remove Txs that have become obsolete. This includes the txs whose validity window ended
+	// before the next block, since the screening rejects them as expired.
 	start = time.Now()
 	count := 0
+	numExpired := 0
 	invalidTxs := []common.Bytes{}
 	txGroups := mp.candidateTxs.ElementList()
 	for _, txGroupEl := range *txGroups {
@@ -361,6 +385,9 @@ func (mp *Mempool) UpdateUnsafe(committedRawTxs []common.Bytes) {
 			if !checkTxRes.IsOK() {
 				invalidTxs = append(invalidTxs, mempoolTx.rawTransaction)
 				mp.txBookeepper.markAbandoned(mempoolTx.rawTransaction)
+				if checkTxRes.Code == result.CodeTxExpired {
+					numExpired++
+				}
 			}
 		}
 	}
@@ -370,7 +397,7 @@ func (mp *Mempool) UpdateUnsafe(committedRawTxs []common.Bytes) {
 	mp.removeTxs(invalidTxs)
 	removeInvalidTxTime := time.Since(start)
 
-	logger.Debugf("UpdateUnsafe: %d tx screened in %v, removeCommittedTxTime = %v, removed %d obsolete Txs in %v: %v,", count, screenTxTime, removeCommittedTxTime, len(invalidTxs), removeInvalidTxTime, invalidTxs)
+	logger.Debugf("UpdateUnsafe: %d tx screened in %v, removeCommittedTxTime = %v, removed %d obsolete Txs (%d expired) in %v: %v,", count, screenTxTime, removeCommittedTxTime, len(invalidTxs), numExpired, removeInvalidTxTime, invalidTxs)
 }
 
 func (mp *Mempool) removeTxs(committedRawTxs []common.Bytes) {
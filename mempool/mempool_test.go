@@ -498,6 +498,10 @@ func (tl *TestLedger) GetGuardianCandidatePool(blockHash common.Hash) (*core.Gua
 	return nil, nil
 }
 
+func (tl *TestLedger) ProveValidatorCandidatePool(blockHash common.Hash) (*core.VCPProof, error) {
+	return nil, nil
+}
+
 func (tl *TestLedger) PruneState(endHeight uint64) error {
 	return nil
 }
@@ -506,6 +510,9 @@ func (tl *TestLedger) ApplyBlockTxsForChainCorrection(block *core.Block) (common
 	return common.Hash{}, result.Result{}
 }
 
+func (tl *TestLedger) AddEquivocationEvidence(evidence *core.EquivocationEvidence) {
+}
+
 type TestNetworkMessageInterceptor struct {
 	lock             *sync.Mutex
 	ReceivedMessages chan p2ptypes.Message
@@ -0,0 +1,316 @@
+package netsync
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/util"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/dispatcher"
+	"github.com/thetatoken/theta/lightclient"
+	"github.com/thetatoken/theta/p2p"
+	p2ptypes "github.com/thetatoken/theta/p2p/types"
+	"github.com/thetatoken/theta/p2pl"
+	"github.com/thetatoken/theta/rlp"
+	"github.com/thetatoken/theta/store"
+)
+
+// HeaderRequestInterval is the interval between the header requests sent to the peers
+const HeaderRequestInterval = 3 * time.Second
+
+var _ p2p.MessageHandler = (*HeaderSyncManager)(nil)
+
+// HeaderSyncManager syncs the finalized headers from the peers into the light client, for the nodes
+// running in the headers only mode. It starts with requesting the trusted checkpoint header and its
+// validator set proof, and keeps the validator set up to date with the proofs of the latest
+// finalized headers.
+type HeaderSyncManager struct {
+	chainID        string
+	db             store.Store
+	checkpointHash common.Hash
+	dispatcher     *dispatcher.Dispatcher
+
+	mu          *sync.RWMutex
+	lightClient *lightclient.LightClient // nil until the checkpoint is received
+
+	wg       *sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
+	incoming chan p2ptypes.Message
+
+	logger *log.Entry
+}
+
+// NewHeaderSyncManager creates a HeaderSyncManager. The light client is restored from the store, or
+// created from the checkpoint with the given hash once its validator set proof is received.
+func NewHeaderSyncManager(chainID string, db store.Store, checkpointHash common.Hash, networkOld p2p.Network, network p2pl.Network, disp *dispatcher.Dispatcher) (*HeaderSyncManager, error) {
+	hsm := &HeaderSyncManager{
+		chainID:        chainID,
+		db:             db,
+		checkpointHash: checkpointHash,
+		dispatcher:     disp,
+		mu:             &sync.RWMutex{},
+		wg:             &sync.WaitGroup{},
+		incoming:       make(chan p2ptypes.Message, viper.GetInt(common.CfgSyncMessageQueueSize)),
+		logger:         util.GetLoggerForModule("sync"),
+	}
+
+	lc, err := lightclient.LoadLightClient(chainID, db)
+	if err == nil {
+		hsm.lightClient = lc
+	} else if err != store.ErrKeyNotFound {
+		return nil, err
+	}
+
+	if !reflect.ValueOf(networkOld).IsNil() {
+		networkOld.RegisterMessageHandler(hsm)
+	}
+	if !reflect.ValueOf(network).IsNil() {
+		network.RegisterMessageHandler(hsm)
+	}
+
+	return hsm, nil
+}
+
+// LightClient returns the light client, or nil if the checkpoint has not been received yet.
+func (hsm *HeaderSyncManager) LightClient() *lightclient.LightClient {
+	hsm.mu.RLock()
+	defer hsm.mu.RUnlock()
+
+	return hsm.lightClient
+}
+
+func (hsm *HeaderSyncManager) Start(ctx context.Context) {
+	c, cancel := context.WithCancel(ctx)
+	hsm.ctx = c
+	hsm.cancel = cancel
+
+	hsm.wg.Add(1)
+	go hsm.mainLoop()
+}
+
+func (hsm *HeaderSyncManager) Stop() {
+	hsm.cancel()
+}
+
+func (hsm *HeaderSyncManager) Wait() {
+	hsm.wg.Wait()
+}
+
+func (hsm *HeaderSyncManager) mainLoop() {
+	defer hsm.wg.Done()
+
+	ticker := time.NewTicker(HeaderRequestInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-hsm.ctx.Done():
+			return
+		case <-ticker.C:
+			hsm.sendRequests()
+		case msg := <-hsm.incoming:
+			hsm.processMessage(msg)
+		}
+	}
+}
+
+// GetChannelIDs implements the p2p.MessageHandler interface.
+func (hsm *HeaderSyncManager) GetChannelIDs() []common.ChannelIDEnum {
+	return []common.ChannelIDEnum{
+		common.ChannelIDHeader,
+		common.ChannelIDBlock,
+		common.ChannelIDValidatorSetProof,
+	}
+}
+
+// ParseMessage implements p2p.MessageHandler interface.
+func (hsm *HeaderSyncManager) ParseMessage(peerID string, channelID common.ChannelIDEnum,
+	rawMessageBytes common.Bytes) (p2ptypes.Message, error) {
+	message := p2ptypes.Message{
+		PeerID:    peerID,
+		ChannelID: channelID,
+	}
+	data, err := decodeMessage(rawMessageBytes)
+	message.Content = data
+	return message, err
+}
+
+// EncodeMessage implements p2p.MessageHandler interface.
+func (hsm *HeaderSyncManager) EncodeMessage(message interface{}) (common.Bytes, error) {
+	return encodeMessage(message)
+}
+
+// HandleMessage implements p2p.MessageHandler interface.
+func (hsm *HeaderSyncManager) HandleMessage(msg p2ptypes.Message) (err error) {
+	hsm.incoming <- msg
+	return
+}
+
+// sendRequests requests the headers following the latest finalized header, and the validator set
+// proof of the latest finalized header if the validator set is proven against an older one. Before
+// the light client is created, the validator set proof of the checkpoint is requested instead.
+func (hsm *HeaderSyncManager) sendRequests() {
+	peerID, ok := hsm.pickPeer()
+	if !ok {
+		return
+	}
+
+	lc := hsm.LightClient()
+	if lc == nil {
+		hsm.requestValidatorSetProof(peerID, hsm.checkpointHash)
+		return
+	}
+
+	finalized := lc.GetLatestFinalizedHeader()
+	if _, validatorsHeader := lc.GetValidatorSet(); validatorsHeader.Height < finalized.Height {
+		hsm.requestValidatorSetProof(peerID, finalized.Hash())
+	}
+	hsm.requestHeaders(peerID, finalized.Hash())
+}
+
+func (hsm *HeaderSyncManager) pickPeer() (string, bool) {
+	peerIDs := hsm.dispatcher.Peers(true)
+	if len(peerIDs) == 0 {
+		return "", false
+	}
+	return peerIDs[rand.Intn(len(peerIDs))], true
+}
+
+// requestHeaders sends an inventory request for the blocks after the given one, to which the peer
+// responds with the headers of the blocks.
+func (hsm *HeaderSyncManager) requestHeaders(peerID string, start common.Hash) {
+	req := dispatcher.InventoryRequest{
+		ChannelID: common.ChannelIDBlock,
+		Starts:    []string{start.Hex()},
+	}
+	hsm.logger.WithFields(log.Fields{
+		"start":  start.Hex(),
+		"peerID": peerID,
+	}).Debug("Requesting headers")
+	hsm.dispatcher.GetInventory([]string{peerID}, req)
+}
+
+func (hsm *HeaderSyncManager) requestValidatorSetProof(peerID string, blockHash common.Hash) {
+	req := dispatcher.DataRequest{
+		ChannelID: common.ChannelIDValidatorSetProof,
+		Entries:   []string{blockHash.Hex()},
+	}
+	hsm.logger.WithFields(log.Fields{
+		"block":  blockHash.Hex(),
+		"peerID": peerID,
+	}).Debug("Requesting validator set proof")
+	hsm.dispatcher.GetData([]string{peerID}, req)
+}
+
+func (hsm *HeaderSyncManager) processMessage(message p2ptypes.Message) {
+	data, ok := message.Content.(dispatcher.DataResponse)
+	if !ok {
+		return // the node does not serve any data
+	}
+
+	switch data.ChannelID {
+	case common.ChannelIDHeader:
+		headers := &Headers{}
+		if err := rlp.DecodeBytes(data.Payload, headers); err != nil {
+			hsm.logger.WithFields(log.Fields{
+				"channelID": data.ChannelID,
+				"error":     err,
+				"peerID":    message.PeerID,
+			}).Debug("Failed to decode HeaderResponse payload")
+			return
+		}
+		hsm.handleHeaders(message.PeerID, headers.HeaderArray)
+	case common.ChannelIDValidatorSetProof:
+		proof := &ValidatorSetProof{}
+		if err := rlp.DecodeBytes(data.Payload, proof); err != nil || proof.Header == nil {
+			hsm.logger.WithFields(log.Fields{
+				"channelID": data.ChannelID,
+				"error":     err,
+				"peerID":    message.PeerID,
+			}).Debug("Failed to decode validator set proof")
+			return
+		}
+		hsm.handleValidatorSetProof(message.PeerID, proof)
+	}
+}
+
+func (hsm *HeaderSyncManager) handleHeaders(peerID string, headers []*core.BlockHeader) {
+	lc := hsm.LightClient()
+	if lc == nil {
+		return
+	}
+
+	finalized, err := lc.AddHeaders(headers)
+	if err == lightclient.ErrValidatorSetExpired || err == lightclient.ErrVotesNotVerified {
+		// The validator set may have changed after the header it is proven against. The rejected
+		// headers are dropped, so request them again after the validator set proof of the latest
+		// finalized header. Otherwise the headers are simply invalid.
+		latest := lc.GetLatestFinalizedHeader()
+		if _, validatorsHeader := lc.GetValidatorSet(); validatorsHeader.Height < latest.Height {
+			hsm.requestValidatorSetProof(peerID, latest.Hash())
+			hsm.requestHeaders(peerID, latest.Hash())
+		}
+		return
+	}
+	if err != nil {
+		hsm.logger.WithFields(log.Fields{"error": err}).Error("Failed to add headers")
+		return
+	}
+	if len(finalized) == 0 {
+		return
+	}
+
+	latest := finalized[len(finalized)-1]
+	hsm.logger.WithFields(log.Fields{
+		"height": latest.Height,
+		"hash":   latest.Hash().Hex(),
+		"count":  len(finalized),
+	}).Info("Finalized headers")
+
+	// Keep downloading from the same peer while making progress
+	hsm.requestHeaders(peerID, latest.Hash())
+}
+
+func (hsm *HeaderSyncManager) handleValidatorSetProof(peerID string, proof *ValidatorSetProof) {
+	lc := hsm.LightClient()
+	if lc != nil {
+		if err := lc.UpdateValidatorSet(proof.Header.Hash(), &proof.Proof); err != nil {
+			hsm.logger.WithFields(log.Fields{
+				"block":  proof.Header.Hash().Hex(),
+				"error":  err,
+				"peerID": peerID,
+			}).Debug("Failed to update the validator set")
+		}
+		return
+	}
+
+	if proof.Header.Hash() != hsm.checkpointHash {
+		return
+	}
+	checkpoint := &lightclient.Checkpoint{
+		Header: proof.Header,
+		Proof:  proof.Proof,
+	}
+	lc, err := lightclient.NewLightClient(hsm.chainID, hsm.db, checkpoint)
+	if err != nil {
+		hsm.logger.WithFields(log.Fields{
+			"checkpoint": hsm.checkpointHash.Hex(),
+			"error":      err,
+			"peerID":     peerID,
+		}).Warn("Failed to create the light client from the checkpoint")
+		return
+	}
+
+	hsm.mu.Lock()
+	hsm.lightClient = lc
+	hsm.mu.Unlock()
+
+	hsm.requestHeaders(peerID, proof.Header.Hash())
+}
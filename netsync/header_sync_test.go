@@ -0,0 +1,156 @@
+package netsync
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/dispatcher"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/lightclient"
+	"github.com/thetatoken/theta/p2p/simulation"
+	p2plmsg "github.com/thetatoken/theta/p2pl/messenger"
+	"github.com/thetatoken/theta/store/database"
+	"github.com/thetatoken/theta/store/database/backend"
+	"github.com/thetatoken/theta/store/kvstore"
+)
+
+const headerSyncTestChainID = "header_sync_test_chain"
+
+func commitTestValidators(t *testing.T, db database.Database, validators []types.PrivAccount) (common.Hash, *core.VCPProof) {
+	vcp := &core.ValidatorCandidatePool{}
+	for _, v := range validators {
+		require.Nil(t, vcp.DepositStake(headerSyncTestChainID, v.Address, v.Address, core.MinValidatorStakeDeposit, 1))
+	}
+	sv := state.NewStoreView(1, common.Hash{}, db)
+	sv.UpdateValidatorCandidatePool(vcp)
+	stateRoot := sv.Save()
+
+	proof := &core.VCPProof{}
+	require.Nil(t, state.NewStoreView(1, stateRoot, db).ProveVCP(state.ValidatorCandidatePoolKey(), proof))
+	return stateRoot, proof
+}
+
+func newTestHeader(parent *core.BlockHeader, stateRoot common.Hash, voters []types.PrivAccount) *core.BlockHeader {
+	var votes *core.VoteSet
+	if len(voters) > 0 {
+		votes = core.NewVoteSet()
+		for _, voter := range voters {
+			vote := core.Vote{Block: parent.Hash(), Height: parent.Height, Epoch: parent.Epoch, ID: voter.Address}
+			vote.Sign(voter.PrivKey)
+			votes.AddVote(vote)
+		}
+	}
+	proposer := types.MakeAcc("header_sync_proposer")
+	header := &core.BlockHeader{
+		ChainID:   headerSyncTestChainID,
+		Epoch:     parent.Epoch + 1,
+		Height:    parent.Height + 1,
+		Parent:    parent.Hash(),
+		HCC:       core.CommitCertificate{BlockHash: parent.Hash(), Votes: votes},
+		StateHash: stateRoot,
+		Timestamp: big.NewInt(int64(parent.Height + 1)),
+		Proposer:  proposer.Address,
+	}
+	sig, err := proposer.PrivKey.Sign(header.SignBytes())
+	if err != nil {
+		panic(err)
+	}
+	header.SetSignature(sig)
+	return header
+}
+
+func receiveTestRequest(t *testing.T, c chan interface{}) interface{} {
+	select {
+	case msg := <-c:
+		return msg
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for the request")
+		return nil
+	}
+}
+
+func TestHeaderSyncAcrossValidatorSetChange(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	stateDB := backend.NewMemDatabase()
+	oldValidators := []types.PrivAccount{}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		oldValidators = append(oldValidators, types.MakeAcc("header_sync_validator_"+name))
+	}
+	newValidators := []types.PrivAccount{
+		types.MakeAcc("header_sync_new_validator_a"),
+		types.MakeAcc("header_sync_new_validator_b"),
+	}
+	oldStateRoot, oldProof := commitTestValidators(t, stateDB, oldValidators)
+	newStateRoot, newProof := commitTestValidators(t, stateDB, newValidators)
+
+	checkpoint := &core.BlockHeader{
+		ChainID:   headerSyncTestChainID,
+		Epoch:     10,
+		Height:    10,
+		StateHash: oldStateRoot,
+		Timestamp: big.NewInt(10),
+	}
+	db := kvstore.NewKVStore(backend.NewMemDatabase())
+	_, err := lightclient.NewLightClient(headerSyncTestChainID, db, &lightclient.Checkpoint{Header: checkpoint, Proof: *oldProof})
+	require.Nil(err)
+
+	// The validator set is replaced at h11, and the blocks after it are voted by the new validators
+	h11 := newTestHeader(checkpoint, newStateRoot, nil)
+	h12 := newTestHeader(h11, newStateRoot, oldValidators)
+	h13 := newTestHeader(h12, newStateRoot, oldValidators)
+	h14 := newTestHeader(h13, newStateRoot, newValidators)
+	h15 := newTestHeader(h14, newStateRoot, newValidators)
+	headers := []*core.BlockHeader{h11, h12, h13, h14, h15}
+
+	simnet := simulation.NewSimnet()
+	net1 := simnet.AddEndpoint("node1")
+	net2 := simnet.AddEndpoint("node2")
+	mockMsgHandler := &MockMsgHandler{C: make(chan interface{}, 128)}
+	net2.RegisterMessageHandler(mockMsgHandler)
+	simnet.Start(context.Background())
+
+	dispatch := dispatcher.NewDispatcher(net1, (*p2plmsg.Messenger)(nil))
+	hsm, err := NewHeaderSyncManager(headerSyncTestChainID, db, checkpoint.Hash(), net1, (*p2plmsg.Messenger)(nil), dispatch)
+	require.Nil(err)
+	require.NotNil(hsm.LightClient())
+
+	// h11 is finalized by the validators of the checkpoint, and the following headers are requested
+	hsm.handleHeaders("node2", headers)
+	assert.Equal(h11.Hash(), hsm.LightClient().GetLatestFinalizedHeader().Hash())
+	invReq, ok := receiveTestRequest(t, mockMsgHandler.C).(dispatcher.InventoryRequest)
+	require.True(ok)
+	assert.Equal([]string{h11.Hash().Hex()}, invReq.Starts)
+
+	// The votes of the new validators fail the verification, so the validator set proof of h11 is
+	// requested along with the headers
+	hsm.handleHeaders("node2", headers)
+	assert.Equal(h11.Hash(), hsm.LightClient().GetLatestFinalizedHeader().Hash())
+	var proofRequested, headersRequested bool
+	for i := 0; i < 2; i++ {
+		switch req := receiveTestRequest(t, mockMsgHandler.C).(type) {
+		case dispatcher.DataRequest:
+			assert.Equal(common.ChannelIDValidatorSetProof, req.ChannelID)
+			assert.Equal([]string{h11.Hash().Hex()}, req.Entries)
+			proofRequested = true
+		case dispatcher.InventoryRequest:
+			assert.Equal([]string{h11.Hash().Hex()}, req.Starts)
+			headersRequested = true
+		}
+	}
+	assert.True(proofRequested)
+	assert.True(headersRequested)
+
+	// The sync resumes once the proof is received
+	hsm.handleValidatorSetProof("node2", &ValidatorSetProof{Header: h11, Proof: *newProof})
+	hsm.handleHeaders("node2", headers)
+	assert.Equal(h13.Hash(), hsm.LightClient().GetLatestFinalizedHeader().Hash())
+}
@@ -211,6 +211,8 @@ func (rm *RequestManager) recoveryModeLoop() {
 
 	for {
 		select {
+		case <-rm.ctx.Done():
+			return
 		case <-rm.recoveryModeTicker.C:
 			rm.attemptToRunRecoveryMode()
 		}
@@ -410,6 +412,14 @@ func (rm *RequestManager) attemptToRunRecoveryMode() {
 	}
 }
 
+// numPendingBlocks returns the number of blocks which are known but not downloaded yet
+func (rm *RequestManager) numPendingBlocks() int {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	return rm.pendingBlocks.Len() + rm.pendingBlocksWithHeader.Len()
+}
+
 func (rm *RequestManager) tryToDownload() {
 	rm.mu.RLock()
 	defer rm.mu.RUnlock()
@@ -12,6 +12,7 @@ import (
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/dispatcher"
@@ -24,6 +25,9 @@ import (
 
 const voteCacheLimit = 512
 
+// maxValidatorSetProofRequestSize is the maximum number of validator set proofs served per request
+const maxValidatorSetProofRequestSize = 4
+
 var logger *log.Entry = log.WithFields(log.Fields{"prefix": "netsync"})
 
 type MessageConsumer interface {
@@ -38,6 +42,13 @@ type Blocks struct {
 	BlockArray []*core.Block
 }
 
+// ValidatorSetProof carries the proof of the validator candidate pool against the state root of the
+// header, which is requested by the light clients to update their validator sets
+type ValidatorSetProof struct {
+	Header *core.BlockHeader
+	Proof  core.VCPProof
+}
+
 var _ p2p.MessageHandler = (*SyncManager)(nil)
 
 // SyncManager is an intermediate layer between consensus engine and p2p network. Its main responsibilities are to manage
@@ -79,6 +90,13 @@ func NewSyncManager(chain *blockchain.Chain, cons core.ConsensusEngine, networkO
 	}
 	sm.requestMgr = NewRequestManager(sm, reporter)
 
+	metrics.NewRegisteredFunctionalGauge("sync/incoming_queue", nil, func() int64 {
+		return int64(len(sm.incoming))
+	})
+	metrics.NewRegisteredFunctionalGauge("sync/pending_blocks", nil, func() int64 {
+		return int64(sm.requestMgr.numPendingBlocks())
+	})
+
 	if !reflect.ValueOf(networkOld).IsNil() {
 		networkOld.RegisterMessageHandler(sm)
 	}
@@ -169,6 +187,8 @@ func (sm *SyncManager) GetChannelIDs() []common.ChannelIDEnum {
 		common.ChannelIDGuardian,
 		common.ChannelIDEliteEdgeNodeVote,
 		common.ChannelIDAggregatedEliteEdgeNodeVotes,
+		common.ChannelIDEvidence,
+		common.ChannelIDValidatorSetProof,
 	}
 }
 
@@ -521,6 +541,13 @@ func (m *SyncManager) handleDataRequest(peerID string, data *dispatcher.DataRequ
 			}).Debug("Sending requested block")
 			m.dispatcher.SendData([]string{peerID}, sendData)
 		}
+	case common.ChannelIDValidatorSetProof:
+		for idx, hashStr := range data.Entries {
+			if idx >= maxValidatorSetProofRequestSize {
+				break
+			}
+			m.sendValidatorSetProof(peerID, hashStr)
+		}
 	default:
 		m.logger.WithFields(log.Fields{
 			"channelID": data.ChannelID,
@@ -528,6 +555,48 @@ func (m *SyncManager) handleDataRequest(peerID string, data *dispatcher.DataRequ
 	}
 }
 
+// sendValidatorSetProof sends the proof of the validator set of a finalized block. The request is
+// ignored if the state of the block has been pruned.
+func (m *SyncManager) sendValidatorSetProof(peerID string, hashStr string) {
+	hash := common.HexToHash(hashStr)
+	block, err := m.chain.FindBlock(hash)
+	if err != nil || !block.Status.IsFinalized() {
+		m.logger.WithFields(log.Fields{
+			"hashStr": hashStr,
+			"peerID":  peerID,
+		}).Debug("Requested validator set proof of unknown or unfinalized block")
+		return
+	}
+
+	proof, err := m.consensus.GetLedger().ProveValidatorCandidatePool(hash)
+	if err != nil {
+		m.logger.WithFields(log.Fields{
+			"hashStr": hashStr,
+			"err":     err,
+			"peerID":  peerID,
+		}).Debug("Failed to prove the validator set")
+		return
+	}
+
+	payload, err := rlp.EncodeToBytes(&ValidatorSetProof{Header: block.BlockHeader, Proof: *proof})
+	if err != nil {
+		m.logger.WithFields(log.Fields{
+			"hashStr": hashStr,
+			"peerID":  peerID,
+		}).Error("Failed to encode validator set proof")
+		return
+	}
+	data := dispatcher.DataResponse{
+		ChannelID: common.ChannelIDValidatorSetProof,
+		Payload:   payload,
+	}
+	m.logger.WithFields(log.Fields{
+		"hashStr": hashStr,
+		"peerID":  peerID,
+	}).Debug("Sending validator set proof")
+	m.dispatcher.SendData([]string{peerID}, data)
+}
+
 func (m *SyncManager) sendSingleBlock(peerID string, hashStr string, channelID common.ChannelIDEnum) {
 	hash := common.HexToHash(hashStr)
 	block, err := m.chain.FindBlock(hash)
@@ -726,6 +795,23 @@ func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataRes
 			"peer":            peerID,
 		}).Debug("Received aggregated elite edge node vote")
 		m.handleAggregatedEliteEdgeNodeVotes(vote)
+	case common.ChannelIDEvidence:
+		evidence := &core.EquivocationEvidence{}
+		err := rlp.DecodeBytes(data.Payload, evidence)
+		if err != nil {
+			m.logger.WithFields(log.Fields{
+				"channelID": data.ChannelID,
+				"payload":   data.Payload,
+				"error":     err,
+				"peerID":    peerID,
+			}).Warn("Failed to decode DataResponse payload")
+			return
+		}
+		m.logger.WithFields(log.Fields{
+			"evidence": evidence,
+			"peer":     peerID,
+		}).Debug("Received equivocation evidence")
+		m.handleEquivocationEvidence(evidence)
 	case common.ChannelIDHeader:
 		headers := &Headers{}
 		err := rlp.DecodeBytes(data.Payload, headers)
@@ -929,3 +1015,7 @@ func (sm *SyncManager) handleEliteEdgeNodeVote(vote *core.EENVote) {
 func (sm *SyncManager) handleAggregatedEliteEdgeNodeVotes(vote *core.AggregatedEENVotes) {
 	sm.PassdownMessage(vote)
 }
+
+func (sm *SyncManager) handleEquivocationEvidence(evidence *core.EquivocationEvidence) {
+	sm.PassdownMessage(evidence)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/p2p/simulation"
 	"github.com/thetatoken/theta/p2p/types"
+	p2plmsg "github.com/thetatoken/theta/p2pl/messenger"
 )
 
 type MockMessageConsumer struct {
@@ -85,11 +86,12 @@ func TestSyncManager(t *testing.T) {
 	privKey, _, _ := crypto.GenerateKeyPair()
 	valMgr := consensus.NewFixedValidatorManager()
 	db := kvstore.NewKVStore(backend.NewMemDatabase())
-	dispatch := dispatcher.NewDispatcher(net1, nil)
-	consensus := consensus.NewConsensusEngine(privKey, db, initChain, dispatch, valMgr)
+	dispatch := dispatcher.NewDispatcher(net1, (*p2plmsg.Messenger)(nil))
+	signer, _ := consensus.NewLocalSigner(privKey, nil)
+	consensus := consensus.NewConsensusEngine(signer, db, initChain, dispatch, valMgr)
 	mockMsgConsumer := NewMockMessageConsumer()
 
-	sm := NewSyncManager(initChain, consensus, net1, nil, dispatch, mockMsgConsumer)
+	sm := NewSyncManager(initChain, consensus, net1, (*p2plmsg.Messenger)(nil), dispatch, mockMsgConsumer, nil)
 	sm.Start(context.Background())
 
 	// Send block A4 to node1
@@ -100,21 +102,10 @@ func TestSyncManager(t *testing.T) {
 			ChannelID: common.ChannelIDBlock,
 			Payload:   payload,
 		},
-	})
+	}, false)
 
-	// node1 should broadcast InventoryResponse
+	// A4 is an orphan block to node1, so node1 requests the missing blocks
 	var res interface{}
-	res = <-mockMsgHandler.C
-	msg1, ok := res.(dispatcher.InventoryResponse)
-	assert.True(ok)
-	assert.Equal(common.ChannelIDBlock, msg1.ChannelID)
-	assert.Equal(core.GetTestBlock("A4").Hash().Hex(), msg1.Entries[0])
-
-	res = <-mockMsgHandler.C
-	msg11, ok := res.(dispatcher.DataResponse)
-	assert.True(ok)
-	assert.Equal(common.ChannelIDHeader, msg11.ChannelID)
-
 	res = <-mockMsgHandler.C
 	msg2, ok := res.(dispatcher.InventoryRequest)
 	assert.True(ok)
@@ -135,7 +126,7 @@ func TestSyncManager(t *testing.T) {
 			ChannelID: common.ChannelIDBlock,
 			Entries:   entries,
 		},
-	})
+	}, false)
 
 	// node2 replies with A3 first
 	payload, _ = rlp.EncodeToBytes(core.CreateTestBlock("A3", "A2"))
@@ -145,7 +136,7 @@ func TestSyncManager(t *testing.T) {
 			ChannelID: common.ChannelIDBlock,
 			Payload:   payload,
 		},
-	})
+	}, false)
 
 	time.Sleep(1 * time.Second)
 
@@ -157,18 +148,18 @@ func TestSyncManager(t *testing.T) {
 			ChannelID: common.ChannelIDBlock,
 			Payload:   payload,
 		},
-	})
+	}, false)
 
 	time.Sleep(1 * time.Second)
 
 	sm.Stop()
 	sm.Wait()
 
-	// Sync manager should output A2, A3, A4 in order.
-	assert.Equal(3, len(mockMsgConsumer.Received))
-	expected := []string{"A2", "A3", "A4"}
-	for i, msg := range mockMsgConsumer.Received {
-		assert.Equal(core.GetTestBlock(expected[i]).Hash(), msg.(*core.Block).Hash())
+	// Sync manager should have added A2, A3, A4 to the chain, which are passed down to the
+	// consensus engine once their parents are valid.
+	for _, name := range []string{"A2", "A3", "A4"} {
+		_, err := initChain.FindBlock(core.GetTestBlock(name).Hash())
+		assert.Nil(err, name)
 	}
 }
 
@@ -201,6 +192,10 @@ func (c *MockConsensus) PrivateKey() *crypto.PrivateKey {
 	return nil
 }
 
+func (c *MockConsensus) SignTx(chainID string, rawTx common.Bytes) (*crypto.Signature, error) {
+	return nil, nil
+}
+
 func (c *MockConsensus) GetTip(includePendingBlockingLeaf bool) *core.ExtendedBlock {
 	return nil
 }
@@ -224,6 +219,15 @@ func (c *MockConsensus) FinalizedBlocks() chan *core.Block {
 func (c *MockConsensus) GetLastFinalizedBlock() *core.ExtendedBlock {
 	return c.lfb
 }
+func (c *MockConsensus) GetHighestCCBlock() *core.ExtendedBlock {
+	return c.lfb
+}
+func (c *MockConsensus) GetEpochVotes() (*core.VoteSet, error) {
+	return core.NewVoteSet(), nil
+}
+func (c *MockConsensus) GetValidatorSet(blockHash common.Hash) *core.ValidatorSet {
+	return core.NewValidatorSet()
+}
 
 func TestCollectBlocks(t *testing.T) {
 	assert := assert.New(t)
@@ -248,12 +252,12 @@ func TestCollectBlocks(t *testing.T) {
 	net2.RegisterMessageHandler(mockMsgHandler)
 	simnet.Start(context.Background())
 
-	dispatch := dispatcher.NewDispatcher(net1, nil)
+	dispatch := dispatcher.NewDispatcher(net1, (*p2plmsg.Messenger)(nil))
 	a3, _ := initChain.FindBlock(core.GetTestBlock("A3").Hash())
 	consensus := NewMockConsensus(initChain, a3)
 	mockMsgConsumer := NewMockMessageConsumer()
 
-	sm := NewSyncManager(initChain, consensus, net1, nil, dispatch, mockMsgConsumer)
+	sm := NewSyncManager(initChain, consensus, net1, (*p2plmsg.Messenger)(nil), dispatch, mockMsgConsumer, nil)
 
 	blocks := sm.collectBlocks(core.GetTestBlock("A1").Hash(), core.GetTestBlock("A5").Hash())
 	// Expected blocks: [A1, A2, A3, A4, D4, A5, A3]
@@ -0,0 +1,78 @@
+package node
+
+import (
+	"context"
+	"log"
+
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	dp "github.com/thetatoken/theta/dispatcher"
+	"github.com/thetatoken/theta/netsync"
+	"github.com/thetatoken/theta/store"
+	"github.com/thetatoken/theta/store/kvstore"
+)
+
+// HeaderNode is a node running in the headers only mode. It tracks the finalized headers with a
+// light client, instead of downloading the blocks and executing the transactions.
+type HeaderNode struct {
+	Store             store.Store
+	Dispatcher        *dp.Dispatcher
+	HeaderSyncManager *netsync.HeaderSyncManager
+
+	// Life cycle
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewHeaderNode creates a node running in the headers only mode. The light client starts from the
+// trusted checkpoint configured, or from the genesis block.
+func NewHeaderNode(params *Params) *HeaderNode {
+	store := kvstore.NewKVStore(params.DB)
+	dispatcher := dp.NewDispatcher(params.NetworkOld, params.Network)
+
+	checkpointHash := getTrustedCheckpointHash(params.ChainID)
+	if checkpointHash.IsEmpty() {
+		log.Fatalf("The trusted checkpoint hash needs to be configured with %v", common.CfgSyncTrustedCheckpointHash)
+	}
+	headerSyncMgr, err := netsync.NewHeaderSyncManager(params.ChainID, store, checkpointHash, params.NetworkOld, params.Network, dispatcher)
+	if err != nil {
+		log.Fatalf("Failed to create the header sync manager: %v", err)
+	}
+
+	return &HeaderNode{
+		Store:             store,
+		Dispatcher:        dispatcher,
+		HeaderSyncManager: headerSyncMgr,
+	}
+}
+
+func getTrustedCheckpointHash(chainID string) common.Hash {
+	if hash := viper.GetString(common.CfgSyncTrustedCheckpointHash); hash != "" {
+		return common.HexToHash(hash)
+	}
+	if chainID == core.MainnetChainID {
+		return common.HexToHash(core.MainnetGenesisBlockHash)
+	}
+	return common.HexToHash(viper.GetString(common.CfgGenesisHash))
+}
+
+// Start starts sub components and kick off the main loop.
+func (n *HeaderNode) Start(ctx context.Context) {
+	c, cancel := context.WithCancel(ctx)
+	n.ctx = c
+	n.cancel = cancel
+
+	n.HeaderSyncManager.Start(n.ctx)
+	n.Dispatcher.Start(n.ctx)
+}
+
+// Stop notifies all sub components to stop without blocking.
+func (n *HeaderNode) Stop() {
+	n.cancel()
+}
+
+// Wait blocks until all sub components stop.
+func (n *HeaderNode) Wait() {
+	n.HeaderSyncManager.Wait()
+}
@@ -0,0 +1,41 @@
+package node
+
+import (
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
+)
+
+// metricsPrefix is prepended to the names of the exported metrics
+const metricsPrefix = "theta/"
+
+// startMetricsServer serves the metrics in the default registry at /metrics in the Prometheus
+// text exposition format, until the node is stopped.
+func (n *Node) startMetricsServer() {
+	address := viper.GetString(common.CfgMetricsPrometheusAddress)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.PrometheusHandler(metrics.DefaultRegistry, metricsPrefix))
+	server := &http.Server{
+		Addr:    address,
+		Handler: mux,
+	}
+
+	go metrics.CollectProcessMetrics(3 * time.Second)
+
+	go func() {
+		log.WithFields(log.Fields{"address": address}).Info("Metrics server started")
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.WithFields(log.Fields{"error": err}).Error("Metrics server stopped")
+		}
+	}()
+
+	go func() {
+		<-n.ctx.Done()
+		server.Close()
+	}()
+}
@@ -37,6 +37,7 @@ type Node struct {
 	Ledger           core.Ledger
 	Mempool          *mp.Mempool
 	RPC              *rpc.ThetaRPCServer
+	AdminRPC         *rpc.ThetaAdminRPCServer
 	reporter         *rp.Reporter
 
 	// Life cycle
@@ -58,16 +59,28 @@ type Params struct {
 	SnapshotPath        string
 	ChainImportDirPath  string
 	ChainCorrectionPath string
+
+	// Signer holds the validator key. The node signs with PrivateKey if it is nil.
+	Signer consensus.Signer
 }
 
 func NewNode(params *Params) *Node {
 	store := kvstore.NewKVStore(params.DB)
 	chain := blockchain.NewChain(params.ChainID, store, params.Root)
+	chain.SetTxAddressIndexEnabled(viper.GetBool(common.CfgStorageTxAddressIndexEnabled))
 	params.RollingDB.SetChain(chain)
 
 	validatorManager := consensus.NewRotatingValidatorManager()
 	dispatcher := dp.NewDispatcher(params.NetworkOld, params.Network)
-	consensus := consensus.NewConsensusEngine(params.PrivateKey, store, chain, dispatcher, validatorManager)
+	signer := params.Signer
+	if signer == nil {
+		localSigner, err := consensus.NewLocalSigner(params.PrivateKey, nil)
+		if err != nil {
+			log.Fatalf("Failed to create the signer: %v", err)
+		}
+		signer = localSigner
+	}
+	consensus := consensus.NewConsensusEngine(signer, store, chain, dispatcher, validatorManager)
 	reporter := rp.NewReporter(dispatcher, consensus, chain)
 
 	// TODO: check if this is a guardian node
@@ -122,6 +135,9 @@ func NewNode(params *Params) *Node {
 	if viper.GetBool(common.CfgRPCEnabled) {
 		node.RPC = rpc.NewThetaRPCServer(mempool, ledger, dispatcher, chain, consensus)
 	}
+	if viper.GetBool(common.CfgRPCAdminEnabled) {
+		node.AdminRPC = rpc.NewThetaAdminRPCServer(mempool, dispatcher, chain, consensus)
+	}
 	return node
 }
 
@@ -140,6 +156,13 @@ func (n *Node) Start(ctx context.Context) {
 	if viper.GetBool(common.CfgRPCEnabled) {
 		n.RPC.Start(n.ctx)
 	}
+	if n.AdminRPC != nil {
+		n.AdminRPC.Start(n.ctx)
+	}
+
+	if viper.GetBool(common.CfgMetricsPrometheusEnabled) {
+		n.startMetricsServer()
+	}
 }
 
 // Stop notifies all sub components to stop without blocking.
@@ -154,4 +177,7 @@ func (n *Node) Wait() {
 	if n.RPC != nil {
 		n.RPC.Wait()
 	}
+	if n.AdminRPC != nil {
+		n.AdminRPC.Wait()
+	}
 }
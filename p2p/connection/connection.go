@@ -113,6 +113,8 @@ func CreateConnection(netconn net.Conn, config ConnectionConfig) *Connection {
 	channelNATMapping := createDefaultChannel(common.ChannelIDNATMapping)
 	channelEliteEdgeNodeVote := createDefaultChannel(common.ChannelIDEliteEdgeNodeVote)
 	channelEliteAggregatedEdgeNodeVotes := createDefaultChannel(common.ChannelIDAggregatedEliteEdgeNodeVotes)
+	channelEvidence := createDefaultChannel(common.ChannelIDEvidence)
+	channelValidatorSetProof := createDefaultChannel(common.ChannelIDValidatorSetProof)
 	channels := []*Channel{
 		&channelCheckpoint,
 		&channelHeader,
@@ -126,6 +128,8 @@ func CreateConnection(netconn net.Conn, config ConnectionConfig) *Connection {
 		&channelNATMapping,
 		&channelEliteEdgeNodeVote,
 		&channelEliteAggregatedEdgeNodeVotes,
+		&channelEvidence,
+		&channelValidatorSetProof,
 	}
 
 	success, channelGroup := createChannelGroup(getDefaultChannelGroupConfig(), channels)
@@ -258,6 +262,7 @@ func (conn *Connection) EnqueueMessage(channelID common.ChannelIDEnum, message i
 	}
 	success := channel.enqueueMessage(msgBytes)
 	if success {
+		p2ptypes.RecordChannelBytesSent(channelID, len(msgBytes))
 		conn.scheduleSendPulse()
 	}
 
@@ -280,6 +285,7 @@ func (conn *Connection) AttemptToEnqueueMessage(channelID common.ChannelIDEnum,
 	}
 	success := channel.attemptToEnqueueMessage(msgBytes)
 	if success {
+		p2ptypes.RecordChannelBytesSent(channelID, len(msgBytes))
 		conn.scheduleSendPulse()
 	}
 
@@ -480,6 +486,7 @@ func (conn *Connection) handleReceivedPacket(packet *Packet) (success bool) {
 	if aggregatedBytes == nil {
 		return true
 	}
+	p2ptypes.RecordChannelBytesReceived(channelID, len(aggregatedBytes))
 
 	message, err := conn.onParse(packet.ChannelID, aggregatedBytes)
 	if err != nil {
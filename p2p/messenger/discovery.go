@@ -3,6 +3,7 @@ package messenger
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"sync"
 	"time"
@@ -237,6 +238,11 @@ func (discMgr *PeerDiscoveryManager) handshakeAndAddPeer(peer *pr.Peer) error {
 		return err
 	}
 
+	if discMgr.messenger != nil && discMgr.messenger.banList.IsBanned(peer.ID()) {
+		peer.Stop()
+		return fmt.Errorf("peer %v is banned", peer.ID())
+	}
+
 	isSeed := discMgr.seedPeerConnector.isASeedPeer(peer.NetAddress())
 	peer.SetSeed(isSeed)
 	if isSeed {
@@ -2,8 +2,10 @@ package messenger
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/spf13/viper"
 
@@ -15,6 +17,7 @@ import (
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/p2p"
+	"github.com/thetatoken/theta/p2p/netutil"
 	pr "github.com/thetatoken/theta/p2p/peer"
 	p2ptypes "github.com/thetatoken/theta/p2p/types"
 )
@@ -33,6 +36,7 @@ type Messenger struct {
 
 	peerTable pr.PeerTable
 	nodeInfo  p2ptypes.NodeInfo // information of our blockchain node
+	banList   *p2ptypes.BanList
 
 	config MessengerConfig
 
@@ -72,6 +76,7 @@ func CreateMessenger(privKey *crypto.PrivateKey, seedPeerNetAddresses []string,
 		msgHandlerMap: make(map[common.ChannelIDEnum](p2p.MessageHandler)),
 		peerTable:     pr.CreatePeerTable(),
 		nodeInfo:      p2ptypes.CreateLocalNodeInfo(privKey, uint16(eport)),
+		banList:       p2ptypes.NewBanList(),
 		config:        msgrConfig,
 		wg:            &sync.WaitGroup{},
 	}
@@ -259,6 +264,38 @@ func (msgr *Messenger) PeerExists(peerID string) bool {
 	return msgr.peerTable.PeerExists(peerID)
 }
 
+// ConnectToPeer connects to the peer at the given network address, e.g. "127.0.0.1:50001"
+func (msgr *Messenger) ConnectToPeer(address string) error {
+	netAddress, err := netutil.NewNetAddressString(address)
+	if err != nil {
+		return err
+	}
+	_, err = msgr.discMgr.connectToOutboundPeer(netAddress, true)
+	return err
+}
+
+// DisconnectPeer disconnects from the given peer
+func (msgr *Messenger) DisconnectPeer(peerID string) error {
+	peer := msgr.peerTable.GetPeer(peerID)
+	if peer == nil {
+		return fmt.Errorf("peer %v is not connected", peerID)
+	}
+	msgr.peerTable.DeletePeer(peerID) // delete first so that the peer is not reconnected
+	peer.Stop()
+	logger.Infof("Disconnected from peer %v", peerID)
+	return nil
+}
+
+// BanPeer disconnects from the given peer, and rejects its connections for the given duration
+func (msgr *Messenger) BanPeer(peerID string, duration time.Duration) error {
+	msgr.banList.Ban(peerID, duration)
+	logger.Infof("Banned peer %v for %v", peerID, duration)
+	if msgr.peerTable.PeerExists(peerID) {
+		return msgr.DisconnectPeer(peerID)
+	}
+	return nil
+}
+
 // RegisterMessageHandler registers the message handler
 func (msgr *Messenger) RegisterMessageHandler(msgHandler p2p.MessageHandler) {
 	channelIDs := msgHandler.GetChannelIDs()
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"sync"
+	"time"
+)
+
+// BanList tracks the peers banned from connecting to the node, each until an expiry time
+type BanList struct {
+	mutex  *sync.Mutex
+	expiry map[string]time.Time // peerID -> time the ban expires
+}
+
+// NewBanList creates an empty BanList
+func NewBanList() *BanList {
+	return &BanList{
+		mutex:  &sync.Mutex{},
+		expiry: make(map[string]time.Time),
+	}
+}
+
+// Ban bans the peer for the given duration
+func (bl *BanList) Ban(peerID string, duration time.Duration) {
+	bl.mutex.Lock()
+	defer bl.mutex.Unlock()
+
+	bl.expiry[peerID] = time.Now().Add(duration)
+}
+
+// IsBanned returns whether the peer is currently banned
+func (bl *BanList) IsBanned(peerID string) bool {
+	bl.mutex.Lock()
+	defer bl.mutex.Unlock()
+
+	expiry, ok := bl.expiry[peerID]
+	if !ok {
+		return false
+	}
+	if time.Now().After(expiry) {
+		delete(bl.expiry, peerID)
+		return false
+	}
+	return true
+}
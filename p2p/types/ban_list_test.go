@@ -0,0 +1,21 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBanList(t *testing.T) {
+	assert := assert.New(t)
+
+	bl := NewBanList()
+	assert.False(bl.IsBanned("peer1"))
+
+	bl.Ban("peer1", time.Hour)
+	bl.Ban("peer2", -time.Second) // already expired
+	assert.True(bl.IsBanned("peer1"))
+	assert.False(bl.IsBanned("peer2"))
+	assert.False(bl.IsBanned("peer3"))
+}
@@ -0,0 +1,26 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
+)
+
+// RecordChannelBytesSent records the number of message bytes sent through the given channel
+func RecordChannelBytesSent(channelID common.ChannelIDEnum, numBytes int) {
+	recordChannelBytes(channelID, "sent", numBytes)
+}
+
+// RecordChannelBytesReceived records the number of message bytes received from the given channel
+func RecordChannelBytesReceived(channelID common.ChannelIDEnum, numBytes int) {
+	recordChannelBytes(channelID, "received", numBytes)
+}
+
+func recordChannelBytes(channelID common.ChannelIDEnum, direction string, numBytes int) {
+	if !metrics.Enabled {
+		return
+	}
+	name := fmt.Sprintf("p2p/channel/%d/%s_bytes", channelID, direction)
+	metrics.GetOrRegisterCounter(name, nil).Inc(int64(numBytes))
+}
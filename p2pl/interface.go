@@ -2,6 +2,7 @@ package p2pl
 
 import (
 	"context"
+	"time"
 
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/p2p/types"
@@ -60,6 +61,15 @@ type Network interface {
 	// PeerExists indicates if the given peerID is a neighboring peer
 	PeerExists(peerID string) bool
 
+	// ConnectToPeer connects to the peer at the given address
+	ConnectToPeer(address string) error
+
+	// DisconnectPeer disconnects from the given peer
+	DisconnectPeer(peerID string) error
+
+	// BanPeer disconnects from the given peer, and rejects its connections for the given duration
+	BanPeer(peerID string, duration time.Duration) error
+
 	// RegisterMessageHandler registers message handler
 	RegisterMessageHandler(messageHandler MessageHandler)
 
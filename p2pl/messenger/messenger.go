@@ -72,6 +72,7 @@ type Messenger struct {
 	seedPeerOnly  bool
 
 	peerTable    *peer.PeerTable
+	banList      *p2ptypes.BanList
 	newPeers     chan pr.ID
 	peerDead     chan pr.ID
 	newPeerError chan pr.ID
@@ -145,6 +146,7 @@ func CreateMessenger(pubKey *crypto.PublicKey, seedPeerMultiAddresses []string,
 
 	messenger := &Messenger{
 		peerTable:           &pt,
+		banList:             p2ptypes.NewBanList(),
 		newPeers:            make(chan pr.ID),
 		peerDead:            make(chan pr.ID),
 		newPeerError:        make(chan pr.ID),
@@ -288,6 +290,11 @@ func (msgr *Messenger) processLoop(ctx context.Context) {
 				continue
 			}
 
+			if msgr.banList.IsBanned(pid.Pretty()) {
+				msgr.host.Network().ClosePeer(pid)
+				continue
+			}
+
 			if msgr.seedPeerOnly {
 				if !msgr.IsSeedPeer(string(pid)) {
 					msgr.host.Network().ClosePeer(pid)
@@ -567,6 +574,7 @@ func (msgr *Messenger) Publish(message p2ptypes.Message) error {
 		log.Errorf("Failed to publish to gossipsub topic: %v", err)
 		return err
 	}
+	p2ptypes.RecordChannelBytesSent(message.ChannelID, len(bytes))
 
 	return nil
 }
@@ -677,6 +685,8 @@ func (msgr *Messenger) PeerExists(peerID string) bool {
 }
 
 func (msgr *Messenger) recordReceivedBytes(cid common.ChannelIDEnum, size int) {
+	p2ptypes.RecordChannelBytesReceived(cid, size)
+
 	if !msgr.statsEnabled {
 		return
 	}
@@ -697,7 +707,7 @@ func (msgr *Messenger) printStats() {
 	defer msgr.statsLock.Unlock()
 
 	ret := "Received bytes:"
-	for k := byte(0); k <= byte(common.ChannelIDAggregatedEliteEdgeNodeVotes); k++ {
+	for k := byte(0); k <= byte(common.ChannelIDValidatorSetProof); k++ {
 		v, ok := msgr.statsCounter[common.ChannelIDEnum(k)]
 		if !ok {
 			continue
@@ -707,6 +717,54 @@ func (msgr *Messenger) printStats() {
 	logger.Debug(ret)
 }
 
+// ConnectToPeer connects to the peer at the given multiaddress, which should include the
+// peer ID, e.g. "/ip4/127.0.0.1/tcp/12000/p2p/12D3KooW..."
+func (msgr *Messenger) ConnectToPeer(address string) error {
+	addr, err := ma.NewMultiaddr(address)
+	if err != nil {
+		return err
+	}
+	addrInfo, err := peerstore.InfoFromP2pAddr(addr)
+	if err != nil {
+		return err
+	}
+	return msgr.host.Connect(msgr.ctx, *addrInfo)
+}
+
+// DisconnectPeer disconnects from the given peer
+func (msgr *Messenger) DisconnectPeer(peerID string) error {
+	pid, err := pr.IDB58Decode(peerID)
+	if err != nil {
+		return err
+	}
+	if !msgr.peerTable.PeerExists(pid) {
+		return fmt.Errorf("peer %v is not connected", peerID)
+	}
+
+	// Let the process loop tear down the peer, as it does for the peers with errors
+	select {
+	case msgr.newPeerError <- pid:
+	case <-msgr.ctx.Done():
+		return msgr.ctx.Err()
+	}
+	logger.Infof("Disconnected from peer %v", peerID)
+	return nil
+}
+
+// BanPeer disconnects from the given peer, and rejects its connections for the given duration
+func (msgr *Messenger) BanPeer(peerID string, duration time.Duration) error {
+	pid, err := pr.IDB58Decode(peerID)
+	if err != nil {
+		return err
+	}
+	msgr.banList.Ban(pid.Pretty(), duration)
+	logger.Infof("Banned peer %v for %v", peerID, duration)
+	if msgr.peerTable.PeerExists(pid) {
+		return msgr.DisconnectPeer(peerID)
+	}
+	return nil
+}
+
 // RegisterMessageHandler registers the message handler
 func (msgr *Messenger) RegisterMessageHandler(msgHandler p2pl.MessageHandler) {
 	channelIDs := msgHandler.GetChannelIDs()
@@ -765,6 +823,12 @@ func (msgr *Messenger) registerStreamHandler(channelID common.ChannelIDEnum) {
 	msgr.host.SetStreamHandler(protocol.ID(msgr.protocolPrefix+strconv.Itoa(int(channelID))), func(strm network.Stream) {
 		peerID := strm.Conn().RemotePeer()
 
+		if msgr.banList.IsBanned(peerID.Pretty()) {
+			strm.Reset()
+			msgr.host.Network().ClosePeer(peerID)
+			return
+		}
+
 		if msgr.seedPeerOnly {
 			if !msgr.IsSeedPeer(string(peerID)) {
 				msgr.host.Network().ClosePeer(peerID)
@@ -32,6 +32,8 @@ var Channels = []cmn.ChannelIDEnum{
 	cmn.ChannelIDGuardian,
 	cmn.ChannelIDEliteEdgeNodeVote,
 	cmn.ChannelIDAggregatedEliteEdgeNodeVotes,
+	cmn.ChannelIDEvidence,
+	cmn.ChannelIDValidatorSetProof,
 }
 
 //
@@ -196,6 +198,7 @@ func (peer *Peer) Send(channelID cmn.ChannelIDEnum, message interface{}) bool {
 		logger.Errorf("Didn't write expected bytes length")
 		return false
 	}
+	p2ptypes.RecordChannelBytesSent(channelID, n)
 
 	return true
 }
@@ -0,0 +1,312 @@
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/util"
+	"github.com/thetatoken/theta/consensus"
+	"github.com/thetatoken/theta/dispatcher"
+	"github.com/thetatoken/theta/mempool"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+	"github.com/thetatoken/theta/version"
+)
+
+// defaultBanDuration is the ban duration used when BanPeer does not specify one
+const defaultBanDuration = 24 * time.Hour
+
+// ThetaAdminRPCService provides the admin.* RPC methods for operating the node at runtime.
+type ThetaAdminRPCService struct {
+	mempool    *mempool.Mempool
+	dispatcher *dispatcher.Dispatcher
+	chain      *blockchain.Chain
+	consensus  *consensus.ConsensusEngine
+}
+
+// ThetaAdminRPCServer is an instance of the admin RPC service. It is bound separately from
+// the public RPC service, to localhost or a Unix socket by default.
+type ThetaAdminRPCServer struct {
+	*ThetaAdminRPCService
+
+	server   *http.Server
+	listener net.Listener
+
+	// Life cycle
+	wg     *sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewThetaAdminRPCServer creates a new instance of ThetaAdminRPCServer.
+func NewThetaAdminRPCServer(mempool *mempool.Mempool, dispatcher *dispatcher.Dispatcher,
+	chain *blockchain.Chain, consensus *consensus.ConsensusEngine) *ThetaAdminRPCServer {
+	t := &ThetaAdminRPCServer{
+		ThetaAdminRPCService: &ThetaAdminRPCService{
+			mempool:    mempool,
+			dispatcher: dispatcher,
+			chain:      chain,
+			consensus:  consensus,
+		},
+		wg: &sync.WaitGroup{},
+	}
+
+	s := rpc.NewServer()
+	s.RegisterName("admin", t.ThetaAdminRPCService)
+
+	authToken := viper.GetString(common.CfgRPCAdminAuthToken)
+	t.server = &http.Server{
+		Handler: adminAuthMiddleware(jsonrpc2.HTTPHandler(s), authToken),
+	}
+
+	logger = util.GetLoggerForModule("rpc")
+
+	return t
+}
+
+// Start creates the main goroutine.
+func (t *ThetaAdminRPCServer) Start(ctx context.Context) {
+	c, cancel := context.WithCancel(ctx)
+	t.ctx = c
+	t.cancel = cancel
+
+	t.wg.Add(1)
+	go t.mainLoop()
+}
+
+func (t *ThetaAdminRPCServer) mainLoop() {
+	defer t.wg.Done()
+
+	go t.serve()
+
+	<-t.ctx.Done()
+	t.server.Shutdown(context.Background())
+}
+
+func (t *ThetaAdminRPCServer) serve() {
+	var l net.Listener
+	var err error
+	socketPath := viper.GetString(common.CfgRPCAdminUnixSocket)
+	if socketPath != "" {
+		os.Remove(socketPath) // clean up the socket left over by the previous run
+		l, err = listenPrivateUnixSocket(socketPath)
+		defer os.Remove(socketPath)
+	} else {
+		address := viper.GetString(common.CfgRPCAdminAddress)
+		port := viper.GetString(common.CfgRPCAdminPort)
+		if !isLoopbackAddress(address) && viper.GetString(common.CfgRPCAdminAuthToken) == "" {
+			logger.Warnf("Admin RPC server is bound to %v without an auth token", address)
+		}
+		l, err = net.Listen("tcp", address+":"+port)
+	}
+	if err != nil {
+		logger.WithFields(log.Fields{"error": err}).Fatal("Failed to create admin RPC listener")
+	}
+	logger.WithFields(log.Fields{"address": l.Addr().String()}).Info("Admin RPC server started")
+	defer l.Close()
+	t.listener = l
+
+	logger.Info(t.server.Serve(l))
+}
+
+// listenPrivateUnixSocket listens on a Unix socket at the given path which only the owner of the
+// process can connect to. The socket is created in a private directory, and moved to the path
+// once its permissions are restricted, so it is never exposed with the permissions derived from
+// the umask.
+func listenPrivateUnixSocket(socketPath string) (net.Listener, error) {
+	dir, err := ioutil.TempDir(filepath.Dir(socketPath), ".admin-rpc-") // created with mode 0700
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmpPath := filepath.Join(dir, "admin.sock")
+	l, err := net.Listen("unix", tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false) // the socket is removed from its final path instead
+	if err = os.Chmod(tmpPath, 0600); err == nil {
+		err = os.Rename(tmpPath, socketPath)
+	}
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Stop notifies all goroutines to stop without blocking.
+func (t *ThetaAdminRPCServer) Stop() {
+	t.cancel()
+}
+
+// Wait blocks until all goroutines stop.
+func (t *ThetaAdminRPCServer) Wait() {
+	t.wg.Wait()
+}
+
+// adminAuthMiddleware rejects the requests which do not carry the auth token as a bearer
+// token. An empty token disables the check.
+func adminAuthMiddleware(handler http.Handler, authToken string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authToken != "" {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(authToken)) != 1 {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func isLoopbackAddress(address string) bool {
+	if address == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(address)
+	return ip != nil && ip.IsLoopback()
+}
+
+// ------------------------------ AddPeer -----------------------------------
+
+type AddPeerArgs struct {
+	Address string `json:"address"` // "ip:port" for the p2p network, or a multiaddress for the libp2p network
+}
+
+type AddPeerResult struct {
+}
+
+func (t *ThetaAdminRPCService) AddPeer(args *AddPeerArgs, result *AddPeerResult) error {
+	if args.Address == "" {
+		return errors.New("Address must be specified")
+	}
+	return t.dispatcher.ConnectToPeer(args.Address)
+}
+
+// ------------------------------ DisconnectPeer -----------------------------------
+
+type DisconnectPeerArgs struct {
+	PeerID string `json:"peer_id"`
+}
+
+type DisconnectPeerResult struct {
+}
+
+func (t *ThetaAdminRPCService) DisconnectPeer(args *DisconnectPeerArgs, result *DisconnectPeerResult) error {
+	if args.PeerID == "" {
+		return errors.New("Peer ID must be specified")
+	}
+	return t.dispatcher.DisconnectPeer(args.PeerID)
+}
+
+// ------------------------------ BanPeer -----------------------------------
+
+type BanPeerArgs struct {
+	PeerID       string            `json:"peer_id"`
+	DurationSecs common.JSONUint64 `json:"duration_secs"` // zero means the default duration of 24 hours
+}
+
+type BanPeerResult struct {
+	BannedUntil common.JSONUint64 `json:"banned_until"` // unix timestamp
+}
+
+func (t *ThetaAdminRPCService) BanPeer(args *BanPeerArgs, result *BanPeerResult) error {
+	if args.PeerID == "" {
+		return errors.New("Peer ID must be specified")
+	}
+	duration := defaultBanDuration
+	if args.DurationSecs > 0 {
+		duration = time.Duration(args.DurationSecs) * time.Second
+	}
+	if err := t.dispatcher.BanPeer(args.PeerID, duration); err != nil {
+		return err
+	}
+	result.BannedUntil = common.JSONUint64(time.Now().Add(duration).Unix())
+	return nil
+}
+
+// ------------------------------ SetLogLevel -----------------------------------
+
+type SetLogLevelArgs struct {
+	Module string `json:"module"` // "*" sets the default level
+	Level  string `json:"level"`
+}
+
+type SetLogLevelResult struct {
+	Levels map[string]string `json:"levels"`
+}
+
+func (t *ThetaAdminRPCService) SetLogLevel(args *SetLogLevelArgs, result *SetLogLevelResult) error {
+	if args.Module == "" {
+		return errors.New("Module must be specified")
+	}
+	if err := util.SetLogLevel(args.Module, args.Level); err != nil {
+		return err
+	}
+	result.Levels = util.GetLogLevels()
+	return nil
+}
+
+// ------------------------------ FlushMempool -----------------------------------
+
+type FlushMempoolArgs struct {
+}
+
+type FlushMempoolResult struct {
+	NumFlushedTxs common.JSONUint64 `json:"num_flushed_txs"`
+}
+
+func (t *ThetaAdminRPCService) FlushMempool(args *FlushMempoolArgs, result *FlushMempoolResult) error {
+	result.NumFlushedTxs = common.JSONUint64(t.mempool.Size())
+	t.mempool.Flush()
+	return nil
+}
+
+// ------------------------------ GetNodeInfo -----------------------------------
+
+type GetNodeInfoArgs struct {
+}
+
+type GetNodeInfoResult struct {
+	Address                    string            `json:"address"`
+	PeerID                     string            `json:"peer_id"`
+	ChainID                    string            `json:"chain_id"`
+	Version                    string            `json:"version"`
+	GitHash                    string            `json:"git_hash"`
+	LatestFinalizedBlockHeight common.JSONUint64 `json:"latest_finalized_block_height"`
+	CurrentEpoch               common.JSONUint64 `json:"current_epoch"`
+	Syncing                    bool              `json:"syncing"`
+	MempoolSize                common.JSONUint64 `json:"mempool_size"`
+	Peers                      []string          `json:"peers"`
+	LogLevels                  map[string]string `json:"log_levels"`
+}
+
+func (t *ThetaAdminRPCService) GetNodeInfo(args *GetNodeInfoArgs, result *GetNodeInfoResult) error {
+	result.Address = t.consensus.ID()
+	result.PeerID = t.dispatcher.LibP2PID()
+	result.ChainID = t.chain.ChainID
+	result.Version = version.Version
+	result.GitHash = version.GitHash
+	result.LatestFinalizedBlockHeight = common.JSONUint64(t.consensus.GetLastFinalizedBlock().Height)
+	result.CurrentEpoch = common.JSONUint64(t.consensus.GetEpoch())
+	result.Syncing = !t.consensus.HasSynced()
+	result.MempoolSize = common.JSONUint64(t.mempool.Size())
+	result.Peers = t.dispatcher.Peers(false)
+	result.LogLevels = util.GetLogLevels()
+	return nil
+}
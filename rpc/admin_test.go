@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	assert := assert.New(t)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serve := func(handler http.Handler, authHeader string) int {
+		req := httptest.NewRequest("POST", "/", nil)
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	noAuth := adminAuthMiddleware(ok, "")
+	assert.Equal(http.StatusOK, serve(noAuth, ""))
+
+	withAuth := adminAuthMiddleware(ok, "s3cret")
+	assert.Equal(http.StatusOK, serve(withAuth, "Bearer s3cret"))
+	assert.Equal(http.StatusUnauthorized, serve(withAuth, "Bearer wrong"))
+	assert.Equal(http.StatusUnauthorized, serve(withAuth, ""))
+}
+
+func TestIsLoopbackAddress(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(isLoopbackAddress("127.0.0.1"))
+	assert.True(isLoopbackAddress("::1"))
+	assert.True(isLoopbackAddress("localhost"))
+	assert.False(isLoopbackAddress("0.0.0.0"))
+	assert.False(isLoopbackAddress("10.0.0.1"))
+}
+
+func TestAdminSetLogLevel(t *testing.T) {
+	assert := assert.New(t)
+
+	service := &ThetaAdminRPCService{}
+	result := &SetLogLevelResult{}
+	assert.Nil(service.SetLogLevel(&SetLogLevelArgs{Module: "mempool", Level: "debug"}, result))
+	assert.Equal("debug", result.Levels["mempool"])
+
+	assert.NotNil(service.SetLogLevel(&SetLogLevelArgs{Module: "mempool", Level: "loud"}, result))
+	assert.NotNil(service.SetLogLevel(&SetLogLevelArgs{Level: "debug"}, result))
+}
+
+func TestListenPrivateUnixSocket(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "theta-admin-rpc-test")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+
+	socketPath := filepath.Join(dir, "admin.sock")
+	l, err := listenPrivateUnixSocket(socketPath)
+	require.Nil(err)
+	defer l.Close()
+
+	info, err := os.Stat(socketPath)
+	require.Nil(err)
+	assert.True(info.Mode()&os.ModeSocket != 0)
+	assert.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	// The private directory the socket was created in is removed
+	files, err := ioutil.ReadDir(dir)
+	require.Nil(err)
+	assert.Equal(1, len(files))
+
+	conn, err := net.Dial("unix", socketPath)
+	require.Nil(err)
+	conn.Close()
+}
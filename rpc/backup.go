@@ -1,6 +1,8 @@
 package rpc
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path"
 
@@ -8,6 +10,25 @@ import (
 	"github.com/thetatoken/theta/snapshot"
 )
 
+// The backup RPCs start the exports as background jobs and return the job ID right away,
+// since exporting the state of a mainnet node takes much longer than the RPC timeout. The
+// progress and result of the jobs can be queried with GetBackupJobStatus.
+
+const (
+	BackupJobTypeSnapshot        = "snapshot"
+	BackupJobTypeChain           = "chain"
+	BackupJobTypeChainCorrection = "chain_correction"
+)
+
+// backupJobContext returns the context the backup jobs run in, which is cancelled when
+// the RPC server stops
+func (t *ThetaRPCService) backupJobContext() context.Context {
+	if t.ctx == nil {
+		return context.Background()
+	}
+	return t.ctx
+}
+
 // ------------------------------- BackupSnapshot -----------------------------------
 
 type BackupSnapshotArgs struct {
@@ -17,14 +38,17 @@ type BackupSnapshotArgs struct {
 }
 
 type BackupSnapshotResult struct {
+	JobID        string `json:"job_id"`
 	SnapshotFile string `json:"snapshot_file"`
 }
 
 func (t *ThetaRPCService) BackupSnapshot(args *BackupSnapshotArgs, result *BackupSnapshotResult) error {
 	// Default to older verison
-	if args.Version == 0 {
-		args.Version = 2
+	version := args.Version
+	if version == 0 {
+		version = 2
 	}
+	height := args.Height
 
 	db := t.ledger.State().DB()
 	consensus := t.consensus
@@ -35,18 +59,22 @@ func (t *ThetaRPCService) BackupSnapshot(args *BackupSnapshotArgs, result *Backu
 		os.MkdirAll(snapshotDir, os.ModePerm)
 	}
 
-	if args.Version == 2 {
-		snapshotFile, err := snapshot.ExportSnapshotV2(db, consensus, chain, snapshotDir, args.Height)
-		result.SnapshotFile = snapshotFile
-		return err
-	} else if args.Version == 3 {
-		snapshotFile, err := snapshot.ExportSnapshotV3(db, consensus, chain, snapshotDir, args.Height)
-		result.SnapshotFile = snapshotFile
-		return err
-	}
-
-	snapshotFile, err := snapshot.ExportSnapshotV4(db, consensus, chain, snapshotDir, args.Height)
-	result.SnapshotFile = snapshotFile
+	// Only the V4 export reports its progress and can be cancelled
+	trackProgress := version > 3
+	jobID, err := t.backupJobs.Start(t.backupJobContext(), t.wg, BackupJobTypeSnapshot, trackProgress,
+		func(progress *snapshot.ExportProgress) (interface{}, error) {
+			var snapshotFile string
+			var err error
+			if version == 2 {
+				snapshotFile, err = snapshot.ExportSnapshotV2(db, consensus, chain, snapshotDir, height)
+			} else if version == 3 {
+				snapshotFile, err = snapshot.ExportSnapshotV3(db, consensus, chain, snapshotDir, height)
+			} else {
+				snapshotFile, err = snapshot.ExportSnapshotV4WithProgress(db, consensus, chain, snapshotDir, height, progress)
+			}
+			return &BackupSnapshotResult{SnapshotFile: snapshotFile}, err
+		})
+	result.JobID = jobID
 	return err
 }
 
@@ -59,6 +87,7 @@ type BackupChainArgs struct {
 }
 
 type BackupChainResult struct {
+	JobID             string `json:"job_id"`
 	ActualStartHeight uint64 `json:"actual_start_height"`
 	ActualEndHeight   uint64 `json:"actual_end_height"`
 	ChainFile         string `json:"chain_file"`
@@ -74,11 +103,16 @@ func (t *ThetaRPCService) BackupChain(args *BackupChainArgs, result *BackupChain
 		os.MkdirAll(backupDir, os.ModePerm)
 	}
 
-	actualStartHeight, actualEndHeight, chainFile, err := snapshot.ExportChainBackup(chain, startHeight, endHeight, backupDir)
-	result.ActualStartHeight = actualStartHeight
-	result.ActualEndHeight = actualEndHeight
-	result.ChainFile = chainFile
-
+	jobID, err := t.backupJobs.Start(t.backupJobContext(), t.wg, BackupJobTypeChain, true,
+		func(progress *snapshot.ExportProgress) (interface{}, error) {
+			actualStartHeight, actualEndHeight, chainFile, err := snapshot.ExportChainBackupWithProgress(chain, startHeight, endHeight, backupDir, progress)
+			return &BackupChainResult{
+				ActualStartHeight: actualStartHeight,
+				ActualEndHeight:   actualEndHeight,
+				ChainFile:         chainFile,
+			}, err
+		})
+	result.JobID = jobID
 	return err
 }
 
@@ -92,6 +126,7 @@ type BackupChainCorrectionArgs struct {
 }
 
 type BackupChainCorrectionResult struct {
+	JobID        string            `json:"job_id"`
 	ChainFile    string            `json:"chain_correction_file"`
 	BlockHashMap map[uint64]string `json:"block_hash_map"`
 }
@@ -108,9 +143,58 @@ func (t *ThetaRPCService) BackupChainCorrection(args *BackupChainCorrectionArgs,
 		os.MkdirAll(backupDir, os.ModePerm)
 	}
 
-	chainFile, blockHashMap, err := snapshot.ExportChainCorrection(chain, ledger, snapshotHeight, endBlockHash, backupDir, exclusionTxs)
-	result.ChainFile = chainFile
-	result.BlockHashMap = blockHashMap
-
+	jobID, err := t.backupJobs.Start(t.backupJobContext(), t.wg, BackupJobTypeChainCorrection, true,
+		func(progress *snapshot.ExportProgress) (interface{}, error) {
+			chainFile, blockHashMap, err := snapshot.ExportChainCorrectionWithProgress(chain, ledger, snapshotHeight, endBlockHash, backupDir, exclusionTxs, progress)
+			return &BackupChainCorrectionResult{
+				ChainFile:    chainFile,
+				BlockHashMap: blockHashMap,
+			}, err
+		})
+	result.JobID = jobID
 	return err
 }
+
+// ------------------------------- GetBackupJobStatus -----------------------------------
+
+type GetBackupJobStatusArgs struct {
+	JobID string `json:"job_id"`
+}
+
+type GetBackupJobStatusResult struct {
+	JobID         string            `json:"job_id"`
+	Type          string            `json:"type"`
+	Status        string            `json:"status"`
+	Error         string            `json:"error,omitempty"`
+	BytesWritten  common.JSONUint64 `json:"bytes_written"`
+	Stage         string            `json:"stage"`
+	CurrentHeight common.JSONUint64 `json:"current_height"`
+	CurrentTrie   string            `json:"current_trie,omitempty"`
+	Progress      float64           `json:"progress"` // estimated fraction completed, in [0, 1]
+	ElapsedSecs   common.JSONUint64 `json:"elapsed_secs"`
+	ETASecs       common.JSONUint64 `json:"eta_secs"` // zero if unknown
+	Result        interface{}       `json:"result,omitempty"`
+}
+
+func (t *ThetaRPCService) GetBackupJobStatus(args *GetBackupJobStatusArgs, result *GetBackupJobStatusResult) error {
+	if args.JobID == "" {
+		return errors.New("Job ID must be specified")
+	}
+	return t.backupJobs.Status(args.JobID, result)
+}
+
+// ------------------------------- CancelBackupJob -----------------------------------
+
+type CancelBackupJobArgs struct {
+	JobID string `json:"job_id"`
+}
+
+type CancelBackupJobResult struct {
+}
+
+func (t *ThetaRPCService) CancelBackupJob(args *CancelBackupJobArgs, result *CancelBackupJobResult) error {
+	if args.JobID == "" {
+		return errors.New("Job ID must be specified")
+	}
+	return t.backupJobs.Cancel(args.JobID)
+}
@@ -0,0 +1,195 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/snapshot"
+)
+
+const (
+	BackupJobStatusRunning   = "running"
+	BackupJobStatusCompleted = "completed"
+	BackupJobStatusFailed    = "failed"
+	BackupJobStatusCancelled = "cancelled"
+)
+
+// maxFinishedBackupJobs is the number of finished jobs kept around for status queries
+const maxFinishedBackupJobs = 16
+
+// backupJobFunc runs the export of a backup job. The progress is nil for the exports
+// which do not support progress tracking and cancellation.
+type backupJobFunc func(progress *snapshot.ExportProgress) (interface{}, error)
+
+type backupJob struct {
+	id        string
+	jobType   string
+	startTime time.Time
+	endTime   time.Time
+	progress  *snapshot.ExportProgress
+	cancel    context.CancelFunc
+	status    string
+	err       error
+	result    interface{}
+}
+
+// BackupJobManager runs the snapshot and chain exports in the background. At most one
+// export runs at a time, since exports are I/O heavy and compete for the same database.
+type BackupJobManager struct {
+	mu       *sync.Mutex
+	jobs     map[string]*backupJob
+	finished []string // IDs of the finished jobs, oldest first
+	running  *backupJob
+	nextID   uint64
+}
+
+// NewBackupJobManager creates a new instance of BackupJobManager
+func NewBackupJobManager() *BackupJobManager {
+	return &BackupJobManager{
+		mu:   &sync.Mutex{},
+		jobs: make(map[string]*backupJob),
+	}
+}
+
+// Start starts a job which runs the given function in a separate goroutine. The job is
+// cancelled when ctx is done. If trackProgress is false, the function is passed a nil
+// progress and the job cannot be cancelled. An error is returned if another job is still
+// running.
+func (m *BackupJobManager) Start(ctx context.Context, wg *sync.WaitGroup, jobType string, trackProgress bool, run backupJobFunc) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running != nil {
+		return "", fmt.Errorf("Backup job %v is still running", m.running.id)
+	}
+
+	m.nextID++
+	c, cancel := context.WithCancel(ctx)
+	job := &backupJob{
+		id:        fmt.Sprintf("%s-%d", jobType, m.nextID),
+		jobType:   jobType,
+		startTime: time.Now(),
+		cancel:    cancel,
+		status:    BackupJobStatusRunning,
+	}
+	if trackProgress {
+		job.progress = snapshot.NewExportProgress(c)
+	}
+	m.jobs[job.id] = job
+	m.running = job
+
+	logger.WithFields(log.Fields{"job": job.id}).Info("Backup job started")
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer cancel()
+		result, err := run(job.progress)
+		m.finish(job, result, err)
+	}()
+
+	return job.id, nil
+}
+
+func (m *BackupJobManager) finish(job *backupJob, result interface{}, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job.endTime = time.Now()
+	job.result = result
+	job.err = err
+	if err == nil {
+		job.status = BackupJobStatusCompleted
+	} else if err == snapshot.ErrExportCancelled {
+		job.status = BackupJobStatusCancelled
+	} else {
+		job.status = BackupJobStatusFailed
+	}
+	m.running = nil
+
+	m.finished = append(m.finished, job.id)
+	if len(m.finished) > maxFinishedBackupJobs {
+		delete(m.jobs, m.finished[0])
+		m.finished = m.finished[1:]
+	}
+
+	logger.WithFields(log.Fields{
+		"job":     job.id,
+		"status":  job.status,
+		"elapsed": job.endTime.Sub(job.startTime),
+		"error":   err,
+	}).Info("Backup job finished")
+}
+
+// Cancel cancels the running job with the given ID
+func (m *BackupJobManager) Cancel(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return fmt.Errorf("Backup job %v not found", id)
+	}
+	if job.status != BackupJobStatusRunning {
+		return fmt.Errorf("Backup job %v is already %v", id, job.status)
+	}
+	if job.progress == nil {
+		return fmt.Errorf("Backup job %v does not support cancellation", id)
+	}
+	job.cancel()
+	return nil
+}
+
+// Status fills in result with the status of the job with the given ID
+func (m *BackupJobManager) Status(id string, result *GetBackupJobStatusResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return fmt.Errorf("Backup job %v not found", id)
+	}
+
+	endTime := job.endTime
+	if job.status == BackupJobStatusRunning {
+		endTime = time.Now()
+	}
+	elapsed := endTime.Sub(job.startTime)
+
+	result.JobID = job.id
+	result.Type = job.jobType
+	result.Status = job.status
+	result.ElapsedSecs = common.JSONUint64(elapsed / time.Second)
+	result.Result = job.result
+	if job.err != nil {
+		result.Error = job.err.Error()
+	}
+
+	if job.progress != nil {
+		progress := job.progress.Status()
+		result.BytesWritten = common.JSONUint64(progress.BytesWritten)
+		result.Stage = progress.Stage
+		result.CurrentHeight = common.JSONUint64(progress.CurrentHeight)
+		if !progress.CurrentTrie.IsEmpty() {
+			result.CurrentTrie = progress.CurrentTrie.Hex()
+		}
+		result.Progress = progress.Fraction
+		if job.status == BackupJobStatusRunning {
+			result.ETASecs = common.JSONUint64(estimateRemainingTime(elapsed, progress.Fraction) / time.Second)
+		}
+	}
+	return nil
+}
+
+// estimateRemainingTime extrapolates the time needed to finish a job from the time it has
+// spent so far. It returns zero if the fraction completed is not known yet.
+func estimateRemainingTime(elapsed time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 || fraction >= 1 {
+		return 0
+	}
+	return time.Duration(float64(elapsed) * (1 - fraction) / fraction)
+}
@@ -0,0 +1,113 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common/util"
+	"github.com/thetatoken/theta/snapshot"
+)
+
+func TestBackupJobManager(t *testing.T) {
+	assert := assert.New(t)
+	logger = util.GetLoggerForModule("rpc")
+
+	m := NewBackupJobManager()
+	wg := &sync.WaitGroup{}
+
+	// A job which runs until it is cancelled
+	started := make(chan struct{})
+	jobID, err := m.Start(context.Background(), wg, BackupJobTypeSnapshot, true,
+		func(progress *snapshot.ExportProgress) (interface{}, error) {
+			close(started)
+			for !progress.Cancelled() {
+				time.Sleep(time.Millisecond)
+			}
+			return nil, snapshot.ErrExportCancelled
+		})
+	assert.Nil(err)
+	<-started
+
+	status := &GetBackupJobStatusResult{}
+	assert.Nil(m.Status(jobID, status))
+	assert.Equal(BackupJobStatusRunning, status.Status)
+	assert.Equal(BackupJobTypeSnapshot, status.Type)
+
+	// Concurrent exports are rejected
+	_, err = m.Start(context.Background(), wg, BackupJobTypeChain, true,
+		func(progress *snapshot.ExportProgress) (interface{}, error) { return nil, nil })
+	assert.NotNil(err)
+
+	assert.Nil(m.Cancel(jobID))
+	wg.Wait()
+
+	status = &GetBackupJobStatusResult{}
+	assert.Nil(m.Status(jobID, status))
+	assert.Equal(BackupJobStatusCancelled, status.Status)
+	assert.NotNil(m.Cancel(jobID))
+
+	// A new job can be started once the previous one has finished
+	jobID, err = m.Start(context.Background(), wg, BackupJobTypeChain, true,
+		func(progress *snapshot.ExportProgress) (interface{}, error) {
+			return &BackupChainResult{ChainFile: "theta_chain-1-100"}, nil
+		})
+	assert.Nil(err)
+	wg.Wait()
+
+	status = &GetBackupJobStatusResult{}
+	assert.Nil(m.Status(jobID, status))
+	assert.Equal(BackupJobStatusCompleted, status.Status)
+	assert.Equal("theta_chain-1-100", status.Result.(*BackupChainResult).ChainFile)
+
+	// Failed job
+	jobID, err = m.Start(context.Background(), wg, BackupJobTypeChainCorrection, false,
+		func(progress *snapshot.ExportProgress) (interface{}, error) {
+			assert.Nil(progress)
+			return nil, errors.New("block not found")
+		})
+	assert.Nil(err)
+	wg.Wait()
+
+	status = &GetBackupJobStatusResult{}
+	assert.Nil(m.Status(jobID, status))
+	assert.Equal(BackupJobStatusFailed, status.Status)
+	assert.Equal("block not found", status.Error)
+
+	assert.NotNil(m.Status("snapshot-100", status))
+}
+
+func TestBackupJobManagerPrunesFinishedJobs(t *testing.T) {
+	assert := assert.New(t)
+	logger = util.GetLoggerForModule("rpc")
+
+	m := NewBackupJobManager()
+	wg := &sync.WaitGroup{}
+
+	var jobIDs []string
+	for i := 0; i < maxFinishedBackupJobs+2; i++ {
+		jobID, err := m.Start(context.Background(), wg, BackupJobTypeChain, true,
+			func(progress *snapshot.ExportProgress) (interface{}, error) { return nil, nil })
+		assert.Nil(err)
+		wg.Wait()
+		jobIDs = append(jobIDs, jobID)
+	}
+
+	status := &GetBackupJobStatusResult{}
+	assert.NotNil(m.Status(jobIDs[0], status))
+	assert.NotNil(m.Status(jobIDs[1], status))
+	assert.Nil(m.Status(jobIDs[2], status))
+	assert.Equal(maxFinishedBackupJobs, len(m.jobs))
+}
+
+func TestEstimateRemainingTime(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(time.Duration(0), estimateRemainingTime(time.Minute, 0))
+	assert.Equal(time.Duration(0), estimateRemainingTime(time.Minute, 1))
+	assert.Equal(3*time.Minute, estimateRemainingTime(time.Minute, 0.25))
+	assert.Equal(time.Minute, estimateRemainingTime(time.Minute, 0.5))
+}
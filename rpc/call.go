@@ -5,6 +5,9 @@ import (
 	"fmt"
 
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/ledger/state"
 	"github.com/thetatoken/theta/ledger/types"
 	"github.com/thetatoken/theta/ledger/vm"
@@ -27,15 +30,14 @@ type CallSmartContractResult struct {
 // the globally consensus state. It can be used for dry run, or for retrieving info from smart contracts
 // without actually spending gas.
 func (t *ThetaRPCService) CallSmartContract(args *CallSmartContractArgs, result *CallSmartContractResult) (err error) {
-	var ledgerState *state.StoreView
-	ledgerState, err = t.ledger.GetDeliveredSnapshot()
+	pb, ledgerState, err := t.ledger.GetDeliveredSnapshotWithParentBlock()
 	if err != nil {
 		return err
 	}
 
 	blockHeight := ledgerState.Height() + 1 // the view points to the parent of the current block
-	if blockHeight < common.HeightEnableSmartContract {
-		return fmt.Errorf("Smart contract feature not enabled until block height %v.", common.HeightEnableSmartContract)
+	if enableHeight := common.GetForkSchedule(pb.ChainID).EnableSmartContract; blockHeight < enableHeight {
+		return fmt.Errorf("Smart contract feature not enabled until block height %v.", enableHeight)
 	}
 
 	sctxBytes, err := hex.DecodeString(args.SctxBytes)
@@ -52,7 +54,6 @@ func (t *ThetaRPCService) CallSmartContract(args *CallSmartContractArgs, result
 		return fmt.Errorf("Failed to parse SmartContractTx: %v", args.SctxBytes)
 	}
 
-	pb := t.ledger.State().ParentBlock()
 	parentBlockInfo := vm.NewBlockInfo(pb.Height, pb.Timestamp, pb.ChainID)
 	vmRet, contractAddr, gasUsed, vmErr := vm.Execute(parentBlockInfo, sctx, ledgerState)
 	ledgerState.Save()
@@ -66,3 +67,180 @@ func (t *ThetaRPCService) CallSmartContract(args *CallSmartContractArgs, result
 
 	return nil
 }
+
+// ------------------------------- EstimateGas -----------------------------------
+
+type EstimateGasArgs struct {
+	SctxBytes string `json:"sctx_bytes"`
+}
+
+type EstimateGasResult struct {
+	GasLimit     common.JSONUint64 `json:"gas_limit"`
+	VmError      string            `json:"vm_error"`
+	RevertReason string            `json:"revert_reason"`
+}
+
+// EstimateGas searches for the minimum gas limit with which the smart contract transaction executes
+// successfully on top of the delivered state. The search is capped by the GasLimit of the transaction
+// if specified, or otherwise by the max gas limit of the block. If the transaction fails even with
+// the cap, the VM error and the revert reason (if any) are returned instead.
+func (t *ThetaRPCService) EstimateGas(args *EstimateGasArgs, result *EstimateGasResult) (err error) {
+	pb, ledgerState, err := t.ledger.GetDeliveredSnapshotWithParentBlock()
+	if err != nil {
+		return err
+	}
+
+	blockHeight := ledgerState.Height() + 1 // the view points to the parent of the current block
+	if enableHeight := common.GetForkSchedule(pb.ChainID).EnableSmartContract; blockHeight < enableHeight {
+		return fmt.Errorf("Smart contract feature not enabled until block height %v.", enableHeight)
+	}
+
+	sctxBytes, err := hex.DecodeString(args.SctxBytes)
+	if err != nil {
+		return err
+	}
+
+	tx, err := types.TxFromBytes(sctxBytes)
+	if err != nil {
+		return fmt.Errorf("Failed to parse SmartContractTx, error: %v", err)
+	}
+	sctx, ok := tx.(*types.SmartContractTx)
+	if !ok {
+		return fmt.Errorf("Failed to parse SmartContractTx: %v", args.SctxBytes)
+	}
+
+	return estimateGas(pb, ledgerState, sctx, result)
+}
+
+// estimateGas searches for the minimum gas limit of the smart contract transaction on top of
+// the given state, which is the state after the parent block
+func estimateGas(pb *core.Block, ledgerState *state.StoreView, sctx *types.SmartContractTx, result *EstimateGasResult) error {
+	blockHeight := ledgerState.Height() + 1
+
+	// Lower bound: the same intrinsic gas check the tx executor performs
+	createContract := (sctx.To.Address == common.Address{})
+	intrinsicGas, err := vm.CalculateIntrinsicGas(sctx.Data, createContract)
+	if err != nil {
+		return err
+	}
+
+	// Upper bound: the gas limit of the tx if specified, otherwise the max gas limit of the block
+	maxGasLimit := ledgerState.GetProtocolParam(pb.ChainID, types.ParamMaximumTxGasLimit, blockHeight).Uint64()
+	gasCap := maxGasLimit
+	if sctx.GasLimit != 0 && sctx.GasLimit < gasCap {
+		gasCap = sctx.GasLimit
+	}
+	if gasCap < intrinsicGas {
+		result.VmError = vm.ErrOutOfGas.Error()
+		return nil
+	}
+
+	parentBlockInfo := vm.NewBlockInfo(pb.Height, pb.Timestamp, pb.ChainID)
+
+	// execute runs the tx with the given gas limit on a fresh copy of the delivered state
+	execute := func(gasLimit uint64) (vmRet common.Bytes, gasUsed uint64, vmErr error, err error) {
+		view, err := ledgerState.Copy()
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		trial := *sctx
+		trial.GasLimit = gasLimit
+		vmRet, _, gasUsed, vmErr = vm.Execute(parentBlockInfo, &trial, view)
+		return vmRet, gasUsed, vmErr, nil
+	}
+
+	vmRet, gasUsed, vmErr, err := execute(gasCap)
+	if err != nil {
+		return err
+	}
+	if vmErr != nil {
+		result.VmError = vmErr.Error()
+		if vm.IsExecutionReverted(vmErr) {
+			result.RevertReason, _ = vm.UnpackRevertReason(vmRet)
+		}
+		return nil
+	}
+
+	// Any gas limit below the gas used with the cap runs out of gas, so the search
+	// starts from there. Invariant: lo fails and hi succeeds.
+	lo := intrinsicGas - 1
+	if gasUsed > 0 && gasUsed-1 > lo {
+		lo = gasUsed - 1
+	}
+	hi := gasCap
+	for lo+1 < hi {
+		mid := lo + (hi-lo)/2
+		_, _, vmErr, err := execute(mid)
+		if err != nil {
+			return err
+		}
+		if vmErr != nil {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	result.GasLimit = common.JSONUint64(hi)
+
+	return nil
+}
+
+// ------------------------------- SimulateTx -----------------------------------
+
+const (
+	SimulateTxViewScreened  = "screened"
+	SimulateTxViewDelivered = "delivered"
+)
+
+type SimulateTxArgs struct {
+	TxBytes            string            `json:"tx_bytes"`
+	Height             common.JSONUint64 `json:"height"` // zero means the latest state
+	View               string            `json:"view"`   // "screened" (default) or "delivered", only used with the latest state
+	SkipSignatureCheck bool              `json:"skip_signature_check"`
+}
+
+type SimulateTxResult struct {
+	TxHash         string                 `json:"hash"`
+	BlockHeight    common.JSONUint64      `json:"block_height"`
+	Code           result.ErrorCode       `json:"code"`
+	Message        string                 `json:"message"`
+	Fee            types.Coins            `json:"fee"`
+	BalanceChanges []*types.BalanceChange `json:"balance_changes"`
+}
+
+// SimulateTx runs a transaction of any type through the same sanity checks and processing as
+// the block execution, on top of a copy of the screened or delivered state, or the state of a
+// finalized block. The signature checks can be skipped, so that unsigned transactions can be
+// simulated. Nothing is committed. The balance changes are the net changes of all the accounts
+// touched by the transaction, including the fee charged.
+func (t *ThetaRPCService) SimulateTx(args *SimulateTxArgs, result *SimulateTxResult) (err error) {
+	txBytes, err := decodeTxHexBytes(args.TxBytes)
+	if err != nil {
+		return err
+	}
+
+	var useDeliveredView bool
+	switch args.View {
+	case "", SimulateTxViewScreened:
+		useDeliveredView = false
+	case SimulateTxViewDelivered:
+		useDeliveredView = true
+	default:
+		return fmt.Errorf("Invalid view: %v", args.View)
+	}
+
+	simResult, err := t.ledger.SimulateTx(txBytes, uint64(args.Height), useDeliveredView, args.SkipSignatureCheck)
+	if err != nil {
+		return err
+	}
+
+	result.TxHash = crypto.Keccak256Hash(txBytes).Hex()
+	result.BlockHeight = common.JSONUint64(simResult.BlockHeight)
+	result.Code = simResult.Result.Code
+	result.Message = simResult.Result.Message
+	result.Fee = simResult.Fee
+	result.BalanceChanges = simResult.BalanceChanges
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/vm"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+// ABI encoding of Error("Insufficient balance")
+const testRevertData = "08c379a0" +
+	"0000000000000000000000000000000000000000000000000000000000000020" +
+	"0000000000000000000000000000000000000000000000000000000000000014" +
+	"496e73756666696369656e742062616c616e6365000000000000000000000000"
+
+func TestUnpackRevertReason(t *testing.T) {
+	assert := assert.New(t)
+
+	evmRet, err := hex.DecodeString(testRevertData)
+	assert.Nil(err)
+
+	reason, ok := vm.UnpackRevertReason(evmRet)
+	assert.True(ok)
+	assert.Equal("Insufficient balance", reason)
+
+	_, ok = vm.UnpackRevertReason(evmRet[:40])
+	assert.False(ok)
+	_, ok = vm.UnpackRevertReason(append([]byte{0x12, 0x34, 0x56, 0x78}, evmRet[4:]...))
+	assert.False(ok)
+
+	assert.False(vm.IsExecutionReverted(vm.ErrOutOfGas))
+}
+
+func newEstimateGasTestState(t *testing.T, contracts map[common.Address]string) (*core.Block, *state.StoreView) {
+	parent := &core.Block{
+		BlockHeader: &core.BlockHeader{
+			ChainID:   "testchain",
+			Height:    common.MainnetForkSchedule.EnableSmartContract + 10,
+			Timestamp: big.NewInt(1600000000),
+		},
+	}
+	view := state.NewStoreView(parent.Height, common.Hash{}, backend.NewMemDatabase())
+	for address, code := range contracts {
+		raw, err := hex.DecodeString(code)
+		require.Nil(t, err)
+		view.CreateAccount(address)
+		view.SetCode(address, raw)
+	}
+	return parent, view
+}
+
+func newEstimateGasTestTx(to common.Address, gasLimit uint64) *types.SmartContractTx {
+	return &types.SmartContractTx{
+		From:     types.TxInput{Address: common.HexToAddress("0x1000"), Coins: types.NewCoins(0, 0), Sequence: 1},
+		To:       types.TxOutput{Address: to},
+		GasLimit: gasLimit,
+		GasPrice: big.NewInt(0),
+	}
+}
+
+func TestEstimateGas(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// PUSH1 1 PUSH1 0 SSTORE STOP
+	storeContract := common.HexToAddress("0x2000")
+	// Copies the 100 bytes of revert data appended to the code into memory, and reverts with them
+	revertContract := common.HexToAddress("0x3000")
+	parent, view := newEstimateGasTestState(t, map[common.Address]string{
+		storeContract:  "600160005500",
+		revertContract: "6064600c60003960646000fd" + testRevertData,
+	})
+
+	// The estimate is the minimum gas limit the tx succeeds with
+	result := &EstimateGasResult{}
+	require.Nil(estimateGas(parent, view, newEstimateGasTestTx(storeContract, 0), result))
+	assert.Equal("", result.VmError)
+	gasLimit := uint64(result.GasLimit)
+	assert.True(gasLimit > 21000)
+
+	execute := func(gasLimit uint64) error {
+		trialView, err := view.Copy()
+		require.Nil(err)
+		parentBlockInfo := vm.NewBlockInfo(parent.Height, parent.Timestamp, parent.ChainID)
+		_, _, _, vmErr := vm.Execute(parentBlockInfo, newEstimateGasTestTx(storeContract, gasLimit), trialView)
+		return vmErr
+	}
+	assert.Nil(execute(gasLimit))
+	assert.NotNil(execute(gasLimit - 1))
+
+	// The search is capped by the gas limit of the tx
+	result = &EstimateGasResult{}
+	require.Nil(estimateGas(parent, view, newEstimateGasTestTx(storeContract, gasLimit-1), result))
+	assert.Equal(vm.ErrOutOfGas.Error(), result.VmError)
+	assert.Equal(common.JSONUint64(0), result.GasLimit)
+
+	result = &EstimateGasResult{}
+	require.Nil(estimateGas(parent, view, newEstimateGasTestTx(storeContract, 20000), result))
+	assert.Equal(vm.ErrOutOfGas.Error(), result.VmError)
+
+	// A reverted tx returns the revert reason instead of an estimate
+	result = &EstimateGasResult{}
+	require.Nil(estimateGas(parent, view, newEstimateGasTestTx(revertContract, 0), result))
+	assert.NotEqual("", result.VmError)
+	assert.Equal("Insufficient balance", result.RevertReason)
+	assert.Equal(common.JSONUint64(0), result.GasLimit)
+
+	// The estimation does not modify the state
+	assert.Equal(common.Hash{}, view.GetState(storeContract, common.Hash{}))
+}
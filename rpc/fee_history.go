@@ -0,0 +1,96 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// maxFeeHistoryBlockCount limits the number of blocks a single GetFeeHistory query can return
+const maxFeeHistoryBlockCount = 1024
+
+// ------------------------------ GetFeeHistory -----------------------------------
+
+type GetFeeHistoryArgs struct {
+	BlockCount   common.JSONUint64 `json:"block_count"`
+	NewestHeight common.JSONUint64 `json:"newest_height"` // zero means the latest finalized height
+}
+
+type FeeHistoryEntry struct {
+	Height       common.JSONUint64 `json:"height"`
+	BaseFee      *common.JSONBig   `json:"base_fee"`
+	GasUsed      common.JSONUint64 `json:"gas_used"`
+	GasUsedRatio float64           `json:"gas_used_ratio"` // 0.5 means the block used exactly the block gas target
+}
+
+type GetFeeHistoryResult struct {
+	OldestHeight common.JSONUint64  `json:"oldest_height"`
+	Blocks       []*FeeHistoryEntry `json:"blocks"`        // in ascending height order
+	NextBaseFee  *common.JSONBig    `json:"next_base_fee"` // base fee of the block following the newest block
+}
+
+// GetFeeHistory returns the base fee and the gas used of up to block_count finalized blocks,
+// ending at newest_height, along with the base fee of the block following them. Blocks before
+// the dynamic base fee is enabled report the minimum gas price as their base fee.
+func (t *ThetaRPCService) GetFeeHistory(args *GetFeeHistoryArgs, result *GetFeeHistoryResult) (err error) {
+	blockCount := uint64(args.BlockCount)
+	if blockCount == 0 {
+		return errors.New("block_count must be specified")
+	}
+	if blockCount > maxFeeHistoryBlockCount {
+		return fmt.Errorf("can't retrieve the fee history of more than %v blocks at a time", maxFeeHistoryBlockCount)
+	}
+
+	newestHeight := uint64(args.NewestHeight)
+	if newestHeight == 0 {
+		newestHeight = t.consensus.GetLastFinalizedBlock().Height
+	}
+	newestBlock := t.findFinalizedBlockByHeight(newestHeight)
+	if newestBlock == nil {
+		return fmt.Errorf("finalized block at height %v not found", newestHeight)
+	}
+
+	oldestHeight := uint64(1)
+	if newestHeight >= blockCount {
+		oldestHeight = newestHeight - blockCount + 1
+	}
+
+	result.OldestHeight = common.JSONUint64(oldestHeight)
+	result.Blocks = []*FeeHistoryEntry{}
+	for height := oldestHeight; height <= newestHeight; height++ {
+		block := t.findFinalizedBlockByHeight(height)
+		if block == nil {
+			continue // e.g. blocks below the snapshot the node started from
+		}
+		result.Blocks = append(result.Blocks, newFeeHistoryEntry(block.BlockHeader))
+	}
+	result.NextBaseFee = (*common.JSONBig)(types.CalculateBaseFee(newestBlock.BlockHeader))
+
+	return nil
+}
+
+func (t *ThetaRPCService) findFinalizedBlockByHeight(height uint64) *core.ExtendedBlock {
+	for _, b := range t.chain.FindBlocksByHeight(height) {
+		if b.Status.IsFinalized() {
+			return b
+		}
+	}
+	return nil
+}
+
+func newFeeHistoryEntry(header *core.BlockHeader) *FeeHistoryEntry {
+	baseFee := header.BaseFee
+	if header.Height < common.GetForkSchedule(header.ChainID).EnableDynamicBaseFee || baseFee == nil {
+		baseFee = types.GetMinimumGasPrice(header.ChainID, header.Height)
+	}
+	maxGasUsed := types.BlockGasTarget * types.BaseFeeElasticityMultiplier
+	return &FeeHistoryEntry{
+		Height:       common.JSONUint64(header.Height),
+		BaseFee:      (*common.JSONBig)(baseFee),
+		GasUsed:      common.JSONUint64(header.GasUsed),
+		GasUsedRatio: float64(header.GasUsed) / float64(maxGasUsed),
+	}
+}
@@ -0,0 +1,38 @@
+package rpc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func TestNewFeeHistoryEntry(t *testing.T) {
+	assert := assert.New(t)
+
+	chainID := "fee_history_test_chain"
+	forks := common.MainnetForkSchedule
+	forks.EnableDynamicBaseFee = 40000000
+	common.RegisterForkSchedule(chainID, forks)
+
+	// Blocks before the fork report the minimum gas price
+	height := forks.EnableDynamicBaseFee - 1
+	entry := newFeeHistoryEntry(&core.BlockHeader{ChainID: chainID, Height: height})
+	assert.Equal(types.GetMinimumGasPrice(chainID, height), (*big.Int)(entry.BaseFee))
+	assert.Equal(0.0, entry.GasUsedRatio)
+
+	baseFee := big.NewInt(5e12)
+	entry = newFeeHistoryEntry(&core.BlockHeader{
+		ChainID: chainID,
+		Height:  forks.EnableDynamicBaseFee,
+		GasUsed: types.BlockGasTarget,
+		BaseFee: baseFee,
+	})
+	assert.Equal(common.JSONUint64(forks.EnableDynamicBaseFee), entry.Height)
+	assert.Equal(baseFee, (*big.Int)(entry.BaseFee))
+	assert.Equal(common.JSONUint64(types.BlockGasTarget), entry.GasUsed)
+	assert.Equal(0.5, entry.GasUsedRatio)
+}
@@ -0,0 +1,151 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/thetatoken/theta/common"
+)
+
+// ------------------------------ Health Endpoints -----------------------------------
+
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+)
+
+// HealthCheck is the outcome of a single readiness check
+type HealthCheck struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+// HealthResult is the response body of the /healthz and /readyz endpoints
+type HealthResult struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks,omitempty"`
+}
+
+// serveHealthz reports liveness. The node is considered alive as long as the RPC server
+// is able to respond.
+func (t *ThetaRPCServer) serveHealthz(w http.ResponseWriter, r *http.Request) {
+	writeHealthResult(w, &HealthResult{Status: HealthStatusOK})
+}
+
+// serveReadyz reports whether the node is ready to serve traffic, i.e. it has synced
+// up with the network, its last finalized block is recent, it has enough peers, and its
+// database accepts writes. It responds with 503 if any of the checks fails.
+func (t *ThetaRPCServer) serveReadyz(w http.ResponseWriter, r *http.Request) {
+	maxBlockAge := viper.GetDuration(common.CfgRPCReadinessMaxBlockAgeSecs) * time.Second
+	minPeers := viper.GetInt(common.CfgRPCReadinessMinPeers)
+
+	var blockTimestamp *big.Int
+	if block := t.consensus.GetLastFinalizedBlock(); block != nil && block.BlockHeader != nil {
+		blockTimestamp = block.Timestamp
+	}
+
+	checks := []HealthCheck{
+		checkSynced(t.consensus.HasSynced()),
+		checkFinalizedBlockAge(blockTimestamp, time.Now(), maxBlockAge),
+		checkPeerCount(len(t.dispatcher.Peers(false)), minPeers),
+		checkDBWritable(t.dbWriteProbe.check(time.Now())),
+	}
+	writeHealthResult(w, newHealthResult(checks))
+}
+
+// dbWriteProbeInterval is how long the outcome of the database write probe is reused, so
+// frequent /readyz polls do not turn into a stream of writes to the database
+const dbWriteProbeInterval = 5 * time.Second
+
+// dbWriteProbe caches the outcome of the database write probe
+type dbWriteProbe struct {
+	mu        *sync.Mutex
+	probe     func() error
+	lastProbe time.Time
+	lastErr   error
+}
+
+func newDBWriteProbe(probe func() error) *dbWriteProbe {
+	return &dbWriteProbe{
+		mu:    &sync.Mutex{},
+		probe: probe,
+	}
+}
+
+// check returns the outcome of the last probe if it ran less than dbWriteProbeInterval
+// before now, and probes the database again otherwise
+func (p *dbWriteProbe) check(now time.Time) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.lastProbe.IsZero() || now.Sub(p.lastProbe) >= dbWriteProbeInterval {
+		p.lastErr = p.probe()
+		p.lastProbe = now
+	}
+	return p.lastErr
+}
+
+func newHealthResult(checks []HealthCheck) *HealthResult {
+	result := &HealthResult{
+		Status: HealthStatusOK,
+		Checks: checks,
+	}
+	for _, check := range checks {
+		if !check.OK {
+			result.Status = HealthStatusUnavailable
+			break
+		}
+	}
+	return result
+}
+
+func writeHealthResult(w http.ResponseWriter, result *HealthResult) {
+	w.Header().Set("Content-Type", "application/json")
+	if result.Status != HealthStatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+func checkSynced(hasSynced bool) HealthCheck {
+	check := HealthCheck{Name: "synced", OK: hasSynced}
+	if !hasSynced {
+		check.Message = "node is still syncing"
+	}
+	return check
+}
+
+func checkFinalizedBlockAge(blockTimestamp *big.Int, now time.Time, maxBlockAge time.Duration) HealthCheck {
+	check := HealthCheck{Name: "finalized_block_age"}
+	if blockTimestamp == nil {
+		check.Message = "last finalized block is unknown"
+		return check
+	}
+	age := now.Sub(time.Unix(blockTimestamp.Int64(), 0))
+	check.OK = age <= maxBlockAge
+	check.Message = fmt.Sprintf("last finalized block is %v old, max allowed: %v", age.Truncate(time.Second), maxBlockAge)
+	return check
+}
+
+func checkPeerCount(numPeers int, minPeers int) HealthCheck {
+	return HealthCheck{
+		Name:    "peers",
+		OK:      numPeers >= minPeers,
+		Message: fmt.Sprintf("%v peers connected, min required: %v", numPeers, minPeers),
+	}
+}
+
+func checkDBWritable(err error) HealthCheck {
+	check := HealthCheck{Name: "db_writable", OK: err == nil}
+	if err != nil {
+		check.Message = err.Error()
+	}
+	return check
+}
@@ -0,0 +1,69 @@
+package rpc
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadinessChecks(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(checkSynced(true).OK)
+	assert.False(checkSynced(false).OK)
+
+	now := time.Unix(1000000, 0)
+	assert.True(checkFinalizedBlockAge(big.NewInt(now.Unix()-30), now, time.Minute).OK)
+	assert.False(checkFinalizedBlockAge(big.NewInt(now.Unix()-90), now, time.Minute).OK)
+	assert.False(checkFinalizedBlockAge(nil, now, time.Minute).OK)
+
+	assert.True(checkPeerCount(3, 1).OK)
+	assert.False(checkPeerCount(0, 1).OK)
+
+	assert.True(checkDBWritable(nil).OK)
+	assert.False(checkDBWritable(errors.New("disk full")).OK)
+}
+
+func TestWriteHealthResult(t *testing.T) {
+	assert := assert.New(t)
+
+	rec := httptest.NewRecorder()
+	writeHealthResult(rec, newHealthResult([]HealthCheck{checkSynced(true), checkPeerCount(2, 1)}))
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Contains(rec.Body.String(), `"status":"ok"`)
+
+	rec = httptest.NewRecorder()
+	writeHealthResult(rec, newHealthResult([]HealthCheck{checkSynced(true), checkPeerCount(0, 1)}))
+	assert.Equal(http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(rec.Body.String(), `"status":"unavailable"`)
+}
+
+func TestDBWriteProbe(t *testing.T) {
+	assert := assert.New(t)
+
+	numProbes := 0
+	var probeErr error
+	probe := newDBWriteProbe(func() error {
+		numProbes++
+		return probeErr
+	})
+
+	now := time.Unix(1000000, 0)
+	assert.Nil(probe.check(now))
+	assert.Equal(1, numProbes)
+
+	// The outcome is reused within the probe interval
+	probeErr = errors.New("disk full")
+	assert.Nil(probe.check(now.Add(dbWriteProbeInterval - time.Second)))
+	assert.Equal(1, numProbes)
+
+	assert.Equal(probeErr, probe.check(now.Add(dbWriteProbeInterval)))
+	assert.Equal(2, numProbes)
+	assert.Equal(probeErr, probe.check(now.Add(dbWriteProbeInterval+time.Second)))
+	assert.Equal(2, numProbes)
+}
@@ -0,0 +1,159 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// ------------------------------ GetLogs -----------------------------------
+
+type GetLogsArgs struct {
+	FromHeight common.JSONUint64 `json:"from_height"`
+	ToHeight   common.JSONUint64 `json:"to_height"` // zero means the latest finalized height
+	Addresses  []common.Address  `json:"addresses"` // match logs emitted by any of the addresses, empty matches all
+	Topics     [][]common.Hash   `json:"topics"`    // Topics[i] lists the alternatives for the i-th topic, empty matches all
+}
+
+type LogResult struct {
+	Address     common.Address    `json:"address"`
+	Topics      []common.Hash     `json:"topics"`
+	Data        common.Bytes      `json:"data"`
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	TxHash      common.Hash       `json:"transaction_hash"`
+	TxIndex     common.JSONUint64 `json:"transaction_index"`
+	LogIndex    common.JSONUint64 `json:"log_index"` // index of the log within the transaction
+}
+
+type GetLogsResult struct {
+	Logs []*LogResult `json:"logs"`
+}
+
+// GetLogs returns the smart contract event logs of the finalized blocks in [from_height, to_height]
+// that match the address and topic filters. The block headers do not carry a log bloom, so the
+// receipts of all the smart contract txs in the range are read. Thus the range is limited, and
+// the large queries are counted against their own pending query limit.
+func (t *ThetaRPCService) GetLogs(args *GetLogsArgs, result *GetLogsResult) (err error) {
+	fromHeight := uint64(args.FromHeight)
+	toHeight := uint64(args.ToHeight)
+	if toHeight == 0 {
+		toHeight = t.consensus.GetLastFinalizedBlock().Height
+	}
+	if fromHeight > toHeight {
+		return errors.New("from_height must not be greater than to_height")
+	}
+
+	queryBlockRange := toHeight - fromHeight
+	maxBlockRange := viper.GetUint64(common.CfgRPCGetLogsMaxBlockRange)
+	if queryBlockRange > maxBlockRange {
+		return fmt.Errorf("can't query logs for more than %v blocks at a time", maxBlockRange)
+	}
+
+	heavyQueryThreshold := viper.GetUint64(common.CfgRPCGetLogsHeavyQueryThreshold)
+	isHeavyQuery := queryBlockRange > heavyQueryThreshold
+	if isHeavyQuery {
+		err = t.startHeavyQuery("getLogs", &t.pendingHeavyGetLogsCounter, t.pendingHeavyGetLogsCounterLock,
+			viper.GetUint64(common.CfgRPCMaxHeavyGetLogsQueryCount), fromHeight, toHeight)
+		if err != nil {
+			return err
+		}
+		defer t.finishHeavyQuery(&t.pendingHeavyGetLogsCounter, t.pendingHeavyGetLogsCounterLock)
+	}
+
+	result.Logs = []*LogResult{}
+	for height := fromHeight; height <= toHeight; height++ {
+		var block *core.ExtendedBlock
+		for _, b := range t.chain.FindBlocksByHeight(height) {
+			if b.Status.IsFinalized() {
+				block = b
+				break
+			}
+		}
+		if block == nil {
+			continue
+		}
+		t.gatherLogs(block, args.Addresses, args.Topics, &result.Logs)
+	}
+
+	return nil
+}
+
+func (t *ThetaRPCService) gatherLogs(block *core.ExtendedBlock, addresses []common.Address, topics [][]common.Hash, logs *[]*LogResult) {
+	blockHash := block.Hash()
+	for txIndex, rawTx := range block.Txs {
+		tx, err := types.TxFromBytes(rawTx)
+		if err != nil {
+			continue
+		}
+		if _, ok := tx.(*types.SmartContractTx); !ok {
+			continue // only smart contract transactions emit logs
+		}
+
+		txHash := crypto.Keccak256Hash(rawTx)
+		receipt, found := t.chain.FindTxReceiptByHash(blockHash, txHash)
+		if !found {
+			continue
+		}
+
+		for logIndex, log := range receipt.Logs {
+			if !logMatches(log, addresses, topics) {
+				continue
+			}
+			*logs = append(*logs, &LogResult{
+				Address:     log.Address,
+				Topics:      log.Topics,
+				Data:        log.Data,
+				BlockHash:   blockHash,
+				BlockHeight: common.JSONUint64(block.Height),
+				TxHash:      txHash,
+				TxIndex:     common.JSONUint64(txIndex),
+				LogIndex:    common.JSONUint64(logIndex),
+			})
+		}
+	}
+}
+
+// logMatches checks whether the log is emitted by one of the addresses, and whether each of its
+// topics matches one of the alternatives at the same position
+func logMatches(log *types.Log, addresses []common.Address, topics [][]common.Hash) bool {
+	if len(addresses) > 0 {
+		addressMatched := false
+		for _, address := range addresses {
+			if log.Address == address {
+				addressMatched = true
+				break
+			}
+		}
+		if !addressMatched {
+			return false
+		}
+	}
+
+	if len(topics) > len(log.Topics) {
+		return false
+	}
+	for i, alternatives := range topics {
+		if len(alternatives) == 0 {
+			continue // wildcard
+		}
+		topicMatched := false
+		for _, topic := range alternatives {
+			if log.Topics[i] == topic {
+				topicMatched = true
+				break
+			}
+		}
+		if !topicMatched {
+			return false
+		}
+	}
+
+	return true
+}
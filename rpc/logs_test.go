@@ -0,0 +1,58 @@
+package rpc
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func TestLogMatches(t *testing.T) {
+	assert := assert.New(t)
+
+	contract := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	other := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	transfer := common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	approval := common.HexToHash("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925")
+	from := common.HexToHash("0x01")
+
+	log := &types.Log{
+		Address: contract,
+		Topics:  []common.Hash{transfer, from},
+	}
+
+	assert.True(logMatches(log, nil, nil))
+	assert.True(logMatches(log, []common.Address{other, contract}, nil))
+	assert.False(logMatches(log, []common.Address{other}, nil))
+
+	assert.True(logMatches(log, nil, [][]common.Hash{{transfer}}))
+	assert.True(logMatches(log, nil, [][]common.Hash{{approval, transfer}, {from}}))
+	assert.True(logMatches(log, nil, [][]common.Hash{{}, {from}}))
+	assert.False(logMatches(log, nil, [][]common.Hash{{approval}}))
+	assert.False(logMatches(log, nil, [][]common.Hash{{transfer}, {}, {}})) // more topic positions than the log has
+}
+
+func TestHeavyQueryLimits(t *testing.T) {
+	assert := assert.New(t)
+
+	service := &ThetaRPCService{
+		pendingHeavyGetBlocksCounterLock: &sync.Mutex{},
+		pendingHeavyGetLogsCounterLock:   &sync.Mutex{},
+	}
+	startGetLogs := func() error {
+		return service.startHeavyQuery("getLogs", &service.pendingHeavyGetLogsCounter, service.pendingHeavyGetLogsCounterLock, 1, 0, 1000)
+	}
+
+	assert.Nil(startGetLogs())
+	assert.Nil(startGetLogs())
+	assert.NotNil(startGetLogs())
+
+	// The pending GetLogs queries do not count against the getBlocksByRange limit
+	assert.Nil(service.startHeavyQuery("getBlocksByRange", &service.pendingHeavyGetBlocksCounter, service.pendingHeavyGetBlocksCounterLock, 1, 0, 1000))
+	assert.Equal(uint64(1), service.pendingHeavyGetBlocksCounter)
+
+	service.finishHeavyQuery(&service.pendingHeavyGetLogsCounter, service.pendingHeavyGetLogsCounterLock)
+	assert.Nil(startGetLogs())
+}
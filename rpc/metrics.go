@@ -0,0 +1,61 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/thetatoken/theta/common/metrics"
+)
+
+// rpcServiceMethods returns the names of the RPC methods exposed by the receiver under the
+// given service name, e.g. "theta.GetStatus"
+func rpcServiceMethods(serviceName string, rcvr interface{}) map[string]bool {
+	methods := make(map[string]bool)
+	typ := reflect.TypeOf(rcvr)
+	for i := 0; i < typ.NumMethod(); i++ {
+		methods[serviceName+"."+typ.Method(i).Name] = true
+	}
+	return methods
+}
+
+// rpcMetricsMiddleware records the latency of the JSON-RPC requests per method. Requests for
+// methods not in the given set are recorded under "unknown", so that arbitrary method names
+// sent by the clients can not inflate the number of metrics.
+func rpcMetricsMiddleware(handler http.Handler, methods map[string]bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !metrics.Enabled || r.Method != "POST" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		start := time.Now()
+		handler.ServeHTTP(w, r)
+		metrics.GetOrRegisterTimer("rpc/latency/"+rpcMethodName(body, methods), nil).UpdateSince(start)
+	})
+}
+
+func rpcMethodName(body []byte, methods map[string]bool) string {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		return "batch"
+	}
+
+	req := struct {
+		Method string `json:"method"`
+	}{}
+	if err := json.Unmarshal(body, &req); err != nil || !methods[req.Method] {
+		return "unknown"
+	}
+	return req.Method
+}
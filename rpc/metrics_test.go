@@ -0,0 +1,20 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRPCMethodName(t *testing.T) {
+	assert := assert.New(t)
+
+	methods := rpcServiceMethods("theta", &ThetaRPCService{})
+	assert.True(methods["theta.GetStatus"])
+	assert.True(methods["theta.GetLogs"])
+
+	assert.Equal("theta.GetStatus", rpcMethodName([]byte(`{"jsonrpc":"2.0","method":"theta.GetStatus","params":[{}],"id":1}`), methods))
+	assert.Equal("unknown", rpcMethodName([]byte(`{"jsonrpc":"2.0","method":"theta.NoSuchMethod","id":1}`), methods))
+	assert.Equal("unknown", rpcMethodName([]byte(`not json`), methods))
+	assert.Equal("batch", rpcMethodName([]byte(` [{"method":"theta.GetStatus"}]`), methods))
+}
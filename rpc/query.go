@@ -6,14 +6,13 @@ import (
 	"fmt"
 	"math/big"
 	"math/rand"
-	"strings"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 
 	"github.com/thetatoken/theta/blockchain"
-	"github.com/thetatoken/theta/crypto/bls"
 
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/core"
@@ -21,6 +20,7 @@ import (
 	"github.com/thetatoken/theta/ledger/state"
 	"github.com/thetatoken/theta/ledger/types"
 	"github.com/thetatoken/theta/mempool"
+	"github.com/thetatoken/theta/rlp"
 	"github.com/thetatoken/theta/version"
 )
 
@@ -121,6 +121,109 @@ func (t *ThetaRPCService) GetAccount(args *GetAccountArgs, result *GetAccountRes
 	return nil
 }
 
+// ------------------------------- GetProof -----------------------------------
+
+type GetProofArgs struct {
+	Address     string            `json:"address"`
+	StorageKeys []common.Hash     `json:"storage_keys"`
+	Height      common.JSONUint64 `json:"height"` // zero means the latest finalized block
+}
+
+type GetProofResult struct {
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	StateHash   common.Hash       `json:"state_hash"`
+	*state.AccountProof
+}
+
+// GetProof returns the merkle proof of the account against the StateHash of the finalized block
+// at the given height, and the merkle proofs of the storage slots against the storage root of
+// the account. The proofs can be verified with state.VerifyAccountProof using the block header.
+func (t *ThetaRPCService) GetProof(args *GetProofArgs, result *GetProofResult) (err error) {
+	if args.Address == "" {
+		return errors.New("Address must be specified")
+	}
+	address := common.HexToAddress(args.Address)
+	height := uint64(args.Height)
+
+	var block *core.ExtendedBlock
+	if height == 0 {
+		block = t.consensus.GetLastFinalizedBlock()
+	} else {
+		for _, b := range t.chain.FindBlocksByHeight(height) {
+			if b.Status.IsFinalized() {
+				block = b
+				break
+			}
+		}
+	}
+	if block == nil {
+		return fmt.Errorf("Finalized block at height %v is not available on current node", height)
+	}
+
+	deliveredView, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+	ledgerState := state.NewStoreView(block.Height, block.StateHash, deliveredView.GetDB())
+	if ledgerState == nil { // might have been pruned
+		return fmt.Errorf("the state for height %v is not available, it might have been pruned", block.Height)
+	}
+
+	accountProof, err := ledgerState.ProveAccount(address, args.StorageKeys)
+	if err != nil {
+		return err
+	}
+
+	result.BlockHash = block.Hash()
+	result.BlockHeight = common.JSONUint64(block.Height)
+	result.StateHash = block.StateHash
+	result.AccountProof = accountProof
+
+	return nil
+}
+
+// ------------------------------- GetFinalityCertificate -----------------------------------
+
+type GetFinalityCertificateArgs struct {
+	Height common.JSONUint64 `json:"height"` // zero means the latest finalized block
+}
+
+type GetFinalityCertificateResult struct {
+	BlockHash   common.Hash               `json:"block_hash"`
+	BlockHeight common.JSONUint64         `json:"block_height"`
+	Certificate *core.FinalityCertificate `json:"certificate"`
+	Encoded     string                    `json:"encoded"` // hex encoded RLP of the certificate
+}
+
+// GetFinalityCertificate returns the finality certificate of the finalized block at the given
+// height, which can be verified with lightclient.VerifyFinalityCertificate. The encoded field is
+// the canonical binary encoding of the certificate. An indirectly finalized block is covered by the
+// certificate of its closest directly finalized descendant, in which case the returned block height
+// is greater than the requested one.
+func (t *ThetaRPCService) GetFinalityCertificate(args *GetFinalityCertificateArgs, result *GetFinalityCertificateResult) (err error) {
+	height := uint64(args.Height)
+	if height == 0 {
+		height = t.consensus.GetLastFinalizedBlock().Height
+	}
+
+	cert, err := t.chain.FindFinalityCertificate(height)
+	if err != nil {
+		return fmt.Errorf("Finality certificate at height %v is not available on current node", height)
+	}
+	raw, err := rlp.EncodeToBytes(cert)
+	if err != nil {
+		return err
+	}
+
+	result.BlockHash = cert.Header.Hash()
+	result.BlockHeight = common.JSONUint64(cert.Header.Height)
+	result.Certificate = cert
+	result.Encoded = hex.EncodeToString(raw)
+
+	return nil
+}
+
 // ------------------------------- GetSplitRule -----------------------------------
 
 type GetSplitRuleArgs struct {
@@ -227,6 +330,75 @@ func (t *ThetaRPCService) GetTransaction(args *GetTransactionArgs, result *GetTr
 	return nil
 }
 
+// ------------------------------ GetTransactionsByAddress -----------------------------------
+
+const maxTransactionsByAddressLimit = 100
+
+type GetTransactionsByAddressArgs struct {
+	Address   string            `json:"address"`
+	Offset    common.JSONUint64 `json:"offset"`
+	Limit     common.JSONUint64 `json:"limit"`
+	Ascending bool              `json:"ascending"` // the newest transactions are returned first by default
+}
+
+type AddressTxResult struct {
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	TxHash      common.Hash       `json:"hash"`
+	Type        byte              `json:"type"`
+	Tx          types.Tx          `json:"transaction"`
+}
+
+type GetTransactionsByAddressResult struct {
+	Total common.JSONUint64  `json:"total"`
+	Txs   []*AddressTxResult `json:"transactions"`
+}
+
+// GetTransactionsByAddress returns the finalized transactions touching the given address. It
+// requires the address tx index to be enabled, and the index to be backfilled for the
+// transactions before the index was enabled.
+func (t *ThetaRPCService) GetTransactionsByAddress(args *GetTransactionsByAddressArgs, result *GetTransactionsByAddressResult) (err error) {
+	if !t.chain.TxAddressIndexEnabled() {
+		return errors.New("The address tx index is not enabled on current node")
+	}
+	if args.Address == "" {
+		return errors.New("Address must be specified")
+	}
+	address := common.HexToAddress(args.Address)
+
+	limit := uint64(args.Limit)
+	if limit == 0 || limit > maxTransactionsByAddressLimit {
+		limit = maxTransactionsByAddressLimit
+	}
+
+	entries, total := t.chain.FindTxsByAddress(address, uint64(args.Offset), limit, !args.Ascending)
+
+	result.Total = common.JSONUint64(total)
+	result.Txs = []*AddressTxResult{}
+	for _, entry := range entries {
+		block, err := t.chain.FindBlock(entry.BlockHash)
+		if err != nil {
+			return err
+		}
+		if entry.Index >= uint64(len(block.Txs)) {
+			return fmt.Errorf("Invalid tx index %v for block %v", entry.Index, entry.BlockHash.Hex())
+		}
+		tx, err := types.TxFromBytes(block.Txs[entry.Index])
+		if err != nil {
+			return err
+		}
+		result.Txs = append(result.Txs, &AddressTxResult{
+			BlockHash:   entry.BlockHash,
+			BlockHeight: common.JSONUint64(entry.BlockHeight),
+			TxHash:      entry.TxHash,
+			Type:        getTxType(tx),
+			Tx:          tx,
+		})
+	}
+
+	return nil
+}
+
 // ------------------------------ GetPendingTransactions -----------------------------------
 
 type GetPendingTransactionsArgs struct {
@@ -359,6 +531,8 @@ type GetBlockResultInner struct {
 	HCC                core.CommitCertificate   `json:"hcc"`
 	GuardianVotes      *core.AggregatedVotes    `json:"guardian_votes"`
 	EliteEdgeNodeVotes *core.AggregatedEENVotes `json:"elite_edge_node_votes"`
+	GasUsed            common.JSONUint64        `json:"gas_used"`
+	BaseFee            *common.JSONBig          `json:"base_fee,omitempty"`
 
 	Children []common.Hash    `json:"children"`
 	Status   core.BlockStatus `json:"status"`
@@ -382,6 +556,13 @@ const (
 	TxTypeWithdrawStake
 	TxTypeDepositStakeTxV2
 	TxTypeStakeRewardDistributionTx
+	_ // the versioned encoding, the txs using it are reported with the type of the tx they wrap
+	TxTypeRedelegateStakeTx
+	TxTypeWithdrawStakeTxV2
+	TxTypeGovernanceProposalTx
+	TxTypeGovernanceVoteTx
+	TxTypeGovernanceExecuteTx
+	TxTypeEvidenceTx
 )
 
 func (t *ThetaRPCService) GetBlock(args *GetBlockArgs, result *GetBlockResult) (err error) {
@@ -407,6 +588,8 @@ func (t *ThetaRPCService) GetBlock(args *GetBlockArgs, result *GetBlockResult) (
 	result.Status = block.Status
 	result.HCC = block.HCC
 	result.GuardianVotes = block.GuardianVotes
+	result.GasUsed = common.JSONUint64(block.GasUsed)
+	result.BaseFee = (*common.JSONBig)(block.BaseFee)
 
 	result.Hash = block.Hash()
 
@@ -478,6 +661,8 @@ func (t *ThetaRPCService) GetBlockByHeight(args *GetBlockByHeightArgs, result *G
 	result.HCC = block.HCC
 	result.GuardianVotes = block.GuardianVotes
 	result.EliteEdgeNodeVotes = block.EliteEdgeNodeVotes
+	result.GasUsed = common.JSONUint64(block.GasUsed)
+	result.BaseFee = (*common.JSONBig)(block.BaseFee)
 
 	result.Hash = block.Hash()
 
@@ -531,27 +716,12 @@ func (t *ThetaRPCService) GetBlocksByRange(args *GetBlocksByRangeArgs, result *G
 	heavyQueryThreshold := viper.GetUint64(common.CfgRPCGetBlocksHeavyQueryThreshold)
 	isHeavyQuery := uint64(queryBlockRange) > heavyQueryThreshold
 	if isHeavyQuery {
-		t.pendingHeavyGetBlocksCounterLock.Lock()
-		hasTooManyPendingHeavyQueries := t.pendingHeavyGetBlocksCounter > viper.GetUint64(common.CfgRPCMaxHeavyGetBlocksQueryCount)
-		t.pendingHeavyGetBlocksCounterLock.Unlock()
-
-		if hasTooManyPendingHeavyQueries {
-			warningMsg := fmt.Sprintf("too many pending heavy getBlocksByRange queries, rejecting getBlocksByRange query from block %v to %v", blockStart, blockEnd)
-			logger.Warnf(warningMsg)
-			return fmt.Errorf(warningMsg)
+		err = t.startHeavyQuery("getBlocksByRange", &t.pendingHeavyGetBlocksCounter, t.pendingHeavyGetBlocksCounterLock,
+			viper.GetUint64(common.CfgRPCMaxHeavyGetBlocksQueryCount), uint64(blockStart), uint64(blockEnd))
+		if err != nil {
+			return err
 		}
-
-		t.pendingHeavyGetBlocksCounterLock.Lock()
-		t.pendingHeavyGetBlocksCounter += 1
-		t.pendingHeavyGetBlocksCounterLock.Unlock()
-
-		defer func() { // the heavy query has now been processed
-			t.pendingHeavyGetBlocksCounterLock.Lock()
-			if t.pendingHeavyGetBlocksCounter > 0 {
-				t.pendingHeavyGetBlocksCounter -= 1
-			}
-			t.pendingHeavyGetBlocksCounterLock.Unlock()
-		}()
+		defer t.finishHeavyQuery(&t.pendingHeavyGetBlocksCounter, t.pendingHeavyGetBlocksCounterLock)
 	}
 
 	blocks := t.chain.FindBlocksByHeight(uint64(args.End))
@@ -587,6 +757,8 @@ func (t *ThetaRPCService) GetBlocksByRange(args *GetBlocksByRangeArgs, result *G
 		blkInner.HCC = block.HCC
 		blkInner.GuardianVotes = block.GuardianVotes
 		blkInner.EliteEdgeNodeVotes = block.EliteEdgeNodeVotes
+		blkInner.GasUsed = common.JSONUint64(block.GasUsed)
+		blkInner.BaseFee = (*common.JSONBig)(block.BaseFee)
 
 		blkInner.Hash = block.Hash()
 
@@ -606,6 +778,34 @@ func (t *ThetaRPCService) GetBlocksByRange(args *GetBlocksByRangeArgs, result *G
 	return
 }
 
+// startHeavyQuery registers a pending heavy range query on the given counter. It returns an error
+// if there are already more than maxCount pending heavy queries of the same kind, in which case the
+// query should be rejected.
+func (t *ThetaRPCService) startHeavyQuery(queryName string, counter *uint64, lock *sync.Mutex, maxCount uint64, start, end uint64) error {
+	lock.Lock()
+	defer lock.Unlock()
+
+	hasTooManyPendingHeavyQueries := *counter > maxCount
+	if hasTooManyPendingHeavyQueries {
+		warningMsg := fmt.Sprintf("too many pending heavy %v queries, rejecting %v query from block %v to %v", queryName, queryName, start, end)
+		logger.Warnf(warningMsg)
+		return fmt.Errorf(warningMsg)
+	}
+
+	*counter += 1
+	return nil
+}
+
+// finishHeavyQuery marks a pending heavy query registered on the given counter as processed
+func (t *ThetaRPCService) finishHeavyQuery(counter *uint64, lock *sync.Mutex) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if *counter > 0 {
+		*counter -= 1
+	}
+}
+
 // ------------------------------ GetStatus -----------------------------------
 
 type GetStatusArgs struct{}
@@ -832,21 +1032,15 @@ type GetGuardianInfoResult struct {
 }
 
 func (t *ThetaRPCService) GetGuardianInfo(args *GetGuardianInfoArgs, result *GetGuardianInfoResult) (err error) {
-	privKey := t.consensus.PrivateKey()
-	blsKey, err := bls.GenKey(strings.NewReader(common.Bytes2Hex(privKey.PublicKey().ToBytes())))
-	if err != nil {
-		return fmt.Errorf("Failed to get BLS key: %v", err.Error())
-	}
-
-	result.Address = privKey.PublicKey().Address().Hex()
-	result.BLSPubkey = hex.EncodeToString(blsKey.PublicKey().ToBytes())
-	popBytes := blsKey.PopProve().ToBytes()
-	result.BLSPop = hex.EncodeToString(popBytes)
-
-	sig, err := privKey.Sign(popBytes)
+	signer := t.consensus.Signer()
+	pop, sig, err := signer.SignBLSPop()
 	if err != nil {
 		return fmt.Errorf("Failed to generate signature: %v", err.Error())
 	}
+
+	result.Address = signer.Address().Hex()
+	result.BLSPubkey = hex.EncodeToString(signer.BLSPublicKey().ToBytes())
+	result.BLSPop = hex.EncodeToString(pop.ToBytes())
 	result.Signature = hex.EncodeToString(sig.ToBytes())
 
 	return nil
@@ -1259,10 +1453,22 @@ func getTxType(tx types.Tx) byte {
 		t = TxTypeDepositStake
 	case *types.WithdrawStakeTx:
 		t = TxTypeWithdrawStake
+	case *types.WithdrawStakeTxV2:
+		t = TxTypeWithdrawStakeTxV2
 	case *types.DepositStakeTxV2:
 		t = TxTypeDepositStakeTxV2
 	case *types.StakeRewardDistributionTx:
 		t = TxTypeStakeRewardDistributionTx
+	case *types.RedelegateStakeTx:
+		t = TxTypeRedelegateStakeTx
+	case *types.GovernanceProposalTx:
+		t = TxTypeGovernanceProposalTx
+	case *types.GovernanceVoteTx:
+		t = TxTypeGovernanceVoteTx
+	case *types.GovernanceExecuteTx:
+		t = TxTypeGovernanceExecuteTx
+	case *types.EvidenceTx:
+		t = TxTypeEvidenceTx
 	}
 
 	return t
@@ -40,9 +40,14 @@ type ThetaRPCService struct {
 	chain      *blockchain.Chain
 	consensus  *consensus.ConsensusEngine
 
+	subscriptions *SubscriptionManager
+	backupJobs    *BackupJobManager
+
 	pendingHeavyGetBlocksCounter           uint64
 	pendingHeavyGetBlocksCounterLock       *sync.Mutex
 	pendingHeavyGetBlocksCounterResetTimer *timer.RepeatTimer
+	pendingHeavyGetLogsCounter             uint64
+	pendingHeavyGetLogsCounterLock         *sync.Mutex
 
 	// Life cycle
 	wg      *sync.WaitGroup
@@ -59,6 +64,8 @@ type ThetaRPCServer struct {
 	handler  *rpc.Server
 	router   *mux.Router
 	listener net.Listener
+
+	dbWriteProbe *dbWriteProbe
 }
 
 // NewThetaRPCServer creates a new instance of ThetaRPCServer.
@@ -68,9 +75,14 @@ func NewThetaRPCServer(mempool *mempool.Mempool, ledger *ledger.Ledger, dispatch
 		ThetaRPCService: &ThetaRPCService{
 			wg: &sync.WaitGroup{},
 
+			subscriptions: NewSubscriptionManager(),
+			backupJobs:    NewBackupJobManager(),
+
 			pendingHeavyGetBlocksCounter:           0,
 			pendingHeavyGetBlocksCounterLock:       &sync.Mutex{},
 			pendingHeavyGetBlocksCounterResetTimer: timer.NewRepeatTimer("pendingHeavyGetBlocksCounterReset", 30*time.Minute),
+			pendingHeavyGetLogsCounter:             0,
+			pendingHeavyGetLogsCounterLock:         &sync.Mutex{},
 		},
 	}
 	t.pendingHeavyGetBlocksCounterResetTimer.Reset()
@@ -80,6 +92,7 @@ func NewThetaRPCServer(mempool *mempool.Mempool, ledger *ledger.Ledger, dispatch
 	t.dispatcher = dispatcher
 	t.chain = chain
 	t.consensus = consensus
+	t.dbWriteProbe = newDBWriteProbe(chain.CheckWritable)
 
 	s := rpc.NewServer()
 	s.RegisterName("theta", t.ThetaRPCService)
@@ -88,10 +101,10 @@ func NewThetaRPCServer(mempool *mempool.Mempool, ledger *ledger.Ledger, dispatch
 
 	t.router = mux.NewRouter()
 	t.router.Handle("/", &defaultHTTPHandler{})
-	t.router.Handle("/rpc", corsMiddleware(TimeoutHandler(jsonrpc2.HTTPHandler(s), viper.GetDuration(common.CfgRPCTimeoutSecs)*time.Second, "")))
-	t.router.Handle("/ws", websocket.Handler(func(ws *websocket.Conn) {
-		s.ServeCodec(jsonrpc2.NewServerCodec(ws, s))
-	}))
+	t.router.Handle("/rpc", corsMiddleware(rpcMetricsMiddleware(TimeoutHandler(jsonrpc2.HTTPHandler(s), viper.GetDuration(common.CfgRPCTimeoutSecs)*time.Second, ""), rpcServiceMethods("theta", t.ThetaRPCService))))
+	t.router.Handle("/ws", websocket.Handler(t.serveWebsocket))
+	t.router.HandleFunc("/healthz", t.serveHealthz)
+	t.router.HandleFunc("/readyz", t.serveReadyz)
 
 	t.server = &http.Server{
 		Handler: t.router,
@@ -117,6 +130,9 @@ func (t *ThetaRPCServer) Start(ctx context.Context) {
 
 	t.wg.Add(1)
 	go t.txCallback()
+
+	t.wg.Add(1)
+	go t.subscriptionLoop()
 }
 
 func (t *ThetaRPCServer) mainLoop() {
@@ -138,6 +154,9 @@ func (t *ThetaRPCServer) heavyQueryCounterLoop() {
 			t.pendingHeavyGetBlocksCounterLock.Lock()
 			t.pendingHeavyGetBlocksCounter = 0 // reset the counter to zero at a fixed time interval, otherwise the counter could get stuck if some pending queries never return
 			t.pendingHeavyGetBlocksCounterLock.Unlock()
+			t.pendingHeavyGetLogsCounterLock.Lock()
+			t.pendingHeavyGetLogsCounter = 0
+			t.pendingHeavyGetLogsCounterLock.Unlock()
 		case <-t.ctx.Done():
 			t.stopped = true
 			return
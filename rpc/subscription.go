@@ -0,0 +1,444 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	SubscriptionTopicNewBlock       = "newBlock"
+	SubscriptionTopicFinalizedBlock = "finalizedBlock"
+	SubscriptionTopicNewPendingTx   = "newPendingTx"
+	SubscriptionTopicTxStatusPrefix = "txStatus:"
+
+	subscriptionNotificationMethod = "theta.subscription"
+)
+
+type subscriberContextKey struct{}
+
+// SubscriptionManager keeps track of the websocket connections and the topics they subscribed to.
+// Notifications are queued per connection so that publishing never blocks the caller. A connection
+// whose queue is full is considered a slow consumer and gets disconnected.
+type SubscriptionManager struct {
+	mu          *sync.Mutex
+	subscribers map[*subscriber]bool
+	nextID      uint64
+
+	bufferSize       int
+	maxSubscriptions int
+	writeTimeout     time.Duration
+}
+
+// NewSubscriptionManager creates a new instance of SubscriptionManager
+func NewSubscriptionManager() *SubscriptionManager {
+	return &SubscriptionManager{
+		mu:               &sync.Mutex{},
+		subscribers:      make(map[*subscriber]bool),
+		bufferSize:       viper.GetInt(common.CfgRPCWSNotificationBufferSize),
+		maxSubscriptions: viper.GetInt(common.CfgRPCWSMaxSubscriptions),
+		writeTimeout:     viper.GetDuration(common.CfgRPCWSWriteTimeoutSecs) * time.Second,
+	}
+}
+
+// subscriber represents one websocket connection. All the writes to the
+// connection, including the JSON-RPC responses, go through subscriber.Write.
+type subscriber struct {
+	conn         io.ReadWriteCloser
+	writeMu      *sync.Mutex
+	writeTimeout time.Duration
+
+	topics map[string]string // subscription ID -> topic
+	queue  chan []byte
+	closed bool
+	done   chan struct{}
+}
+
+var _ io.ReadWriteCloser = (*subscriber)(nil)
+
+func (s *subscriber) Read(p []byte) (int, error) {
+	return s.conn.Read(p)
+}
+
+func (s *subscriber) Write(p []byte) (int, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if ws, ok := s.conn.(*websocket.Conn); ok && s.writeTimeout > 0 {
+		ws.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+	}
+	return s.conn.Write(p)
+}
+
+func (s *subscriber) Close() error {
+	return s.conn.Close()
+}
+
+// writeLoop pushes the queued notifications to the connection.
+func (s *subscriber) writeLoop() {
+	for {
+		select {
+		case msg := <-s.queue:
+			if _, err := s.Write(msg); err != nil {
+				logger.Infof("Failed to push notification, closing websocket connection: %v", err)
+				s.Close()
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+type subscriptionNotification struct {
+	Version string                         `json:"jsonrpc"`
+	Method  string                         `json:"method"`
+	Params  subscriptionNotificationParams `json:"params"`
+}
+
+type subscriptionNotificationParams struct {
+	Subscription string          `json:"subscription"`
+	Result       json.RawMessage `json:"result"`
+}
+
+// addSubscriber registers a new connection.
+func (sm *SubscriptionManager) addSubscriber(conn io.ReadWriteCloser) *subscriber {
+	sub := &subscriber{
+		conn:         conn,
+		writeMu:      &sync.Mutex{},
+		writeTimeout: sm.writeTimeout,
+		topics:       make(map[string]string),
+		queue:        make(chan []byte, sm.bufferSize),
+		done:         make(chan struct{}),
+	}
+
+	sm.mu.Lock()
+	sm.subscribers[sub] = true
+	sm.mu.Unlock()
+
+	go sub.writeLoop()
+
+	return sub
+}
+
+// removeSubscriber unregisters the connection and stops its write loop.
+func (sm *SubscriptionManager) removeSubscriber(sub *subscriber) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.removeSubscriberUnsafe(sub)
+}
+
+func (sm *SubscriptionManager) removeSubscriberUnsafe(sub *subscriber) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(sm.subscribers, sub)
+	close(sub.done)
+}
+
+// subscribe adds the topic to the subscriber, and returns the subscription ID.
+func (sm *SubscriptionManager) subscribe(sub *subscriber, topic string) (string, error) {
+	topic, err := normalizeSubscriptionTopic(topic)
+	if err != nil {
+		return "", err
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sub.closed {
+		return "", errors.New("connection is closed")
+	}
+	if len(sub.topics) >= sm.maxSubscriptions {
+		return "", fmt.Errorf("can't have more than %v subscriptions per connection", sm.maxSubscriptions)
+	}
+
+	sm.nextID++
+	subID := fmt.Sprintf("0x%x", sm.nextID)
+	sub.topics[subID] = topic
+
+	return subID, nil
+}
+
+// unsubscribe removes the subscription from the subscriber.
+func (sm *SubscriptionManager) unsubscribe(sub *subscriber, subID string) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if _, ok := sub.topics[subID]; !ok {
+		return false
+	}
+	delete(sub.topics, subID)
+	return true
+}
+
+// publish pushes the payload to all the subscribers of the given topic. It never blocks.
+func (sm *SubscriptionManager) publish(topic string, payload interface{}) {
+	// Closing a connection may block, so the slow consumers are disconnected after releasing
+	// the lock
+	slowSubs := []*subscriber{}
+	defer func() {
+		for _, sub := range slowSubs {
+			sub.Close()
+		}
+	}()
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	var result json.RawMessage
+	for sub := range sm.subscribers {
+		for subID, subTopic := range sub.topics {
+			if subTopic != topic {
+				continue
+			}
+
+			if result == nil {
+				raw, err := json.Marshal(payload)
+				if err != nil {
+					logger.Warnf("Failed to encode notification for topic %v: %v", topic, err)
+					return
+				}
+				result = raw
+			}
+
+			msg, err := json.Marshal(subscriptionNotification{
+				Version: "2.0",
+				Method:  subscriptionNotificationMethod,
+				Params: subscriptionNotificationParams{
+					Subscription: subID,
+					Result:       result,
+				},
+			})
+			if err != nil {
+				logger.Warnf("Failed to encode notification for topic %v: %v", topic, err)
+				continue
+			}
+
+			select {
+			case sub.queue <- msg:
+			default:
+				logger.Infof("Notification queue is full, disconnecting slow websocket consumer")
+				sm.removeSubscriberUnsafe(sub)
+				slowSubs = append(slowSubs, sub)
+			}
+
+			if sub.closed {
+				break
+			}
+		}
+	}
+}
+
+// hasSubscribers returns whether any connection subscribed to the given topic.
+func (sm *SubscriptionManager) hasSubscribers(topic string) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for sub := range sm.subscribers {
+		for _, subTopic := range sub.topics {
+			if subTopic == topic {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func normalizeSubscriptionTopic(topic string) (string, error) {
+	switch topic {
+	case SubscriptionTopicNewBlock, SubscriptionTopicFinalizedBlock, SubscriptionTopicNewPendingTx:
+		return topic, nil
+	}
+	if strings.HasPrefix(topic, SubscriptionTopicTxStatusPrefix) {
+		hashStr := strings.TrimPrefix(topic, SubscriptionTopicTxStatusPrefix)
+		if len(strings.TrimPrefix(hashStr, "0x")) != 2*common.HashLength {
+			return "", fmt.Errorf("invalid transaction hash: %v", hashStr)
+		}
+		return txStatusTopic(common.HexToHash(hashStr)), nil
+	}
+	return "", fmt.Errorf("unknown topic: %v", topic)
+}
+
+func txStatusTopic(txHash common.Hash) string {
+	return SubscriptionTopicTxStatusPrefix + txHash.Hex()
+}
+
+// ------------------------------- Notifications -----------------------------------
+
+type BlockNotification struct {
+	Hash     common.Hash       `json:"hash"`
+	Height   common.JSONUint64 `json:"height"`
+	Header   *core.BlockHeader `json:"header"`
+	TxHashes []common.Hash     `json:"transaction_hashes"`
+}
+
+type TxStatusNotification struct {
+	TxHash      common.Hash       `json:"hash"`
+	Status      TxStatus          `json:"status"`
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+}
+
+type PendingTxNotification struct {
+	TxHash common.Hash `json:"hash"`
+}
+
+func newBlockNotification(block *core.Block) *BlockNotification {
+	txHashes := []common.Hash{}
+	for _, rawTx := range block.Txs {
+		txHashes = append(txHashes, crypto.Keccak256Hash(rawTx))
+	}
+	return &BlockNotification{
+		Hash:     block.Hash(),
+		Height:   common.JSONUint64(block.Height),
+		Header:   block.BlockHeader,
+		TxHashes: txHashes,
+	}
+}
+
+func (sm *SubscriptionManager) publishNewBlock(block *core.Block) {
+	if !sm.hasSubscribers(SubscriptionTopicNewBlock) {
+		return
+	}
+	sm.publish(SubscriptionTopicNewBlock, newBlockNotification(block))
+}
+
+func (sm *SubscriptionManager) publishFinalizedBlock(block *core.Block) {
+	if sm.hasSubscribers(SubscriptionTopicFinalizedBlock) {
+		sm.publish(SubscriptionTopicFinalizedBlock, newBlockNotification(block))
+	}
+
+	blockHash := block.Hash()
+	for _, rawTx := range block.Txs {
+		txHash := crypto.Keccak256Hash(rawTx)
+		topic := txStatusTopic(txHash)
+		if !sm.hasSubscribers(topic) {
+			continue
+		}
+		sm.publish(topic, &TxStatusNotification{
+			TxHash:      txHash,
+			Status:      TxStatusFinalized,
+			BlockHash:   blockHash,
+			BlockHeight: common.JSONUint64(block.Height),
+		})
+	}
+}
+
+func (sm *SubscriptionManager) publishNewPendingTx(rawTx common.Bytes) {
+	txHash := crypto.Keccak256Hash(rawTx)
+	if sm.hasSubscribers(SubscriptionTopicNewPendingTx) {
+		sm.publish(SubscriptionTopicNewPendingTx, &PendingTxNotification{
+			TxHash: txHash,
+		})
+	}
+
+	topic := txStatusTopic(txHash)
+	if sm.hasSubscribers(topic) {
+		sm.publish(topic, &TxStatusNotification{
+			TxHash: txHash,
+			Status: TxStatusPending,
+		})
+	}
+}
+
+// subscriptionLoop forwards the new blocks and pending transactions to the subscribers.
+// Finalized blocks are forwarded by txCallback() since it is the consumer of the
+// ConsensusEngine.FinalizedBlocks() channel.
+func (t *ThetaRPCService) subscriptionLoop() {
+	defer t.wg.Done()
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case block := <-t.consensus.ValidatedBlocks():
+			t.subscriptions.publishNewBlock(block)
+		case rawTx := <-t.mempool.NewPendingTxs():
+			t.subscriptions.publishNewPendingTx(rawTx)
+		}
+	}
+}
+
+// serveWebsocket serves JSON-RPC requests over the websocket connection, with support of
+// subscriptions. It blocks until the client hangs up.
+func (t *ThetaRPCServer) serveWebsocket(ws *websocket.Conn) {
+	sub := t.subscriptions.addSubscriber(ws)
+	defer t.subscriptions.removeSubscriber(sub)
+
+	ctx := context.WithValue(context.Background(), subscriberContextKey{}, sub)
+	t.handler.ServeCodec(jsonrpc2.NewServerCodecContext(ctx, sub, t.handler))
+}
+
+func subscriberFromContext(ctx context.Context) (*subscriber, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	sub, ok := ctx.Value(subscriberContextKey{}).(*subscriber)
+	return sub, ok
+}
+
+// ------------------------------- Subscribe -----------------------------------
+
+type SubscribeArgs struct {
+	jsonrpc2.Ctx
+	Topic string `json:"topic"`
+}
+
+type SubscribeResult struct {
+	SubscriptionID string `json:"subscription"`
+}
+
+// Subscribe subscribes to the given topic. Only available over the websocket endpoint. Supported topics
+// are "newBlock", "finalizedBlock", "newPendingTx" and "txStatus:<tx_hash>". Notifications are pushed
+// as JSON-RPC notifications with method "theta.subscription".
+func (t *ThetaRPCService) Subscribe(args *SubscribeArgs, result *SubscribeResult) (err error) {
+	sub, ok := subscriberFromContext(args.Context())
+	if !ok {
+		return errors.New("subscriptions are only supported over websocket")
+	}
+
+	subID, err := t.subscriptions.subscribe(sub, args.Topic)
+	if err != nil {
+		return err
+	}
+	result.SubscriptionID = subID
+
+	return nil
+}
+
+// ------------------------------- Unsubscribe -----------------------------------
+
+type UnsubscribeArgs struct {
+	jsonrpc2.Ctx
+	SubscriptionID string `json:"subscription"`
+}
+
+type UnsubscribeResult struct {
+	Success bool `json:"success"`
+}
+
+func (t *ThetaRPCService) Unsubscribe(args *UnsubscribeArgs, result *UnsubscribeResult) (err error) {
+	sub, ok := subscriberFromContext(args.Context())
+	if !ok {
+		return errors.New("subscriptions are only supported over websocket")
+	}
+
+	result.Success = t.subscriptions.unsubscribe(sub, args.SubscriptionID)
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/util"
+	"github.com/thetatoken/theta/core"
+)
+
+type mockConn struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	blocked chan struct{}
+	closed  bool
+}
+
+func (c *mockConn) Read(p []byte) (int, error) { return 0, nil }
+
+func (c *mockConn) Write(p []byte) (int, error) {
+	if c.blocked != nil {
+		<-c.blocked
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.Write(p)
+}
+
+func (c *mockConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *mockConn) messages() []subscriptionNotification {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	msgs := []subscriptionNotification{}
+	dec := json.NewDecoder(bytes.NewReader(c.buf.Bytes()))
+	for dec.More() {
+		var msg subscriptionNotification
+		if err := dec.Decode(&msg); err != nil {
+			break
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+func (c *mockConn) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+func newTestSubscriptionManager(bufferSize int) *SubscriptionManager {
+	logger = util.GetLoggerForModule("rpc")
+	sm := NewSubscriptionManager()
+	sm.bufferSize = bufferSize
+	sm.maxSubscriptions = 2
+	return sm
+}
+
+func TestSubscriptionTopics(t *testing.T) {
+	assert := assert.New(t)
+
+	sm := newTestSubscriptionManager(16)
+	conn := &mockConn{}
+	sub := sm.addSubscriber(conn)
+	defer sm.removeSubscriber(sub)
+
+	_, err := sm.subscribe(sub, "unknownTopic")
+	assert.NotNil(err)
+	_, err = sm.subscribe(sub, SubscriptionTopicTxStatusPrefix+"0x1234")
+	assert.NotNil(err)
+
+	txHash := common.HexToHash("0xABCD000000000000000000000000000000000000000000000000000000001234")
+	subID1, err := sm.subscribe(sub, SubscriptionTopicTxStatusPrefix+"ABCD000000000000000000000000000000000000000000000000000000001234")
+	assert.Nil(err)
+	assert.True(sm.hasSubscribers(txStatusTopic(txHash)))
+
+	subID2, err := sm.subscribe(sub, SubscriptionTopicFinalizedBlock)
+	assert.Nil(err)
+	assert.NotEqual(subID1, subID2)
+
+	_, err = sm.subscribe(sub, SubscriptionTopicNewBlock)
+	assert.NotNil(err) // exceeds the max number of subscriptions
+
+	assert.True(sm.unsubscribe(sub, subID1))
+	assert.False(sm.unsubscribe(sub, subID1))
+	assert.False(sm.hasSubscribers(txStatusTopic(txHash)))
+}
+
+func TestSubscriptionPublish(t *testing.T) {
+	assert := assert.New(t)
+
+	sm := newTestSubscriptionManager(16)
+	conn := &mockConn{}
+	sub := sm.addSubscriber(conn)
+	defer sm.removeSubscriber(sub)
+
+	subID, err := sm.subscribe(sub, SubscriptionTopicFinalizedBlock)
+	assert.Nil(err)
+
+	block := core.NewBlock()
+	block.Height = 100
+	block.Timestamp = common.Big0
+	block.AddTxs([]common.Bytes{common.Bytes("tx1")})
+	sm.publishFinalizedBlock(block)
+	sm.publishNewBlock(block) // no subscriber for the newBlock topic
+
+	time.Sleep(100 * time.Millisecond)
+	msgs := conn.messages()
+	assert.Equal(1, len(msgs))
+	assert.Equal(subscriptionNotificationMethod, msgs[0].Method)
+	assert.Equal(subID, msgs[0].Params.Subscription)
+
+	var notification BlockNotification
+	assert.Nil(json.Unmarshal(msgs[0].Params.Result, &notification))
+	assert.Equal(common.JSONUint64(100), notification.Height)
+	assert.Equal(block.Hash(), notification.Hash)
+	assert.Equal(1, len(notification.TxHashes))
+}
+
+func TestSubscriptionSlowConsumer(t *testing.T) {
+	assert := assert.New(t)
+
+	sm := newTestSubscriptionManager(1)
+	conn := &mockConn{blocked: make(chan struct{})}
+	defer close(conn.blocked)
+	sub := sm.addSubscriber(conn)
+
+	_, err := sm.subscribe(sub, SubscriptionTopicNewPendingTx)
+	assert.Nil(err)
+
+	// The first notification is picked up by the write loop, which then blocks on the
+	// connection. The second one fills the queue, and the third one overflows it.
+	for i := 0; i < 3; i++ {
+		sm.publishNewPendingTx(common.Bytes{byte(i)})
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.True(conn.isClosed())
+	assert.False(sm.hasSubscribers(SubscriptionTopicNewPendingTx))
+
+	_, err = sm.subscribe(sub, SubscriptionTopicNewPendingTx)
+	assert.NotNil(err)
+}
@@ -0,0 +1,215 @@
+package rpc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/vm"
+)
+
+// ------------------------------ Tracing Utilities -----------------------------------
+
+type TraceConfig struct {
+	DisableMemory  bool `json:"disable_memory"`
+	DisableStack   bool `json:"disable_stack"`
+	DisableStorage bool `json:"disable_storage"`
+	Limit          int  `json:"limit"` // maximum number of struct logs per tx, zero means unlimited
+}
+
+func (tc TraceConfig) logConfig() *vm.LogConfig {
+	return &vm.LogConfig{
+		DisableMemory:  tc.DisableMemory,
+		DisableStack:   tc.DisableStack,
+		DisableStorage: tc.DisableStorage,
+		Limit:          tc.Limit,
+	}
+}
+
+// TraceStructLog is the JSON representation of a vm.StructLog. Memory is split into
+// 32-byte words, and Storage contains the storage slots modified so far by the contract.
+type TraceStructLog struct {
+	Pc         common.JSONUint64 `json:"pc"`
+	Op         string            `json:"op"`
+	Gas        common.JSONUint64 `json:"gas"`
+	GasCost    common.JSONUint64 `json:"gas_cost"`
+	Depth      int               `json:"depth"`
+	Error      string            `json:"error,omitempty"`
+	Stack      []string          `json:"stack,omitempty"`
+	Memory     []string          `json:"memory,omitempty"`
+	MemorySize int               `json:"memory_size"`
+	Storage    map[string]string `json:"storage,omitempty"`
+}
+
+type TxTraceResult struct {
+	TxHash      common.Hash       `json:"hash"`
+	Type        byte              `json:"type"`
+	GasUsed     common.JSONUint64 `json:"gas_used"`
+	Failed      bool              `json:"failed"`
+	ReturnValue common.Bytes      `json:"return_value"`
+	EvmErr      string            `json:"evm_error"`
+	StructLogs  []TraceStructLog  `json:"struct_logs"`
+}
+
+func formatStructLogs(logs []vm.StructLog) []TraceStructLog {
+	formatted := make([]TraceStructLog, len(logs))
+	for i, log := range logs {
+		formatted[i] = TraceStructLog{
+			Pc:         common.JSONUint64(log.Pc),
+			Op:         log.Op.String(),
+			Gas:        common.JSONUint64(log.Gas),
+			GasCost:    common.JSONUint64(log.GasCost),
+			Depth:      log.Depth,
+			Error:      log.ErrorString(),
+			MemorySize: log.MemorySize,
+		}
+		if log.Stack != nil {
+			formatted[i].Stack = make([]string, len(log.Stack))
+			for j, item := range log.Stack {
+				formatted[i].Stack[j] = fmt.Sprintf("0x%x", item)
+			}
+		}
+		if log.Memory != nil {
+			formatted[i].Memory = make([]string, 0, (len(log.Memory)+31)/32)
+			for j := 0; j < len(log.Memory); j += 32 {
+				end := j + 32
+				if end > len(log.Memory) {
+					end = len(log.Memory)
+				}
+				formatted[i].Memory = append(formatted[i].Memory, fmt.Sprintf("%x", log.Memory[j:end]))
+			}
+		}
+		if log.Storage != nil {
+			formatted[i].Storage = make(map[string]string, len(log.Storage))
+			for key, value := range log.Storage {
+				formatted[i].Storage[key.Hex()] = value.Hex()
+			}
+		}
+	}
+	return formatted
+}
+
+// traceBlockTxs replays the first numTxs transactions of the given block, and traces
+// the transactions for which shouldTrace returns true
+func (t *ThetaRPCService) traceBlockTxs(block *core.ExtendedBlock, numTxs int, config TraceConfig,
+	shouldTrace func(txIndex int) bool) ([]*TxTraceResult, error) {
+	tracers := []*vm.StructLogger{}
+	tracedIndices := []int{}
+	results := []*TxTraceResult{}
+	replays, err := t.ledger.TraceBlockTxs(block.Block, numTxs, func(txIndex int, tx types.Tx) vm.Tracer {
+		if !shouldTrace(txIndex) {
+			return nil
+		}
+		tracer := vm.NewStructLogger(config.logConfig())
+		tracers = append(tracers, tracer)
+		tracedIndices = append(tracedIndices, txIndex)
+		results = append(results, &TxTraceResult{
+			TxHash: crypto.Keccak256Hash(block.Txs[txIndex]),
+			Type:   getTxType(tx),
+		})
+		return tracer
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, result := range results {
+		replay := replays[tracedIndices[i]]
+		result.GasUsed = common.JSONUint64(replay.GasUsed)
+		result.ReturnValue = replay.EvmRet
+		result.EvmErr = replay.EvmErr
+		result.Failed = replay.EvmErr != ""
+		result.StructLogs = formatStructLogs(tracers[i].StructLogs())
+	}
+	return results, nil
+}
+
+// ------------------------------ TraceTransaction -----------------------------------
+
+type TraceTransactionArgs struct {
+	Hash string `json:"hash"`
+	TraceConfig
+}
+
+type TraceTransactionResult struct {
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	*TxTraceResult
+}
+
+func (t *ThetaRPCService) TraceTransaction(args *TraceTransactionArgs, result *TraceTransactionResult) (err error) {
+	if args.Hash == "" {
+		return errors.New("Transanction hash must be specified")
+	}
+	hash := common.HexToHash(args.Hash)
+
+	raw, block, found := t.chain.FindTxByHash(hash)
+	if !found {
+		return fmt.Errorf("Transaction %v not found in any block", args.Hash)
+	}
+
+	txIndex := -1
+	for idx, rawTx := range block.Txs {
+		if bytes.Equal(rawTx, raw) {
+			txIndex = idx
+			break
+		}
+	}
+	if txIndex < 0 {
+		return fmt.Errorf("Transaction %v not found in block %v", args.Hash, block.Hash().Hex())
+	}
+
+	traces, err := t.traceBlockTxs(block, txIndex+1, args.TraceConfig, func(idx int) bool {
+		return idx == txIndex
+	})
+	if err != nil {
+		return err
+	}
+
+	result.BlockHash = block.Hash()
+	result.BlockHeight = common.JSONUint64(block.Height)
+	result.TxTraceResult = traces[0]
+
+	return nil
+}
+
+// ------------------------------ TraceBlock -----------------------------------
+
+type TraceBlockArgs struct {
+	Hash common.Hash `json:"hash"`
+	TraceConfig
+}
+
+type TraceBlockResult struct {
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	Txs         []*TxTraceResult  `json:"transactions"`
+}
+
+func (t *ThetaRPCService) TraceBlock(args *TraceBlockArgs, result *TraceBlockResult) (err error) {
+	if args.Hash.IsEmpty() {
+		return errors.New("Block hash must be specified")
+	}
+
+	block, err := t.chain.FindBlock(args.Hash)
+	if err != nil {
+		return err
+	}
+
+	traces, err := t.traceBlockTxs(block, len(block.Txs), args.TraceConfig, func(idx int) bool {
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	result.BlockHash = block.Hash()
+	result.BlockHeight = common.JSONUint64(block.Height)
+	result.Txs = traces
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package rpc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/vm"
+)
+
+func TestFormatStructLogs(t *testing.T) {
+	assert := assert.New(t)
+
+	memory := make([]byte, 40)
+	memory[31] = 0x80
+	logs := []vm.StructLog{
+		{
+			Pc:         2,
+			Op:         vm.SSTORE,
+			Gas:        1000,
+			GasCost:    20000,
+			Memory:     memory,
+			MemorySize: 40,
+			Stack:      []*big.Int{big.NewInt(1), big.NewInt(255)},
+			Storage:    map[common.Hash]common.Hash{common.BigToHash(big.NewInt(255)): common.BigToHash(big.NewInt(1))},
+			Depth:      1,
+			Err:        vm.ErrOutOfGas,
+		},
+		{
+			Pc:    3,
+			Op:    vm.STOP,
+			Depth: 1,
+		},
+	}
+
+	formatted := formatStructLogs(logs)
+	assert.Equal(2, len(formatted))
+
+	assert.Equal("SSTORE", formatted[0].Op)
+	assert.Equal(common.JSONUint64(20000), formatted[0].GasCost)
+	assert.Equal(vm.ErrOutOfGas.Error(), formatted[0].Error)
+	assert.Equal([]string{"0x1", "0xff"}, formatted[0].Stack)
+	assert.Equal(2, len(formatted[0].Memory))
+	assert.Equal("0000000000000000000000000000000000000000000000000000000000000080", formatted[0].Memory[0])
+	assert.Equal("0000000000000000", formatted[0].Memory[1])
+	assert.Equal(common.BigToHash(big.NewInt(1)).Hex(), formatted[0].Storage[common.BigToHash(big.NewInt(255)).Hex()])
+
+	// memory, stack and storage capture disabled
+	assert.Equal("STOP", formatted[1].Op)
+	assert.Nil(formatted[1].Stack)
+	assert.Nil(formatted[1].Memory)
+	assert.Nil(formatted[1].Storage)
+}
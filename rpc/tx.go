@@ -112,6 +112,8 @@ func (t *ThetaRPCService) txCallback() {
 				}
 			}
 
+			t.subscriptions.publishFinalizedBlock(block)
+
 			logger.Infof("Done processing finalized block, height=%v", block.Height)
 		case <-timer.C:
 			logger.Debugf("txCallbackManager.Trim()")
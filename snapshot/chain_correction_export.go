@@ -33,6 +33,9 @@ func ExcludeTxs(txs []common.Bytes, exclusionTxMap map[string]bool, chain *block
 		if _, ok := t.(*types.WithdrawStakeTx); ok {
 			continue
 		}
+		if _, ok := t.(*types.WithdrawStakeTxV2); ok {
+			continue
+		}
 
 		hash := crypto.Keccak256Hash(tx).Hex()
 		if _, ok := exclusionTxMap[hash]; !ok {
@@ -43,6 +46,13 @@ func ExcludeTxs(txs []common.Bytes, exclusionTxMap map[string]bool, chain *block
 }
 
 func ExportChainCorrection(chain *blockchain.Chain, ledger core.Ledger, snapshotHeight uint64, endBlockHash common.Hash, backupDir string, exclusionTxs []string) (backupFile string, blockHashMap map[uint64]string, err error) {
+	return ExportChainCorrectionWithProgress(chain, ledger, snapshotHeight, endBlockHash, backupDir, exclusionTxs, nil)
+}
+
+// ExportChainCorrectionWithProgress is the same as ExportChainCorrection, except that it
+// reports its progress to, and can be cancelled through, the given ExportProgress. The
+// partially written correction file is removed if the export is cancelled.
+func ExportChainCorrectionWithProgress(chain *blockchain.Chain, ledger core.Ledger, snapshotHeight uint64, endBlockHash common.Hash, backupDir string, exclusionTxs []string, progress *ExportProgress) (backupFile string, blockHashMap map[uint64]string, err error) {
 	block, err := chain.FindBlock(endBlockHash)
 	if err != nil {
 		return "", nil, fmt.Errorf("Can't find block for hash %v", endBlockHash)
@@ -62,7 +72,7 @@ func ExportChainCorrection(chain *blockchain.Chain, ledger core.Ledger, snapshot
 		return "", nil, err
 	}
 	defer file.Close()
-	writer := bufio.NewWriter(file)
+	writer := bufio.NewWriter(progress.writer(file))
 
 	var stack []*core.ExtendedBlock
 
@@ -71,7 +81,17 @@ func ExportChainCorrection(chain *blockchain.Chain, ledger core.Ledger, snapshot
 		exclusionTxMap[exclusion] = true
 	}
 
+	// Replaying the blocks dominates the export time
+	endHeight := block.Height
+	numBlocks := float64(endHeight - snapshotHeight)
+	progress.setStage("collecting blocks", 0, 0.1)
 	for {
+		progress.setHeight(block.Height)
+		if progress.Cancelled() {
+			return "", nil, removeCancelledExport(file, backupPath, ErrExportCancelled)
+		}
+		progress.setStageFraction(float64(endHeight-block.Height) / numBlocks)
+
 		block.Txs = ExcludeTxs(block.Txs, exclusionTxMap, chain)
 		block.TxHash = core.CalculateRootHash(block.Txs)
 		block.UpdateHash()
@@ -97,8 +117,15 @@ func ExportChainCorrection(chain *blockchain.Chain, ledger core.Ledger, snapshot
 			break
 		}
 	}
+	progress.setStage("replaying blocks", 0.1, 0.9)
 	for i := len(stack) - 1; i >= 0; i-- {
 		block = stack[i]
+		progress.setHeight(block.Height)
+		if progress.Cancelled() {
+			return "", nil, removeCancelledExport(file, backupPath, ErrExportCancelled)
+		}
+		progress.setStageFraction(float64(len(stack)-1-i) / float64(len(stack)))
+
 		block.Parent = parent.Hash()
 		block.HCC.BlockHash = snapshot.Hash()
 		block.Children = []common.Hash{}
@@ -121,15 +148,19 @@ func ExportChainCorrection(chain *blockchain.Chain, ledger core.Ledger, snapshot
 		parent = block
 	}
 
+	progress.setStage("writing blocks", 0.9, 1)
 	blockHashMap = make(map[uint64]string)
 	for i := 0; i < len(stack); i++ {
 		block = stack[i]
+		progress.setHeight(block.Height)
+		progress.setStageFraction(float64(i) / float64(len(stack)))
 
 		backupBlock := &core.BackupBlock{Block: block}
 		writeBlock(writer, backupBlock)
 
 		blockHashMap[block.Height] = block.Hash().Hex()
 	}
+	progress.setStageFraction(1)
 
 	return
 }
@@ -15,6 +15,13 @@ import (
 )
 
 func ExportChainBackup(chain *blockchain.Chain, startHeight, endHeight uint64, backupDir string) (actualStartHeight, actualEndHeight uint64, backupFile string, err error) {
+	return ExportChainBackupWithProgress(chain, startHeight, endHeight, backupDir, nil)
+}
+
+// ExportChainBackupWithProgress is the same as ExportChainBackup, except that it reports its
+// progress to, and can be cancelled through, the given ExportProgress. The partially written
+// backup file is removed if the export is cancelled.
+func ExportChainBackupWithProgress(chain *blockchain.Chain, startHeight, endHeight uint64, backupDir string, progress *ExportProgress) (actualStartHeight, actualEndHeight uint64, backupFile string, err error) {
 	if startHeight > endHeight {
 		return 0, 0, "", errors.New("start height must be <= end height")
 	}
@@ -45,11 +52,20 @@ func ExportChainBackup(chain *blockchain.Chain, startHeight, endHeight uint64, b
 		return 0, 0, "", err
 	}
 	defer file.Close()
-	writer := bufio.NewWriter(file)
+	writer := bufio.NewWriter(progress.writer(file))
 
 	actualEndHeight = finalizedBlock.Height
+	progress.setStage("blocks", 0, 1)
 
 	for {
+		progress.setHeight(finalizedBlock.Height)
+		if (actualEndHeight-finalizedBlock.Height)%progressCheckInterval == 0 {
+			if progress.Cancelled() {
+				return 0, 0, "", removeCancelledExport(file, backupPath, ErrExportCancelled)
+			}
+			progress.setStageFraction(float64(actualEndHeight-finalizedBlock.Height) / float64(actualEndHeight-startHeight+1))
+		}
+
 		voteSet := chain.FindVotesByHash(finalizedBlock.Hash())
 		backupBlock := &core.BackupBlock{Block: finalizedBlock, Votes: voteSet}
 		writeBlock(writer, backupBlock)
@@ -66,6 +82,7 @@ func ExportChainBackup(chain *blockchain.Chain, startHeight, endHeight uint64, b
 		}
 		finalizedBlock = parentBlock
 	}
+	progress.setStageFraction(1)
 
 	return startHeight, actualEndHeight, filename, nil
 }
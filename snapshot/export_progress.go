@@ -0,0 +1,151 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/thetatoken/theta/common"
+)
+
+// ErrExportCancelled is returned by an export which was cancelled through its ExportProgress
+var ErrExportCancelled = errors.New("Export cancelled")
+
+// progressCheckInterval is the number of trie nodes or blocks processed between two
+// progress updates/cancellation checks
+const progressCheckInterval = 1024
+
+// ExportProgress tracks the progress of a snapshot or chain export, and allows the export
+// to be cancelled through its context. It is safe for concurrent use, and a nil
+// *ExportProgress is valid and tracks nothing.
+type ExportProgress struct {
+	bytesWritten uint64 // accessed atomically, kept first for 64-bit alignment
+
+	ctx context.Context
+
+	mu            sync.Mutex
+	stage         string
+	stageStart    float64
+	stageEnd      float64
+	currentHeight uint64
+	currentTrie   common.Hash
+	fraction      float64
+}
+
+// ExportProgressStatus is a point-in-time view of an ExportProgress
+type ExportProgressStatus struct {
+	BytesWritten  uint64
+	Stage         string
+	CurrentHeight uint64
+	CurrentTrie   common.Hash
+	Fraction      float64 // estimated fraction of the export completed, in [0, 1]
+}
+
+// NewExportProgress creates an ExportProgress. The export is cancelled once ctx is done.
+func NewExportProgress(ctx context.Context) *ExportProgress {
+	return &ExportProgress{ctx: ctx}
+}
+
+// Status returns the current progress of the export
+func (p *ExportProgress) Status() ExportProgressStatus {
+	if p == nil {
+		return ExportProgressStatus{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return ExportProgressStatus{
+		BytesWritten:  atomic.LoadUint64(&p.bytesWritten),
+		Stage:         p.stage,
+		CurrentHeight: p.currentHeight,
+		CurrentTrie:   p.currentTrie,
+		Fraction:      p.fraction,
+	}
+}
+
+// Cancelled returns true if the export has been cancelled
+func (p *ExportProgress) Cancelled() bool {
+	if p == nil || p.ctx == nil {
+		return false
+	}
+	return p.ctx.Err() != nil
+}
+
+// setStage starts a new stage of the export, which covers the [start, end] fraction of
+// the whole export
+func (p *ExportProgress) setStage(stage string, start, end float64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stage = stage
+	p.stageStart = start
+	p.stageEnd = end
+	p.currentTrie = common.Hash{}
+	p.fraction = start
+}
+
+// setStageFraction updates the fraction of the current stage completed
+func (p *ExportProgress) setStageFraction(stageFraction float64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fraction = p.stageStart + stageFraction*(p.stageEnd-p.stageStart)
+}
+
+func (p *ExportProgress) setHeight(height uint64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.currentHeight = height
+}
+
+func (p *ExportProgress) setTrie(root common.Hash) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.currentTrie = root
+}
+
+// writer wraps w so that the bytes written through it are counted
+func (p *ExportProgress) writer(w io.Writer) io.Writer {
+	if p == nil {
+		return w
+	}
+	return &countingWriter{w: w, count: &p.bytesWritten}
+}
+
+type countingWriter struct {
+	w     io.Writer
+	count *uint64
+}
+
+func (cw *countingWriter) Write(b []byte) (int, error) {
+	n, err := cw.w.Write(b)
+	atomic.AddUint64(cw.count, uint64(n))
+	return n, err
+}
+
+// trieIteratorFraction estimates the fraction of a trie already traversed from the
+// hex-encoded path of the current node. Keys are hashed, so they are evenly distributed
+// over the key space.
+func trieIteratorFraction(path []byte) float64 {
+	fraction := 0.0
+	scale := 1.0
+	for i := 0; i < len(path) && i < 6; i++ {
+		if path[i] >= 16 { // terminator
+			break
+		}
+		scale /= 16
+		fraction += float64(path[i]) * scale
+	}
+	return fraction
+}
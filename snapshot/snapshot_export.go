@@ -438,23 +438,38 @@ func ExportSnapshotV3(db database.Database, consensus *cns.ConsensusEngine, chai
 
 	// Genesis storeview
 	genesisSV := state.NewStoreView(genesisBlockHeader.Height, genesisBlockHeader.StateHash, db)
-	writeStoreViewV3(genesisSV, false, writer, db, common.Hash{})
+	if err = writeStoreViewV3(genesisSV, false, writer, db, common.Hash{}, nil); err != nil {
+		return "", err
+	}
 
 	// Last checkpoint storeview
 	if lastFinalizedBlock.Height != lastCheckpointHeight {
 		lastCheckpointSV := state.NewStoreView(lastCheckpointBlock.Height, lastCheckpointBlock.StateHash, db)
-		writeStoreViewV3(lastCheckpointSV, false, writer, db, genesisSV.Hash())
+		if err = writeStoreViewV3(lastCheckpointSV, false, writer, db, genesisSV.Hash(), nil); err != nil {
+			return "", err
+		}
 	}
 
 	// Parent block storeview
 	parentSV := state.NewStoreView(parentBlock.Height, parentBlock.StateHash, db)
-	writeStoreViewV3(parentSV, false, writer, db, genesisSV.Hash())
-	writeStoreViewV3(sv, true, writer, db, parentSV.Hash())
+	if err = writeStoreViewV3(parentSV, false, writer, db, genesisSV.Hash(), nil); err != nil {
+		return "", err
+	}
+	if err = writeStoreViewV3(sv, true, writer, db, parentSV.Hash(), nil); err != nil {
+		return "", err
+	}
 
 	return filename, nil
 }
 
 func ExportSnapshotV4(db database.Database, consensus *cns.ConsensusEngine, chain *blockchain.Chain, snapshotDir string, height uint64) (string, error) {
+	return ExportSnapshotV4WithProgress(db, consensus, chain, snapshotDir, height, nil)
+}
+
+// ExportSnapshotV4WithProgress is the same as ExportSnapshotV4, except that it reports its
+// progress to, and can be cancelled through, the given ExportProgress. The partially written
+// snapshot file is removed if the export is cancelled.
+func ExportSnapshotV4WithProgress(db database.Database, consensus *cns.ConsensusEngine, chain *blockchain.Chain, snapshotDir string, height uint64, progress *ExportProgress) (string, error) {
 	var lastFinalizedBlock *core.ExtendedBlock
 	if height != 0 {
 		blocks := chain.FindBlocksByHeight(height)
@@ -486,7 +501,7 @@ func ExportSnapshotV4(db database.Database, consensus *cns.ConsensusEngine, chai
 		return "", err
 	}
 	defer file.Close()
-	writer := bufio.NewWriter(file)
+	writer := bufio.NewWriter(progress.writer(file))
 
 	// --------------- Export the Header Section --------------- //
 
@@ -566,17 +581,35 @@ func ExportSnapshotV4(db database.Database, consensus *cns.ConsensusEngine, chai
 	}
 
 	// -------------- Export the StoreView Section -------------- //
-	// Last checkpoint storeview
+	// The full state tries dominate the export time, the state diff and the account
+	// storage of the last finalized block are estimated to take a fraction of it.
+	fullTrieStageEnd := 0.9
 	if lastFinalizedBlock.Height != lastCheckpointHeight {
+		// Last checkpoint storeview
+		progress.setStage("last checkpoint state", 0, 0.45)
+		progress.setHeight(lastCheckpointBlock.Height)
 		lastCheckpointSV := state.NewStoreView(lastCheckpointBlock.Height, lastCheckpointBlock.StateHash, db)
-		writeStoreViewV3(lastCheckpointSV, false, writer, db, common.Hash{})
+		if err = writeStoreViewV3(lastCheckpointSV, false, writer, db, common.Hash{}, progress); err != nil {
+			return "", removeCancelledExport(file, snapshotPath, err)
+		}
+		progress.setStage("parent block state", 0.45, fullTrieStageEnd)
+	} else {
+		progress.setStage("parent block state", 0, fullTrieStageEnd)
 	}
 
 	// Parent block storeview
+	progress.setHeight(parentBlock.Height)
 	parentSV := state.NewStoreView(parentBlock.Height, parentBlock.StateHash, db)
-	writeStoreViewV3(parentSV, false, writer, db, common.Hash{})
+	if err = writeStoreViewV3(parentSV, false, writer, db, common.Hash{}, progress); err != nil {
+		return "", removeCancelledExport(file, snapshotPath, err)
+	}
 
-	writeStoreViewV3(sv, true, writer, db, parentSV.Hash())
+	progress.setStage("last finalized block state", fullTrieStageEnd, 1)
+	progress.setHeight(sv.Height())
+	if err = writeStoreViewV3(sv, true, writer, db, parentSV.Hash(), progress); err != nil {
+		return "", removeCancelledExport(file, snapshotPath, err)
+	}
+	progress.setStageFraction(1)
 
 	return filename, nil
 }
@@ -663,28 +696,42 @@ func writeStoreView(sv *state.StoreView, needAccountStorage bool, writer *bufio.
 	writer.Flush()
 }
 
-func writeStoreViewV3(sv *state.StoreView, needAccountStorage bool, writer *bufio.Writer, db database.Database, base common.Hash) {
-	writeTrie(sv.Hash(), writer, db, base)
+// writeStoreViewV3 writes the nodes of the state trie of sv which are not in the base trie,
+// followed by the account storage tries if needAccountStorage is set. It returns
+// ErrExportCancelled if the export is cancelled through progress.
+func writeStoreViewV3(sv *state.StoreView, needAccountStorage bool, writer *bufio.Writer, db database.Database, base common.Hash, progress *ExportProgress) error {
+	if err := writeTrie(sv.Hash(), writer, db, base, progress, true); err != nil {
+		return err
+	}
 
 	if needAccountStorage {
+		var err error
 		sv.GetStore().Traverse(nil, func(k, v common.Bytes) bool {
 			if needAccountStorage && bytes.HasPrefix(k, []byte("ls/a")) {
 				account := &types.Account{}
-				err := types.FromBytes([]byte(v), account)
-				if err != nil {
+				if perr := types.FromBytes([]byte(v), account); perr != nil {
 					logger.Errorf("Failed to parse account for %v", []byte(v))
-					panic(err)
+					panic(perr)
 				}
-				if account.Root != (common.Hash{}) {
-					writeTrie(account.Root, writer, db, common.Hash{})
+				if progress.Cancelled() {
+					err = ErrExportCancelled
+				} else if account.Root != (common.Hash{}) {
+					err = writeTrie(account.Root, writer, db, common.Hash{}, progress, false)
 				}
 			}
-			return true
+			return err == nil
 		})
+		return err
 	}
+	return nil
 }
 
-func writeTrie(root common.Hash, writer *bufio.Writer, db database.Database, base common.Hash) {
+// writeTrie writes the nodes of the trie with the given root which are not in the base trie.
+// If trackFraction is set, the fraction of the current stage in progress is updated with
+// the position of the trie iterator.
+func writeTrie(root common.Hash, writer *bufio.Writer, db database.Database, base common.Hash, progress *ExportProgress, trackFraction bool) error {
+	progress.setTrie(root)
+
 	tr, err := trie.New(root, trie.NewDatabase(db))
 	if err != nil {
 		log.Panic(err)
@@ -699,7 +746,18 @@ func writeTrie(root common.Hash, writer *bufio.Writer, db database.Database, bas
 	} else {
 		it = tr.NodeIterator(nil)
 	}
+	numNodes := 0
 	for it.Next(true) {
+		numNodes++
+		if numNodes%progressCheckInterval == 0 {
+			if progress.Cancelled() {
+				writer.Flush()
+				return ErrExportCancelled
+			}
+			if trackFraction {
+				progress.setStageFraction(trieIteratorFraction(it.Path()))
+			}
+		}
 		if it.Hash() != (common.Hash{}) {
 			hash := it.Hash()
 			val, err := db.Get(hash.Bytes())
@@ -713,4 +771,15 @@ func writeTrie(root common.Hash, writer *bufio.Writer, db database.Database, bas
 		}
 	}
 	writer.Flush()
+	return nil
+}
+
+// removeCancelledExport removes the partially written export file if the export was
+// cancelled. It returns err unchanged.
+func removeCancelledExport(file *os.File, filePath string, err error) error {
+	if err == ErrExportCancelled {
+		file.Close()
+		os.Remove(filePath)
+	}
+	return err
 }
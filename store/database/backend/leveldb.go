@@ -49,6 +49,8 @@ type LDBDatabase struct {
 	db    *leveldb.DB // LevelDB instance
 	refdb *leveldb.DB // LevelDB instance for references
 
+	refLock *sync.Mutex // Mutex serializing the read-modify-write updates of the references
+
 	compTimeMeter    metrics.Meter // Meter for measuring the total time spent in database compaction
 	compReadMeter    metrics.Meter // Meter for measuring the data read during compaction
 	compWriteMeter   metrics.Meter // Meter for measuring the data written during compaction
@@ -57,6 +59,9 @@ type LDBDatabase struct {
 	diskReadMeter    metrics.Meter // Meter for measuring the effective amount of data read
 	diskWriteMeter   metrics.Meter // Meter for measuring the effective amount of data written
 
+	getTimer metrics.Timer // Timer for measuring the latency of the Get operations
+	putTimer metrics.Timer // Timer for measuring the latency of the Put operations
+
 	quitLock sync.Mutex      // Mutex protecting the quit channel access
 	quitChan chan chan error // Quit channel to stop the metrics collection before closing the database
 }
@@ -103,9 +108,13 @@ func NewLDBDatabase(file string, reffile string, cache int, handles int) (*LDBDa
 	}
 
 	return &LDBDatabase{
-		fn:    file,
-		db:    db,
-		refdb: refdb,
+		fn:      file,
+		db:      db,
+		refdb:   refdb,
+		refLock: &sync.Mutex{},
+
+		getTimer: metrics.GetOrRegisterTimer("db/get", nil),
+		putTimer: metrics.GetOrRegisterTimer("db/put", nil),
 	}, nil
 }
 
@@ -116,6 +125,7 @@ func (db *LDBDatabase) Path() string {
 
 // Put puts the given key / value to the queue
 func (db *LDBDatabase) Put(key []byte, value []byte) error {
+	defer db.putTimer.UpdateSince(time.Now())
 	return db.db.Put(key, value, nil)
 }
 
@@ -125,6 +135,7 @@ func (db *LDBDatabase) Has(key []byte) (bool, error) {
 
 // Get returns the given key if it's present.
 func (db *LDBDatabase) Get(key []byte) ([]byte, error) {
+	defer db.getTimer.UpdateSince(time.Now())
 	dat, err := db.db.Get(key, nil)
 	if err != nil {
 		if err == leveldb.ErrNotFound {
@@ -158,6 +169,9 @@ func (db *LDBDatabase) Reference(key []byte) error {
 		return store.ErrKeyNotFound
 	}
 
+	db.refLock.Lock()
+	defer db.refLock.Unlock()
+
 	var ref int
 	dat, err := db.refdb.Get(key, nil)
 	if err != nil {
@@ -188,6 +202,9 @@ func (db *LDBDatabase) Dereference(key []byte) error {
 		return store.ErrKeyNotFound
 	}
 
+	db.refLock.Lock()
+	defer db.refLock.Unlock()
+
 	var ref int
 	dat, err := db.refdb.Get(key, nil)
 	if err != nil {
@@ -468,12 +485,13 @@ func (db *LDBDatabase) meter(refresh time.Duration) {
 }
 
 func (db *LDBDatabase) NewBatch() database.Batch {
-	return &ldbBatch{db: db.db, refdb: db.refdb, b: new(leveldb.Batch), references: make(map[string]int)}
+	return &ldbBatch{db: db.db, refdb: db.refdb, refLock: db.refLock, b: new(leveldb.Batch), references: make(map[string]int)}
 }
 
 type ldbBatch struct {
 	db         *leveldb.DB
 	refdb      *leveldb.DB
+	refLock    *sync.Mutex
 	b          *leveldb.Batch
 	references map[string]int
 	size       int
@@ -517,6 +535,9 @@ func (b *ldbBatch) Write() error {
 		}
 	}
 
+	b.refLock.Lock()
+	defer b.refLock.Unlock()
+
 	for k, v := range b.references {
 		var ref int
 		dat, err := b.refdb.Get([]byte(k), nil)
@@ -151,3 +151,29 @@ func get(tn node, key []byte) ([]byte, node) {
 		}
 	}
 }
+
+// ProofList collects the encoded nodes of a merkle proof in order. It can be passed to
+// Prove to construct a proof, and to VerifyProof to verify it.
+type ProofList []common.Bytes
+
+// Put implements the database.Putter interface.
+func (pl *ProofList) Put(key []byte, value []byte) error {
+	*pl = append(*pl, value)
+	return nil
+}
+
+// Get implements the DatabaseReader interface, looking up the node by its hash.
+func (pl ProofList) Get(key []byte) ([]byte, error) {
+	for _, node := range pl {
+		if bytes.Equal(crypto.Keccak256(node), key) {
+			return node, nil
+		}
+	}
+	return nil, fmt.Errorf("proof node %x missing", key)
+}
+
+// Has implements the DatabaseReader interface.
+func (pl ProofList) Has(key []byte) (bool, error) {
+	_, err := pl.Get(key)
+	return err == nil, nil
+}